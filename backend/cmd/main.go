@@ -1,22 +1,38 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xiajiayi/ai-motion/internal/application/service"
 	"github.com/xiajiayi/ai-motion/internal/domain/character"
+	"github.com/xiajiayi/ai-motion/internal/domain/media"
 	"github.com/xiajiayi/ai-motion/internal/domain/novel"
 	"github.com/xiajiayi/ai-motion/internal/domain/scene"
+	"github.com/xiajiayi/ai-motion/internal/domain/usage"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai"
 	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai/gemini"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai/mock"
 	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai/sora"
 	"github.com/xiajiayi/ai-motion/internal/infrastructure/config"
 	"github.com/xiajiayi/ai-motion/internal/infrastructure/database"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/imaging"
 	infra_middleware "github.com/xiajiayi/ai-motion/internal/infrastructure/middleware"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/queue"
 	"github.com/xiajiayi/ai-motion/internal/infrastructure/repository/supabase"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/storage"
 	"github.com/xiajiayi/ai-motion/internal/infrastructure/storage/local"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/storage/s3"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/video"
 	"github.com/xiajiayi/ai-motion/internal/interfaces/http/handler"
 	"github.com/xiajiayi/ai-motion/internal/interfaces/http/middleware"
 )
@@ -32,25 +48,52 @@ func main() {
 	var sceneHandler *handler.SceneHandler
 	var generationHandler *handler.GenerationHandler
 	var mangaWorkflowHandler *handler.MangaWorkflowHandler
+	var mediaHandler *handler.MediaHandler
+	var cacheHandler *handler.CacheHandler
+	var usageHandler *handler.UsageHandler
+	var usageService *service.UsageService
+	var workerPool *queue.WorkerPool
+	var videoPoller *queue.MediaPoller
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	geminiBaseURL := os.Getenv("GEMINI_BASE_URL")
 	geminiAPIKey := os.Getenv("GEMINI_API_KEY")
 	soraBaseURL := os.Getenv("SORA_BASE_URL")
 	soraAPIKey := os.Getenv("SORA_API_KEY")
-	storagePath := os.Getenv("STORAGE_PATH")
-	if storagePath == "" {
-		storagePath = "./storage"
+
+	retryPolicy := ai.RetryPolicy{
+		MaxAttempts:    cfg.AI.Retry.MaxAttempts,
+		BaseDelay:      cfg.AI.Retry.BaseDelay,
+		MaxDelay:       cfg.AI.Retry.MaxDelay,
+		AttemptTimeout: cfg.AI.Retry.RequestTimeout,
+	}
+
+	// 熔断器在限流之内，排队中的调用获得并发名额后仍会先检查熔断状态
+	breakers := ai.NewBreakers(ai.BreakerConfig{
+		FailureThreshold: cfg.AI.Breaker.FailureThreshold,
+		Cooldown:         cfg.AI.Breaker.Cooldown,
+	})
+
+	// 计量在熔断之内，只记录实际发往服务的调用；用量账本依赖数据库，连接建立前不记账
+	meter := func(ctx context.Context, record ai.CallRecord) {
+		if usageService != nil {
+			usageService.Record(ctx, record)
+		}
 	}
 
-	var geminiClient *gemini.Client
-	var soraClient *sora.Client
+	imageProviders := ai.NewImageRegistry()
+	var videoGenerator ai.VideoGenerator
+	var mockClient *mock.Client
 
 	if geminiBaseURL != "" && geminiAPIKey != "" {
-		client, clientErr := gemini.NewClient(geminiBaseURL, geminiAPIKey)
+		client, clientErr := gemini.NewClient(geminiBaseURL, geminiAPIKey, retryPolicy)
 		if clientErr != nil {
 			log.Printf("Warning: Failed to initialize Gemini client: %v", clientErr)
 		} else {
-			geminiClient = client
+			breaker := breakers.For(gemini.ProviderName)
+			imageProviders.Register(ai.LimitImageGenerator(ai.BreakImageGenerator(ai.MeterImageGenerator(client, meter), breaker), cfg.Queue.ProviderLimits[gemini.ProviderName]))
 			log.Printf("Gemini client initialized (baseURL: %s)", geminiBaseURL)
 		}
 	} else {
@@ -58,30 +101,95 @@ func main() {
 	}
 
 	if soraBaseURL != "" && soraAPIKey != "" {
-		client, clientErr := sora.NewClient(soraBaseURL, soraAPIKey)
+		client, clientErr := sora.NewClient(soraBaseURL, soraAPIKey, retryPolicy)
 		if clientErr != nil {
 			log.Printf("Warning: Failed to initialize Sora client: %v", clientErr)
 		} else {
-			soraClient = client
+			breaker := breakers.For(sora.ProviderName)
+			videoGenerator = ai.LimitVideoGenerator(ai.BreakVideoGenerator(ai.MeterVideoGenerator(client, meter), breaker), cfg.Queue.ProviderLimits[sora.ProviderName])
 			log.Printf("Sora client initialized (baseURL: %s)", soraBaseURL)
 		}
 	} else {
 		log.Println("SORA_BASE_URL or SORA_API_KEY not set, AI video generation will be unavailable")
 	}
 
-	fileStorage, storageErr := local.NewFileStorage(storagePath)
+	if cfg.AI.Mock.Enabled {
+		client, clientErr := mock.NewClient(cfg.AI.Mock.OutputDir, cfg.AI.Mock.PublicURL)
+		if clientErr != nil {
+			log.Printf("Warning: Failed to initialize mock AI client: %v", clientErr)
+		} else {
+			mockClient = client
+			mockLimit := cfg.Queue.ProviderLimits[mock.ProviderName]
+			breaker := breakers.For(mock.ProviderName)
+			imageProviders.Register(ai.LimitImageGenerator(ai.BreakImageGenerator(ai.MeterImageGenerator(client, meter), breaker), mockLimit))
+			if videoGenerator == nil || cfg.AI.VideoProvider == mock.ProviderName {
+				videoGenerator = ai.LimitVideoGenerator(ai.BreakVideoGenerator(ai.MeterVideoGenerator(client, meter), breaker), mockLimit)
+			}
+			log.Printf("Mock AI client initialized (output: %s)", cfg.AI.Mock.OutputDir)
+		}
+	}
+
+	if !imageProviders.IsEmpty() {
+		if err := imageProviders.SetDefault(cfg.AI.ImageProvider); err != nil {
+			log.Printf("Warning: Image provider %q not available, using fallback: %v", cfg.AI.ImageProvider, err)
+		}
+		for style, name := range cfg.AI.ImageStyleRoutes {
+			if err := imageProviders.RouteStyle(style, name); err != nil {
+				log.Printf("Warning: Failed to route style %q: %v", style, err)
+			}
+		}
+	}
+
+	// 生成的图片和视频保存到存储后通过 cfg.Storage.PublicURL 前缀访问
+	fileStorage, storageErr := newFileStorage(cfg.Storage)
 	if storageErr != nil {
-		log.Printf("Warning: Failed to initialize file storage: %v", storageErr)
-	} else {
-		log.Printf("File storage initialized at %s", storagePath)
-		_ = fileStorage
+		log.Fatalf("Failed to initialize file storage: %v", storageErr)
 	}
+	assetStore := storage.NewAssetStore(fileStorage, cfg.Storage.PublicURL)
+	if mockClient != nil {
+		assetStore.MountDir(cfg.AI.Mock.PublicURL, cfg.AI.Mock.OutputDir)
+	}
+
+	// 响应中的文件和媒体地址附加有时效的签名，浏览器加载时无需 Authorization 头
+	if cfg.Storage.URLSecret == "" {
+		log.Printf("Warning: STORAGE_URL_SECRET not set, signed file URLs will not survive restarts or work across instances")
+	}
+	urlSigner := storage.NewURLSigner(cfg.Storage.URLSecret, cfg.Storage.URLTTL)
+	fileHandler := handler.NewFileHandler(assetStore, urlSigner)
+
+	// 页面嵌字使用的字体，没有 CJK 字体时中文台词会显示为方框
+	fonts, fontErr := imaging.LoadFonts(cfg.Lettering.FontPaths)
+	if fontErr != nil {
+		log.Fatalf("Failed to load lettering fonts: %v", fontErr)
+	}
+	if !fonts.HasCJK() {
+		log.Printf("Warning: No CJK font found for lettering, set LETTERING_FONT_PATHS to a font such as NotoSansCJK-Regular.ttc")
+	}
+	letterer := imaging.NewLetterer(fonts)
+
+	// 动态分镜导出的渲染方式，没有 ffmpeg 时 auto 退回图片序列
+	videoEncoder, encoderErr := video.NewEncoder(cfg.Video.Encoder, cfg.Video.FFmpegPath)
+	if encoderErr != nil {
+		log.Fatalf("Failed to initialize video encoder: %v", encoderErr)
+	}
+
+	// 台词配音使用的语音合成服务，mock 离线生成音调，不需要 API Key
+	var speechSynthesizer ai.SpeechSynthesizer
+	switch cfg.AI.SpeechProvider {
+	case mock.ProviderName:
+		speechSynthesizer = ai.MeterSpeechSynthesizer(mock.NewSpeechSynthesizer(), meter)
+	default:
+		log.Printf("Warning: Speech provider %q not available, dialogue voice-over will be unavailable", cfg.AI.SpeechProvider)
+	}
+	providerHandler := handler.NewProviderHandler(breakers)
 
 	log.Println("=== Service Initialization ===")
 	log.Printf("Supabase URL configured: %v", cfg.Supabase.URL != "")
 	log.Printf("Supabase API Key configured: %v", cfg.Supabase.APIKey != "")
-	log.Printf("Gemini client available: %v", geminiClient != nil)
-	log.Printf("Sora client available: %v", soraClient != nil)
+	log.Printf("Image providers available: %v", imageProviders.Names())
+	log.Printf("Video provider available: %v", videoGenerator != nil)
+	log.Printf("Animatic encoder: %s", videoEncoder.Name())
+	log.Printf("Speech provider available: %v", speechSynthesizer != nil)
 
 	if cfg.Supabase.URL != "" && cfg.Supabase.APIKey != "" {
 		supabaseCfg := &database.SupabaseConfig{
@@ -102,13 +210,24 @@ func main() {
 			sceneRepo := supabase.NewSceneRepository(supabaseClient)
 			mediaRepo := supabase.NewMediaRepository(supabaseClient)
 			taskRepo := supabase.NewTaskRepository(supabaseClient)
+			taskEventRepo := supabase.NewTaskEventRepository(supabaseClient)
+			taskPanelRepo := supabase.NewTaskPanelRepository(supabaseClient)
+			taskPageRepo := supabase.NewTaskPageRepository(supabaseClient)
+			taskExportRepo := supabase.NewTaskExportRepository(supabaseClient)
+			taskVoiceoverRepo := supabase.NewTaskVoiceoverRepository(supabaseClient)
+			usageRepo := supabase.NewUsageRepository(supabaseClient)
+			workerPool = queue.NewWorkerPool(queue.Config{
+				Workers:       cfg.Queue.Workers,
+				PollInterval:  cfg.Queue.PollInterval,
+				LeaseDuration: cfg.Queue.LeaseDuration,
+			}, taskRepo, mediaRepo, taskExportRepo)
 
 			parserService := novel.NewParserService()
 			novelService := service.NewNovelService(novelRepo, chapterRepo, parserService)
 			novelHandler = handler.NewNovelHandler(novelService)
 
 			extractorService := character.NewCharacterExtractorService(characterRepo)
-			characterService := service.NewCharacterService(characterRepo, novelRepo, extractorService)
+			characterService := service.NewCharacterService(characterRepo, novelRepo, extractorService, urlSigner)
 			characterHandler = handler.NewCharacterHandler(characterService)
 
 			dividerService := scene.NewSceneDividerService(sceneRepo)
@@ -116,17 +235,44 @@ func main() {
 			sceneService := service.NewSceneService(sceneRepo, chapterRepo, characterRepo, dividerService, promptGeneratorService)
 			sceneHandler = handler.NewSceneHandler(sceneService)
 
-			if geminiClient != nil && soraClient != nil {
-				generationService := service.NewGenerationService(mediaRepo, sceneRepo, geminiClient, soraClient)
+			mediaService := service.NewMediaService(mediaRepo, sceneRepo, taskRepo, assetStore)
+			mediaHandler = handler.NewMediaHandler(mediaService, urlSigner)
+
+			usageService = service.NewUsageService(usageRepo, usage.Pricing{
+				ImagePrices:  cfg.Usage.ImagePrices,
+				VideoPrices:  cfg.Usage.VideoPrices,
+				SpeechPrices: cfg.Usage.SpeechPrices,
+			}, usage.Quota{
+				DailyGenerations: cfg.Usage.DailyGenerationLimit,
+				MonthlyCost:      cfg.Usage.MonthlyCostLimit,
+			})
+			usageHandler = handler.NewUsageHandler(usageService)
+
+			generationCache := service.NewGenerationCache(mediaRepo)
+			cacheHandler = handler.NewCacheHandler(generationCache)
+
+			if !imageProviders.IsEmpty() && videoGenerator != nil {
+				generationService := service.NewGenerationService(mediaRepo, sceneRepo, imageProviders, videoGenerator, assetStore, generationCache, usageService, urlSigner)
 				generationHandler = handler.NewGenerationHandler(generationService)
+				workerPool.HandleMedia(generationService.ProcessMedia)
+				videoPoller = queue.NewMediaPoller(queue.PollerConfig{
+					Interval:   cfg.Queue.VideoPollInterval,
+					MinBackoff: cfg.Queue.VideoPollInterval,
+					MaxBackoff: cfg.Queue.VideoPollMaxBackoff,
+				}, workerPool.Owner(), mediaRepo, media.MediaTypeVideo, generationService.PollVideo)
 				log.Println("Generation service initialized")
 			} else {
 				log.Println("AI clients not available, generation service disabled")
 			}
 
-			if geminiClient != nil {
+			if !imageProviders.IsEmpty() {
 				mangaWorkflowService := service.NewMangaWorkflowService(
 					taskRepo,
+					taskEventRepo,
+					taskPanelRepo,
+					taskPageRepo,
+					taskExportRepo,
+					taskVoiceoverRepo,
 					novelRepo,
 					chapterRepo,
 					characterRepo,
@@ -135,12 +281,25 @@ func main() {
 					parserService,
 					extractorService,
 					dividerService,
-					geminiClient,
+					promptGeneratorService,
+					imageProviders,
+					assetStore,
+					generationCache,
+					usageService,
+					letterer,
+					videoEncoder,
+					speechSynthesizer,
+					urlSigner,
 				)
 				mangaWorkflowHandler = handler.NewMangaWorkflowHandler(mangaWorkflowService)
+				workerPool.HandleTasks(mangaWorkflowService.ExecuteTask)
+				workerPool.HandleExports(mangaWorkflowService.ExecuteExport)
+				if err := mangaWorkflowService.RecoverInterruptedTasks(ctx, cfg.Queue.ResumeMaxAge); err != nil {
+					log.Printf("Warning: Failed to recover interrupted tasks: %v", err)
+				}
 				log.Println("✓ Manga workflow service initialized")
 			} else {
-				log.Println("✗ Manga workflow DISABLED: Gemini client not available")
+				log.Println("✗ Manga workflow DISABLED: no image provider available")
 			}
 		}
 	} else {
@@ -153,6 +312,7 @@ func main() {
 	log.Printf("Scene Handler: %v", sceneHandler != nil)
 	log.Printf("Generation Handler: %v", generationHandler != nil)
 	log.Printf("Manga Workflow Handler: %v", mangaWorkflowHandler != nil)
+	log.Printf("Media Handler: %v", mediaHandler != nil)
 	log.Println("=============================")
 
 	r := gin.New()
@@ -173,10 +333,27 @@ func main() {
 		log.Println("Warning: SUPABASE_JWT_SECRET not configured, authentication disabled")
 	}
 
+	if mockClient != nil {
+		r.Static("/mock-assets", cfg.AI.Mock.OutputDir)
+	}
+
+	// 公开 URL 为外部地址（如 CDN）时仍在同一路径下提供文件，只接受签名地址
+	if publicURL, err := url.Parse(cfg.Storage.PublicURL); err == nil && publicURL.Path != "" && publicURL.Path != "/" {
+		filesPath := strings.TrimRight(publicURL.Path, "/") + "/*filepath"
+		r.GET(filesPath, fileHandler.Serve)
+		r.HEAD(filesPath, fileHandler.Serve)
+	}
+
+	// AI 服务熔断时服务本身仍可用，状态标记为 degraded
 	r.GET("/health", func(c *gin.Context) {
+		status := "ok"
+		if !breakers.Healthy() {
+			status = "degraded"
+		}
 		c.JSON(http.StatusOK, gin.H{
-			"status":  "ok",
-			"service": "ai-motion",
+			"status":    status,
+			"service":   "ai-motion",
+			"providers": breakers.States(),
 		})
 	})
 
@@ -230,6 +407,9 @@ func main() {
 
 		if generationHandler != nil {
 			generateGroup := v1.Group("/generate")
+			if authMiddleware != nil {
+				generateGroup.Use(authMiddleware.SupabaseAuth())
+			}
 			{
 				generateGroup.POST("/image", generationHandler.GenerateImage)
 				generateGroup.POST("/video", generationHandler.GenerateVideo)
@@ -254,6 +434,18 @@ func main() {
 				mangaGroup.GET("/task/:task_id", mangaWorkflowHandler.GetTaskStatus)
 				mangaGroup.GET("/tasks", mangaWorkflowHandler.GetTaskList)
 				mangaGroup.POST("/task/:task_id/cancel", mangaWorkflowHandler.CancelTask)
+				mangaGroup.POST("/task/:task_id/retry", mangaWorkflowHandler.RetryTask)
+				mangaGroup.GET("/task/:task_id/events", mangaWorkflowHandler.StreamTaskEvents)
+				mangaGroup.GET("/task/:task_id/panels", mangaWorkflowHandler.ListPanels)
+				mangaGroup.POST("/task/:task_id/pages", mangaWorkflowHandler.ComposePages)
+				mangaGroup.GET("/task/:task_id/pages", mangaWorkflowHandler.ListPages)
+				mangaGroup.POST("/task/:task_id/export", mangaWorkflowHandler.RequestExport)
+				mangaGroup.GET("/task/:task_id/exports/:export_id", mangaWorkflowHandler.GetExport)
+				mangaGroup.POST("/task/:task_id/voiceover", mangaWorkflowHandler.GenerateVoiceover)
+				mangaGroup.GET("/task/:task_id/voiceover", mangaWorkflowHandler.GetVoiceover)
+				mangaGroup.POST("/task/:task_id/panels/:media_id/regenerate", mangaWorkflowHandler.RegeneratePanel)
+				mangaGroup.GET("/task/:task_id/panels/:media_id/versions", mangaWorkflowHandler.ListPanelVersions)
+				mangaGroup.POST("/task/:task_id/panels/:media_id/activate", mangaWorkflowHandler.ActivatePanelVersion)
 			}
 		} else {
 			v1.POST("/manga/generate", func(c *gin.Context) {
@@ -263,14 +455,102 @@ func main() {
 			})
 		}
 
-		v1.POST("/anime/:id/export", func(c *gin.Context) {
-			c.JSON(http.StatusOK, gin.H{"message": "export anime endpoint - coming soon"})
-		})
+		if mediaHandler != nil {
+			mediaGroup := v1.Group("/media")
+			if authMiddleware != nil {
+				// 带有效签名的地址供 <img>/<video> 直接加载，无需 Authorization 头
+				mediaGroup.Use(mediaHandler.Authenticate(authMiddleware.SupabaseAuth()))
+			}
+			{
+				mediaGroup.GET("/:id/content", mediaHandler.GetContent)
+				mediaGroup.GET("/:id/thumbnail", mediaHandler.GetThumbnail)
+			}
+		}
+
+		if usageHandler != nil {
+			usageGroup := v1.Group("/usage")
+			if authMiddleware != nil {
+				usageGroup.Use(authMiddleware.SupabaseAuth())
+			}
+			{
+				usageGroup.GET("", usageHandler.GetUsage)
+			}
+		}
+
+		// 管理接口只对管理员开放，未启用认证时一律拒绝
+		adminGroup := v1.Group("/admin")
+		if authMiddleware != nil {
+			adminGroup.Use(authMiddleware.SupabaseAuth())
+		}
+		adminGroup.Use(infra_middleware.RequireRole(infra_middleware.RoleAdmin))
+		{
+			adminGroup.GET("/providers", providerHandler.List)
+			if cacheHandler != nil {
+				adminGroup.GET("/cache", cacheHandler.Stats)
+			}
+		}
+	}
+
+	if workerPool != nil {
+		workerPool.Start(ctx)
+	}
+	if videoPoller != nil {
+		videoPoller.Start(ctx)
 	}
 
 	serverAddr := ":" + cfg.Server.Port
-	log.Printf("Starting AI-Motion server on %s", serverAddr)
-	if err := r.Run(serverAddr); err != nil {
-		log.Fatal("Failed to start server:", err)
+	server := &http.Server{
+		Addr:    serverAddr,
+		Handler: r,
+	}
+
+	go func() {
+		log.Printf("Starting AI-Motion server on %s", serverAddr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Failed to start server:", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down server...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server shutdown error: %v", err)
+	}
+
+	// 等待 worker 退出，未完成的任务会在租约过期后被重新领取
+	if workerPool != nil {
+		workerPool.Wait()
+	}
+	if videoPoller != nil {
+		videoPoller.Wait()
+	}
+}
+
+// newFileStorage 按配置创建文件存储：local 为本地目录（单实例），s3 为 S3 兼容对象存储（多实例共享）
+func newFileStorage(cfg config.StorageConfig) (storage.Storage, error) {
+	if cfg.Backend == "s3" {
+		objectStorage, err := s3.NewStorage(s3.Config{
+			Endpoint:        cfg.S3.Endpoint,
+			Region:          cfg.S3.Region,
+			Bucket:          cfg.S3.Bucket,
+			AccessKeyID:     cfg.S3.AccessKeyID,
+			SecretAccessKey: cfg.S3.SecretAccessKey,
+			UsePathStyle:    cfg.S3.UsePathStyle,
+		})
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("File storage initialized: s3 bucket %s at %s (public URL: %s)", cfg.S3.Bucket, cfg.S3.Endpoint, cfg.PublicURL)
+		return objectStorage, nil
+	}
+
+	fileStorage, err := local.NewFileStorage(cfg.Path)
+	if err != nil {
+		return nil, err
 	}
+	log.Printf("File storage initialized at %s (public URL: %s)", cfg.Path, cfg.PublicURL)
+	return fileStorage, nil
 }
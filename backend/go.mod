@@ -9,6 +9,7 @@ require (
 	github.com/golang-migrate/migrate/v4 v4.19.0
 	github.com/google/uuid v1.6.0
 	github.com/supabase-community/postgrest-go v0.0.11
+	golang.org/x/image v0.29.0
 )
 
 require (
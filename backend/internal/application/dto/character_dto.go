@@ -3,16 +3,17 @@ package dto
 import "time"
 
 type CharacterResponse struct {
-	ID                string              `json:"id"`
-	NovelID           string              `json:"novel_id"`
-	Name              string              `json:"name"`
-	Role              string              `json:"role"`
-	Appearance        AppearanceResponse  `json:"appearance"`
-	Personality       PersonalityResponse `json:"personality"`
-	Description       string              `json:"description"`
-	ReferenceImageURL string              `json:"reference_image_url,omitempty"`
-	CreatedAt         time.Time           `json:"created_at"`
-	UpdatedAt         time.Time           `json:"updated_at"`
+	ID                string               `json:"id"`
+	NovelID           string               `json:"novel_id"`
+	Name              string               `json:"name"`
+	Role              string               `json:"role"`
+	Appearance        AppearanceResponse   `json:"appearance"`
+	Personality       PersonalityResponse  `json:"personality"`
+	Description       string               `json:"description"`
+	ReferenceImageURL string               `json:"reference_image_url,omitempty"`
+	Voice             VoiceProfileResponse `json:"voice"`
+	CreatedAt         time.Time            `json:"created_at"`
+	UpdatedAt         time.Time            `json:"updated_at"`
 }
 
 type AppearanceResponse struct {
@@ -29,13 +30,21 @@ type PersonalityResponse struct {
 	Background string `json:"background,omitempty"`
 }
 
+type VoiceProfileResponse struct {
+	Voice    string  `json:"voice,omitempty"`
+	Language string  `json:"language"`
+	Speed    float64 `json:"speed"`
+	Pitch    float64 `json:"pitch"`
+}
+
 type UpdateCharacterRequest struct {
-	Name              string                    `json:"name"`
-	Role              string                    `json:"role"`
-	Appearance        *UpdateAppearanceRequest  `json:"appearance,omitempty"`
-	Personality       *UpdatePersonalityRequest `json:"personality,omitempty"`
-	Description       string                    `json:"description,omitempty"`
-	ReferenceImageURL string                    `json:"reference_image_url,omitempty"`
+	Name              string                     `json:"name"`
+	Role              string                     `json:"role"`
+	Appearance        *UpdateAppearanceRequest   `json:"appearance,omitempty"`
+	Personality       *UpdatePersonalityRequest  `json:"personality,omitempty"`
+	Description       string                     `json:"description,omitempty"`
+	ReferenceImageURL string                     `json:"reference_image_url,omitempty"`
+	Voice             *UpdateVoiceProfileRequest `json:"voice,omitempty"`
 }
 
 type UpdateAppearanceRequest struct {
@@ -52,6 +61,13 @@ type UpdatePersonalityRequest struct {
 	Background string `json:"background"`
 }
 
+type UpdateVoiceProfileRequest struct {
+	Voice    string  `json:"voice"`
+	Language string  `json:"language"`
+	Speed    float64 `json:"speed"`
+	Pitch    float64 `json:"pitch"`
+}
+
 type ExtractCharactersRequest struct {
 	NovelID string `json:"novel_id" binding:"required"`
 }
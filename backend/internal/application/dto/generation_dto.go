@@ -3,14 +3,16 @@ package dto
 import "time"
 
 type GenerateImageRequest struct {
-	SceneID        string `json:"scene_id" binding:"required"`
-	Prompt         string `json:"prompt" binding:"required"`
-	NegativePrompt string `json:"negative_prompt"`
-	ReferenceImage string `json:"reference_image"`
-	Width          int    `json:"width"`
-	Height         int    `json:"height"`
-	Quality        string `json:"quality"`
-	Style          string `json:"style"`
+	SceneID         string `json:"scene_id" binding:"required"`
+	Prompt          string `json:"prompt" binding:"required"`
+	NegativePrompt  string `json:"negative_prompt"`
+	ReferenceImage  string `json:"reference_image"`
+	Width           int    `json:"width"`
+	Height          int    `json:"height"`
+	Quality         string `json:"quality"`
+	Style           string `json:"style"`
+	Seed            int64  `json:"seed"`             // 随机种子，0 表示由服务随机选择，不支持种子的服务忽略
+	ForceRegenerate bool   `json:"force_regenerate"` // 忽略参数相同的已生成结果，重新调用 AI 服务
 }
 
 type GenerateVideoRequest struct {
@@ -27,17 +29,38 @@ type BatchGenerateRequest struct {
 }
 
 type MediaResponse struct {
-	ID           string        `json:"id"`
-	SceneID      string        `json:"scene_id"`
-	Type         string        `json:"type"`
-	Status       string        `json:"status"`
-	URL          string        `json:"url"`
-	Metadata     MediaMetadata `json:"metadata"`
-	GenerationID string        `json:"generation_id"`
-	ErrorMessage string        `json:"error_message,omitempty"`
-	CreatedAt    time.Time     `json:"created_at"`
-	UpdatedAt    time.Time     `json:"updated_at"`
-	CompletedAt  *time.Time    `json:"completed_at,omitempty"`
+	ID           string                `json:"id"`
+	SceneID      string                `json:"scene_id"`
+	Type         string                `json:"type"`
+	Status       string                `json:"status"`
+	URL          string                `json:"url"`
+	ContentURL   string                `json:"content_url,omitempty"`   // 带签名的媒体内容接口地址，媒体完成后返回
+	ThumbnailURL string                `json:"thumbnail_url,omitempty"` // 带签名的缩略图接口地址，仅图片
+	Metadata     MediaMetadata         `json:"metadata"`
+	GenerationID string                `json:"generation_id"`
+	ErrorMessage string                `json:"error_message,omitempty"`
+	Cached       bool                  `json:"cached,omitempty"`     // 复用了参数相同的已生成结果
+	Generation   *GenerationProvenance `json:"generation,omitempty"` // 生成来源，未记录时省略
+	CreatedAt    time.Time             `json:"created_at"`
+	UpdatedAt    time.Time             `json:"updated_at"`
+	CompletedAt  *time.Time            `json:"completed_at,omitempty"`
+}
+
+// GenerationProvenance 生成媒体时使用的服务、模型和完整参数，可据此复现或微调
+type GenerationProvenance struct {
+	Provider       string  `json:"provider"`
+	Model          string  `json:"model,omitempty"`
+	Prompt         string  `json:"prompt"`
+	NegativePrompt string  `json:"negative_prompt,omitempty"`
+	Style          string  `json:"style,omitempty"`
+	Quality        string  `json:"quality,omitempty"`
+	Seed           int64   `json:"seed"`
+	Steps          int     `json:"steps,omitempty"`
+	CFGScale       float64 `json:"cfg_scale,omitempty"`
+	ReferenceImage string  `json:"reference_image,omitempty"`
+	Width          int     `json:"width,omitempty"`
+	Height         int     `json:"height,omitempty"`
+	RevisedPrompt  string  `json:"revised_prompt,omitempty"`
 }
 
 type MediaMetadata struct {
@@ -63,3 +86,11 @@ type BatchGenerateResponse struct {
 	Status      string    `json:"status"`
 	CreatedAt   time.Time `json:"created_at"`
 }
+
+// CacheStatsResponse 生成缓存的命中统计（本进程启动以来）
+type CacheStatsResponse struct {
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`
+	Bypassed int64   `json:"bypassed"` // 指定 force_regenerate 跳过缓存的次数
+	HitRate  float64 `json:"hit_rate"`
+}
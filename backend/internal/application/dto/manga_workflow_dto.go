@@ -6,15 +6,17 @@ import "time"
 
 // TaskStatusResponse 任务状态响应
 type TaskStatusResponse struct {
-	TaskID      string                 `json:"task_id"`
-	Status      string                 `json:"status"`
-	Progress    TaskProgressResponse   `json:"progress"`
-	Result      *TaskResultResponse    `json:"result,omitempty"`
-	Error       *TaskErrorResponse     `json:"error,omitempty"`
-	CreatedAt   time.Time              `json:"created_at"`
-	UpdatedAt   time.Time              `json:"updated_at"`
-	CompletedAt *time.Time             `json:"completed_at,omitempty"`
-	FailedAt    *time.Time             `json:"failed_at,omitempty"`
+	TaskID      string                `json:"task_id"`
+	Status      string                `json:"status"`
+	Progress    TaskProgressResponse  `json:"progress"`
+	Result      *TaskResultResponse   `json:"result,omitempty"`
+	Error       *TaskErrorResponse    `json:"error,omitempty"`
+	RetryCount  int                   `json:"retry_count"`
+	Attempts    []TaskAttemptResponse `json:"attempts,omitempty"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+	CompletedAt *time.Time            `json:"completed_at,omitempty"`
+	FailedAt    *time.Time            `json:"failed_at,omitempty"`
 }
 
 // TaskProgressResponse 任务进度响应
@@ -36,12 +38,12 @@ type TaskProgressDetailsResponse struct {
 
 // TaskResultResponse 任务结果响应
 type TaskResultResponse struct {
-	NovelID        string                       `json:"novel_id"`
-	Title          string                       `json:"title"`
-	CharacterCount int                          `json:"character_count"`
-	SceneCount     int                          `json:"scene_count"`
-	Characters     []TaskCharacterResponse      `json:"characters"`
-	Scenes         []TaskSceneResponse          `json:"scenes"`
+	NovelID        string                  `json:"novel_id"`
+	Title          string                  `json:"title"`
+	CharacterCount int                     `json:"character_count"`
+	SceneCount     int                     `json:"scene_count"`
+	Characters     []TaskCharacterResponse `json:"characters"`
+	Scenes         []TaskSceneResponse     `json:"scenes"`
 }
 
 // TaskCharacterResponse 任务中的角色信息（简化版）
@@ -53,32 +55,54 @@ type TaskCharacterResponse struct {
 
 // TaskSceneResponse 任务中的场景信息（简化版）
 type TaskSceneResponse struct {
-	ID          string `json:"id"`
-	SequenceNum int    `json:"sequence_num"`
-	Description string `json:"description"`
-	ImageURL    string `json:"image_url"`
+	ID           string `json:"id"`
+	SequenceNum  int    `json:"sequence_num"`
+	Description  string `json:"description"`
+	ImageURL     string `json:"image_url"`
+	MediaID      string `json:"media_id"`
+	ContentURL   string `json:"content_url"`   // 带签名的媒体内容接口地址
+	ThumbnailURL string `json:"thumbnail_url"` // 带签名的缩略图接口地址
+	Version      int    `json:"version"`       // 当前使用的版本号
+	VersionCount int    `json:"version_count"` // 面板的版本总数
 }
 
 // TaskErrorResponse 任务错误信息
 type TaskErrorResponse struct {
-	Code       int    `json:"code"`
-	Message    string `json:"message"`
-	RetryAble  bool   `json:"retry_able"`
+	Code      int    `json:"code"`
+	Message   string `json:"message"`
+	RetryAble bool   `json:"retry_able"`
+}
+
+// TaskAttemptResponse 任务历次失败的执行记录
+type TaskAttemptResponse struct {
+	Attempt         int       `json:"attempt"`
+	ErrorCode       int       `json:"error_code"`
+	ErrorMessage    string    `json:"error_message"`
+	ProgressStep    string    `json:"progress_step"`
+	ScenesGenerated int       `json:"scenes_generated"`
+	FailedAt        time.Time `json:"failed_at"`
+}
+
+// TaskRetryResponse 重试任务响应
+type TaskRetryResponse struct {
+	TaskID     string `json:"task_id"`
+	Status     string `json:"status"`
+	RetryCount int    `json:"retry_count"`
 }
 
 // TaskListItemResponse 任务列表项
 type TaskListItemResponse struct {
-	TaskID         string                `json:"task_id"`
-	Title          string                `json:"title"`
-	Status         string                `json:"status"`
-	Progress       TaskProgressResponse  `json:"progress"`
-	CharacterCount int                   `json:"character_count,omitempty"`
-	SceneCount     int                   `json:"scene_count,omitempty"`
-	Error          *TaskErrorResponse    `json:"error,omitempty"`
-	CreatedAt      time.Time             `json:"created_at"`
-	UpdatedAt      time.Time             `json:"updated_at"`
-	CompletedAt    *time.Time            `json:"completed_at,omitempty"`
-	FailedAt       *time.Time            `json:"failed_at,omitempty"`
+	TaskID         string               `json:"task_id"`
+	Title          string               `json:"title"`
+	Status         string               `json:"status"`
+	Progress       TaskProgressResponse `json:"progress"`
+	CharacterCount int                  `json:"character_count,omitempty"`
+	SceneCount     int                  `json:"scene_count,omitempty"`
+	Error          *TaskErrorResponse   `json:"error,omitempty"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+	CompletedAt    *time.Time           `json:"completed_at,omitempty"`
+	FailedAt       *time.Time           `json:"failed_at,omitempty"`
 }
 
 // PaginationResponse 分页响应
@@ -90,3 +114,119 @@ type PaginationResponse struct {
 	HasNext    bool `json:"has_next"`
 	HasPrev    bool `json:"has_prev"`
 }
+
+// RegeneratePanelRequest 重新生成单个面板的请求，字段均可省略，省略时沿用任务的生成参数
+type RegeneratePanelRequest struct {
+	Prompt         string `json:"prompt"`          // 替换自动生成的提示词
+	Seed           int64  `json:"seed"`            // 随机种子，0 表示由服务随机选择，不支持种子的服务忽略
+	ReferenceImage string `json:"reference_image"` // 替换角色参考图，需要图像服务支持参考图
+}
+
+// PanelVersionResponse 面板的一个图片版本
+type PanelVersionResponse struct {
+	Version      int                   `json:"version"`
+	MediaID      string                `json:"media_id"`
+	SequenceNum  int                   `json:"sequence_num"`
+	SceneID      string                `json:"scene_id"`
+	ImageURL     string                `json:"image_url"`
+	ContentURL   string                `json:"content_url"`   // 带签名的媒体内容接口地址
+	ThumbnailURL string                `json:"thumbnail_url"` // 带签名的缩略图接口地址
+	Active       bool                  `json:"active"`        // 是否为任务结果中使用的版本
+	Generation   *GenerationProvenance `json:"generation,omitempty"`
+	CreatedAt    time.Time             `json:"created_at"`
+}
+
+// PanelVersionsResponse 面板位置及其全部图片版本，版本按生成顺序排列
+type PanelVersionsResponse struct {
+	SequenceNum   int                    `json:"sequence_num"`
+	SceneID       string                 `json:"scene_id"`
+	ActiveMediaID string                 `json:"active_media_id"`
+	ActiveVersion int                    `json:"active_version"`
+	Versions      []PanelVersionResponse `json:"versions"`
+}
+
+// ComposePagesRequest 漫画页面排版请求，字段均可省略，省略时使用默认版式
+type ComposePagesRequest struct {
+	Template         string `json:"template"`          // 页面模板：grid、wide（默认）、tall、mixed
+	Columns          int    `json:"columns"`           // grid 模板的列数，默认 2
+	Rows             int    `json:"rows"`              // grid 模板的行数，默认 3
+	ReadingDirection string `json:"reading_direction"` // 阅读方向：rtl（默认）或 ltr
+	PageWidth        int    `json:"page_width"`        // 页面尺寸（像素），默认 1600x2400
+	PageHeight       int    `json:"page_height"`
+	Margin           *int   `json:"margin"`    // 页边距，默认 60
+	Gutter           *int   `json:"gutter"`    // 面板间距，默认 24
+	Border           *int   `json:"border"`    // 面板边框宽度，默认 4，0 表示无边框
+	Lettering        *bool  `json:"lettering"` // 是否将场景台词嵌入面板，默认 true
+}
+
+// TaskPageResponse 合成后的一页漫画
+type TaskPageResponse struct {
+	PageNumber       int                 `json:"page_number"`
+	MediaID          string              `json:"media_id"`
+	ImageURL         string              `json:"image_url"`
+	ContentURL       string              `json:"content_url"`   // 带签名的媒体内容接口地址
+	ThumbnailURL     string              `json:"thumbnail_url"` // 带签名的缩略图接口地址
+	Width            int                 `json:"width"`
+	Height           int                 `json:"height"`
+	Template         string              `json:"template"`
+	ReadingDirection string              `json:"reading_direction"`
+	Panels           []PagePanelResponse `json:"panels"` // 按阅读顺序排列
+	CreatedAt        time.Time           `json:"created_at"`
+}
+
+// PagePanelResponse 面板在页面中的位置（像素）
+type PagePanelResponse struct {
+	PanelNumber int    `json:"panel_number"`
+	MediaID     string `json:"media_id"`
+	X           int    `json:"x"`
+	Y           int    `json:"y"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Balloons    int    `json:"balloons"` // 嵌入的气泡和旁白框数量
+}
+
+// TaskExportResponse 导出作业状态，完成后可通过 download_url 下载文件
+type TaskExportResponse struct {
+	ExportID     string               `json:"export_id"`
+	TaskID       string               `json:"task_id"`
+	Format       string               `json:"format"` // cbz、pdf、epub、animatic
+	Status       string               `json:"status"` // pending、processing、completed、failed
+	Progress     int                  `json:"progress"`
+	MediaID      string               `json:"media_id,omitempty"`
+	DownloadURL  string               `json:"download_url,omitempty"` // 带签名的媒体内容接口地址
+	MimeType     string               `json:"mime_type,omitempty"`    // animatic 为 video/mp4，未安装 ffmpeg 时为图片序列 application/zip
+	FileSize     int64                `json:"file_size,omitempty"`
+	Files        []ExportFileResponse `json:"files,omitempty"` // 附属文件：时间线清单和字幕
+	ErrorMessage string               `json:"error_message,omitempty"`
+	CreatedAt    time.Time            `json:"created_at"`
+	CompletedAt  *time.Time           `json:"completed_at,omitempty"`
+}
+
+// ExportFileResponse 导出的附属文件
+type ExportFileResponse struct {
+	Kind        string `json:"kind"` // timeline、srt、vtt
+	MediaID     string `json:"media_id"`
+	DownloadURL string `json:"download_url"`
+}
+
+// TaskVoiceoverResponse 一个场景的台词配音，时间相对于场景开始（秒）
+type TaskVoiceoverResponse struct {
+	SceneID     string                  `json:"scene_id"`
+	SceneNumber int                     `json:"scene_number"`
+	Duration    float64                 `json:"duration"` // 最后一句台词结束的时间
+	Lines       []VoiceoverLineResponse `json:"lines"`
+	CreatedAt   time.Time               `json:"created_at"`
+}
+
+// VoiceoverLineResponse 一句台词的配音
+type VoiceoverLineResponse struct {
+	Index       int     `json:"index"` // 台词在场景中的序号，从 0 开始
+	Speaker     string  `json:"speaker"`
+	CharacterID string  `json:"character_id,omitempty"`
+	Text        string  `json:"text"`
+	Voice       string  `json:"voice,omitempty"`
+	MediaID     string  `json:"media_id"`
+	AudioURL    string  `json:"audio_url"` // 带签名的媒体内容接口地址
+	Start       float64 `json:"start"`
+	Duration    float64 `json:"duration"`
+}
@@ -35,9 +35,15 @@ type ChapterResponse struct {
 }
 
 type GenerateMangaRequest struct {
-	Title   string `json:"title" binding:"required"`
-	Author  string `json:"author" binding:"required"`
-	Content string `json:"content" binding:"required"`
+	Title           string `json:"title" binding:"required"`
+	Author          string `json:"author" binding:"required"`
+	Content         string `json:"content" binding:"required"`
+	PanelCount      int    `json:"panel_count" binding:"omitempty,min=1,max=100"`                   // 面板数量，不传则每个场景一个面板
+	AspectRatio     string `json:"aspect_ratio"`                                                    // 宽高比，如 16:9、1:1、9:16
+	Orientation     string `json:"orientation" binding:"omitempty,oneof=landscape portrait square"` // 未指定宽高比时按方向选择
+	ArtStyle        string `json:"art_style"`                                                       // 画风预设：anime、manga、realistic、cartoon、painting、watercolor
+	NegativePrompt  string `json:"negative_prompt" binding:"omitempty,max=500"`                     // 反向提示词
+	ForceRegenerate bool   `json:"force_regenerate"`                                                // 不复用参数相同的已生成图片，重新调用 AI 服务
 }
 
 type MangaWorkflowResponse struct {
@@ -30,6 +30,7 @@ type DialogueResponse struct {
 	Speaker string `json:"speaker"`
 	Content string `json:"content"`
 	Emotion string `json:"emotion,omitempty"`
+	Kind    string `json:"kind"` // speech、thought 或 narration
 }
 
 type DivideChapterRequest struct {
@@ -0,0 +1,35 @@
+package dto
+
+// UsageTotalsResponse 一段时间内的 AI 调用用量
+type UsageTotalsResponse struct {
+	Calls         int     `json:"calls"`
+	FailedCalls   int     `json:"failed_calls"`
+	Images        int     `json:"images"`
+	Videos        int     `json:"videos"`
+	VideoSeconds  float64 `json:"video_seconds"`
+	SpeechLines   int     `json:"speech_lines"`
+	EstimatedCost float64 `json:"estimated_cost"` // 估算费用（美元）
+}
+
+// DailyUsageResponse 某一天（UTC）的用量
+type DailyUsageResponse struct {
+	Date string `json:"date"`
+	UsageTotalsResponse
+}
+
+// UsageQuotaResponse 当前用户的配额，限额为 0 表示不限制，此时剩余量为 null
+type UsageQuotaResponse struct {
+	DailyGenerationLimit      int      `json:"daily_generation_limit"`
+	DailyGenerationsRemaining *int     `json:"daily_generations_remaining"`
+	MonthlyCostLimit          float64  `json:"monthly_cost_limit"`
+	MonthlyCostRemaining      *float64 `json:"monthly_cost_remaining"`
+	Exceeded                  bool     `json:"exceeded"`
+}
+
+// UsageSummaryResponse 当前用户本日和本月（UTC 自然月）的用量
+type UsageSummaryResponse struct {
+	Today UsageTotalsResponse  `json:"today"`
+	Month UsageTotalsResponse  `json:"month"`
+	Daily []DailyUsageResponse `json:"daily"`
+	Quota UsageQuotaResponse   `json:"quota"`
+}
@@ -7,23 +7,27 @@ import (
 	"github.com/xiajiayi/ai-motion/internal/application/dto"
 	"github.com/xiajiayi/ai-motion/internal/domain/character"
 	"github.com/xiajiayi/ai-motion/internal/domain/novel"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/storage"
 )
 
 type CharacterService struct {
 	characterRepo    character.CharacterRepository
 	novelRepo        novel.NovelRepository
 	extractorService *character.CharacterExtractorService
+	links            *storage.URLSigner
 }
 
 func NewCharacterService(
 	characterRepo character.CharacterRepository,
 	novelRepo novel.NovelRepository,
 	extractorService *character.CharacterExtractorService,
+	links *storage.URLSigner,
 ) *CharacterService {
 	return &CharacterService{
 		characterRepo:    characterRepo,
 		novelRepo:        novelRepo,
 		extractorService: extractorService,
+		links:            links,
 	}
 }
 
@@ -113,6 +117,18 @@ func (s *CharacterService) UpdateCharacter(ctx context.Context, id string, req *
 		char.SetReferenceImage(req.ReferenceImageURL)
 	}
 
+	if req.Voice != nil {
+		voice := character.VoiceProfile{
+			Voice:    req.Voice.Voice,
+			Language: req.Voice.Language,
+			Speed:    req.Voice.Speed,
+			Pitch:    req.Voice.Pitch,
+		}
+		if err := char.SetVoice(voice); err != nil {
+			return nil, fmt.Errorf("failed to set voice: %w", err)
+		}
+	}
+
 	if err := s.characterRepo.Save(ctx, char); err != nil {
 		return nil, fmt.Errorf("failed to save character: %w", err)
 	}
@@ -161,8 +177,20 @@ func (s *CharacterService) toCharacterResponse(char *character.Character) *dto.C
 			Background: char.Personality.Background,
 		},
 		Description:       char.Description,
-		ReferenceImageURL: char.ReferenceImageURL,
+		ReferenceImageURL: s.links.Sign(char.ReferenceImageURL),
+		Voice:             toVoiceProfileResponse(char.Voice),
 		CreatedAt:         char.CreatedAt,
 		UpdatedAt:         char.UpdatedAt,
 	}
 }
+
+// toVoiceProfileResponse 返回补全默认值后的配音设置
+func toVoiceProfileResponse(voice character.VoiceProfile) dto.VoiceProfileResponse {
+	voice = voice.WithDefaults()
+	return dto.VoiceProfileResponse{
+		Voice:    voice.Voice,
+		Language: voice.Language,
+		Speed:    voice.Speed,
+		Pitch:    voice.Pitch,
+	}
+}
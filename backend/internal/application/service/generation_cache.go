@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync/atomic"
+
+	"github.com/xiajiayi/ai-motion/internal/application/dto"
+	"github.com/xiajiayi/ai-motion/internal/domain/media"
+)
+
+// GenerationCache 按生成参数的内容寻址键复用已完成的媒体，参数完全相同的请求不再调用 AI 服务。
+// 命中的媒体文件保存在存储中且不会被修改，新媒体直接引用同一文件
+type GenerationCache struct {
+	mediaRepo media.MediaRepository
+	hits      atomic.Int64
+	misses    atomic.Int64
+	bypasses  atomic.Int64
+}
+
+func NewGenerationCache(mediaRepo media.MediaRepository) *GenerationCache {
+	return &GenerationCache{
+		mediaRepo: mediaRepo,
+	}
+}
+
+// Lookup 查询键对应的已完成媒体；force 为 true 时跳过缓存（强制重新生成）。
+// 查询失败按未命中处理，缓存不可用不影响生成
+func (c *GenerationCache) Lookup(ctx context.Context, key string, force bool) (*media.Media, bool) {
+	if c == nil {
+		return nil, false
+	}
+	if force {
+		c.bypasses.Add(1)
+		return nil, false
+	}
+
+	cached, err := c.mediaRepo.FindByCacheKey(ctx, key)
+	if err != nil {
+		if !errors.Is(err, media.ErrMediaNotFound) {
+			log.Printf("Generation cache lookup failed for %s: %v", key, err)
+		}
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.hits.Add(1)
+	return cached, true
+}
+
+// Stats 返回本进程启动以来的命中统计
+func (c *GenerationCache) Stats() dto.CacheStatsResponse {
+	stats := dto.CacheStatsResponse{
+		Hits:     c.hits.Load(),
+		Misses:   c.misses.Load(),
+		Bypassed: c.bypasses.Load(),
+	}
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(lookups)
+	}
+	return stats
+}
@@ -2,69 +2,144 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/xiajiayi/ai-motion/internal/application/dto"
 	"github.com/xiajiayi/ai-motion/internal/domain/media"
 	"github.com/xiajiayi/ai-motion/internal/domain/scene"
-	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai/gemini"
-	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai/sora"
+	"github.com/xiajiayi/ai-motion/internal/domain/usage"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/queue"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/storage"
 )
 
+// videoGenerationTimeout 视频提交后等待生成完成的最长时间，超时后标记为失败
+const videoGenerationTimeout = time.Hour
+
+var errVideoNotSubmitted = errors.New("video generation was never submitted")
+
 type GenerationService struct {
-	mediaRepo    media.MediaRepository
-	sceneRepo    scene.SceneRepository
-	geminiClient *gemini.Client
-	soraClient   *sora.Client
+	mediaRepo      media.MediaRepository
+	sceneRepo      scene.SceneRepository
+	imageProviders *ai.ImageRegistry
+	videoGenerator ai.VideoGenerator
+	assets         *storage.AssetStore
+	cache          *GenerationCache
+	usage          *UsageService
+	links          *storage.URLSigner
 }
 
 func NewGenerationService(
 	mediaRepo media.MediaRepository,
 	sceneRepo scene.SceneRepository,
-	geminiClient *gemini.Client,
-	soraClient *sora.Client,
+	imageProviders *ai.ImageRegistry,
+	videoGenerator ai.VideoGenerator,
+	assets *storage.AssetStore,
+	cache *GenerationCache,
+	usageService *UsageService,
+	links *storage.URLSigner,
 ) *GenerationService {
 	return &GenerationService{
-		mediaRepo:    mediaRepo,
-		sceneRepo:    sceneRepo,
-		geminiClient: geminiClient,
-		soraClient:   soraClient,
+		mediaRepo:      mediaRepo,
+		sceneRepo:      sceneRepo,
+		imageProviders: imageProviders,
+		videoGenerator: videoGenerator,
+		assets:         assets,
+		cache:          cache,
+		usage:          usageService,
+		links:          links,
 	}
 }
 
-func (s *GenerationService) GenerateSceneImage(ctx context.Context, req *dto.GenerateImageRequest) (*dto.MediaResponse, error) {
+// GenerateSceneImage 同步生成场景图片，生成前检查用户配额，调用计入该用户的用量
+func (s *GenerationService) GenerateSceneImage(ctx context.Context, userID string, req *dto.GenerateImageRequest) (*dto.MediaResponse, error) {
+	if err := s.usage.CheckQuota(ctx, userID); err != nil {
+		return nil, err
+	}
+	ctx = usage.WithCaller(ctx, usage.Caller{UserID: userID})
+
 	sceneEntity, err := s.sceneRepo.FindByID(ctx, scene.SceneID(req.SceneID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to find scene: %w", err)
 	}
 
+	generator, err := s.imageProviders.ForStyle(req.Style)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve image provider: %w", err)
+	}
+
+	if req.ReferenceImage != "" && !generator.Capabilities().SupportsReferenceImage {
+		return nil, fmt.Errorf("%w: %s", ai.ErrReferenceUnsupported, generator.Name())
+	}
+
+	referenceImage, err := s.assets.Inline(ctx, req.ReferenceImage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reference image: %w", err)
+	}
+
+	// 不支持种子的服务忽略该参数，来源和缓存键中记为 0（由服务随机选择）
+	seed := req.Seed
+	if !generator.Capabilities().SupportsSeed {
+		seed = 0
+	}
+
 	mediaEntity := media.NewMedia(string(sceneEntity.ID), media.MediaTypeImage)
+	mediaEntity.NovelID = sceneEntity.NovelID
+	mediaEntity.UserID = userID
+
+	// 参考图以内容参与计算，同一张图换了地址仍能命中
+	params := media.GenerationParams{
+		Prompt:         req.Prompt,
+		NegativePrompt: req.NegativePrompt,
+		Style:          req.Style,
+		Quality:        req.Quality,
+		ReferenceImage: referenceImage,
+		Seed:           seed,
+	}
+	mediaEntity.CacheKey = params.CacheKey(generator.Name(), generator.Capabilities().Model, req.Width, req.Height)
+	mediaEntity.Provenance = imageProvenance(generator, params, req.ReferenceImage, req.Width, req.Height)
+
+	if cached, ok := s.cache.Lookup(ctx, mediaEntity.CacheKey, req.ForceRegenerate); ok {
+		mediaEntity.CompleteFromCache(cached)
+		if err := s.mediaRepo.Save(ctx, mediaEntity); err != nil {
+			return nil, fmt.Errorf("failed to save media: %w", err)
+		}
+		response := s.toMediaDTO(mediaEntity)
+		response.Cached = true
+		return response, nil
+	}
+
+	// 同步生成，先标记为生成中，避免被后台队列重复领取
+	mediaEntity.MarkGenerating("")
 
 	if err := s.mediaRepo.Save(ctx, mediaEntity); err != nil {
 		return nil, fmt.Errorf("failed to save media: %w", err)
 	}
 
-	var imageURL string
-	if req.ReferenceImage != "" {
-		geminiReq := gemini.ImageToImageRequest{
-			ReferenceImage: req.ReferenceImage,
+	var result *ai.ImageResult
+	if referenceImage != "" {
+		imageReq := ai.ImageToImageRequest{
+			ReferenceImage: referenceImage,
 			Prompt:         req.Prompt,
 			NegativePrompt: req.NegativePrompt,
 			Width:          req.Width,
 			Height:         req.Height,
+			Seed:           seed,
 		}
-		imageURL, err = s.geminiClient.ImageToImage(ctx, geminiReq)
+		result, err = generator.ImageToImage(ctx, imageReq)
 	} else {
-		geminiReq := gemini.TextToImageRequest{
+		imageReq := ai.TextToImageRequest{
 			Prompt:         req.Prompt,
 			NegativePrompt: req.NegativePrompt,
 			Width:          req.Width,
 			Height:         req.Height,
 			Quality:        req.Quality,
 			Style:          req.Style,
+			Seed:           seed,
 		}
-		imageURL, err = s.geminiClient.TextToImage(ctx, geminiReq)
+		result, err = generator.TextToImage(ctx, imageReq)
 	}
 
 	if err != nil {
@@ -72,9 +147,16 @@ func (s *GenerationService) GenerateSceneImage(ctx context.Context, req *dto.Gen
 		s.mediaRepo.Save(ctx, mediaEntity)
 		return nil, fmt.Errorf("failed to generate image: %w", err)
 	}
+	mediaEntity.Provenance.RevisedPrompt = result.RevisedPrompt
 
-	metadata := media.NewImageMetadata(req.Width, req.Height, "image/jpeg", 0)
-	mediaEntity.MarkCompleted(imageURL, metadata)
+	asset, err := s.assets.Save(ctx, result.URL, string(mediaEntity.ID))
+	if err != nil {
+		mediaEntity.MarkFailed(err.Error())
+		s.mediaRepo.Save(ctx, mediaEntity)
+		return nil, fmt.Errorf("failed to store generated image: %w", err)
+	}
+
+	mediaEntity.MarkCompleted(asset.URL, imageMetadata(asset, req.Width, req.Height))
 
 	if err := s.mediaRepo.Save(ctx, mediaEntity); err != nil {
 		return nil, fmt.Errorf("failed to update media: %w", err)
@@ -83,25 +165,34 @@ func (s *GenerationService) GenerateSceneImage(ctx context.Context, req *dto.Gen
 	return s.toMediaDTO(mediaEntity), nil
 }
 
-func (s *GenerationService) GenerateSceneVideo(ctx context.Context, req *dto.GenerateVideoRequest) (*dto.MediaResponse, error) {
+// GenerateSceneVideo 提交场景视频生成，生成前检查用户配额，调用计入该用户的用量
+func (s *GenerationService) GenerateSceneVideo(ctx context.Context, userID string, req *dto.GenerateVideoRequest) (*dto.MediaResponse, error) {
+	if err := s.usage.CheckQuota(ctx, userID); err != nil {
+		return nil, err
+	}
+	ctx = usage.WithCaller(ctx, usage.Caller{UserID: userID})
+
 	sceneEntity, err := s.sceneRepo.FindByID(ctx, scene.SceneID(req.SceneID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to find scene: %w", err)
 	}
 
 	mediaEntity := media.NewMedia(string(sceneEntity.ID), media.MediaTypeVideo)
+	mediaEntity.NovelID = sceneEntity.NovelID
+	mediaEntity.UserID = userID
+	mediaEntity.MarkGenerating("")
 
 	if err := s.mediaRepo.Save(ctx, mediaEntity); err != nil {
 		return nil, fmt.Errorf("failed to save media: %w", err)
 	}
 
-	soraReq := sora.ImageToVideoRequest{
+	videoReq := ai.ImageToVideoRequest{
 		ImageURL: req.ImageURL,
 		Prompt:   req.Prompt,
 		Duration: req.Duration,
 	}
 
-	videoID, err := s.soraClient.ImageToVideo(ctx, soraReq)
+	videoID, err := s.videoGenerator.ImageToVideo(ctx, videoReq)
 	if err != nil {
 		mediaEntity.MarkFailed(err.Error())
 		s.mediaRepo.Save(ctx, mediaEntity)
@@ -116,7 +207,12 @@ func (s *GenerationService) GenerateSceneVideo(ctx context.Context, req *dto.Gen
 	return s.toMediaDTO(mediaEntity), nil
 }
 
-func (s *GenerationService) BatchGenerateScenes(ctx context.Context, req *dto.BatchGenerateRequest) (*dto.BatchGenerateResponse, error) {
+// BatchGenerateScenes 创建待生成的媒体，由后台任务队列生成；媒体记录发起的用户，生成时按该用户检查配额和记账
+func (s *GenerationService) BatchGenerateScenes(ctx context.Context, userID string, req *dto.BatchGenerateRequest) (*dto.BatchGenerateResponse, error) {
+	if err := s.usage.CheckQuota(ctx, userID); err != nil {
+		return nil, err
+	}
+
 	jobID := fmt.Sprintf("job-%d", time.Now().Unix())
 
 	for _, sceneID := range req.SceneIDs {
@@ -127,6 +223,7 @@ func (s *GenerationService) BatchGenerateScenes(ctx context.Context, req *dto.Ba
 			}
 
 			mediaEntity := media.NewMedia(string(sceneEntity.ID), media.MediaTypeImage)
+			mediaEntity.UserID = userID
 			s.mediaRepo.Save(ctx, mediaEntity)
 		}
 
@@ -137,6 +234,7 @@ func (s *GenerationService) BatchGenerateScenes(ctx context.Context, req *dto.Ba
 			}
 
 			mediaEntity := media.NewMedia(string(sceneEntity.ID), media.MediaTypeVideo)
+			mediaEntity.UserID = userID
 			s.mediaRepo.Save(ctx, mediaEntity)
 		}
 	}
@@ -149,6 +247,259 @@ func (s *GenerationService) BatchGenerateScenes(ctx context.Context, req *dto.Ba
 	}, nil
 }
 
+// ProcessMedia 处理批量生成创建的待生成媒体（由后台任务队列领取后调用），
+// 生成前检查发起用户的配额，超出时标记为失败
+func (s *GenerationService) ProcessMedia(ctx context.Context, m *media.Media) error {
+	if m.UserID != "" {
+		if err := s.usage.CheckQuota(ctx, m.UserID); err != nil {
+			if errors.Is(err, usage.ErrQuotaExceeded) {
+				return s.failMedia(ctx, m, err)
+			}
+			return err
+		}
+	}
+	ctx = usage.WithCaller(ctx, usage.Caller{UserID: m.UserID})
+
+	sceneEntity, err := s.sceneRepo.FindByID(ctx, scene.SceneID(m.SceneID))
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return s.failMedia(ctx, m, fmt.Errorf("failed to find scene: %w", err))
+	}
+
+	switch m.Type {
+	case media.MediaTypeImage:
+		return s.processImage(ctx, m, sceneEntity)
+	case media.MediaTypeVideo:
+		return s.processVideo(ctx, m, sceneEntity)
+	default:
+		return s.failMedia(ctx, m, media.ErrInvalidMediaType)
+	}
+}
+
+func (s *GenerationService) processImage(ctx context.Context, m *media.Media, sceneEntity *scene.Scene) error {
+	prompt := sceneEntity.ImagePrompt
+	if prompt == "" {
+		prompt = sceneEntity.Description.ToPrompt()
+	}
+
+	generator, err := s.imageProviders.Default()
+	if err != nil {
+		return s.failMedia(ctx, m, fmt.Errorf("failed to resolve image provider: %w", err))
+	}
+
+	const width, height = 1344, 768
+	m.Provenance = imageProvenance(generator, media.GenerationParams{Prompt: prompt}, "", width, height)
+	result, err := generator.TextToImage(ctx, ai.TextToImageRequest{
+		Prompt: prompt,
+		Width:  width,
+		Height: height,
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, ai.ErrCircuitOpen) {
+			return fmt.Errorf("%w: %v", queue.ErrNotReady, err)
+		}
+		return s.failMedia(ctx, m, fmt.Errorf("failed to generate image: %w", err))
+	}
+	m.Provenance.RevisedPrompt = result.RevisedPrompt
+
+	asset, err := s.assets.Save(ctx, result.URL, string(m.ID))
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return s.failMedia(ctx, m, fmt.Errorf("failed to store generated image: %w", err))
+	}
+
+	m.MarkCompleted(asset.URL, imageMetadata(asset, width, height))
+	if err := s.mediaRepo.Save(ctx, m); err != nil {
+		return fmt.Errorf("failed to update media: %w", err)
+	}
+
+	return nil
+}
+
+// processVideo 视频以场景最新完成的图片为首帧，图片尚未生成时延后处理
+func (s *GenerationService) processVideo(ctx context.Context, m *media.Media, sceneEntity *scene.Scene) error {
+	mediaList, err := s.mediaRepo.FindBySceneID(ctx, string(sceneEntity.ID))
+	if err != nil {
+		return fmt.Errorf("failed to find scene media: %w", err)
+	}
+
+	var imageURL string
+	imagePending := false
+	for _, candidate := range mediaList {
+		if candidate.Type != media.MediaTypeImage {
+			continue
+		}
+		if candidate.Status == media.MediaStatusCompleted {
+			imageURL = candidate.URL
+			break
+		}
+		if candidate.Status == media.MediaStatusPending || candidate.Status == media.MediaStatusGenerating {
+			imagePending = true
+		}
+	}
+
+	if imageURL == "" {
+		if imagePending {
+			return queue.ErrNotReady
+		}
+		return s.failMedia(ctx, m, fmt.Errorf("no completed image for scene %s", sceneEntity.ID))
+	}
+
+	prompt := sceneEntity.VideoPrompt
+	if prompt == "" {
+		prompt = sceneEntity.Description.ToPrompt()
+	}
+
+	// 视频没有模型和尺寸信息，只记录服务、提示词和首帧图片地址
+	m.Provenance = &media.Provenance{
+		Provider: s.videoGenerator.Name(),
+		Params:   media.GenerationParams{Prompt: prompt, ReferenceImage: imageURL},
+	}
+
+	imageURL, err = s.assets.Inline(ctx, imageURL)
+	if err != nil {
+		return s.failMedia(ctx, m, fmt.Errorf("failed to load scene image: %w", err))
+	}
+
+	videoID, err := s.videoGenerator.ImageToVideo(ctx, ai.ImageToVideoRequest{
+		ImageURL: imageURL,
+		Prompt:   prompt,
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, ai.ErrCircuitOpen) {
+			return fmt.Errorf("%w: %v", queue.ErrNotReady, err)
+		}
+		return s.failMedia(ctx, m, fmt.Errorf("failed to generate video: %w", err))
+	}
+
+	m.MarkGenerating(videoID)
+	if err := s.mediaRepo.Save(ctx, m); err != nil {
+		return fmt.Errorf("failed to update media: %w", err)
+	}
+
+	return nil
+}
+
+// PollVideo 查询已提交视频的生成状态（由后台轮询器调用）：完成后下载到存储并记录视频元数据，
+// 失败时记录服务返回的错误；仍在生成或查询暂时失败时返回 queue.ErrNotReady，由轮询器退避后再次查询
+func (s *GenerationService) PollVideo(ctx context.Context, m *media.Media) error {
+	timedOut := time.Since(m.GeneratingSince()) > videoGenerationTimeout
+
+	if m.GenerationID == "" {
+		// 提交前已保存为生成中，提交结果未写回（如进程在提交期间退出）时无法查询
+		if timedOut {
+			return s.failMedia(ctx, m, errVideoNotSubmitted)
+		}
+		return queue.ErrNotReady
+	}
+
+	status, err := s.videoGenerator.GetVideoStatus(ctx, m.GenerationID)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if timedOut {
+			return s.failMedia(ctx, m, fmt.Errorf("failed to query video status: %w", err))
+		}
+		return fmt.Errorf("%w: failed to query video status: %v", queue.ErrNotReady, err)
+	}
+
+	switch status.Status {
+	case ai.VideoStatusCompleted:
+		return s.completeVideo(ctx, m, status, timedOut)
+	case ai.VideoStatusFailed:
+		return s.failMedia(ctx, m, fmt.Errorf("video generation failed: %s", status.Error))
+	}
+
+	if timedOut {
+		return s.failMedia(ctx, m, fmt.Errorf("video generation timed out after %s", videoGenerationTimeout))
+	}
+	return queue.ErrNotReady
+}
+
+// completeVideo 下载生成的视频，优先使用状态中的下载地址；视频尺寸和时长优先取文件中的实际值
+func (s *GenerationService) completeVideo(ctx context.Context, m *media.Media, status *ai.VideoGenerationResponse, timedOut bool) error {
+	var asset *storage.Asset
+	var err error
+	if status.URL != "" {
+		asset, err = s.assets.Save(ctx, status.URL, string(m.ID))
+	} else {
+		asset, err = s.saveVideoContent(ctx, m)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if timedOut {
+			return s.failMedia(ctx, m, fmt.Errorf("failed to store generated video: %w", err))
+		}
+		return fmt.Errorf("%w: failed to store generated video: %v", queue.ErrNotReady, err)
+	}
+
+	width, height, duration := asset.Width, asset.Height, asset.Duration
+	if width == 0 || height == 0 {
+		width, height = status.Width, status.Height
+	}
+	if duration == 0 {
+		duration = status.Duration
+	}
+
+	m.MarkCompleted(asset.URL, media.NewVideoMetadata(width, height, duration, asset.MimeType, asset.Size))
+	if err := s.mediaRepo.Save(ctx, m); err != nil {
+		return fmt.Errorf("failed to update media: %w", err)
+	}
+
+	return nil
+}
+
+func (s *GenerationService) saveVideoContent(ctx context.Context, m *media.Media) (*storage.Asset, error) {
+	reader, err := s.videoGenerator.DownloadVideo(ctx, m.GenerationID)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return s.assets.SaveReader(ctx, reader, string(m.ID))
+}
+
+// imageProvenance 记录图片的生成来源；params 中的参考图为参与缓存键计算的内容，这里替换为参考图地址
+func imageProvenance(generator ai.ImageGenerator, params media.GenerationParams, referenceURL string, width, height int) *media.Provenance {
+	params.ReferenceImage = referenceURL
+	return &media.Provenance{
+		Provider: generator.Name(),
+		Model:    generator.Capabilities().Model,
+		Width:    width,
+		Height:   height,
+		Params:   params,
+	}
+}
+
+// imageMetadata 使用解码得到的实际尺寸，无法解码时（如 WebP）沿用请求尺寸
+func imageMetadata(asset *storage.Asset, width, height int) media.MediaMetadata {
+	if asset.Width > 0 && asset.Height > 0 {
+		width, height = asset.Width, asset.Height
+	}
+	return media.NewImageMetadata(width, height, asset.MimeType, asset.Size)
+}
+
+func (s *GenerationService) failMedia(ctx context.Context, m *media.Media, cause error) error {
+	m.MarkFailed(cause.Error())
+	if err := s.mediaRepo.Save(ctx, m); err != nil {
+		return fmt.Errorf("failed to save failed media: %w", err)
+	}
+	return cause
+}
+
 func (s *GenerationService) GetGenerationStatus(ctx context.Context, sceneID string) (*dto.GenerationStatusResponse, error) {
 	mediaList, err := s.mediaRepo.FindBySceneID(ctx, sceneID)
 	if err != nil {
@@ -177,12 +528,12 @@ func (s *GenerationService) GetGenerationStatus(ctx context.Context, sceneID str
 }
 
 func (s *GenerationService) toMediaDTO(m *media.Media) *dto.MediaResponse {
-	return &dto.MediaResponse{
+	response := &dto.MediaResponse{
 		ID:      string(m.ID),
 		SceneID: m.SceneID,
 		Type:    string(m.Type),
 		Status:  string(m.Status),
-		URL:     m.URL,
+		URL:     s.links.Sign(m.URL),
 		Metadata: dto.MediaMetadata{
 			Width:      m.Metadata.Width,
 			Height:     m.Metadata.Height,
@@ -193,8 +544,39 @@ func (s *GenerationService) toMediaDTO(m *media.Media) *dto.MediaResponse {
 		},
 		GenerationID: m.GenerationID,
 		ErrorMessage: m.ErrorMessage,
+		Generation:   toGenerationProvenance(m.Provenance),
 		CreatedAt:    m.CreatedAt,
 		UpdatedAt:    m.UpdatedAt,
 		CompletedAt:  m.CompletedAt,
 	}
+
+	if m.IsReady() {
+		response.ContentURL = mediaContentURL(s.links, string(m.ID))
+		if m.Type == media.MediaTypeImage {
+			response.ThumbnailURL = mediaThumbnailURL(s.links, string(m.ID))
+		}
+	}
+
+	return response
+}
+
+func toGenerationProvenance(p *media.Provenance) *dto.GenerationProvenance {
+	if p == nil {
+		return nil
+	}
+	return &dto.GenerationProvenance{
+		Provider:       p.Provider,
+		Model:          p.Model,
+		Prompt:         p.Params.Prompt,
+		NegativePrompt: p.Params.NegativePrompt,
+		Style:          p.Params.Style,
+		Quality:        p.Params.Quality,
+		Seed:           p.Params.Seed,
+		Steps:          p.Params.Steps,
+		CFGScale:       p.Params.CFGScale,
+		ReferenceImage: p.Params.ReferenceImage,
+		Width:          p.Width,
+		Height:         p.Height,
+		RevisedPrompt:  p.RevisedPrompt,
+	}
 }
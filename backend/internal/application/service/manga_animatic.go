@@ -0,0 +1,298 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/xiajiayi/ai-motion/internal/domain/export"
+	"github.com/xiajiayi/ai-motion/internal/domain/media"
+	"github.com/xiajiayi/ai-motion/internal/domain/novel"
+	"github.com/xiajiayi/ai-motion/internal/domain/scene"
+	"github.com/xiajiayi/ai-motion/internal/domain/task"
+	"github.com/xiajiayi/ai-motion/internal/domain/voiceover"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/video"
+)
+
+const (
+	// animaticLoadProgress 下载素材阶段完成时的进度
+	animaticLoadProgress = 40
+	// animaticEncodeProgress 渲染完成时的进度，其余为保存文件
+	animaticEncodeProgress = 90
+)
+
+// animaticClip 一个场景选用的素材
+type animaticClip struct {
+	source export.ClipSource
+	media  *media.Media
+	poster *media.Media   // 视频片段的场景图片，可为空
+	voices []*media.Media // 台词配音的音频
+}
+
+// buildAnimatic 按章节和场景顺序拼接场景视频和图片：生成时间线清单和字幕，由视频编码器渲染成片并混入台词配音，
+// 成片保存为导出的主文件，时间线清单和字幕保存为附属文件
+func (s *MangaWorkflowService) buildAnimatic(ctx context.Context, e *export.Export, t *task.Task) error {
+	if s.videoEncoder == nil {
+		return errors.New("video encoder not configured")
+	}
+
+	clips, err := s.animaticClips(ctx, t)
+	if err != nil {
+		return err
+	}
+
+	workDir, err := os.MkdirTemp("", "animatic-"+e.ID+"-")
+	if err != nil {
+		return fmt.Errorf("failed to create work dir: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	sources := make([]export.ClipSource, 0, len(clips))
+	files := make(map[string]video.ClipFile, len(clips))
+	for i, clip := range clips {
+		file, err := s.downloadClip(ctx, workDir, &clip)
+		if err != nil {
+			return fmt.Errorf("failed to load scene %d of chapter %d: %w", clip.source.SceneNumber, clip.source.ChapterNumber, err)
+		}
+		files[clip.source.MediaID] = file
+		for _, voice := range clip.voices {
+			_, audioPath, err := s.downloadMedia(ctx, workDir, voice)
+			if err != nil {
+				return fmt.Errorf("failed to load voiceover of scene %d of chapter %d: %w", clip.source.SceneNumber, clip.source.ChapterNumber, err)
+			}
+			files[string(voice.ID)] = video.ClipFile{Path: audioPath}
+		}
+		sources = append(sources, clip.source)
+		s.saveExportProgress(ctx, e, (i+1)*animaticLoadProgress/len(clips))
+	}
+
+	timeline := export.BuildTimeline(sources, export.DefaultAnimaticWidth, export.DefaultAnimaticHeight, export.DefaultAnimaticFPS)
+
+	manifest, err := json.MarshalIndent(timeline, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal timeline: %w", err)
+	}
+	var srt, vtt bytes.Buffer
+	if err := video.WriteSRT(&srt, timeline.Cues); err != nil {
+		return fmt.Errorf("failed to write srt: %w", err)
+	}
+	if err := video.WriteWebVTT(&vtt, timeline.Cues); err != nil {
+		return fmt.Errorf("failed to write webvtt: %w", err)
+	}
+
+	job := video.Job{Timeline: timeline, Files: files, WorkDir: workDir}
+	if len(timeline.Cues) > 0 {
+		job.Subtitles = filepath.Join(workDir, "subtitles.srt")
+		if err := os.WriteFile(job.Subtitles, srt.Bytes(), 0o600); err != nil {
+			return fmt.Errorf("failed to write subtitles: %w", err)
+		}
+	}
+
+	output, err := s.videoEncoder.Encode(ctx, job)
+	if err != nil {
+		return fmt.Errorf("failed to render animatic with %s: %w", s.videoEncoder.Name(), err)
+	}
+	s.saveExportProgress(ctx, e, animaticEncodeProgress)
+
+	rendered, err := os.ReadFile(output.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read rendered animatic: %w", err)
+	}
+	artifact, err := s.saveExportFile(ctx, t.NovelID, rendered, output.Extension, output.MimeType, media.MediaMetadata{
+		Width:    timeline.Width,
+		Height:   timeline.Height,
+		Duration: timeline.Duration,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, attachment := range []struct {
+		kind     export.AttachmentKind
+		data     []byte
+		ext      string
+		mimeType string
+	}{
+		{export.AttachmentTimeline, manifest, ".json", "application/json"},
+		{export.AttachmentSRT, srt.Bytes(), ".srt", "application/x-subrip"},
+		{export.AttachmentWebVTT, vtt.Bytes(), ".vtt", "text/vtt"},
+	} {
+		// 没有台词时不生成字幕文件
+		if attachment.kind != export.AttachmentTimeline && len(timeline.Cues) == 0 {
+			continue
+		}
+		m, err := s.saveExportFile(ctx, t.NovelID, attachment.data, attachment.ext, attachment.mimeType, media.MediaMetadata{})
+		if err != nil {
+			return err
+		}
+		e.AddAttachment(attachment.kind, string(m.ID))
+	}
+
+	e.Complete(string(artifact.ID))
+	if err := s.exportRepo.Save(ctx, e); err != nil {
+		return fmt.Errorf("failed to save export: %w", err)
+	}
+	return nil
+}
+
+// animaticClips 按章节号和场景编号为每个场景选择素材：优先使用最新完成的场景视频，
+// 没有视频时使用任务面板当前选定的图片，再没有时使用场景最新完成的图片。没有任何素材的场景跳过。
+// 任务已合成配音时附带各句台词的配音
+func (s *MangaWorkflowService) animaticClips(ctx context.Context, t *task.Task) ([]animaticClip, error) {
+	mediaByID, err := s.novelMedia(ctx, t.NovelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load media: %w", err)
+	}
+	panels, err := s.taskPanels(ctx, t)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load panels: %w", err)
+	}
+	tracks, err := s.voiceoverRepo.FindByTaskID(ctx, t.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load voiceover: %w", err)
+	}
+	tracksByScene := make(map[string]*voiceover.Track, len(tracks))
+	for _, track := range tracks {
+		tracksByScene[track.SceneID] = track
+	}
+
+	activeImages := make(map[string]*media.Media)
+	for _, panel := range panels {
+		if m, ok := mediaByID[panel.ActiveMediaID]; ok && m.IsReady() {
+			activeImages[panel.SceneID] = m
+		}
+	}
+	latest := make(map[media.MediaType]map[string]*media.Media)
+	for _, m := range mediaByID {
+		if m.SceneID == "" || !m.IsReady() || (m.Type != media.MediaTypeVideo && m.Type != media.MediaTypeImage) {
+			continue
+		}
+		if latest[m.Type] == nil {
+			latest[m.Type] = make(map[string]*media.Media)
+		}
+		if current, ok := latest[m.Type][m.SceneID]; !ok || m.CreatedAt.After(current.CreatedAt) {
+			latest[m.Type][m.SceneID] = m
+		}
+	}
+
+	chapters, err := s.chapterRepo.FindByNovelID(ctx, novel.NovelID(t.NovelID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chapters: %w", err)
+	}
+
+	var clips []animaticClip
+	for _, ch := range chapters {
+		scenes, err := s.sceneRepo.FindByChapterID(ctx, ch.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load scenes: %w", err)
+		}
+		for _, scn := range scenes {
+			sceneID := string(scn.ID)
+			image := activeImages[sceneID]
+			if image == nil {
+				image = latest[media.MediaTypeImage][sceneID]
+			}
+
+			lines, voices := animaticLines(scn.Dialogues, tracksByScene[sceneID], mediaByID)
+			clip := animaticClip{source: export.ClipSource{
+				SceneID:       sceneID,
+				ChapterNumber: ch.ChapterNumber,
+				SceneNumber:   scn.SceneNumber,
+				Lines:         lines,
+			}, voices: voices}
+			if m, ok := latest[media.MediaTypeVideo][sceneID]; ok {
+				clip.media, clip.poster = m, image
+				clip.source.Kind = export.ClipKindVideo
+				clip.source.Duration = m.Metadata.Duration
+			} else if image != nil {
+				clip.media = image
+				clip.source.Kind = export.ClipKindStill
+			} else {
+				continue
+			}
+			clip.source.MediaID = string(clip.media.ID)
+			clips = append(clips, clip)
+		}
+	}
+
+	if len(clips) == 0 {
+		return nil, export.ErrNoClips
+	}
+	return clips, nil
+}
+
+// animaticLines 将场景台词转换为字幕行，旁白不显示说话人。配音的文本与台词一致且音频可用时
+// 附带配音时间，台词在合成配音后被修改的不使用配音。返回使用到的配音音频
+func animaticLines(dialogues []scene.Dialogue, track *voiceover.Track, mediaByID map[string]*media.Media) ([]export.Line, []*media.Media) {
+	lines := make([]export.Line, 0, len(dialogues))
+	var voices []*media.Media
+	for i, d := range dialogues {
+		line := export.Line{
+			Speaker:   d.Speaker,
+			Text:      d.Content,
+			Narration: d.EffectiveKind() == scene.DialogueKindNarration,
+		}
+		if track != nil {
+			if voiced, ok := track.LineAt(i); ok && voiced.Text == strings.TrimSpace(d.Content) {
+				if m, ok := mediaByID[voiced.MediaID]; ok && m.IsReady() {
+					line.AudioMediaID = voiced.MediaID
+					line.Offset = voiced.Start
+					line.Duration = voiced.Duration
+					voices = append(voices, m)
+				}
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines, voices
+}
+
+// downloadClip 将片段的素材（和视频片段的场景图片）写入工作目录。
+// 视频未记录时长时从 MP4 头部读取
+func (s *MangaWorkflowService) downloadClip(ctx context.Context, workDir string, clip *animaticClip) (video.ClipFile, error) {
+	data, filePath, err := s.downloadMedia(ctx, workDir, clip.media)
+	if err != nil {
+		return video.ClipFile{}, err
+	}
+	file := video.ClipFile{Path: filePath}
+
+	if clip.source.Kind == export.ClipKindVideo {
+		if clip.source.Duration <= 0 {
+			if info, err := video.ProbeMP4(data); err == nil {
+				clip.source.Duration = info.Duration
+			}
+		}
+		if clip.poster != nil {
+			if _, file.Poster, err = s.downloadMedia(ctx, workDir, clip.poster); err != nil {
+				return video.ClipFile{}, err
+			}
+		}
+	}
+	return file, nil
+}
+
+// downloadMedia 读取媒体文件并写入工作目录，返回文件内容和路径
+func (s *MangaWorkflowService) downloadMedia(ctx context.Context, workDir string, m *media.Media) ([]byte, string, error) {
+	reader, err := s.assets.Open(ctx, m.URL)
+	if err != nil {
+		return nil, "", err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read media %s: %w", m.ID, err)
+	}
+	filePath := filepath.Join(workDir, string(m.ID)+path.Ext(m.URL))
+	if err := os.WriteFile(filePath, data, 0o600); err != nil {
+		return nil, "", fmt.Errorf("failed to write media %s: %w", m.ID, err)
+	}
+	return data, filePath, nil
+}
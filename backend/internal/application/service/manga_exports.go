@@ -0,0 +1,298 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/xiajiayi/ai-motion/internal/application/dto"
+	"github.com/xiajiayi/ai-motion/internal/domain/export"
+	"github.com/xiajiayi/ai-motion/internal/domain/media"
+	"github.com/xiajiayi/ai-motion/internal/domain/novel"
+	"github.com/xiajiayi/ai-motion/internal/domain/page"
+	"github.com/xiajiayi/ai-motion/internal/domain/task"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/comicbook"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/imaging"
+)
+
+const (
+	// exportQuality 非 JPEG 的面板图片转换为 JPEG 时的质量
+	exportQuality = 90
+	// exportLoadProgress 读取图片阶段完成时的进度，其余为打包和保存
+	exportLoadProgress = 90
+)
+
+// RequestExport 为已完成的任务创建导出作业，由任务队列在后台打包，完成后通过 GetExport 获取下载地址
+func (s *MangaWorkflowService) RequestExport(ctx context.Context, userID, taskID, format string) (*dto.TaskExportResponse, error) {
+	t, err := s.taskRepo.FindByIDAndUserID(ctx, taskID, userID)
+	if err != nil {
+		return nil, task.ErrTaskNotFound
+	}
+	if !t.IsCompleted() {
+		return nil, task.ErrTaskNotCompleted
+	}
+
+	exportFormat, err := export.ParseFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	e := export.NewExport(t.ID, userID, exportFormat)
+	if err := s.exportRepo.Save(ctx, e); err != nil {
+		return nil, fmt.Errorf("failed to save export: %w", err)
+	}
+
+	log.Printf("Export %s of task %s queued (%s)", e.ID, t.ID, e.Format)
+
+	return s.toTaskExportResponse(e, nil), nil
+}
+
+// GetExport 查询导出作业的进度，完成时返回下载地址
+func (s *MangaWorkflowService) GetExport(ctx context.Context, userID, taskID, exportID string) (*dto.TaskExportResponse, error) {
+	t, err := s.taskRepo.FindByIDAndUserID(ctx, taskID, userID)
+	if err != nil {
+		return nil, task.ErrTaskNotFound
+	}
+
+	e, err := s.exportRepo.FindByID(ctx, exportID)
+	if err != nil {
+		return nil, err
+	}
+	if e.TaskID != t.ID {
+		return nil, export.ErrExportNotFound
+	}
+
+	var artifact *media.Media
+	if e.MediaID != "" {
+		if artifact, err = s.mediaRepo.FindByID(ctx, media.MediaID(e.MediaID)); err != nil {
+			return nil, fmt.Errorf("failed to load export file: %w", err)
+		}
+	}
+
+	return s.toTaskExportResponse(e, artifact), nil
+}
+
+// ExecuteExport 任务队列调用的导出作业处理函数。
+// 租约丢失或服务关闭导致 ctx 取消时作业保持处理中，由其他 worker 重新领取并从头开始
+func (s *MangaWorkflowService) ExecuteExport(ctx context.Context, exportID string) error {
+	e, err := s.exportRepo.FindByID(ctx, exportID)
+	if err != nil {
+		return fmt.Errorf("failed to load export: %w", err)
+	}
+	if e.IsFinished() {
+		return nil
+	}
+
+	e.Start()
+	if err := s.exportRepo.Save(ctx, e); err != nil {
+		return fmt.Errorf("failed to save export: %w", err)
+	}
+
+	if err := s.buildExport(ctx, e); err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		e.Fail(err.Error())
+		if saveErr := s.exportRepo.Save(ctx, e); saveErr != nil {
+			log.Printf("Failed to save failed export %s: %v", e.ID, saveErr)
+		}
+		return err
+	}
+
+	log.Printf("Export %s of task %s completed (%s, media %s)", e.ID, e.TaskID, e.Format, e.MediaID)
+	return nil
+}
+
+// buildExport 读取任务的页面图片，按格式打包后写入存储并保存为 export 类型的媒体，
+// 动态分镜由 buildAnimatic 渲染
+func (s *MangaWorkflowService) buildExport(ctx context.Context, e *export.Export) error {
+	t, err := s.taskRepo.FindByID(ctx, e.TaskID)
+	if err != nil {
+		return fmt.Errorf("failed to load task: %w", err)
+	}
+	if e.Format == export.FormatAnimatic {
+		return s.buildAnimatic(ctx, e, t)
+	}
+	novelEntity, err := s.novelRepo.FindByID(ctx, novel.NovelID(t.NovelID))
+	if err != nil {
+		return fmt.Errorf("failed to load novel: %w", err)
+	}
+
+	images, direction, err := s.exportImages(ctx, t)
+	if err != nil {
+		return err
+	}
+
+	book := &comicbook.Book{
+		ID:          e.ID,
+		Title:       novelEntity.Title,
+		Author:      novelEntity.Author,
+		RightToLeft: direction == page.ReadingDirectionRTL,
+		Modified:    time.Now(),
+		Pages:       make([][]byte, 0, len(images)),
+	}
+	for i, m := range images {
+		data, err := s.exportPage(ctx, m)
+		if err != nil {
+			return fmt.Errorf("failed to load page %d: %w", i+1, err)
+		}
+		book.Pages = append(book.Pages, data)
+		s.saveExportProgress(ctx, e, (i+1)*exportLoadProgress/len(images))
+	}
+
+	var buf bytes.Buffer
+	switch e.Format {
+	case export.FormatCBZ:
+		err = comicbook.WriteCBZ(&buf, book)
+	case export.FormatPDF:
+		err = comicbook.WritePDF(&buf, book)
+	case export.FormatEPUB:
+		err = comicbook.WriteEPUB(&buf, book)
+	default:
+		err = export.ErrInvalidFormat
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", e.Format, err)
+	}
+
+	artifact, err := s.saveExportFile(ctx, t.NovelID, buf.Bytes(), e.Format.Extension(), e.Format.MimeType(), media.MediaMetadata{})
+	if err != nil {
+		return err
+	}
+
+	e.Complete(string(artifact.ID))
+	if err := s.exportRepo.Save(ctx, e); err != nil {
+		return fmt.Errorf("failed to save export: %w", err)
+	}
+	return nil
+}
+
+// exportImages 按阅读顺序返回要导出的图片和阅读方向：已排版时使用页面，
+// 否则使用各面板当前选定的版本（跳过未完成的面板），方向取默认版式
+func (s *MangaWorkflowService) exportImages(ctx context.Context, t *task.Task) ([]*media.Media, page.ReadingDirection, error) {
+	mediaByID, err := s.novelMedia(ctx, t.NovelID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load media: %w", err)
+	}
+
+	pages, err := s.pageRepo.FindByTaskID(ctx, t.ID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load pages: %w", err)
+	}
+	if len(pages) > 0 {
+		images := make([]*media.Media, 0, len(pages))
+		for _, p := range pages {
+			m, ok := mediaByID[p.MediaID]
+			if !ok || !m.IsReady() {
+				return nil, "", fmt.Errorf("page %d: %w", p.Number, media.ErrMediaNotFound)
+			}
+			images = append(images, m)
+		}
+		return images, pages[0].Direction, nil
+	}
+
+	panels, err := s.taskPanels(ctx, t)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load panels: %w", err)
+	}
+	var images []*media.Media
+	for _, panel := range panels {
+		if m, ok := mediaByID[panel.ActiveMediaID]; ok && m.IsReady() {
+			images = append(images, m)
+		}
+	}
+	if len(images) == 0 {
+		return nil, "", page.ErrNoPanels
+	}
+	return images, page.DefaultLayout().Direction, nil
+}
+
+// exportPage 读取图片文件，JPEG 原样使用，其他格式铺白底后转换为 JPEG
+func (s *MangaWorkflowService) exportPage(ctx context.Context, m *media.Media) ([]byte, error) {
+	reader, err := s.assets.Open(ctx, m.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image %s: %w", m.ID, err)
+	}
+	if http.DetectContentType(data) == "image/jpeg" {
+		return data, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image %s: %w", m.ID, err)
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, imaging.Flatten(img, color.White), &jpeg.Options{Quality: exportQuality}); err != nil {
+		return nil, fmt.Errorf("failed to encode image %s: %w", m.ID, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// saveExportFile 将导出的文件写入存储并保存为 export 类型的媒体，metadata 中的格式和大小由存储结果填写
+func (s *MangaWorkflowService) saveExportFile(ctx context.Context, novelID string, data []byte, ext, mimeType string, metadata media.MediaMetadata) (*media.Media, error) {
+	m := media.NewMediaForNovel(novelID, media.MediaTypeExport)
+	asset, err := s.assets.SaveFile(ctx, data, string(m.ID), ext, mimeType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store export file: %w", err)
+	}
+	metadata.Format = asset.MimeType
+	metadata.FileSize = asset.Size
+	m.MarkCompleted(asset.URL, metadata)
+	if err := s.mediaRepo.Save(ctx, m); err != nil {
+		return nil, fmt.Errorf("failed to save export media: %w", err)
+	}
+	return m, nil
+}
+
+// saveExportProgress 进度变化时保存，保存失败只记录日志，不影响导出
+func (s *MangaWorkflowService) saveExportProgress(ctx context.Context, e *export.Export, progress int) {
+	if progress <= e.Progress {
+		return
+	}
+	e.UpdateProgress(progress)
+	if err := s.exportRepo.Save(ctx, e); err != nil {
+		log.Printf("Failed to save progress of export %s: %v", e.ID, err)
+	}
+}
+
+func (s *MangaWorkflowService) toTaskExportResponse(e *export.Export, artifact *media.Media) *dto.TaskExportResponse {
+	response := &dto.TaskExportResponse{
+		ExportID:     e.ID,
+		TaskID:       e.TaskID,
+		Format:       string(e.Format),
+		Status:       string(e.Status),
+		Progress:     e.Progress,
+		MediaID:      e.MediaID,
+		ErrorMessage: e.ErrorMessage,
+		CreatedAt:    e.CreatedAt,
+		CompletedAt:  e.CompletedAt,
+	}
+	if e.MediaID != "" {
+		response.DownloadURL = mediaContentURL(s.links, e.MediaID)
+	}
+	if artifact != nil {
+		response.MimeType = artifact.Metadata.Format
+		response.FileSize = artifact.Metadata.FileSize
+	}
+	for _, attachment := range e.Attachments {
+		response.Files = append(response.Files, dto.ExportFileResponse{
+			Kind:        string(attachment.Kind),
+			MediaID:     attachment.MediaID,
+			DownloadURL: mediaContentURL(s.links, attachment.MediaID),
+		})
+	}
+	return response
+}
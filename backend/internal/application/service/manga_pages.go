@@ -0,0 +1,294 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"log"
+	"strings"
+
+	"github.com/xiajiayi/ai-motion/internal/application/dto"
+	"github.com/xiajiayi/ai-motion/internal/domain/lettering"
+	"github.com/xiajiayi/ai-motion/internal/domain/media"
+	"github.com/xiajiayi/ai-motion/internal/domain/novel"
+	"github.com/xiajiayi/ai-motion/internal/domain/page"
+	"github.com/xiajiayi/ai-motion/internal/domain/scene"
+	"github.com/xiajiayi/ai-motion/internal/domain/task"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/imaging"
+)
+
+const (
+	// pageQuality 页面图片的 JPEG 质量
+	pageQuality = 90
+	// defaultGridColumns、defaultGridRows grid 模板未指定行列数时的默认值
+	defaultGridColumns = 2
+	defaultGridRows    = 3
+)
+
+// ComposePages 将已完成任务的面板按版式排入页面，合成页面图片并替换任务原有的页面。
+// 每个面板使用当前选定的版本，没有完成图片的面板跳过；旧页面的图片保留在媒体中。
+// 默认将面板对应场景的台词嵌入为对话气泡、思考气泡和旁白框
+func (s *MangaWorkflowService) ComposePages(ctx context.Context, userID, taskID string, req *dto.ComposePagesRequest) ([]dto.TaskPageResponse, error) {
+	t, err := s.taskRepo.FindByIDAndUserID(ctx, taskID, userID)
+	if err != nil {
+		return nil, task.ErrTaskNotFound
+	}
+	if !t.IsCompleted() {
+		return nil, task.ErrTaskNotCompleted
+	}
+
+	layout, err := resolveLayout(req)
+	if err != nil {
+		return nil, err
+	}
+
+	panels, err := s.taskPanels(ctx, t)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load panels: %w", err)
+	}
+	mediaByID, err := s.novelMedia(ctx, t.NovelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load media: %w", err)
+	}
+
+	var scripts map[string][]lettering.Balloon
+	if s.letterer != nil && (req.Lettering == nil || *req.Lettering) {
+		scripts, err = s.sceneScripts(ctx, t.NovelID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load scene dialogues: %w", err)
+		}
+	}
+
+	var sources []*task.Panel
+	for _, panel := range panels {
+		if m, ok := mediaByID[panel.ActiveMediaID]; ok && m.IsReady() {
+			sources = append(sources, panel)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, page.ErrNoPanels
+	}
+
+	pages := make([]*page.Page, 0)
+	for i, slots := range layout.Paginate(len(sources)) {
+		frames := make([]imaging.Frame, 0, len(slots))
+		balloons := make([][]lettering.Balloon, 0, len(slots))
+		placements := make([]page.Placement, 0, len(slots))
+		for _, slot := range slots {
+			panel := sources[slot.Panel]
+			img, err := s.loadImage(ctx, mediaByID[panel.ActiveMediaID])
+			if err != nil {
+				return nil, fmt.Errorf("failed to load panel %d: %w", panel.SequenceNum, err)
+			}
+			frames = append(frames, imaging.Frame{
+				Image: img,
+				Rect:  image.Rect(slot.X, slot.Y, slot.X+slot.Width, slot.Y+slot.Height),
+			})
+			balloons = append(balloons, scripts[panel.SceneID])
+			placements = append(placements, page.Placement{
+				PanelNumber: panel.SequenceNum,
+				MediaID:     panel.ActiveMediaID,
+				X:           slot.X,
+				Y:           slot.Y,
+				Width:       slot.Width,
+				Height:      slot.Height,
+			})
+		}
+
+		pageMedia, placed, err := s.renderPage(ctx, t.NovelID, layout, frames, balloons)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render page %d: %w", i+1, err)
+		}
+		for j := range placements {
+			placements[j].Balloons = placed[j]
+		}
+		mediaByID[string(pageMedia.ID)] = pageMedia
+		pages = append(pages, page.NewPage(t.ID, i+1, string(pageMedia.ID), layout, placements))
+	}
+
+	if err := s.pageRepo.ReplaceByTaskID(ctx, t.ID, pages); err != nil {
+		return nil, fmt.Errorf("failed to save pages: %w", err)
+	}
+
+	log.Printf("Composed %d panels of task %s into %d pages (%s, %s)", len(sources), t.ID, len(pages), layout.Template.Name, layout.Direction)
+
+	return s.toTaskPageResponses(pages, mediaByID), nil
+}
+
+// ListPages 查询任务当前的页面，按页码升序，尚未排版时返回空列表
+func (s *MangaWorkflowService) ListPages(ctx context.Context, userID, taskID string) ([]dto.TaskPageResponse, error) {
+	t, err := s.taskRepo.FindByIDAndUserID(ctx, taskID, userID)
+	if err != nil {
+		return nil, task.ErrTaskNotFound
+	}
+
+	pages, err := s.pageRepo.FindByTaskID(ctx, t.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pages: %w", err)
+	}
+	mediaByID, err := s.novelMedia(ctx, t.NovelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load media: %w", err)
+	}
+
+	return s.toTaskPageResponses(pages, mediaByID), nil
+}
+
+// resolveLayout 以默认版式为基础应用请求中的参数
+func resolveLayout(req *dto.ComposePagesRequest) (page.Layout, error) {
+	layout := page.DefaultLayout()
+
+	if name := strings.TrimSpace(req.Template); name != "" {
+		cols, rows := req.Columns, req.Rows
+		if cols == 0 {
+			cols = defaultGridColumns
+		}
+		if rows == 0 {
+			rows = defaultGridRows
+		}
+		template, err := page.LookupTemplate(name, cols, rows)
+		if err != nil {
+			return layout, err
+		}
+		layout.Template = template
+	}
+	if direction := strings.TrimSpace(req.ReadingDirection); direction != "" {
+		layout.Direction = page.ReadingDirection(direction)
+	}
+	if req.PageWidth > 0 {
+		layout.Width = req.PageWidth
+	}
+	if req.PageHeight > 0 {
+		layout.Height = req.PageHeight
+	}
+	if req.Margin != nil {
+		layout.Margin = *req.Margin
+	}
+	if req.Gutter != nil {
+		layout.Gutter = *req.Gutter
+	}
+	if req.Border != nil {
+		layout.Border = *req.Border
+	}
+
+	return layout, layout.Validate()
+}
+
+// loadImage 读取并解码存储中的图片
+func (s *MangaWorkflowService) loadImage(ctx context.Context, m *media.Media) (image.Image, error) {
+	reader, err := s.assets.Open(ctx, m.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	img, _, err := image.Decode(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image %s: %w", m.ID, err)
+	}
+	return img, nil
+}
+
+// renderPage 合成页面图片并在各面板内嵌字，写入存储并保存为 page 类型的媒体。
+// 返回每个面板实际嵌入的气泡数
+func (s *MangaWorkflowService) renderPage(ctx context.Context, novelID string, layout page.Layout, frames []imaging.Frame, balloons [][]lettering.Balloon) (*media.Media, []int, error) {
+	canvas := imaging.Compose(layout.Width, layout.Height, frames, layout.Border)
+
+	placed := make([]int, len(frames))
+	for i, frame := range frames {
+		if len(balloons[i]) == 0 {
+			continue
+		}
+		n, err := s.letterer.Letter(canvas, frame.Rect.Inset(layout.Border), balloons[i], layout.Direction == page.ReadingDirectionRTL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to letter panel: %w", err)
+		}
+		placed[i] = n
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, canvas, &jpeg.Options{Quality: pageQuality}); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode page: %w", err)
+	}
+
+	pageMedia := media.NewMediaForNovel(novelID, media.MediaTypePage)
+	asset, err := s.assets.SaveReader(ctx, &buf, string(pageMedia.ID))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to store page: %w", err)
+	}
+	pageMedia.MarkCompleted(asset.URL, imageMetadata(asset, layout.Width, layout.Height))
+
+	if err := s.mediaRepo.Save(ctx, pageMedia); err != nil {
+		return nil, nil, fmt.Errorf("failed to save page media: %w", err)
+	}
+	return pageMedia, placed, nil
+}
+
+// sceneScripts 小说各场景要嵌入的台词，按场景 ID 索引
+func (s *MangaWorkflowService) sceneScripts(ctx context.Context, novelID string) (map[string][]lettering.Balloon, error) {
+	scenes, err := s.orderedScenes(ctx, novel.NovelID(novelID))
+	if err != nil {
+		return nil, err
+	}
+
+	scripts := make(map[string][]lettering.Balloon, len(scenes))
+	for _, scn := range scenes {
+		scripts[string(scn.ID)] = sceneBalloons(scn)
+	}
+	return scripts, nil
+}
+
+// sceneBalloons 将场景台词按出现顺序转换为气泡：内心独白为思考气泡，旁白为旁白框
+func sceneBalloons(scn *scene.Scene) []lettering.Balloon {
+	balloons := make([]lettering.Balloon, 0, len(scn.Dialogues))
+	for _, d := range scn.Dialogues {
+		if strings.TrimSpace(d.Content) == "" {
+			continue
+		}
+		kind := lettering.KindSpeech
+		switch d.EffectiveKind() {
+		case scene.DialogueKindThought:
+			kind = lettering.KindThought
+		case scene.DialogueKindNarration:
+			kind = lettering.KindCaption
+		}
+		balloons = append(balloons, lettering.NewBalloon(kind, d.Speaker, d.Content, d.Emotion))
+	}
+	return balloons
+}
+
+func (s *MangaWorkflowService) toTaskPageResponses(pages []*page.Page, mediaByID map[string]*media.Media) []dto.TaskPageResponse {
+	responses := make([]dto.TaskPageResponse, 0, len(pages))
+	for _, p := range pages {
+		response := dto.TaskPageResponse{
+			PageNumber:       p.Number,
+			MediaID:          p.MediaID,
+			ContentURL:       mediaContentURL(s.links, p.MediaID),
+			ThumbnailURL:     mediaThumbnailURL(s.links, p.MediaID),
+			Template:         p.Template,
+			ReadingDirection: string(p.Direction),
+			Panels:           make([]dto.PagePanelResponse, 0, len(p.Panels)),
+			CreatedAt:        p.CreatedAt,
+		}
+		if m, ok := mediaByID[p.MediaID]; ok {
+			response.ImageURL = m.URL
+			response.Width = m.Metadata.Width
+			response.Height = m.Metadata.Height
+		}
+		for _, placement := range p.Panels {
+			response.Panels = append(response.Panels, dto.PagePanelResponse{
+				PanelNumber: placement.PanelNumber,
+				MediaID:     placement.MediaID,
+				X:           placement.X,
+				Y:           placement.Y,
+				Width:       placement.Width,
+				Height:      placement.Height,
+				Balloons:    placement.Balloons,
+			})
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
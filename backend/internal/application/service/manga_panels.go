@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/xiajiayi/ai-motion/internal/application/dto"
+	"github.com/xiajiayi/ai-motion/internal/domain/media"
+	"github.com/xiajiayi/ai-motion/internal/domain/scene"
+	"github.com/xiajiayi/ai-motion/internal/domain/task"
+	"github.com/xiajiayi/ai-motion/internal/domain/usage"
+)
+
+// RegeneratePanel 为已完成任务的单个面板生成新的图片版本，并选定为任务结果中使用的版本。
+// mediaID 可以是该面板的任意一个版本，旧版本保留在面板历史中，可通过 SelectPanelVersion 切换回去
+func (s *MangaWorkflowService) RegeneratePanel(ctx context.Context, userID, taskID, mediaID string, req *dto.RegeneratePanelRequest) (*dto.PanelVersionResponse, error) {
+	t, panel, err := s.findPanel(ctx, userID, taskID, mediaID)
+	if err != nil {
+		return nil, err
+	}
+	if !t.IsCompleted() {
+		return nil, task.ErrTaskNotCompleted
+	}
+	if err := s.usage.CheckQuota(ctx, userID); err != nil {
+		return nil, err
+	}
+	ctx = usage.WithCaller(ctx, usage.Caller{UserID: t.UserID, TaskID: t.ID})
+
+	scn, err := s.sceneRepo.FindByID(ctx, scene.SceneID(panel.SceneID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scene: %w", err)
+	}
+	characters, err := s.characterRepo.FindByNovelID(ctx, t.NovelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load characters: %w", err)
+	}
+
+	// 重新生成总是调用 AI 服务，参数未修改时也不复用缓存
+	options := t.Options
+	options.ForceRegenerate = true
+	override := panelOverride{
+		Prompt:         req.Prompt,
+		Seed:           req.Seed,
+		ReferenceImage: req.ReferenceImage,
+	}
+	mediaEntity, err := s.generateSceneImage(ctx, scn, characters, options, override)
+	if err != nil {
+		return nil, err
+	}
+
+	panel.AddVersion(string(mediaEntity.ID))
+	if err := s.panelRepo.Save(ctx, panel); err != nil {
+		return nil, fmt.Errorf("failed to save panel: %w", err)
+	}
+
+	version, _ := panel.ActiveVersion()
+	log.Printf("Regenerated panel %d of task %s as version %d: %s", panel.SequenceNum, t.ID, version.Version, mediaEntity.ID)
+
+	return s.toPanelVersionResponse(panel, version, mediaEntity), nil
+}
+
+// ListPanels 列出任务的全部面板及其版本历史，按面板序号升序
+func (s *MangaWorkflowService) ListPanels(ctx context.Context, userID, taskID string) ([]dto.PanelVersionsResponse, error) {
+	t, err := s.taskRepo.FindByIDAndUserID(ctx, taskID, userID)
+	if err != nil {
+		return nil, task.ErrTaskNotFound
+	}
+
+	panels, err := s.taskPanels(ctx, t)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load panels: %w", err)
+	}
+	mediaByID, err := s.novelMedia(ctx, t.NovelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load media: %w", err)
+	}
+
+	responses := make([]dto.PanelVersionsResponse, 0, len(panels))
+	for _, panel := range panels {
+		responses = append(responses, *s.toPanelVersionsResponse(panel, mediaByID))
+	}
+	return responses, nil
+}
+
+// ListPanelVersions 列出面板的版本历史，mediaID 可以是该面板的任意一个版本
+func (s *MangaWorkflowService) ListPanelVersions(ctx context.Context, userID, taskID, mediaID string) (*dto.PanelVersionsResponse, error) {
+	t, panel, err := s.findPanel(ctx, userID, taskID, mediaID)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaByID, err := s.novelMedia(ctx, t.NovelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load media: %w", err)
+	}
+
+	return s.toPanelVersionsResponse(panel, mediaByID), nil
+}
+
+// SelectPanelVersion 选定面板在任务结果中使用的版本
+func (s *MangaWorkflowService) SelectPanelVersion(ctx context.Context, userID, taskID, mediaID string) (*dto.PanelVersionResponse, error) {
+	t, panel, err := s.findPanel(ctx, userID, taskID, mediaID)
+	if err != nil {
+		return nil, err
+	}
+	if !t.IsCompleted() {
+		return nil, task.ErrTaskNotCompleted
+	}
+
+	mediaEntity, err := s.mediaRepo.FindByID(ctx, media.MediaID(mediaID))
+	if err != nil {
+		return nil, err
+	}
+	if !mediaEntity.IsReady() {
+		return nil, ErrMediaNotReady
+	}
+
+	if err := panel.Activate(mediaID); err != nil {
+		return nil, err
+	}
+	if err := s.panelRepo.Save(ctx, panel); err != nil {
+		return nil, fmt.Errorf("failed to save panel: %w", err)
+	}
+
+	version, _ := panel.ActiveVersion()
+	return s.toPanelVersionResponse(panel, version, mediaEntity), nil
+}
+
+// findPanel 加载用户的任务和包含 mediaID 版本的面板，图片不属于任务的任何面板时返回 task.ErrPanelNotFound
+func (s *MangaWorkflowService) findPanel(ctx context.Context, userID, taskID, mediaID string) (*task.Task, *task.Panel, error) {
+	t, err := s.taskRepo.FindByIDAndUserID(ctx, taskID, userID)
+	if err != nil {
+		return nil, nil, task.ErrTaskNotFound
+	}
+
+	panels, err := s.taskPanels(ctx, t)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load panels: %w", err)
+	}
+	for _, panel := range panels {
+		if _, ok := panel.Version(mediaID); ok {
+			return t, panel, nil
+		}
+	}
+
+	return nil, nil, task.ErrPanelNotFound
+}
+
+func (s *MangaWorkflowService) toPanelVersionsResponse(panel *task.Panel, mediaByID map[string]*media.Media) *dto.PanelVersionsResponse {
+	active, _ := panel.ActiveVersion()
+	response := &dto.PanelVersionsResponse{
+		SequenceNum:   panel.SequenceNum,
+		SceneID:       panel.SceneID,
+		ActiveMediaID: panel.ActiveMediaID,
+		ActiveVersion: active.Version,
+		Versions:      make([]dto.PanelVersionResponse, 0, len(panel.Versions)),
+	}
+	// 已删除的图片不再列出，版本号保持不变
+	for _, version := range panel.Versions {
+		m, ok := mediaByID[version.MediaID]
+		if !ok {
+			continue
+		}
+		response.Versions = append(response.Versions, *s.toPanelVersionResponse(panel, version, m))
+	}
+	return response
+}
+
+func (s *MangaWorkflowService) toPanelVersionResponse(panel *task.Panel, version task.PanelVersion, m *media.Media) *dto.PanelVersionResponse {
+	return &dto.PanelVersionResponse{
+		Version:      version.Version,
+		MediaID:      version.MediaID,
+		SequenceNum:  panel.SequenceNum,
+		SceneID:      panel.SceneID,
+		ImageURL:     s.links.Sign(m.URL),
+		ContentURL:   mediaContentURL(s.links, version.MediaID),
+		ThumbnailURL: mediaThumbnailURL(s.links, version.MediaID),
+		Active:       version.MediaID == panel.ActiveMediaID,
+		Generation:   toGenerationProvenance(m.Provenance),
+		CreatedAt:    version.CreatedAt,
+	}
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/xiajiayi/ai-motion/internal/application/dto"
+	"github.com/xiajiayi/ai-motion/internal/domain/character"
+	"github.com/xiajiayi/ai-motion/internal/domain/media"
+	"github.com/xiajiayi/ai-motion/internal/domain/novel"
+	"github.com/xiajiayi/ai-motion/internal/domain/scene"
+	"github.com/xiajiayi/ai-motion/internal/domain/task"
+	"github.com/xiajiayi/ai-motion/internal/domain/usage"
+	"github.com/xiajiayi/ai-motion/internal/domain/voiceover"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai"
+)
+
+var ErrSpeechUnavailable = errors.New("speech synthesizer not configured")
+
+// GenerateVoiceover 按章节和场景顺序为已完成任务的每句台词合成配音：说话人按名字匹配角色并使用角色的配音设置，
+// 旁白和未匹配的说话人使用默认设置。每句音频保存为 audio 类型的媒体，在场景内依次排开，替换任务原有的配音。
+// 合成前检查配额，每句台词的合成记入任务所属用户的用量
+func (s *MangaWorkflowService) GenerateVoiceover(ctx context.Context, userID, taskID string) ([]dto.TaskVoiceoverResponse, error) {
+	if s.speech == nil {
+		return nil, ErrSpeechUnavailable
+	}
+
+	t, err := s.taskRepo.FindByIDAndUserID(ctx, taskID, userID)
+	if err != nil {
+		return nil, task.ErrTaskNotFound
+	}
+	if !t.IsCompleted() {
+		return nil, task.ErrTaskNotCompleted
+	}
+	if err := s.usage.CheckQuota(ctx, userID); err != nil {
+		return nil, err
+	}
+	ctx = usage.WithCaller(ctx, usage.Caller{UserID: t.UserID, TaskID: t.ID})
+
+	scenes, err := s.orderedScenes(ctx, novel.NovelID(t.NovelID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scenes: %w", err)
+	}
+	characters, err := s.characterRepo.FindByNovelID(ctx, t.NovelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load characters: %w", err)
+	}
+	byName := make(map[string]*character.Character, len(characters))
+	for _, char := range characters {
+		byName[strings.TrimSpace(char.Name)] = char
+	}
+
+	var tracks []*voiceover.Track
+	var lines int
+	for _, scn := range scenes {
+		track := voiceover.NewTrack(t.ID, string(scn.ID))
+		for i, d := range scn.Dialogues {
+			text := strings.TrimSpace(d.Content)
+			if text == "" {
+				continue
+			}
+			line, err := s.voiceLine(ctx, t.NovelID, string(scn.ID), d, byName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to voice line %d of scene %d: %w", i+1, scn.SceneNumber, err)
+			}
+			line.Index = i
+			track.AddLine(line)
+		}
+		if len(track.Lines) > 0 {
+			tracks = append(tracks, track)
+			lines += len(track.Lines)
+		}
+	}
+	if len(tracks) == 0 {
+		return nil, voiceover.ErrNoDialogues
+	}
+
+	if err := s.voiceoverRepo.ReplaceByTaskID(ctx, t.ID, tracks); err != nil {
+		return nil, fmt.Errorf("failed to save voiceover: %w", err)
+	}
+
+	log.Printf("Voiced %d lines in %d scenes of task %s with %s", lines, len(tracks), t.ID, s.speech.Name())
+
+	return s.toTaskVoiceoverResponses(tracks, scenes), nil
+}
+
+// GetVoiceover 查询任务当前的配音，按章节和场景顺序排列，尚未合成时返回空列表
+func (s *MangaWorkflowService) GetVoiceover(ctx context.Context, userID, taskID string) ([]dto.TaskVoiceoverResponse, error) {
+	t, err := s.taskRepo.FindByIDAndUserID(ctx, taskID, userID)
+	if err != nil {
+		return nil, task.ErrTaskNotFound
+	}
+
+	tracks, err := s.voiceoverRepo.FindByTaskID(ctx, t.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load voiceover: %w", err)
+	}
+	scenes, err := s.orderedScenes(ctx, novel.NovelID(t.NovelID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scenes: %w", err)
+	}
+
+	return s.toTaskVoiceoverResponses(tracks, scenes), nil
+}
+
+// voiceLine 合成一句台词并保存音频，返回未排定开始时间的配音
+func (s *MangaWorkflowService) voiceLine(ctx context.Context, novelID, sceneID string, d scene.Dialogue, byName map[string]*character.Character) (voiceover.Line, error) {
+	line := voiceover.Line{Speaker: d.Speaker, Text: strings.TrimSpace(d.Content)}
+
+	var profile character.VoiceProfile
+	if d.EffectiveKind() != scene.DialogueKindNarration {
+		if char, ok := byName[strings.TrimSpace(d.Speaker)]; ok {
+			line.CharacterID = string(char.ID)
+			profile = char.Voice
+		}
+	}
+	profile = profile.WithDefaults()
+	line.Voice = profile.Voice
+
+	result, err := s.speech.Synthesize(ctx, ai.SpeechRequest{
+		Text:     line.Text,
+		Speaker:  d.Speaker,
+		Voice:    profile.Voice,
+		Language: profile.Language,
+		Speed:    profile.Speed,
+		Pitch:    profile.Pitch,
+		Emotion:  d.Emotion,
+	})
+	if err != nil {
+		return line, err
+	}
+
+	m := media.NewMediaForNovel(novelID, media.MediaTypeAudio)
+	m.SceneID = sceneID
+	asset, err := s.assets.SaveFile(ctx, result.Audio, string(m.ID), audioExtension(result.MimeType), result.MimeType)
+	if err != nil {
+		return line, fmt.Errorf("failed to store audio: %w", err)
+	}
+	m.MarkCompleted(asset.URL, media.MediaMetadata{
+		Duration: result.Duration,
+		Format:   asset.MimeType,
+		FileSize: asset.Size,
+	})
+	if err := s.mediaRepo.Save(ctx, m); err != nil {
+		return line, fmt.Errorf("failed to save audio media: %w", err)
+	}
+
+	line.MediaID = string(m.ID)
+	line.Duration = result.Duration
+	return line, nil
+}
+
+// audioExtension 语音合成服务返回的音频格式对应的扩展名
+func audioExtension(mimeType string) string {
+	if mimeType == "audio/mpeg" {
+		return ".mp3"
+	}
+	return ".wav"
+}
+
+// toTaskVoiceoverResponses 按场景顺序排列配音，已删除场景的配音排在最后
+func (s *MangaWorkflowService) toTaskVoiceoverResponses(tracks []*voiceover.Track, scenes []*scene.Scene) []dto.TaskVoiceoverResponse {
+	order := make(map[string]int, len(scenes))
+	numbers := make(map[string]int, len(scenes))
+	for i, scn := range scenes {
+		order[string(scn.ID)] = i
+		numbers[string(scn.ID)] = scn.SceneNumber
+	}
+	position := func(sceneID string) int {
+		if i, ok := order[sceneID]; ok {
+			return i
+		}
+		return len(scenes)
+	}
+
+	sorted := append([]*voiceover.Track(nil), tracks...)
+	slices.SortStableFunc(sorted, func(a, b *voiceover.Track) int {
+		return position(a.SceneID) - position(b.SceneID)
+	})
+
+	responses := make([]dto.TaskVoiceoverResponse, 0, len(sorted))
+	for _, track := range sorted {
+		response := dto.TaskVoiceoverResponse{
+			SceneID:     track.SceneID,
+			SceneNumber: numbers[track.SceneID],
+			Duration:    track.Duration,
+			Lines:       make([]dto.VoiceoverLineResponse, 0, len(track.Lines)),
+			CreatedAt:   track.CreatedAt,
+		}
+		for _, line := range track.Lines {
+			response.Lines = append(response.Lines, dto.VoiceoverLineResponse{
+				Index:       line.Index,
+				Speaker:     line.Speaker,
+				CharacterID: line.CharacterID,
+				Text:        line.Text,
+				Voice:       line.Voice,
+				MediaID:     line.MediaID,
+				AudioURL:    mediaContentURL(s.links, line.MediaID),
+				Start:       line.Start,
+				Duration:    line.Duration,
+			})
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
@@ -2,20 +2,36 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/xiajiayi/ai-motion/internal/application/dto"
 	"github.com/xiajiayi/ai-motion/internal/domain/character"
+	"github.com/xiajiayi/ai-motion/internal/domain/export"
 	"github.com/xiajiayi/ai-motion/internal/domain/media"
 	"github.com/xiajiayi/ai-motion/internal/domain/novel"
+	"github.com/xiajiayi/ai-motion/internal/domain/page"
 	"github.com/xiajiayi/ai-motion/internal/domain/scene"
 	"github.com/xiajiayi/ai-motion/internal/domain/task"
-	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai/gemini"
+	"github.com/xiajiayi/ai-motion/internal/domain/usage"
+	"github.com/xiajiayi/ai-motion/internal/domain/voiceover"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/imaging"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/storage"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/video"
 )
 
 type MangaWorkflowService struct {
 	taskRepo         task.Repository
+	taskEventRepo    task.EventRepository
+	panelRepo        task.PanelRepository
+	pageRepo         page.Repository
+	exportRepo       export.Repository
+	voiceoverRepo    voiceover.Repository
 	novelRepo        novel.NovelRepository
 	chapterRepo      novel.ChapterRepository
 	characterRepo    character.CharacterRepository
@@ -24,11 +40,37 @@ type MangaWorkflowService struct {
 	parserService    *novel.ParserService
 	extractorService *character.CharacterExtractorService
 	dividerService   *scene.SceneDividerService
-	geminiClient     *gemini.Client
+	promptGenerator  *scene.PromptGeneratorService
+	imageProviders   *ai.ImageRegistry
+	assets           *storage.AssetStore
+	cache            *GenerationCache
+	usage            *UsageService
+	letterer         *imaging.Letterer
+	videoEncoder     video.Encoder
+	speech           ai.SpeechSynthesizer
+	links            *storage.URLSigner
+	cancellations    *TaskCancelRegistry
+	eventBroker      *TaskEventBroker
 }
 
+const (
+	// cancelPollInterval 执行中轮询数据库取消标记的间隔，用于感知其他实例发起的取消
+	cancelPollInterval = 3 * time.Second
+	// eventPollInterval 事件订阅轮询数据库的间隔，用于获取其他实例产生的事件
+	eventPollInterval = time.Second
+	// eventPageSize 每次读取的事件数量
+	eventPageSize = 100
+	// eventSettleDelay 任务结束后等待终态事件写入的时间，超过后即使没有终态事件也结束订阅
+	eventSettleDelay = 5 * time.Second
+)
+
 func NewMangaWorkflowService(
 	taskRepo task.Repository,
+	taskEventRepo task.EventRepository,
+	panelRepo task.PanelRepository,
+	pageRepo page.Repository,
+	exportRepo export.Repository,
+	voiceoverRepo voiceover.Repository,
 	novelRepo novel.NovelRepository,
 	chapterRepo novel.ChapterRepository,
 	characterRepo character.CharacterRepository,
@@ -37,10 +79,23 @@ func NewMangaWorkflowService(
 	parserService *novel.ParserService,
 	extractorService *character.CharacterExtractorService,
 	dividerService *scene.SceneDividerService,
-	geminiClient *gemini.Client,
+	promptGenerator *scene.PromptGeneratorService,
+	imageProviders *ai.ImageRegistry,
+	assets *storage.AssetStore,
+	cache *GenerationCache,
+	usageService *UsageService,
+	letterer *imaging.Letterer,
+	videoEncoder video.Encoder,
+	speech ai.SpeechSynthesizer,
+	links *storage.URLSigner,
 ) *MangaWorkflowService {
 	return &MangaWorkflowService{
 		taskRepo:         taskRepo,
+		taskEventRepo:    taskEventRepo,
+		panelRepo:        panelRepo,
+		pageRepo:         pageRepo,
+		exportRepo:       exportRepo,
+		voiceoverRepo:    voiceoverRepo,
 		novelRepo:        novelRepo,
 		chapterRepo:      chapterRepo,
 		characterRepo:    characterRepo,
@@ -49,13 +104,32 @@ func NewMangaWorkflowService(
 		parserService:    parserService,
 		extractorService: extractorService,
 		dividerService:   dividerService,
-		geminiClient:     geminiClient,
+		promptGenerator:  promptGenerator,
+		imageProviders:   imageProviders,
+		assets:           assets,
+		cache:            cache,
+		usage:            usageService,
+		letterer:         letterer,
+		videoEncoder:     videoEncoder,
+		speech:           speech,
+		links:            links,
+		cancellations:    NewTaskCancelRegistry(),
+		eventBroker:      NewTaskEventBroker(),
 	}
 }
 
 // CreateTask 创建漫画生成任务
 func (s *MangaWorkflowService) CreateTask(ctx context.Context, userID string, req *dto.GenerateMangaRequest) (*task.Task, error) {
-	// 1. 创建Novel实体
+	// 1. 校验生成参数和用户配额，避免创建无法执行的任务
+	options, err := s.resolveOptions(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.usage.CheckQuota(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	// 2. 创建Novel实体
 	author := req.Author
 	if author == "" {
 		author = "Unknown"
@@ -65,15 +139,16 @@ func (s *MangaWorkflowService) CreateTask(ctx context.Context, userID string, re
 		return nil, fmt.Errorf("failed to create novel: %w", err)
 	}
 
-	// 2. 保存Novel到数据库
+	// 3. 保存Novel到数据库
 	if err := s.novelRepo.Save(ctx, novelEntity); err != nil {
 		return nil, fmt.Errorf("failed to save novel: %w", err)
 	}
 
-	// 3. 创建Task实体
+	// 4. 创建Task实体
 	taskEntity := task.NewTask(userID, string(novelEntity.ID))
+	taskEntity.Options = options
 
-	// 4. 保存Task到数据库
+	// 5. 保存Task到数据库
 	if err := s.taskRepo.Save(ctx, taskEntity); err != nil {
 		return nil, fmt.Errorf("failed to save task: %w", err)
 	}
@@ -81,145 +156,589 @@ func (s *MangaWorkflowService) CreateTask(ctx context.Context, userID string, re
 	return taskEntity, nil
 }
 
-// ExecuteTask 异步执行任务（在goroutine中调用）
-func (s *MangaWorkflowService) ExecuteTask(ctx context.Context, taskID string) {
+// resolveOptions 校验请求中的生成参数，并按画风对应图像服务的能力解析面板尺寸。
+// 同时指定宽高比和方向时以宽高比为准
+func (s *MangaWorkflowService) resolveOptions(req *dto.GenerateMangaRequest) (task.GenerationOptions, error) {
+	options := task.GenerationOptions{
+		PanelCount:      req.PanelCount,
+		ArtStyle:        strings.TrimSpace(req.ArtStyle),
+		NegativePrompt:  strings.TrimSpace(req.NegativePrompt),
+		ForceRegenerate: req.ForceRegenerate,
+	}
+
+	if options.PanelCount < 0 || options.PanelCount > task.MaxPanelCount {
+		return options, fmt.Errorf("%w: panel_count must be between 1 and %d", task.ErrInvalidOptions, task.MaxPanelCount)
+	}
+
+	if options.ArtStyle == "" {
+		options.ArtStyle = string(scene.PromptStyleAnime)
+	} else if !scene.IsValidPromptStyle(options.ArtStyle) {
+		return options, fmt.Errorf("%w: unsupported art_style %q", task.ErrInvalidOptions, options.ArtStyle)
+	}
+
+	ratio := strings.TrimSpace(req.AspectRatio)
+	if ratio == "" {
+		switch req.Orientation {
+		case "portrait":
+			ratio = "9:16"
+		case "square":
+			ratio = "1:1"
+		default:
+			ratio = "16:9"
+		}
+	}
+
+	generator, err := s.imageProviders.ForStyle(options.ArtStyle)
+	if err != nil {
+		return options, fmt.Errorf("failed to resolve image provider: %w", err)
+	}
+
+	size, err := generator.Capabilities().SizeForAspectRatio(ratio)
+	if err != nil {
+		return options, fmt.Errorf("%w: %s: %v", task.ErrInvalidOptions, generator.Name(), err)
+	}
+
+	options.AspectRatio = ratio
+	options.Width = size.Width
+	options.Height = size.Height
+	return options, nil
+}
+
+// ExecuteTask 执行任务（由后台任务队列领取后调用）
+// ctx 被取消（服务关闭或租约丢失）时不标记失败，任务保留给下一个 worker 继续执行；
+// 任务被用户取消时中止执行并保持 cancelled 状态
+func (s *MangaWorkflowService) ExecuteTask(ctx context.Context, taskID string) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	s.cancellations.Register(taskID, cancel)
+	defer s.cancellations.Unregister(taskID)
+	go s.watchCancellation(ctx, taskID, cancel)
+
 	// 1. 加载任务
 	taskEntity, err := s.taskRepo.FindByID(ctx, taskID)
 	if err != nil {
 		log.Printf("Failed to load task %s: %v", taskID, err)
-		return
+		return fmt.Errorf("failed to load task: %w", err)
 	}
+	if taskEntity.IsCancelled() {
+		return nil
+	}
+	// 本任务触发的 AI 调用记入任务所属用户的用量
+	ctx = usage.WithCaller(ctx, usage.Caller{UserID: taskEntity.UserID, TaskID: taskID})
 
 	// 2. 加载小说
 	novelEntity, err := s.novelRepo.FindByID(ctx, novel.NovelID(taskEntity.NovelID))
 	if err != nil {
+		if ctx.Err() != nil {
+			return s.interrupted(ctx, taskID)
+		}
 		log.Printf("Failed to load novel %s: %v", taskEntity.NovelID, err)
-		taskEntity.MarkFailed(50001, "加载小说失败")
-		s.taskRepo.Save(ctx, taskEntity)
+		taskEntity.MarkFailed(task.ErrorCodeNovelLoad, "加载小说失败")
+		s.saveProgress(ctx, taskEntity)
+		return fmt.Errorf("failed to load novel: %w", err)
+	}
+
+	// 步骤1-5: 解析章节、提取角色、生成角色参考图、划分场景、生成场景图片
+	if err := s.runPipeline(ctx, taskEntity, novelEntity); err != nil {
+		if ctx.Err() != nil || errors.Is(err, task.ErrTaskCancelled) {
+			return s.interrupted(ctx, taskID)
+		}
+		code := task.ErrorCodeAIService
+		message := fmt.Sprintf("生成漫画失败: %v", err)
+		var stepErr *stepError
+		if errors.As(err, &stepErr) {
+			code = stepErr.code
+		} else if errors.Is(err, ai.ErrCircuitOpen) {
+			code = task.ErrorCodeProviderUnavailable
+			message = fmt.Sprintf("AI 服务暂时不可用，请稍后重试: %v", err)
+		}
+		taskEntity.MarkFailed(code, message)
+		s.saveProgress(ctx, taskEntity)
+		return err
+	}
+
+	// 步骤6: 完成
+	taskEntity.MarkCompleted()
+	if err := s.saveProgress(ctx, taskEntity); err != nil {
+		if errors.Is(err, task.ErrTaskCancelled) {
+			return s.interrupted(ctx, taskID)
+		}
+		return fmt.Errorf("failed to save task: %w", err)
+	}
+
+	return nil
+}
+
+// saveProgress 保存执行中的任务状态，任务已在数据库中被取消时返回 ErrTaskCancelled，
+// 避免进度更新覆盖用户的取消操作
+func (s *MangaWorkflowService) saveProgress(ctx context.Context, t *task.Task) error {
+	saved, err := s.taskRepo.SaveUnlessCancelled(ctx, t)
+	if err != nil {
+		return err
+	}
+	if !saved {
+		t.PullEvents()
+		return task.ErrTaskCancelled
+	}
+	s.publishEvents(ctx, t)
+	return nil
+}
+
+// publishEvents 持久化任务实体记录的事件并通知订阅者，失败只记录日志，不影响任务执行
+func (s *MangaWorkflowService) publishEvents(ctx context.Context, t *task.Task) {
+	events := t.PullEvents()
+	if len(events) == 0 {
 		return
 	}
 
-	// 步骤1: 生成漫画图片(直接调用 Gemini 生成10张)
-	if err := s.stepGenerateMangaImages(ctx, taskEntity, novelEntity); err != nil {
-		taskEntity.MarkFailed(40001, fmt.Sprintf("生成漫画失败: %v", err))
-		s.taskRepo.Save(ctx, taskEntity)
+	if err := s.taskEventRepo.Append(ctx, events); err != nil {
+		log.Printf("Failed to append events for task %s: %v", t.ID, err)
 		return
 	}
+	s.eventBroker.Publish(t.ID)
+}
 
-	// 步骤2: 完成
-	taskEntity.MarkCompleted()
-	s.taskRepo.Save(ctx, taskEntity)
+// interrupted 任务被中断后的返回值：用户取消视为正常结束，其他原因交由任务队列处理
+func (s *MangaWorkflowService) interrupted(ctx context.Context, taskID string) error {
+	cause := context.Cause(ctx)
+	if ctx.Err() == nil || errors.Is(cause, task.ErrTaskCancelled) {
+		log.Printf("Task %s cancelled, stopped execution", taskID)
+		return nil
+	}
+	return cause
+}
+
+// watchCancellation 定期检查数据库中的取消标记，任务在其他实例上被取消时中断本地执行
+func (s *MangaWorkflowService) watchCancellation(ctx context.Context, taskID string, cancel context.CancelCauseFunc) {
+	ticker := time.NewTicker(cancelPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		status, err := s.taskRepo.FindStatus(ctx, taskID)
+		if err != nil {
+			continue
+		}
+		if status == task.TaskStatusCancelled {
+			cancel(task.ErrTaskCancelled)
+			return
+		}
+	}
 }
 
-// 步骤1: 生成漫画图片（直接调用 Gemini 生成10张漫画）
-func (s *MangaWorkflowService) stepGenerateMangaImages(ctx context.Context, t *task.Task, n *novel.Novel) error {
-	if t.IsCancelled() {
-		return fmt.Errorf("task cancelled")
+// stepError 流水线步骤失败，携带任务错误码
+type stepError struct {
+	code int
+	err  error
+}
+
+func (e *stepError) Error() string { return e.err.Error() }
+
+func (e *stepError) Unwrap() error { return e.err }
+
+// stepRanges 各步骤在总进度百分比中所占的区间，按步骤索引排列
+var stepRanges = [task.TotalSteps + 1]struct {
+	name       string
+	start, end int
+}{
+	task.StepParseNovel:         {"解析小说", 0, 5},
+	task.StepExtractCharacters:  {"提取角色", 5, 10},
+	task.StepGenerateCharacters: {"生成角色参考图", 10, 30},
+	task.StepDivideScenes:       {"划分场景", 30, 35},
+	task.StepGenerateScenes:     {"生成场景图片", 35, 100},
+	task.StepCompleted:          {"完成", 100, 100},
+}
+
+// reportProgress 按步骤内完成数量换算总进度并保存
+func (s *MangaWorkflowService) reportProgress(ctx context.Context, t *task.Task, stepIndex, done, total int, details task.ProgressDetails) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r := stepRanges[stepIndex]
+	percentage := r.start
+	step := r.name
+	if total > 0 {
+		percentage += (r.end - r.start) * done / total
+		if total > 1 {
+			step = fmt.Sprintf("%s %d/%d", r.name, min(done+1, total), total)
+		}
 	}
 
-	const totalImages = 10
+	t.UpdateProgress(step, stepIndex, percentage, details)
+	return s.saveProgress(ctx, t)
+}
+
+// runPipeline 执行漫画生成流水线，每一步都会复用已有结果，任务中断或重试后从断点继续
+func (s *MangaWorkflowService) runPipeline(ctx context.Context, t *task.Task, n *novel.Novel) error {
 	details := t.ProgressDetails
+	options := t.Options.WithDefaults()
+
+	// 步骤1: 解析章节
+	chapters, err := s.stepParseNovel(ctx, t, n, &details)
+	if err != nil {
+		return err
+	}
+
+	// 步骤2: 提取角色
+	characters, err := s.stepExtractCharacters(ctx, t, n, &details)
+	if err != nil {
+		return err
+	}
+
+	// 步骤3: 生成角色参考图
+	if err := s.stepGenerateCharacterImages(ctx, t, characters, options, &details); err != nil {
+		return err
+	}
+
+	// 步骤4: 划分场景并匹配角色
+	scenes, err := s.stepDivideScenes(ctx, t, chapters, characters, &details)
+	if err != nil {
+		return err
+	}
+
+	// 步骤5: 生成场景图片
+	return s.stepGenerateSceneImages(ctx, t, n, selectPanelScenes(scenes, options.PanelCount), characters, options, &details)
+}
 
-	// 准备小说内容摘要（限制长度）
-	contentSummary := n.Content
-	if len(contentSummary) > 2000 {
-		contentSummary = contentSummary[:2000] + "..."
+// 步骤1: 解析小说章节，已解析过的小说直接复用章节
+func (s *MangaWorkflowService) stepParseNovel(ctx context.Context, t *task.Task, n *novel.Novel, details *task.ProgressDetails) ([]novel.Chapter, error) {
+	if err := s.reportProgress(ctx, t, task.StepParseNovel, 0, 1, *details); err != nil {
+		return nil, err
+	}
+
+	chapters, err := s.chapterRepo.FindByNovelID(ctx, n.ID)
+	if err != nil {
+		return nil, &stepError{task.ErrorCodePipeline, fmt.Errorf("failed to load chapters: %w", err)}
+	}
+	if len(chapters) > 0 {
+		return chapters, nil
 	}
 
-	for i := 0; i < totalImages; i++ {
-		if t.IsCancelled() {
-			return fmt.Errorf("task cancelled")
+	if err := s.parserService.Parse(n); err != nil {
+		return nil, &stepError{task.ErrorCodeNovelLoad, fmt.Errorf("failed to parse novel: %w", err)}
+	}
+	if err := s.novelRepo.Save(ctx, n); err != nil {
+		return nil, &stepError{task.ErrorCodePipeline, fmt.Errorf("failed to save novel: %w", err)}
+	}
+	if err := s.chapterRepo.SaveBatch(ctx, n.Chapters); err != nil {
+		return nil, &stepError{task.ErrorCodePipeline, fmt.Errorf("failed to save chapters: %w", err)}
+	}
+
+	log.Printf("Parsed novel %s into %d chapters", n.ID, len(n.Chapters))
+	return n.Chapters, nil
+}
+
+// 步骤2: 提取角色，已提取的同名角色不会重复创建
+func (s *MangaWorkflowService) stepExtractCharacters(ctx context.Context, t *task.Task, n *novel.Novel, details *task.ProgressDetails) ([]*character.Character, error) {
+	if err := s.reportProgress(ctx, t, task.StepExtractCharacters, 0, 1, *details); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.extractorService.ExtractFromNovel(ctx, string(n.ID), n.Content); err != nil {
+		return nil, &stepError{task.ErrorCodePipeline, fmt.Errorf("failed to extract characters: %w", err)}
+	}
+
+	characters, err := s.characterRepo.FindByNovelID(ctx, string(n.ID))
+	if err != nil {
+		return nil, &stepError{task.ErrorCodePipeline, fmt.Errorf("failed to load characters: %w", err)}
+	}
+
+	details.CharactersExtracted = len(characters)
+	log.Printf("Extracted %d characters for novel %s", len(characters), n.ID)
+	return characters, nil
+}
+
+// 步骤3: 为尚无参考图的角色生成参考图
+func (s *MangaWorkflowService) stepGenerateCharacterImages(ctx context.Context, t *task.Task, characters []*character.Character, options task.GenerationOptions, details *task.ProgressDetails) error {
+	generated := 0
+	for _, char := range characters {
+		if char.HasReferenceImage() {
+			generated++
+		}
+	}
+	details.CharactersGenerated = generated
+
+	for _, char := range characters {
+		if char.HasReferenceImage() {
+			continue
+		}
+
+		if err := s.reportProgress(ctx, t, task.StepGenerateCharacters, generated, len(characters), *details); err != nil {
+			return err
+		}
+
+		if err := s.generateCharacterReferenceImage(ctx, char, options); err != nil {
+			log.Printf("Failed to generate reference image for character %s: %v", char.Name, err)
+			return fmt.Errorf("failed to generate reference image for %s: %w", char.Name, err)
 		}
 
-		// 更新进度
-		percentage := (i + 1) * 100 / totalImages
-		details.ScenesGenerated = i
-		t.UpdateProgress(fmt.Sprintf("生成漫画图片 %d/%d", i+1, totalImages), 1, percentage, details)
-		s.taskRepo.Save(ctx, t)
+		generated++
+		details.CharactersGenerated = generated
+	}
+
+	return s.reportProgress(ctx, t, task.StepGenerateCharacters, 1, 1, *details)
+}
 
-		// 构建 Prompt：让 Gemini 根据小说内容生成漫画风格的图片
-		prompt := s.buildMangaPanelPrompt(n.Title, contentSummary, i+1, totalImages)
+// 步骤4: 按章节划分场景并匹配出场角色，已划分的章节直接复用
+func (s *MangaWorkflowService) stepDivideScenes(ctx context.Context, t *task.Task, chapters []novel.Chapter, characters []*character.Character, details *task.ProgressDetails) ([]*scene.Scene, error) {
+	var scenes []*scene.Scene
 
-		// 调用 Gemini 文生图接口
-		req := gemini.TextToImageRequest{
-			Prompt: prompt,
-			Width:  1344,
-			Height: 768,
+	for i, ch := range chapters {
+		if err := s.reportProgress(ctx, t, task.StepDivideScenes, i, len(chapters), *details); err != nil {
+			return nil, err
 		}
 
-		imageURL, err := s.geminiClient.TextToImage(ctx, req)
+		chapterScenes, err := s.sceneRepo.FindByChapterID(ctx, ch.ID)
 		if err != nil {
-			log.Printf("Failed to generate manga image %d: %v", i+1, err)
-			return fmt.Errorf("failed to generate manga image %d: %w", i+1, err)
+			return nil, &stepError{task.ErrorCodePipeline, fmt.Errorf("failed to load scenes of chapter %d: %w", ch.ChapterNumber, err)}
 		}
 
-		// 保存 Media 实体（使用 NewMediaForNovel 关联小说）
-		mediaEntity := media.NewMediaForNovel(string(n.ID), media.MediaTypeImage)
-		metadata := media.NewImageMetadata(1344, 768, "image/jpeg", 0)
-		mediaEntity.MarkCompleted(imageURL, metadata)
+		if len(chapterScenes) == 0 {
+			chapterScenes, err = s.dividerService.DivideChapterIntoScenes(ctx, scene.Chapter{
+				ID:      ch.ID,
+				NovelID: string(ch.NovelID),
+				Content: ch.Content,
+			})
+			if err != nil {
+				return nil, &stepError{task.ErrorCodePipeline, fmt.Errorf("failed to divide chapter %d: %w", ch.ChapterNumber, err)}
+			}
+		}
 
-		if err := s.mediaRepo.Save(ctx, mediaEntity); err != nil {
-			return fmt.Errorf("failed to save media %d: %w", i+1, err)
+		for _, scn := range chapterScenes {
+			matched := s.matchCharactersToScene(scn, characters)
+			if slices.Equal(matched, scn.CharacterIDs) {
+				continue
+			}
+			scn.SetCharacters(matched)
+			if err := s.sceneRepo.Save(ctx, scn); err != nil {
+				return nil, &stepError{task.ErrorCodePipeline, fmt.Errorf("failed to save scene characters: %w", err)}
+			}
 		}
 
-		log.Printf("Successfully generated manga image %d/%d for novel %s", i+1, totalImages, n.ID)
+		scenes = append(scenes, chapterScenes...)
+	}
+
+	if len(scenes) == 0 {
+		return nil, &stepError{task.ErrorCodeNoScenes, fmt.Errorf("no scenes divided from novel")}
 	}
 
-	// 完成所有图片生成
-	details.ScenesGenerated = totalImages
-	t.UpdateProgress("生成漫画图片完成", 1, 100, details)
-	s.taskRepo.Save(ctx, t)
+	details.ScenesDivided = len(scenes)
+	return scenes, nil
+}
+
+// selectPanelScenes 面板数少于场景数时在全部场景中等间隔选取，保证覆盖整个故事；
+// 选取结果只取决于场景列表，任务恢复或重试时选中的场景保持不变
+func selectPanelScenes(scenes []*scene.Scene, panelCount int) []*scene.Scene {
+	if panelCount <= 0 || panelCount >= len(scenes) {
+		return scenes
+	}
 
+	selected := make([]*scene.Scene, 0, panelCount)
+	for i := 0; i < panelCount; i++ {
+		selected = append(selected, scenes[i*len(scenes)/panelCount])
+	}
+	return selected
+}
+
+// 步骤5: 按顺序为每个面板对应的场景生成图片，已有完成图片的场景直接跳过。
+// 每张图片记入面板位置 (任务ID, 面板序号) 的版本历史，面板顺序由序号决定
+func (s *MangaWorkflowService) stepGenerateSceneImages(ctx context.Context, t *task.Task, n *novel.Novel, scenes []*scene.Scene, characters []*character.Character, options task.GenerationOptions, details *task.ProgressDetails) error {
+	completed, err := s.completedSceneImages(ctx, string(n.ID))
+	if err != nil {
+		return &stepError{task.ErrorCodePipeline, err}
+	}
+	panels, err := s.panelRepo.FindByTaskID(ctx, t.ID)
+	if err != nil {
+		return &stepError{task.ErrorCodePipeline, fmt.Errorf("failed to load panels: %w", err)}
+	}
+	slots := make(map[int]*task.Panel, len(panels))
+	for _, panel := range panels {
+		slots[panel.SequenceNum] = panel
+	}
+
+	generated := 0
+	for _, scn := range scenes {
+		if _, ok := completed[string(scn.ID)]; ok {
+			generated++
+		}
+	}
+	if generated > 0 {
+		log.Printf("Resuming task %s with %d/%d scene images already generated", t.ID, generated, len(scenes))
+	}
+	details.ScenesGenerated = generated
+
+	for i, scn := range scenes {
+		if existing, ok := completed[string(scn.ID)]; ok {
+			// 上次执行已生成图片但面板未保存时补齐面板
+			if _, ok := slots[i+1]; !ok {
+				if err := s.recordPanelVersion(ctx, slots, t.ID, i+1, string(scn.ID), string(existing.ID)); err != nil {
+					return &stepError{task.ErrorCodePipeline, err}
+				}
+			}
+			continue
+		}
+
+		if err := s.reportProgress(ctx, t, task.StepGenerateScenes, generated, len(scenes), *details); err != nil {
+			return err
+		}
+
+		mediaEntity, err := s.generateSceneImage(ctx, scn, characters, options, panelOverride{})
+		if err != nil {
+			log.Printf("Failed to generate image for scene %d: %v", i+1, err)
+			return fmt.Errorf("failed to generate image for scene %d: %w", i+1, err)
+		}
+
+		if err := s.recordPanelVersion(ctx, slots, t.ID, i+1, string(scn.ID), string(mediaEntity.ID)); err != nil {
+			return &stepError{task.ErrorCodePipeline, err}
+		}
+
+		generated++
+		details.ScenesGenerated = generated
+		t.RecordPanelCompleted(i+1, string(mediaEntity.ID), mediaEntity.URL)
+		s.publishEvents(ctx, t)
+
+		log.Printf("Successfully generated scene image %d/%d for novel %s", i+1, len(scenes), n.ID)
+	}
+
+	return s.reportProgress(ctx, t, task.StepGenerateScenes, generated, len(scenes), *details)
+}
+
+// recordPanelVersion 将图片加入面板的版本历史并设为当前版本，面板不存在时创建
+func (s *MangaWorkflowService) recordPanelVersion(ctx context.Context, slots map[int]*task.Panel, taskID string, sequenceNum int, sceneID, mediaID string) error {
+	panel, ok := slots[sequenceNum]
+	if !ok {
+		panel = task.NewPanel(taskID, sequenceNum, sceneID)
+		slots[sequenceNum] = panel
+	}
+	panel.AddVersion(mediaID)
+
+	if err := s.panelRepo.Save(ctx, panel); err != nil {
+		return fmt.Errorf("failed to save panel %d: %w", sequenceNum, err)
+	}
 	return nil
 }
 
-// buildMangaPanelPrompt 构建漫画面板的 Prompt
-func (s *MangaWorkflowService) buildMangaPanelPrompt(title, content string, panelNum, totalPanels int) string {
-	// 为不同的面板生成不同的 Prompt，确保故事连贯性
-	stageDesc := ""
-	switch {
-	case panelNum <= 2:
-		stageDesc = "opening scene, introducing the setting and atmosphere"
-	case panelNum <= 4:
-		stageDesc = "introducing main characters and their personalities"
-	case panelNum <= 7:
-		stageDesc = "developing the plot with key story events"
-	case panelNum <= 9:
-		stageDesc = "building toward the climax with tension and drama"
-	default:
-		stageDesc = "conclusion or cliffhanger ending"
-	}
-
-	prompt := fmt.Sprintf(
-		"Create a beautiful anime manga panel (image %d of %d) based on the novel titled '%s'. "+
-			"Story context: %s. "+
-			"This panel should depict: %s. "+
-			"Style: Professional anime manga art with clean lines, dynamic composition, expressive characters, "+
-			"cinematic lighting, and detailed backgrounds. "+
-			"Use vibrant colors and dramatic angles typical of high-quality manga adaptations. "+
-			"Make it visually engaging and emotionally resonant.",
-		panelNum, totalPanels, title, content, stageDesc,
-	)
+// completedSceneImages 查询小说下各场景最新一张已完成的图片
+func (s *MangaWorkflowService) completedSceneImages(ctx context.Context, novelID string) (map[string]*media.Media, error) {
+	versions, err := s.sceneImageVersions(ctx, novelID)
+	if err != nil {
+		return nil, err
+	}
 
-	return prompt
+	images := make(map[string]*media.Media, len(versions))
+	for sceneID, list := range versions {
+		images[sceneID] = list[len(list)-1]
+	}
+
+	return images, nil
+}
+
+// sceneImageVersions 查询小说下各场景全部已完成的图片，按生成时间升序
+func (s *MangaWorkflowService) sceneImageVersions(ctx context.Context, novelID string) (map[string][]*media.Media, error) {
+	mediaList, err := s.mediaRepo.FindByNovelID(ctx, novelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load existing media: %w", err)
+	}
+
+	versions := make(map[string][]*media.Media)
+	for _, m := range mediaList {
+		if m.SceneID == "" || m.Type != media.MediaTypeImage || m.Status != media.MediaStatusCompleted {
+			continue
+		}
+		versions[m.SceneID] = append(versions[m.SceneID], m)
+	}
+	for _, list := range versions {
+		slices.SortStableFunc(list, func(a, b *media.Media) int {
+			return a.CreatedAt.Compare(b.CreatedAt)
+		})
+	}
+
+	return versions, nil
+}
+
+// RecoverInterruptedTasks 服务启动时处理上次进程退出时仍在执行的任务：
+// 可恢复的任务重新置为待处理，由任务队列从最后完成的面板继续执行；
+// 超过 maxAge 或小说已丢失的任务标记为可重试的失败。
+// 尚未开始的 pending 任务不在此处理：任务队列领取租约为空或已过期的 pending 任务，执行时同样校验小说
+func (s *MangaWorkflowService) RecoverInterruptedTasks(ctx context.Context, maxAge time.Duration) error {
+	const batchSize = 100
+
+	tasks, err := s.taskRepo.FindInterrupted(ctx, batchSize)
+	if err != nil {
+		return fmt.Errorf("failed to find interrupted tasks: %w", err)
+	}
+
+	for _, t := range tasks {
+		if _, err := s.novelRepo.FindByID(ctx, novel.NovelID(t.NovelID)); err != nil {
+			t.MarkFailed(task.ErrorCodeNovelLoad, "任务中断后恢复失败: 加载小说失败")
+		} else if maxAge > 0 && !t.CreatedAt.IsZero() && time.Since(t.CreatedAt) > maxAge {
+			t.MarkFailed(task.ErrorCodeInterrupted, "任务因服务重启中断，请重试")
+		} else {
+			t.Requeue()
+		}
+
+		// 条件更新，查询后被用户取消的任务不会被改回待处理
+		saved, err := s.taskRepo.SaveUnlessCancelled(ctx, t)
+		if err != nil {
+			log.Printf("Failed to recover task %s: %v", t.ID, err)
+			continue
+		}
+		if !saved {
+			t.PullEvents()
+			log.Printf("Skipped recovering task %s: cancelled", t.ID)
+			continue
+		}
+		s.publishEvents(ctx, t)
+
+		log.Printf("Recovered interrupted task %s (status: %s, scenes generated: %d)",
+			t.ID, t.Status, t.ProgressDetails.ScenesGenerated)
+	}
+
+	return nil
 }
 
-func (s *MangaWorkflowService) generateCharacterReferenceImage(ctx context.Context, char *character.Character) error {
-	prompt := s.buildCharacterPrompt(char)
+func (s *MangaWorkflowService) generateCharacterReferenceImage(ctx context.Context, char *character.Character, options task.GenerationOptions) error {
+	prompt := s.buildCharacterPrompt(char, options.ArtStyle)
+
+	generator, err := s.imageProviders.ForStyle(options.ArtStyle)
+	if err != nil {
+		return fmt.Errorf("failed to resolve image provider: %w", err)
+	}
 
-	req := gemini.TextToImageRequest{
+	// 参考图优先使用方图，服务不支持时沿用面板尺寸
+	size, err := generator.Capabilities().SizeForAspectRatio("1:1")
+	if err != nil {
+		size = ai.Size{Width: options.Width, Height: options.Height}
+	}
+
+	req := ai.TextToImageRequest{
 		Prompt: prompt,
-		Width:  1024,
-		Height: 1024,
-		Style:  "anime",
+		Width:  size.Width,
+		Height: size.Height,
+		Style:  options.ArtStyle,
+	}
+	if generator.Capabilities().SupportsNegativePrompt {
+		req.NegativePrompt = options.NegativePrompt
+	}
+
+	result, err := generator.TextToImage(ctx, req)
+	if err != nil {
+		return fmt.Errorf("%s text-to-image failed: %w", generator.Name(), err)
 	}
 
-	imageURL, err := s.geminiClient.TextToImage(ctx, req)
+	asset, err := s.assets.Save(ctx, result.URL, "character_"+string(char.ID))
 	if err != nil {
-		return fmt.Errorf("gemini text-to-image failed: %w", err)
+		return fmt.Errorf("failed to store reference image: %w", err)
 	}
 
-	char.SetReferenceImage(imageURL)
+	char.SetReferenceImage(asset.URL)
 
 	if err := s.characterRepo.Save(ctx, char); err != nil {
 		return fmt.Errorf("failed to save character with reference image: %w", err)
@@ -228,8 +747,8 @@ func (s *MangaWorkflowService) generateCharacterReferenceImage(ctx context.Conte
 	return nil
 }
 
-func (s *MangaWorkflowService) buildCharacterPrompt(char *character.Character) string {
-	prompt := fmt.Sprintf("anime character portrait: %s", char.Name)
+func (s *MangaWorkflowService) buildCharacterPrompt(char *character.Character, style string) string {
+	prompt := fmt.Sprintf("character portrait: %s", char.Name)
 
 	if char.Appearance.PhysicalTraits != "" {
 		prompt += fmt.Sprintf(", %s", char.Appearance.PhysicalTraits)
@@ -247,118 +766,192 @@ func (s *MangaWorkflowService) buildCharacterPrompt(char *character.Character) s
 		prompt += fmt.Sprintf(", %s", char.Description)
 	}
 
-	prompt += ", high quality anime art style, detailed, clean background"
+	prompt += fmt.Sprintf(", high quality %s, detailed, clean background", scene.PromptStyle(style).Description())
 
 	return prompt
 }
 
+// matchCharactersToScene 匹配场景中出场的角色：对白的说话人或场景描述中提到的角色
 func (s *MangaWorkflowService) matchCharactersToScene(scn *scene.Scene, characters []*character.Character) []string {
 	var matchedIDs []string
 
 	for _, char := range characters {
+		matched := strings.Contains(scn.Description.FullText, char.Name)
 		for _, dialogue := range scn.Dialogues {
-			if dialogue.Speaker == char.Name {
-				matchedIDs = append(matchedIDs, string(char.ID))
+			if matched {
 				break
 			}
+			matched = dialogue.Speaker == char.Name
+		}
+		if matched {
+			matchedIDs = append(matchedIDs, string(char.ID))
 		}
 	}
 
 	return matchedIDs
 }
 
-func (s *MangaWorkflowService) generateSceneImage(ctx context.Context, scn *scene.Scene, characters []*character.Character) error {
+// panelOverride 重新生成单个面板时用户指定的参数，零值字段沿用自动生成的值
+type panelOverride struct {
+	Prompt         string
+	Seed           int64
+	ReferenceImage string
+}
+
+// generateSceneImage 使用出场角色的参考图生成场景图片
+func (s *MangaWorkflowService) generateSceneImage(ctx context.Context, scn *scene.Scene, characters []*character.Character, options task.GenerationOptions, override panelOverride) (*media.Media, error) {
 	charMap := make(map[string]*character.Character)
 	for _, char := range characters {
 		charMap[string(char.ID)] = char
 	}
 
 	var referenceImages []string
-	var characterDescriptions []string
+	var sceneCharacters []scene.Character
 
 	for _, charID := range scn.CharacterIDs {
 		if char, ok := charMap[charID]; ok {
 			if char.ReferenceImageURL != "" {
 				referenceImages = append(referenceImages, char.ReferenceImageURL)
 			}
-			characterDescriptions = append(characterDescriptions, char.Name)
+			sceneCharacters = append(sceneCharacters, scene.Character{
+				ID:   string(char.ID),
+				Name: char.Name,
+				Appearance: scene.CharacterAppearance{
+					PhysicalTraits:   char.Appearance.PhysicalTraits,
+					ClothingStyle:    char.Appearance.ClothingStyle,
+					DistinctFeatures: char.Appearance.DistinctFeatures,
+					Age:              char.Appearance.Age,
+					Height:           char.Appearance.Height,
+				},
+			})
 		}
 	}
 
-	prompt := s.buildScenePrompt(scn, characterDescriptions)
+	generator, err := s.imageProviders.ForStyle(options.ArtStyle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve image provider: %w", err)
+	}
+	if override.ReferenceImage != "" && !generator.Capabilities().SupportsReferenceImage {
+		return nil, fmt.Errorf("%w: %s", ai.ErrReferenceUnsupported, generator.Name())
+	}
 
-	mediaEntity := media.NewMedia(string(scn.ID), media.MediaTypeImage)
-	if err := s.mediaRepo.Save(ctx, mediaEntity); err != nil {
-		return fmt.Errorf("failed to create media entity: %w", err)
+	promptOptions := scene.DefaultPromptOptions()
+	promptOptions.Style = scene.PromptStyle(options.ArtStyle)
+	promptOptions.AspectRatio = options.AspectRatio
+	if options.NegativePrompt != "" {
+		promptOptions.Negative = append(promptOptions.Negative, options.NegativePrompt)
 	}
 
-	var imageURL string
-	var err error
+	// 支持反向提示词的服务单独传递，否则拼接到 Prompt 中
+	var negativePrompt string
+	if generator.Capabilities().SupportsNegativePrompt {
+		negativePrompt = strings.Join(promptOptions.Negative, ", ")
+		promptOptions.Negative = nil
+	}
 
-	if len(referenceImages) > 0 {
-		req := gemini.ImageToImageRequest{
-			ReferenceImage: referenceImages[0],
-			Prompt:         prompt,
-			Width:          1024,
-			Height:         768,
-			Strength:       0.6,
+	// 生成并保存场景的图片 Prompt，用户指定时直接使用
+	prompt := override.Prompt
+	if prompt == "" {
+		prompt, err = s.promptGenerator.GenerateImagePrompt(ctx, scn, sceneCharacters, promptOptions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate scene prompt: %w", err)
 		}
-		imageURL, err = s.geminiClient.ImageToImage(ctx, req)
-	} else {
-		req := gemini.TextToImageRequest{
-			Prompt: prompt,
-			Width:  1024,
-			Height: 768,
-			Style:  "anime",
+	}
+
+	// 存储中的参考图对外部服务不可达，以 data URI 形式传入
+	var reference, referenceURL string
+	if override.ReferenceImage != "" {
+		referenceURL = override.ReferenceImage
+	} else if len(referenceImages) > 0 && generator.Capabilities().SupportsReferenceImage {
+		referenceURL = referenceImages[0]
+	}
+	if referenceURL != "" {
+		reference, err = s.assets.Inline(ctx, referenceURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load reference image: %w", err)
 		}
-		imageURL, err = s.geminiClient.TextToImage(ctx, req)
 	}
 
-	if err != nil {
-		mediaEntity.MarkFailed(err.Error())
-		s.mediaRepo.Save(ctx, mediaEntity)
-		return fmt.Errorf("failed to generate scene image: %w", err)
+	// 不支持种子的服务忽略该参数，来源和缓存键中记为 0（由服务随机选择）
+	seed := override.Seed
+	if !generator.Capabilities().SupportsSeed {
+		seed = 0
 	}
 
-	metadata := media.NewImageMetadata(1024, 768, "image/jpeg", 0)
-	mediaEntity.MarkCompleted(imageURL, metadata)
+	mediaEntity := media.NewMedia(string(scn.ID), media.MediaTypeImage)
+	mediaEntity.NovelID = scn.NovelID
+
+	params := media.GenerationParams{
+		Prompt:         prompt,
+		NegativePrompt: negativePrompt,
+		Style:          options.ArtStyle,
+		ReferenceImage: reference,
+		Seed:           seed,
+	}
+	mediaEntity.CacheKey = params.CacheKey(generator.Name(), generator.Capabilities().Model, options.Width, options.Height)
+	mediaEntity.Provenance = imageProvenance(generator, params, referenceURL, options.Width, options.Height)
 
-	if err := s.mediaRepo.Save(ctx, mediaEntity); err != nil {
-		return fmt.Errorf("failed to save media: %w", err)
+	if cached, ok := s.cache.Lookup(ctx, mediaEntity.CacheKey, options.ForceRegenerate); ok {
+		mediaEntity.CompleteFromCache(cached)
+		if err := s.mediaRepo.Save(ctx, mediaEntity); err != nil {
+			return nil, fmt.Errorf("failed to save media: %w", err)
+		}
+		log.Printf("Reused cached image %s for scene %s", cached.ID, scn.ID)
+		return mediaEntity, nil
 	}
 
-	return nil
-}
+	// 同步生成，先标记为生成中，避免被后台队列重复领取
+	mediaEntity.MarkGenerating("")
+	if err := s.mediaRepo.Save(ctx, mediaEntity); err != nil {
+		return nil, fmt.Errorf("failed to create media entity: %w", err)
+	}
 
-func (s *MangaWorkflowService) buildScenePrompt(scn *scene.Scene, characterNames []string) string {
-	prompt := "anime manga panel: "
+	var result *ai.ImageResult
 
-	if scn.Location != "" {
-		prompt += fmt.Sprintf("scene in %s", scn.Location)
+	if reference != "" {
+		req := ai.ImageToImageRequest{
+			ReferenceImage: reference,
+			Prompt:         prompt,
+			NegativePrompt: negativePrompt,
+			Width:          options.Width,
+			Height:         options.Height,
+			Strength:       0.6,
+			Seed:           seed,
+		}
+		result, err = generator.ImageToImage(ctx, req)
+	} else {
+		req := ai.TextToImageRequest{
+			Prompt:         prompt,
+			NegativePrompt: negativePrompt,
+			Width:          options.Width,
+			Height:         options.Height,
+			Style:          options.ArtStyle,
+			Seed:           seed,
+		}
+		result, err = generator.TextToImage(ctx, req)
 	}
 
-	if scn.TimeOfDay != "" {
-		prompt += fmt.Sprintf(", time: %s", scn.TimeOfDay)
+	if err != nil {
+		mediaEntity.MarkFailed(err.Error())
+		s.mediaRepo.Save(ctx, mediaEntity)
+		return nil, fmt.Errorf("%s image generation failed: %w", generator.Name(), err)
 	}
+	mediaEntity.Provenance.RevisedPrompt = result.RevisedPrompt
 
-	if len(characterNames) > 0 {
-		prompt += fmt.Sprintf(", featuring characters: %s", characterNames[0])
-		for i := 1; i < len(characterNames); i++ {
-			prompt += fmt.Sprintf(" and %s", characterNames[i])
-		}
+	asset, err := s.assets.Save(ctx, result.URL, string(mediaEntity.ID))
+	if err != nil {
+		mediaEntity.MarkFailed(err.Error())
+		s.mediaRepo.Save(ctx, mediaEntity)
+		return nil, fmt.Errorf("failed to store generated image: %w", err)
 	}
 
-	if scn.Description.FullText != "" {
-		descriptionPreview := scn.Description.FullText
-		if len(descriptionPreview) > 200 {
-			descriptionPreview = descriptionPreview[:200]
-		}
-		prompt += fmt.Sprintf(", scene: %s", descriptionPreview)
-	}
+	mediaEntity.MarkCompleted(asset.URL, imageMetadata(asset, options.Width, options.Height))
 
-	prompt += ", anime art style, manga panel composition, high quality"
+	if err := s.mediaRepo.Save(ctx, mediaEntity); err != nil {
+		return nil, fmt.Errorf("failed to save media: %w", err)
+	}
 
-	return prompt
+	return mediaEntity, nil
 }
 
 // GetTaskStatus 获取任务状态
@@ -370,26 +963,38 @@ func (s *MangaWorkflowService) GetTaskStatus(ctx context.Context, userID, taskID
 	}
 
 	response := &dto.TaskStatusResponse{
-		TaskID:      taskEntity.ID,
-		Status:      string(taskEntity.Status),
+		TaskID: taskEntity.ID,
+		Status: string(taskEntity.Status),
 		Progress: dto.TaskProgressResponse{
 			CurrentStep:      taskEntity.ProgressStep,
 			CurrentStepIndex: taskEntity.ProgressStepIndex,
-			TotalSteps:       2,
+			TotalSteps:       task.TotalSteps,
 			Percentage:       taskEntity.ProgressPercentage,
 			Details: &dto.TaskProgressDetailsResponse{
-				CharactersExtracted: 0,
-				CharactersGenerated: 0,
-				ScenesDivided:       0,
+				CharactersExtracted: taskEntity.ProgressDetails.CharactersExtracted,
+				CharactersGenerated: taskEntity.ProgressDetails.CharactersGenerated,
+				ScenesDivided:       taskEntity.ProgressDetails.ScenesDivided,
 				ScenesGenerated:     taskEntity.ProgressDetails.ScenesGenerated,
 			},
 		},
+		RetryCount:  taskEntity.RetryCount,
 		CreatedAt:   taskEntity.CreatedAt,
 		UpdatedAt:   taskEntity.UpdatedAt,
 		CompletedAt: taskEntity.CompletedAt,
 		FailedAt:    taskEntity.FailedAt,
 	}
 
+	for _, attempt := range taskEntity.Attempts {
+		response.Attempts = append(response.Attempts, dto.TaskAttemptResponse{
+			Attempt:         attempt.Attempt,
+			ErrorCode:       attempt.ErrorCode,
+			ErrorMessage:    attempt.ErrorMessage,
+			ProgressStep:    attempt.ProgressStep,
+			ScenesGenerated: attempt.ScenesGenerated,
+			FailedAt:        attempt.FailedAt,
+		})
+	}
+
 	// 如果任务失败，添加错误信息
 	if taskEntity.Status == task.TaskStatusFailed {
 		response.Error = &dto.TaskErrorResponse{
@@ -420,36 +1025,165 @@ func (s *MangaWorkflowService) buildTaskResult(ctx context.Context, t *task.Task
 		return nil, err
 	}
 
-	// 使用 FindByNovelID 查询所有关联的媒体文件
-	mediaList, err := s.mediaRepo.FindByNovelID(ctx, t.NovelID)
+	// 角色及参考图
+	characters, err := s.characterRepo.FindByNovelID(ctx, t.NovelID)
+	if err != nil {
+		log.Printf("Failed to find characters by novel ID: %v", err)
+		return nil, err
+	}
+
+	characterResponses := make([]dto.TaskCharacterResponse, 0, len(characters))
+	for _, char := range characters {
+		characterResponses = append(characterResponses, dto.TaskCharacterResponse{
+			ID:                string(char.ID),
+			Name:              char.Name,
+			ReferenceImageURL: s.links.Sign(char.ReferenceImageURL),
+		})
+	}
+
+	// 按面板序号排列，每个面板使用当前选定的版本
+	panels, err := s.taskPanels(ctx, t)
+	if err != nil {
+		log.Printf("Failed to find panels by task ID: %v", err)
+		return nil, err
+	}
+
+	scenes, err := s.orderedScenes(ctx, novelEntity.ID)
+	if err != nil {
+		log.Printf("Failed to find scenes by novel ID: %v", err)
+		return nil, err
+	}
+	sceneByID := make(map[string]*scene.Scene, len(scenes))
+	for _, scn := range scenes {
+		sceneByID[string(scn.ID)] = scn
+	}
+
+	mediaByID, err := s.novelMedia(ctx, t.NovelID)
 	if err != nil {
 		log.Printf("Failed to find media by novel ID: %v", err)
 		return nil, err
 	}
 
-	// 构建漫画图片响应
-	var mangaImages []dto.TaskSceneResponse
-	for i, mediaEntity := range mediaList {
-		if mediaEntity.Status == media.MediaStatusCompleted {
-			mangaImages = append(mangaImages, dto.TaskSceneResponse{
-				ID:          string(mediaEntity.ID),
-				SequenceNum: i + 1,
-				Description: fmt.Sprintf("漫画面板 %d", i+1),
-				ImageURL:    mediaEntity.URL,
-			})
+	sceneResponses := make([]dto.TaskSceneResponse, 0, len(panels))
+	for _, panel := range panels {
+		mediaEntity, ok := mediaByID[panel.ActiveMediaID]
+		if !ok || mediaEntity.Status != media.MediaStatusCompleted {
+			continue
 		}
+		var description string
+		if scn, ok := sceneByID[panel.SceneID]; ok {
+			description = sceneSummary(scn)
+		}
+		active, _ := panel.ActiveVersion()
+		sceneResponses = append(sceneResponses, dto.TaskSceneResponse{
+			ID:           panel.SceneID,
+			SequenceNum:  panel.SequenceNum,
+			Description:  description,
+			ImageURL:     s.links.Sign(mediaEntity.URL),
+			MediaID:      string(mediaEntity.ID),
+			ContentURL:   mediaContentURL(s.links, string(mediaEntity.ID)),
+			ThumbnailURL: mediaThumbnailURL(s.links, string(mediaEntity.ID)),
+			Version:      active.Version,
+			VersionCount: len(panel.Versions),
+		})
 	}
 
 	return &dto.TaskResultResponse{
 		NovelID:        string(novelEntity.ID),
 		Title:          novelEntity.Title,
-		CharacterCount: 0,
-		SceneCount:     len(mangaImages),
-		Characters:     []dto.TaskCharacterResponse{},
-		Scenes:         mangaImages,
+		CharacterCount: len(characterResponses),
+		SceneCount:     len(sceneResponses),
+		Characters:     characterResponses,
+		Scenes:         sceneResponses,
 	}, nil
 }
 
+// taskPanels 查询任务的面板，按面板序号升序。
+// 面板表引入前完成的任务没有面板记录，按场景顺序和已生成的图片补建，各场景的图片按生成时间依次作为版本
+func (s *MangaWorkflowService) taskPanels(ctx context.Context, t *task.Task) ([]*task.Panel, error) {
+	panels, err := s.panelRepo.FindByTaskID(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(panels) > 0 || !t.IsCompleted() {
+		return panels, nil
+	}
+
+	scenes, err := s.orderedScenes(ctx, novel.NovelID(t.NovelID))
+	if err != nil {
+		return nil, err
+	}
+	versions, err := s.sceneImageVersions(ctx, t.NovelID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, scn := range selectPanelScenes(scenes, t.Options.PanelCount) {
+		images, ok := versions[string(scn.ID)]
+		if !ok {
+			continue
+		}
+		panel := task.NewPanel(t.ID, i+1, string(scn.ID))
+		for _, m := range images {
+			panel.AddVersion(string(m.ID))
+		}
+		if err := s.panelRepo.Save(ctx, panel); err != nil {
+			log.Printf("Failed to backfill panel %d of task %s: %v", panel.SequenceNum, t.ID, err)
+		}
+		panels = append(panels, panel)
+	}
+
+	return panels, nil
+}
+
+// novelMedia 查询小说下的全部媒体，按ID索引
+func (s *MangaWorkflowService) novelMedia(ctx context.Context, novelID string) (map[string]*media.Media, error) {
+	mediaList, err := s.mediaRepo.FindByNovelID(ctx, novelID)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaByID := make(map[string]*media.Media, len(mediaList))
+	for _, m := range mediaList {
+		mediaByID[string(m.ID)] = m
+	}
+	return mediaByID, nil
+}
+
+// orderedScenes 按章节顺序和场景编号返回小说的全部场景
+func (s *MangaWorkflowService) orderedScenes(ctx context.Context, novelID novel.NovelID) ([]*scene.Scene, error) {
+	chapters, err := s.chapterRepo.FindByNovelID(ctx, novelID)
+	if err != nil {
+		return nil, err
+	}
+
+	var scenes []*scene.Scene
+	for _, ch := range chapters {
+		chapterScenes, err := s.sceneRepo.FindByChapterID(ctx, ch.ID)
+		if err != nil {
+			return nil, err
+		}
+		scenes = append(scenes, chapterScenes...)
+	}
+
+	return scenes, nil
+}
+
+// sceneSummary 场景的简短描述：优先使用地点，否则截取描述原文
+func sceneSummary(scn *scene.Scene) string {
+	summary := scn.Location
+	if summary == "" {
+		summary = scn.Description.FullText
+	}
+
+	const maxRunes = 50
+	runes := []rune(strings.TrimSpace(summary))
+	if len(runes) > maxRunes {
+		return string(runes[:maxRunes]) + "..."
+	}
+	return string(runes)
+}
+
 // GetTaskList 获取任务列表
 func (s *MangaWorkflowService) GetTaskList(ctx context.Context, userID string, page, pageSize int, status string) ([]*dto.TaskListItemResponse, *dto.PaginationResponse, error) {
 	// 查询任务列表
@@ -467,7 +1201,7 @@ func (s *MangaWorkflowService) GetTaskList(ctx context.Context, userID string, p
 			Progress: dto.TaskProgressResponse{
 				CurrentStep:      t.ProgressStep,
 				CurrentStepIndex: t.ProgressStepIndex,
-				TotalSteps:       6,
+				TotalSteps:       task.TotalSteps,
 				Percentage:       t.ProgressPercentage,
 			},
 			CreatedAt:   t.CreatedAt,
@@ -483,7 +1217,7 @@ func (s *MangaWorkflowService) GetTaskList(ctx context.Context, userID string, p
 
 		// 如果任务完成，添加统计信息
 		if t.Status == task.TaskStatusCompleted {
-			item.CharacterCount = 0
+			item.CharacterCount = t.ProgressDetails.CharactersExtracted
 			item.SceneCount = t.ProgressDetails.ScenesGenerated // 漫画图片数量
 		}
 
@@ -523,14 +1257,122 @@ func (s *MangaWorkflowService) CancelTask(ctx context.Context, userID, taskID st
 
 	// 检查任务状态
 	if taskEntity.Status != task.TaskStatusPending && taskEntity.Status != task.TaskStatusProcessing {
-		return fmt.Errorf("任务已完成或已取消，无法取消")
+		return task.ErrTaskNotCancellable
 	}
 
-	// 取消任务
+	// 取消任务：先持久化取消状态，再中断本进程内的执行（其他实例通过轮询感知）
+	// 条件更新，worker 在读取之后已写入结束状态时不覆盖
 	taskEntity.Cancel()
-	if err := s.taskRepo.Save(ctx, taskEntity); err != nil {
+	cancelled, err := s.taskRepo.CancelIfActive(ctx, taskEntity)
+	if err != nil {
 		return fmt.Errorf("failed to cancel task: %w", err)
 	}
+	if !cancelled {
+		taskEntity.PullEvents()
+		return task.ErrTaskNotCancellable
+	}
+	s.publishEvents(ctx, taskEntity)
+	s.cancellations.Cancel(taskID, task.ErrTaskCancelled)
 
 	return nil
 }
+
+// RetryTask 重试失败的任务
+// 任务重新置为待处理后由任务队列领取，执行时跳过已完成的面板，只重新生成缺失或失败的面板
+func (s *MangaWorkflowService) RetryTask(ctx context.Context, userID, taskID string) (*task.Task, error) {
+	// 根据用户ID和任务ID查找任务（权限验证）
+	taskEntity, err := s.taskRepo.FindByIDAndUserID(ctx, taskID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("task not found or access denied")
+	}
+
+	if err := taskEntity.Retry(); err != nil {
+		return nil, err
+	}
+
+	if err := s.taskRepo.Save(ctx, taskEntity); err != nil {
+		return nil, fmt.Errorf("failed to retry task: %w", err)
+	}
+	s.publishEvents(ctx, taskEntity)
+
+	log.Printf("Task %s re-queued for retry (attempt %d, scenes generated: %d)",
+		taskEntity.ID, taskEntity.RetryCount+1, taskEntity.ProgressDetails.ScenesGenerated)
+
+	return taskEntity, nil
+}
+
+// SubscribeTaskEvents 订阅任务进度事件（权限验证同 GetTaskStatus）
+// 先补发 lastEventID 之后的历史事件，再持续推送新事件，任务结束或 ctx 取消后关闭通道
+func (s *MangaWorkflowService) SubscribeTaskEvents(ctx context.Context, userID, taskID string, lastEventID int64) (<-chan task.Event, error) {
+	// 根据用户ID和任务ID查找任务（权限验证）
+	if _, err := s.taskRepo.FindByIDAndUserID(ctx, taskID, userID); err != nil {
+		return nil, fmt.Errorf("task not found or access denied")
+	}
+
+	// 先订阅再读取，避免读取与订阅之间产生的事件丢失通知
+	wake, unsubscribe := s.eventBroker.Subscribe(taskID)
+	events := make(chan task.Event)
+
+	go func() {
+		defer close(events)
+		defer unsubscribe()
+
+		ticker := time.NewTicker(eventPollInterval)
+		defer ticker.Stop()
+
+		lastID := lastEventID
+		finished := false
+		for {
+			batch, err := s.taskEventRepo.FindAfter(ctx, taskID, lastID, eventPageSize)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("Failed to load events for task %s: %v", taskID, err)
+				}
+				return
+			}
+
+			for _, event := range batch {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+				lastID = event.ID
+				finished = event.IsTerminal()
+			}
+
+			if len(batch) == eventPageSize {
+				continue
+			}
+			if finished {
+				return
+			}
+			if len(batch) == 0 && s.taskSettled(ctx, userID, taskID) {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-wake:
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// taskSettled 任务已结束一段时间但没有终态事件（如事件写入失败或历史任务），用于结束订阅
+func (s *MangaWorkflowService) taskSettled(ctx context.Context, userID, taskID string) bool {
+	t, err := s.taskRepo.FindByIDAndUserID(ctx, taskID, userID)
+	if err != nil {
+		return false
+	}
+
+	switch t.Status {
+	case task.TaskStatusCompleted, task.TaskStatusFailed, task.TaskStatusCancelled:
+		return time.Since(t.UpdatedAt) > eventSettleDelay
+	}
+	return false
+}
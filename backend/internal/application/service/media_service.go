@@ -0,0 +1,245 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/xiajiayi/ai-motion/internal/domain/media"
+	"github.com/xiajiayi/ai-motion/internal/domain/scene"
+	"github.com/xiajiayi/ai-motion/internal/domain/task"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/imaging"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/storage"
+)
+
+var (
+	ErrMediaNotReady        = errors.New("media content is not ready")
+	ErrThumbnailUnsupported = errors.New("thumbnail is only available for images")
+)
+
+const (
+	// DefaultThumbnailSize 缩略图默认长边尺寸
+	DefaultThumbnailSize = 320
+	// MaxThumbnailSize 缩略图最大长边尺寸
+	MaxThumbnailSize = 1024
+
+	thumbnailQuality = 85
+)
+
+// MediaContent 媒体文件内容，使用后需调用 Close
+type MediaContent struct {
+	Content  io.ReadSeeker
+	MimeType string
+	ETag     string
+	ModTime  time.Time
+	Filename string // 非空时作为附件下载（导出文件）
+	closer   io.Closer
+}
+
+func (c *MediaContent) Close() error {
+	if c.closer == nil {
+		return nil
+	}
+	return c.closer.Close()
+}
+
+// MediaService 读取存储中的媒体文件，访问前校验媒体的发起用户或所属小说的任务归属
+type MediaService struct {
+	mediaRepo media.MediaRepository
+	sceneRepo scene.SceneRepository
+	taskRepo  task.Repository
+	assets    *storage.AssetStore
+}
+
+func NewMediaService(
+	mediaRepo media.MediaRepository,
+	sceneRepo scene.SceneRepository,
+	taskRepo task.Repository,
+	assets *storage.AssetStore,
+) *MediaService {
+	return &MediaService{
+		mediaRepo: mediaRepo,
+		sceneRepo: sceneRepo,
+		taskRepo:  taskRepo,
+		assets:    assets,
+	}
+}
+
+// GetMediaContent 获取媒体文件内容，媒体不存在或不属于该用户时均返回 media.ErrMediaNotFound
+func (s *MediaService) GetMediaContent(ctx context.Context, userID, mediaID string) (*MediaContent, error) {
+	m, err := s.findAuthorized(ctx, userID, mediaID)
+	if err != nil {
+		return nil, err
+	}
+	return s.openContent(ctx, m)
+}
+
+// GetSignedMediaContent 获取签名地址指向的媒体文件内容。签名地址只在已校验归属的接口响应中签发，不再按用户校验
+func (s *MediaService) GetSignedMediaContent(ctx context.Context, mediaID string) (*MediaContent, error) {
+	m, err := s.mediaRepo.FindByID(ctx, media.MediaID(mediaID))
+	if err != nil {
+		return nil, err
+	}
+	return s.openContent(ctx, m)
+}
+
+func (s *MediaService) openContent(ctx context.Context, m *media.Media) (*MediaContent, error) {
+	if !m.IsReady() {
+		return nil, ErrMediaNotReady
+	}
+
+	reader, err := s.assets.Open(ctx, m.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open media file: %w", err)
+	}
+
+	content := &MediaContent{
+		MimeType: mediaMimeType(m),
+		ETag:     mediaETag(m),
+		ModTime:  mediaModTime(m),
+		closer:   reader,
+	}
+	if m.Type == media.MediaTypeExport {
+		content.Filename = path.Base(m.URL)
+	}
+
+	// 本地文件可直接按 Range 读取，其他存储读入内存
+	if seeker, ok := reader.(io.ReadSeeker); ok {
+		content.Content = seeker
+		return content, nil
+	}
+
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read media file: %w", err)
+	}
+	content.Content = bytes.NewReader(data)
+	content.closer = nil
+	return content, nil
+}
+
+// GetMediaThumbnail 生成图片媒体的 JPEG 缩略图，长边不超过 size
+func (s *MediaService) GetMediaThumbnail(ctx context.Context, userID, mediaID string, size int) (*MediaContent, error) {
+	content, err := s.GetMediaContent(ctx, userID, mediaID)
+	if err != nil {
+		return nil, err
+	}
+	return thumbnail(content, size)
+}
+
+// GetSignedMediaThumbnail 生成签名地址指向的图片媒体的缩略图
+func (s *MediaService) GetSignedMediaThumbnail(ctx context.Context, mediaID string, size int) (*MediaContent, error) {
+	content, err := s.GetSignedMediaContent(ctx, mediaID)
+	if err != nil {
+		return nil, err
+	}
+	return thumbnail(content, size)
+}
+
+// thumbnail 将图片内容缩放为 JPEG 缩略图并关闭原内容
+func thumbnail(content *MediaContent, size int) (*MediaContent, error) {
+	defer content.Close()
+	if size <= 0 {
+		size = DefaultThumbnailSize
+	}
+	size = min(size, MaxThumbnailSize)
+
+	if !strings.HasPrefix(content.MimeType, "image/") {
+		return nil, ErrThumbnailUnsupported
+	}
+
+	img, _, err := image.Decode(content.Content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode image: %v", ErrThumbnailUnsupported, err)
+	}
+
+	thumbnail := imaging.Flatten(imaging.Fit(img, size), color.White)
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+
+	return &MediaContent{
+		Content:  bytes.NewReader(buf.Bytes()),
+		MimeType: "image/jpeg",
+		ETag:     fmt.Sprintf(`"%s-t%d"`, strings.Trim(content.ETag, `"`), size),
+		ModTime:  content.ModTime,
+	}, nil
+}
+
+// findAuthorized 查找媒体并校验归属：记录了发起用户的媒体直接比对用户，
+// 未记录用户的旧数据和流水线生成的媒体按用户是否拥有其所属小说的任务判断
+func (s *MediaService) findAuthorized(ctx context.Context, userID, mediaID string) (*media.Media, error) {
+	m, err := s.mediaRepo.FindByID(ctx, media.MediaID(mediaID))
+	if err != nil {
+		return nil, err
+	}
+	if m.UserID != "" && m.UserID == userID {
+		return m, nil
+	}
+
+	novelID := m.NovelID
+	if novelID == "" && m.SceneID != "" {
+		sceneEntity, err := s.sceneRepo.FindByID(ctx, scene.SceneID(m.SceneID))
+		if err != nil {
+			return nil, media.ErrMediaNotFound
+		}
+		novelID = sceneEntity.NovelID
+	}
+	if novelID == "" {
+		return nil, media.ErrMediaNotFound
+	}
+
+	owned, err := s.taskRepo.ExistsByNovelIDAndUserID(ctx, novelID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !owned {
+		return nil, media.ErrMediaNotFound
+	}
+
+	return m, nil
+}
+
+// mediaMimeType 优先使用保存时检测到的类型，旧数据按扩展名推断
+func mediaMimeType(m *media.Media) string {
+	if strings.Contains(m.Metadata.Format, "/") {
+		return m.Metadata.Format
+	}
+	if mimeType := mime.TypeByExtension(path.Ext(m.URL)); mimeType != "" {
+		return mimeType
+	}
+	return "application/octet-stream"
+}
+
+func mediaModTime(m *media.Media) time.Time {
+	if m.CompletedAt != nil {
+		return *m.CompletedAt
+	}
+	return m.UpdatedAt
+}
+
+// mediaETag 媒体文件写入后不再修改，以媒体ID和完成时间作为实体标签
+func mediaETag(m *media.Media) string {
+	return fmt.Sprintf(`"%s-%x"`, m.ID, mediaModTime(m).Unix())
+}
+
+// mediaContentURL 媒体内容接口的签名地址，可直接用于 <img>/<video>/<audio>
+func mediaContentURL(links *storage.URLSigner, mediaID string) string {
+	return links.Sign("/api/v1/media/" + mediaID + "/content")
+}
+
+// mediaThumbnailURL 媒体缩略图接口的签名地址
+func mediaThumbnailURL(links *storage.URLSigner, mediaID string) string {
+	return links.Sign("/api/v1/media/" + mediaID + "/thumbnail")
+}
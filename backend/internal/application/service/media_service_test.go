@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/xiajiayi/ai-motion/internal/domain/media"
+	"github.com/xiajiayi/ai-motion/internal/domain/scene"
+	"github.com/xiajiayi/ai-motion/internal/domain/task"
+)
+
+// 以下仓储只实现媒体授权用到的方法
+type fakeMediaLookup struct {
+	media.MediaRepository
+	list map[media.MediaID]*media.Media
+}
+
+func (r *fakeMediaLookup) FindByID(ctx context.Context, id media.MediaID) (*media.Media, error) {
+	if m, ok := r.list[id]; ok {
+		return m, nil
+	}
+	return nil, media.ErrMediaNotFound
+}
+
+type fakeSceneLookup struct {
+	scene.SceneRepository
+	list map[scene.SceneID]*scene.Scene
+}
+
+func (r *fakeSceneLookup) FindByID(ctx context.Context, id scene.SceneID) (*scene.Scene, error) {
+	if s, ok := r.list[id]; ok {
+		return s, nil
+	}
+	return nil, scene.ErrSceneNotFound
+}
+
+type fakeNovelOwners struct {
+	task.Repository
+	owners map[string]string // 小说ID → 用户ID
+}
+
+func (r *fakeNovelOwners) ExistsByNovelIDAndUserID(ctx context.Context, novelID, userID string) (bool, error) {
+	return r.owners[novelID] == userID, nil
+}
+
+func TestMediaServiceFindAuthorized(t *testing.T) {
+	mediaRepo := &fakeMediaLookup{list: map[media.MediaID]*media.Media{
+		"task-panel":   {ID: "task-panel", NovelID: "n1"},
+		"legacy-scene": {ID: "legacy-scene", SceneID: "s1"},
+		// 直接生成场景图片的用户没有该小说的任务，按发起用户授权
+		"direct-scene": {ID: "direct-scene", UserID: "u2", SceneID: "s2"},
+		"orphan":       {ID: "orphan"},
+	}}
+	sceneRepo := &fakeSceneLookup{list: map[scene.SceneID]*scene.Scene{
+		"s1": {ID: "s1", NovelID: "n1"},
+		"s2": {ID: "s2", NovelID: "n2"},
+	}}
+	service := NewMediaService(mediaRepo, sceneRepo, &fakeNovelOwners{owners: map[string]string{"n1": "u1", "n2": "u3"}}, nil)
+
+	tests := []struct {
+		name    string
+		mediaID string
+		userID  string
+		wantErr error
+	}{
+		{"task owner", "task-panel", "u1", nil},
+		{"other user", "task-panel", "u2", media.ErrMediaNotFound},
+		{"legacy scene media via novel task", "legacy-scene", "u1", nil},
+		{"legacy scene media other user", "legacy-scene", "u2", media.ErrMediaNotFound},
+		{"media owner without task", "direct-scene", "u2", nil},
+		{"novel task owner of user media", "direct-scene", "u3", nil},
+		{"media of another user", "direct-scene", "u1", media.ErrMediaNotFound},
+		{"empty user does not match media without owner", "orphan", "", media.ErrMediaNotFound},
+		{"missing media", "missing", "u1", media.ErrMediaNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := service.findAuthorized(context.Background(), tt.userID, tt.mediaID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("findAuthorized() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && string(m.ID) != tt.mediaID {
+				t.Errorf("findAuthorized() media = %s, want %s", m.ID, tt.mediaID)
+			}
+		})
+	}
+}
@@ -233,6 +233,7 @@ func (s *SceneService) toSceneResponse(sc *scene.Scene) *dto.SceneResponse {
 			Speaker: d.Speaker,
 			Content: d.Content,
 			Emotion: d.Emotion,
+			Kind:    string(d.EffectiveKind()),
 		}
 	}
 
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"sync"
+)
+
+// TaskCancelRegistry 记录本进程内正在执行的任务，取消任务时直接中断其 context，
+// 使进行中的 AI 请求立即中止；其他实例上的任务通过轮询数据库状态感知取消
+type TaskCancelRegistry struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelCauseFunc
+}
+
+func NewTaskCancelRegistry() *TaskCancelRegistry {
+	return &TaskCancelRegistry{
+		cancels: make(map[string]context.CancelCauseFunc),
+	}
+}
+
+// Register 登记任务的取消函数
+func (r *TaskCancelRegistry) Register(taskID string, cancel context.CancelCauseFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cancels[taskID] = cancel
+}
+
+// Unregister 任务结束后移除登记
+func (r *TaskCancelRegistry) Unregister(taskID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.cancels, taskID)
+}
+
+// Cancel 取消本进程内正在执行的任务，任务不在本进程执行时返回 false
+func (r *TaskCancelRegistry) Cancel(taskID string, cause error) bool {
+	r.mu.Lock()
+	cancel, ok := r.cancels[taskID]
+	r.mu.Unlock()
+
+	if ok {
+		cancel(cause)
+	}
+	return ok
+}
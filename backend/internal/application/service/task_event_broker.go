@@ -0,0 +1,54 @@
+package service
+
+import "sync"
+
+// TaskEventBroker 进程内的任务事件通知：事件持久化后唤醒同一实例上的订阅者立即读取，
+// 其他实例产生的事件由订阅者定期轮询数据库获得
+type TaskEventBroker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan struct{}]struct{}
+}
+
+func NewTaskEventBroker() *TaskEventBroker {
+	return &TaskEventBroker{
+		subscribers: make(map[string]map[chan struct{}]struct{}),
+	}
+}
+
+// Subscribe 订阅任务的新事件通知，返回通知通道和取消订阅函数，取消订阅后关闭通道，可重复调用
+func (b *TaskEventBroker) Subscribe(taskID string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	b.mu.Lock()
+	if b.subscribers[taskID] == nil {
+		b.subscribers[taskID] = make(map[chan struct{}]struct{})
+	}
+	b.subscribers[taskID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[taskID][ch]; !ok {
+			return
+		}
+		delete(b.subscribers[taskID], ch)
+		close(ch)
+		if len(b.subscribers[taskID]) == 0 {
+			delete(b.subscribers, taskID)
+		}
+	}
+}
+
+// Publish 通知任务的所有订阅者有新事件，订阅者尚未处理上一次通知时合并
+func (b *TaskEventBroker) Publish(taskID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[taskID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+// notified 通道中是否有待处理的通知
+func notified(ch <-chan struct{}) bool {
+	select {
+	case _, ok := <-ch:
+		return ok
+	default:
+		return false
+	}
+}
+
+func TestTaskEventBrokerNotifiesAllSubscribers(t *testing.T) {
+	broker := NewTaskEventBroker()
+	first, unsubscribeFirst := broker.Subscribe("task-1")
+	defer unsubscribeFirst()
+	second, unsubscribeSecond := broker.Subscribe("task-1")
+	defer unsubscribeSecond()
+	other, unsubscribeOther := broker.Subscribe("task-2")
+	defer unsubscribeOther()
+
+	broker.Publish("task-1")
+
+	if !notified(first) || !notified(second) {
+		t.Error("subscriber of task-1 not notified")
+	}
+	if notified(other) {
+		t.Error("subscriber of task-2 notified for task-1")
+	}
+}
+
+func TestTaskEventBrokerSlowSubscriberDoesNotBlockPublish(t *testing.T) {
+	broker := NewTaskEventBroker()
+	slow, unsubscribeSlow := broker.Subscribe("task-1")
+	defer unsubscribeSlow()
+	fast, unsubscribeFast := broker.Subscribe("task-1")
+	defer unsubscribeFast()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// slow 从不读取，多次通知合并为一次
+		for i := 0; i < 3; i++ {
+			broker.Publish("task-1")
+			if !notified(fast) {
+				t.Errorf("publish %d: fast subscriber not notified", i+1)
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a subscriber that is not reading")
+	}
+	if !notified(slow) {
+		t.Error("slow subscriber lost the pending notification")
+	}
+	if notified(slow) {
+		t.Error("slow subscriber received more than one coalesced notification")
+	}
+}
+
+func TestTaskEventBrokerUnsubscribeClosesChannel(t *testing.T) {
+	broker := NewTaskEventBroker()
+	ch, unsubscribe := broker.Subscribe("task-1")
+	remaining, unsubscribeRemaining := broker.Subscribe("task-1")
+	defer unsubscribeRemaining()
+
+	unsubscribe()
+	unsubscribe()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Error("received a notification after unsubscribe, want closed channel")
+		}
+	default:
+		t.Error("channel still open after unsubscribe")
+	}
+
+	// 已取消的订阅不再接收通知，Publish 也不会向已关闭的通道发送
+	broker.Publish("task-1")
+	if !notified(remaining) {
+		t.Error("remaining subscriber not notified")
+	}
+
+	unsubscribeRemaining()
+	if len(broker.subscribers) != 0 {
+		t.Errorf("subscribers = %v, want task removed after its last subscriber left", broker.subscribers)
+	}
+}
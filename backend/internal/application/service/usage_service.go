@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/xiajiayi/ai-motion/internal/application/dto"
+	"github.com/xiajiayi/ai-motion/internal/domain/usage"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai"
+)
+
+// UsageService 记录每次 AI 调用的用量并按用户执行配额
+type UsageService struct {
+	repo    usage.Repository
+	pricing usage.Pricing
+	quota   usage.Quota
+	now     func() time.Time
+}
+
+func NewUsageService(repo usage.Repository, pricing usage.Pricing, quota usage.Quota) *UsageService {
+	return &UsageService{
+		repo:    repo,
+		pricing: pricing,
+		quota:   quota,
+		now:     time.Now,
+	}
+}
+
+// Record 写入一条用量记录，用户和任务取自 ctx 中的 usage.Caller。
+// 作为 ai.Meter 在 AI 调用返回后执行，记账失败只记录日志，不影响生成结果；没有调用方的调用无法归属，只记录日志
+func (s *UsageService) Record(ctx context.Context, record ai.CallRecord) {
+	caller := usage.CallerFrom(ctx)
+	operation := usage.Operation(record.Operation)
+	if caller.UserID == "" {
+		log.Printf("Usage of %s %s has no caller, not recorded", record.Provider, record.Operation)
+		return
+	}
+
+	entry := &usage.Entry{
+		UserID:    caller.UserID,
+		TaskID:    caller.TaskID,
+		Provider:  record.Provider,
+		Operation: operation,
+		Width:     record.Width,
+		Height:    record.Height,
+		Latency:   record.Latency,
+		Success:   record.Err == nil,
+		CreatedAt: s.now(),
+	}
+	if operation.IsVideo() {
+		entry.Duration = float64(record.Duration)
+	}
+	if entry.Success {
+		entry.EstimatedCost = s.pricing.Estimate(record.Provider, operation, entry.Duration)
+	}
+
+	if err := s.repo.Append(ctx, entry); err != nil {
+		log.Printf("Failed to record usage for user %q (%s %s): %v", caller.UserID, record.Provider, record.Operation, err)
+	}
+}
+
+// CheckQuota 检查用户是否还有剩余配额，超出时返回 *usage.QuotaError；未配置配额时不查询账本
+func (s *UsageService) CheckQuota(ctx context.Context, userID string) error {
+	if s == nil || (s.quota == usage.Quota{}) {
+		return nil
+	}
+
+	summary, err := s.summarize(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.quota.Check(summary)
+}
+
+// GetSummary 查询用户本日和本月的用量及剩余配额
+func (s *UsageService) GetSummary(ctx context.Context, userID string) (*dto.UsageSummaryResponse, error) {
+	summary, err := s.summarize(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.UsageSummaryResponse{
+		Today: toUsageTotalsResponse(summary.Today),
+		Month: toUsageTotalsResponse(summary.Month),
+		Daily: make([]dto.DailyUsageResponse, 0, len(summary.Daily)),
+		Quota: dto.UsageQuotaResponse{
+			DailyGenerationLimit: s.quota.DailyGenerations,
+			MonthlyCostLimit:     s.quota.MonthlyCost,
+			Exceeded:             s.quota.Check(summary) != nil,
+		},
+	}
+	for _, day := range summary.Daily {
+		response.Daily = append(response.Daily, dto.DailyUsageResponse{
+			Date:                day.Date,
+			UsageTotalsResponse: toUsageTotalsResponse(day.Totals),
+		})
+	}
+	if s.quota.DailyGenerations > 0 {
+		remaining := max(s.quota.DailyGenerations-summary.Today.Generations(), 0)
+		response.Quota.DailyGenerationsRemaining = &remaining
+	}
+	if s.quota.MonthlyCost > 0 {
+		remaining := max(s.quota.MonthlyCost-summary.Month.EstimatedCost, 0)
+		response.Quota.MonthlyCostRemaining = &remaining
+	}
+
+	return response, nil
+}
+
+func (s *UsageService) summarize(ctx context.Context, userID string) (usage.Summary, error) {
+	now := s.now()
+	entries, err := s.repo.FindByUserSince(ctx, userID, usage.MonthStart(now))
+	if err != nil {
+		return usage.Summary{}, fmt.Errorf("failed to load usage: %w", err)
+	}
+	return usage.Summarize(entries, now), nil
+}
+
+func toUsageTotalsResponse(totals usage.Totals) dto.UsageTotalsResponse {
+	return dto.UsageTotalsResponse{
+		Calls:         totals.Calls,
+		FailedCalls:   totals.FailedCalls,
+		Images:        totals.Images,
+		Videos:        totals.Videos,
+		VideoSeconds:  totals.VideoSeconds,
+		SpeechLines:   totals.SpeechLines,
+		EstimatedCost: totals.EstimatedCost,
+	}
+}
@@ -24,6 +24,14 @@ var (
 	ErrEmptyCharacterName = errors.New("character name cannot be empty")
 	ErrInvalidRole        = errors.New("invalid character role")
 	ErrEmptyAppearance    = errors.New("character appearance cannot be empty")
+	ErrInvalidVoice       = errors.New("invalid character voice profile")
+)
+
+const (
+	DefaultVoiceLanguage = "zh-CN"
+	MinVoiceSpeed        = 0.5
+	MaxVoiceSpeed        = 2.0
+	MaxVoicePitch        = 12.0 // 音高偏移的范围（半音），正负对称
 )
 
 type Character struct {
@@ -35,6 +43,7 @@ type Character struct {
 	Personality       Personality
 	Description       string
 	ReferenceImageURL string
+	Voice             VoiceProfile
 	CreatedAt         time.Time
 	UpdatedAt         time.Time
 }
@@ -83,6 +92,39 @@ func (p Personality) IsEmpty() bool {
 	return p.Traits == "" && p.Motivation == "" && p.Background == ""
 }
 
+// VoiceProfile 角色的配音设置，零值表示使用语音服务的默认音色
+type VoiceProfile struct {
+	Voice    string  // 语音服务的音色名称
+	Language string  // BCP 47 语言标签，空值为 zh-CN
+	Speed    float64 // 语速倍率，0 表示正常语速
+	Pitch    float64 // 音高偏移（半音）
+}
+
+func (v VoiceProfile) IsEmpty() bool {
+	return v.Voice == "" && v.Language == "" && v.Speed == 0 && v.Pitch == 0
+}
+
+func (v VoiceProfile) Validate() error {
+	if v.Speed != 0 && (v.Speed < MinVoiceSpeed || v.Speed > MaxVoiceSpeed) {
+		return ErrInvalidVoice
+	}
+	if v.Pitch < -MaxVoicePitch || v.Pitch > MaxVoicePitch {
+		return ErrInvalidVoice
+	}
+	return nil
+}
+
+// WithDefaults 补全未设置的语言和语速
+func (v VoiceProfile) WithDefaults() VoiceProfile {
+	if v.Language == "" {
+		v.Language = DefaultVoiceLanguage
+	}
+	if v.Speed == 0 {
+		v.Speed = 1
+	}
+	return v
+}
+
 func NewCharacter(novelID, name string, role CharacterRole) (*Character, error) {
 	if err := validateCharacterInput(name, role); err != nil {
 		return nil, err
@@ -113,6 +155,17 @@ func (c *Character) SetPersonality(personality Personality) {
 	c.UpdatedAt = time.Now()
 }
 
+func (c *Character) SetVoice(voice VoiceProfile) error {
+	if err := voice.Validate(); err != nil {
+		return err
+	}
+	voice.Voice = strings.TrimSpace(voice.Voice)
+	voice.Language = strings.TrimSpace(voice.Language)
+	c.Voice = voice
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
 func (c *Character) SetDescription(description string) {
 	c.Description = strings.TrimSpace(description)
 	c.UpdatedAt = time.Now()
@@ -1,6 +1,7 @@
 package character
 
 import (
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -260,9 +261,9 @@ func TestCharacter_SetReferenceImage(t *testing.T) {
 
 func TestCharacter_HasReferenceImage(t *testing.T) {
 	tests := []struct {
-		name  string
-		char  *Character
-		want  bool
+		name string
+		char *Character
+		want bool
 	}{
 		{
 			name: "has reference image",
@@ -518,3 +519,51 @@ func TestCharacter_GeneratePrompt(t *testing.T) {
 		})
 	}
 }
+
+func TestCharacter_SetVoice(t *testing.T) {
+	tests := []struct {
+		name    string
+		voice   VoiceProfile
+		wantErr bool
+	}{
+		{name: "empty profile", voice: VoiceProfile{}},
+		{name: "valid profile", voice: VoiceProfile{Voice: " warm-male ", Language: "zh-CN", Speed: 1.2, Pitch: -3}},
+		{name: "speed too slow", voice: VoiceProfile{Speed: 0.2}, wantErr: true},
+		{name: "speed too fast", voice: VoiceProfile{Speed: 3}, wantErr: true},
+		{name: "pitch out of range", voice: VoiceProfile{Pitch: 13}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			char := &Character{Name: "Hero"}
+			err := char.SetVoice(tt.voice)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidVoice) {
+					t.Errorf("SetVoice() error = %v, want %v", err, ErrInvalidVoice)
+				}
+				if !char.Voice.IsEmpty() {
+					t.Error("SetVoice() should not change voice on error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetVoice() error = %v", err)
+			}
+			if char.Voice.Voice != strings.TrimSpace(tt.voice.Voice) {
+				t.Errorf("Voice = %q, want trimmed %q", char.Voice.Voice, tt.voice.Voice)
+			}
+		})
+	}
+}
+
+func TestVoiceProfile_WithDefaults(t *testing.T) {
+	got := VoiceProfile{Voice: "narrator"}.WithDefaults()
+	if got.Language != DefaultVoiceLanguage || got.Speed != 1 || got.Voice != "narrator" {
+		t.Errorf("WithDefaults() = %+v", got)
+	}
+
+	custom := VoiceProfile{Language: "en-US", Speed: 0.8}.WithDefaults()
+	if custom.Language != "en-US" || custom.Speed != 0.8 {
+		t.Errorf("WithDefaults() overrode explicit values: %+v", custom)
+	}
+}
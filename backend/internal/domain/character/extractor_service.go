@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 type CharacterExtractorService struct {
@@ -77,7 +78,8 @@ func (s *CharacterExtractorService) extractCharacterNames(content string) []Extr
 		regex *regexp.Regexp
 		role  CharacterRole
 	}{
-		{regexp.MustCompile(`([一-龥]{2,4})(?:说道?|道|答|问|喊|叫|笑|哭|想|心想|暗想)`), CharacterRoleMinor},
+		// 非贪婪匹配，避免把“说道”中的“说”并入人名
+		{regexp.MustCompile(`([一-龥]{2,4}?)(?:说道?|道|答|问|喊|叫|笑|哭|想|心想|暗想)`), CharacterRoleMinor},
 		{regexp.MustCompile(`"([一-龥]{2,4}),`), CharacterRoleMinor},
 		{regexp.MustCompile(`([一-龥]{2,4})(?:心中|眼中|脸上|手中)`), CharacterRoleMinor},
 		{regexp.MustCompile(`主角([一-龥]{2,4})`), CharacterRoleMain},
@@ -196,7 +198,8 @@ func (s *CharacterExtractorService) rankAndFilterCharacters(characters []Extract
 }
 
 func isValidCharacterName(name string) bool {
-	if len(name) < 2 || len(name) > 4 {
+	// 按字符数而非字节数判断，中文字符在 UTF-8 中占 3 个字节
+	if n := utf8.RuneCountInString(name); n < 2 || n > 4 {
 		return false
 	}
 
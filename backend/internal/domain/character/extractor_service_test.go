@@ -0,0 +1,40 @@
+package character
+
+import "testing"
+
+func TestIsValidCharacterName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"李明", true},
+		{"欧阳小红", true},
+		{"王", false},
+		{"司马相如之", false},
+		{"他们", false},
+		{"Tom", false},
+	}
+
+	for _, tt := range tests {
+		if got := isValidCharacterName(tt.name); got != tt.want {
+			t.Errorf("isValidCharacterName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExtractCharacterNames(t *testing.T) {
+	content := "李明说道：\"早上好。\"\n王芳笑道：\"你来了。\"\n李明问：\"今天去哪里？\"\n王芳答：\"去学校。\"\n李明想了想。\n王芳是一个学生。"
+
+	s := &CharacterExtractorService{}
+	characters := s.extractCharacterNames(content)
+
+	names := make(map[string]bool)
+	for _, c := range characters {
+		names[c.Name] = true
+	}
+	for _, want := range []string{"李明", "王芳"} {
+		if !names[want] {
+			t.Errorf("expected character %q to be extracted, got %+v", want, characters)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package export
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Format 导出文件格式
+type Format string
+
+const (
+	FormatCBZ  Format = "cbz"  // 漫画压缩包，附带 ComicInfo.xml 元数据
+	FormatPDF  Format = "pdf"  // 每页一张图片的 PDF
+	FormatEPUB Format = "epub" // 固定版式的 EPUB 3
+	// FormatAnimatic 动态分镜视频：按章节和场景顺序拼接场景视频和图片的 MP4，
+	// 附带时间线清单和 SRT/WebVTT 字幕
+	FormatAnimatic Format = "animatic"
+)
+
+// AttachmentKind 导出的附属文件类型
+type AttachmentKind string
+
+const (
+	AttachmentTimeline AttachmentKind = "timeline" // 时间线清单（JSON）
+	AttachmentSRT      AttachmentKind = "srt"      // SRT 字幕
+	AttachmentWebVTT   AttachmentKind = "vtt"      // WebVTT 字幕
+)
+
+// Status 导出状态
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusProcessing Status = "processing"
+	StatusCompleted  Status = "completed"
+	StatusFailed     Status = "failed"
+)
+
+var (
+	ErrExportNotFound = errors.New("export not found")
+	ErrInvalidFormat  = errors.New("invalid export format, must be one of cbz, pdf, epub, animatic")
+	ErrLeaseLost      = errors.New("export lease lost")
+	ErrNoClips        = errors.New("no completed scene video or image to export")
+)
+
+// Attachment 导出的附属文件，与主文件一样保存为 export 类型的媒体
+type Attachment struct {
+	Kind    AttachmentKind `json:"kind"`
+	MediaID string         `json:"media_id"`
+}
+
+// Export 将已完成任务的页面（未排版时为面板）打包为一个可下载文件的后台作业，
+// 或将场景视频和图片合成为动态分镜视频，生成的文件保存为 export 类型的媒体
+type Export struct {
+	ID           string
+	TaskID       string
+	UserID       string
+	Format       Format
+	Status       Status
+	Progress     int          // 0-100
+	MediaID      string       // 完成后生成的文件
+	Attachments  []Attachment // 附属文件，仅动态分镜导出生成
+	ErrorMessage string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	CompletedAt  *time.Time
+}
+
+// ParseFormat 解析导出格式，不区分大小写
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(strings.ToLower(strings.TrimSpace(value))); format {
+	case FormatCBZ, FormatPDF, FormatEPUB, FormatAnimatic:
+		return format, nil
+	default:
+		return "", ErrInvalidFormat
+	}
+}
+
+// NewExport 创建待处理的导出作业
+func NewExport(taskID, userID string, format Format) *Export {
+	now := time.Now()
+	return &Export{
+		ID:        uuid.New().String(),
+		TaskID:    taskID,
+		UserID:    userID,
+		Format:    format,
+		Status:    StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Start 开始处理，中断后重新领取的作业从头开始
+func (e *Export) Start() {
+	e.Status = StatusProcessing
+	e.Progress = 0
+	e.Attachments = nil
+	e.ErrorMessage = ""
+	e.UpdatedAt = time.Now()
+}
+
+// UpdateProgress 更新进度，超出范围的值取边界，进度不回退
+func (e *Export) UpdateProgress(progress int) {
+	progress = min(max(progress, 0), 100)
+	if progress > e.Progress {
+		e.Progress = progress
+	}
+	e.UpdatedAt = time.Now()
+}
+
+// AddAttachment 记录附属文件，应在 Complete 之前调用
+func (e *Export) AddAttachment(kind AttachmentKind, mediaID string) {
+	e.Attachments = append(e.Attachments, Attachment{Kind: kind, MediaID: mediaID})
+	e.UpdatedAt = time.Now()
+}
+
+// Complete 记录生成的文件
+func (e *Export) Complete(mediaID string) {
+	now := time.Now()
+	e.Status = StatusCompleted
+	e.Progress = 100
+	e.MediaID = mediaID
+	e.UpdatedAt = now
+	e.CompletedAt = &now
+}
+
+// Fail 标记导出失败
+func (e *Export) Fail(errorMsg string) {
+	e.Status = StatusFailed
+	e.ErrorMessage = errorMsg
+	e.UpdatedAt = time.Now()
+}
+
+// IsFinished 是否已结束（完成或失败）
+func (e *Export) IsFinished() bool {
+	return e.Status == StatusCompleted || e.Status == StatusFailed
+}
+
+// Extension 导出文件的扩展名
+func (f Format) Extension() string {
+	if f == FormatAnimatic {
+		return ".mp4"
+	}
+	return "." + string(f)
+}
+
+// MimeType 导出文件的 MIME 类型
+func (f Format) MimeType() string {
+	switch f {
+	case FormatCBZ:
+		return "application/vnd.comicbook+zip"
+	case FormatPDF:
+		return "application/pdf"
+	case FormatEPUB:
+		return "application/epub+zip"
+	case FormatAnimatic:
+		return "video/mp4"
+	default:
+		return "application/octet-stream"
+	}
+}
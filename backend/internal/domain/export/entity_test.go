@@ -0,0 +1,57 @@
+package export
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Format
+		wantErr error
+	}{
+		{"cbz", FormatCBZ, nil},
+		{" PDF ", FormatPDF, nil},
+		{"Epub", FormatEPUB, nil},
+		{"animatic", FormatAnimatic, nil},
+		{"zip", "", ErrInvalidFormat},
+		{"", "", ErrInvalidFormat},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.value)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("ParseFormat(%q) error = %v, want %v", tt.value, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestExportProgress(t *testing.T) {
+	e := NewExport("task-1", "user-1", FormatCBZ)
+	e.Start()
+
+	e.UpdateProgress(40)
+	e.UpdateProgress(20)
+	if e.Progress != 40 {
+		t.Errorf("Progress = %d, want 40 (progress must not go back)", e.Progress)
+	}
+	e.UpdateProgress(150)
+	if e.Progress != 100 {
+		t.Errorf("Progress = %d, want 100", e.Progress)
+	}
+
+	// 中断后重新领取时从头开始
+	e.Start()
+	if e.Progress != 0 || e.Status != StatusProcessing {
+		t.Errorf("after restart: Progress = %d, Status = %s", e.Progress, e.Status)
+	}
+
+	e.Complete("media-1")
+	if !e.IsFinished() || e.MediaID != "media-1" || e.Progress != 100 || e.CompletedAt == nil {
+		t.Errorf("after complete: %+v", e)
+	}
+}
@@ -0,0 +1,27 @@
+package export
+
+import (
+	"context"
+	"time"
+)
+
+// Repository 导出作业仓储，导出记录本身即任务队列，worker 通过租约独占领取
+type Repository interface {
+	// Save 保存导出作业（创建或更新）
+	Save(ctx context.Context, e *Export) error
+
+	// FindByID 查询导出作业，不存在时返回 ErrExportNotFound
+	FindByID(ctx context.Context, id string) (*Export, error)
+
+	// FindClaimable 查询可被 worker 领取的导出作业（待处理或处理中且租约已过期）
+	FindClaimable(ctx context.Context, limit int) ([]*Export, error)
+
+	// AcquireLease 尝试获取租约，租约未过期时返回 false
+	AcquireLease(ctx context.Context, id, owner string, until time.Time) (bool, error)
+
+	// RenewLease 续约（心跳），租约不再属于 owner 时返回 ErrLeaseLost
+	RenewLease(ctx context.Context, id, owner string, until time.Time) error
+
+	// ReleaseLease 释放租约
+	ReleaseLease(ctx context.Context, id, owner string) error
+}
@@ -0,0 +1,282 @@
+package export
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ClipKind 片段素材类型
+type ClipKind string
+
+const (
+	ClipKindVideo ClipKind = "video" // 场景视频
+	ClipKindStill ClipKind = "still" // 场景图片，按台词长度停留
+)
+
+// TransitionType 进入片段的转场
+type TransitionType string
+
+const (
+	TransitionCut  TransitionType = "cut"  // 直接切换，同一章节内的场景之间
+	TransitionFade TransitionType = "fade" // 从黑场淡入，影片开头和每个新章节
+)
+
+const (
+	// DefaultAnimaticWidth、DefaultAnimaticHeight、DefaultAnimaticFPS 动态分镜的默认画面规格
+	DefaultAnimaticWidth  = 1280
+	DefaultAnimaticHeight = 720
+	DefaultAnimaticFPS    = 24
+
+	// MinStillDuration、MaxStillDuration 图片片段的停留时间范围（秒）
+	MinStillDuration = 3.0
+	MaxStillDuration = 12.0
+	// DefaultVideoDuration 无法读取时长的视频片段按此时长处理（秒）
+	DefaultVideoDuration = 5.0
+	// FadeDuration 淡入转场的时长（秒）
+	FadeDuration = 1.0
+
+	// readingRate 字幕阅读速度（字/秒），决定图片片段的停留时间
+	readingRate = 6.0
+	// readingPause 图片片段在台词读完后额外停留的时间（秒）
+	readingPause = 1.0
+)
+
+// Line 片段中的一句台词。有配音时 Offset 和 Duration 为配音相对片段开始的时间（秒）
+type Line struct {
+	Speaker      string  `json:"speaker"`
+	Text         string  `json:"text"`
+	Narration    bool    `json:"narration,omitempty"` // 旁白字幕不显示说话人
+	AudioMediaID string  `json:"audio_media_id,omitempty"`
+	Offset       float64 `json:"offset,omitempty"`
+	Duration     float64 `json:"duration,omitempty"`
+}
+
+// ClipSource 一个场景用于成片的素材
+type ClipSource struct {
+	SceneID       string
+	ChapterNumber int
+	SceneNumber   int
+	MediaID       string
+	Kind          ClipKind
+	Duration      float64 // 视频素材时长（秒），图片为 0
+	Lines         []Line
+}
+
+// Transition 进入片段时的转场
+type Transition struct {
+	Type     TransitionType `json:"type"`
+	Duration float64        `json:"duration"`
+}
+
+// Clip 时间线上的一个片段。In/Out 为素材内的入点和出点，Start 为片段在成片中的开始时间（秒）
+type Clip struct {
+	Index         int        `json:"index"`
+	SceneID       string     `json:"scene_id"`
+	ChapterNumber int        `json:"chapter_number"`
+	SceneNumber   int        `json:"scene_number"`
+	MediaID       string     `json:"media_id"`
+	Kind          ClipKind   `json:"kind"`
+	Start         float64    `json:"start"`
+	In            float64    `json:"in"`
+	Out           float64    `json:"out"`
+	Duration      float64    `json:"duration"`
+	Transition    Transition `json:"transition"`
+}
+
+// End 片段在成片中的结束时间（秒）
+func (c Clip) End() float64 {
+	return c.Start + c.Duration
+}
+
+// Cue 一条字幕
+type Cue struct {
+	Index int     `json:"index"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+}
+
+// AudioCue 时间线上的一段配音，Start 为在成片中的开始时间（秒）
+type AudioCue struct {
+	Index    int     `json:"index"`
+	MediaID  string  `json:"media_id"`
+	Start    float64 `json:"start"`
+	Duration float64 `json:"duration"`
+}
+
+// Timeline 动态分镜的时间线清单：按章节和场景编号排列的片段及对应的字幕和配音
+type Timeline struct {
+	Width    int        `json:"width"`
+	Height   int        `json:"height"`
+	FPS      int        `json:"fps"`
+	Duration float64    `json:"duration"`
+	Clips    []Clip     `json:"clips"`
+	Cues     []Cue      `json:"cues"`
+	Audio    []AudioCue `json:"audio"`
+}
+
+// BuildTimeline 按章节号和场景编号排列素材并首尾相接：视频使用完整时长，图片按台词阅读时间停留，
+// 影片开头和每个新章节从黑场淡入，章节内直接切换。每个片段的台词按字数比例分配片段时长生成字幕。
+// 片段的台词全部有配音时，片段至少持续到配音结束，字幕与配音同步
+func BuildTimeline(sources []ClipSource, width, height, fps int) Timeline {
+	ordered := append([]ClipSource(nil), sources...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].ChapterNumber != ordered[j].ChapterNumber {
+			return ordered[i].ChapterNumber < ordered[j].ChapterNumber
+		}
+		return ordered[i].SceneNumber < ordered[j].SceneNumber
+	})
+
+	timeline := Timeline{
+		Width:  width,
+		Height: height,
+		FPS:    fps,
+		Clips:  make([]Clip, 0, len(ordered)),
+		Cues:   make([]Cue, 0),
+		Audio:  make([]AudioCue, 0),
+	}
+
+	var start float64
+	for i, source := range ordered {
+		duration := clipDuration(source)
+		clip := Clip{
+			Index:         i + 1,
+			SceneID:       source.SceneID,
+			ChapterNumber: source.ChapterNumber,
+			SceneNumber:   source.SceneNumber,
+			MediaID:       source.MediaID,
+			Kind:          source.Kind,
+			Start:         start,
+			Out:           duration,
+			Duration:      duration,
+			Transition:    Transition{Type: TransitionCut},
+		}
+		if i == 0 || source.ChapterNumber != ordered[i-1].ChapterNumber {
+			clip.Transition = Transition{Type: TransitionFade, Duration: min(FadeDuration, duration/2)}
+		}
+		timeline.Clips = append(timeline.Clips, clip)
+		if voiced(source.Lines) {
+			timeline.Cues = append(timeline.Cues, voicedCues(clip, source.Lines, len(timeline.Cues))...)
+			timeline.Audio = append(timeline.Audio, clipAudio(clip, source.Lines, len(timeline.Audio))...)
+		} else {
+			timeline.Cues = append(timeline.Cues, clipCues(clip, source.Lines, len(timeline.Cues))...)
+		}
+		start = clip.End()
+	}
+	timeline.Duration = start
+
+	return timeline
+}
+
+// clipDuration 视频使用素材时长，图片按台词阅读时间在 [MinStillDuration, MaxStillDuration] 内停留。
+// 有配音时视频延长到配音结束，图片在配音结束后再停留 readingPause，不受 MaxStillDuration 限制
+func clipDuration(source ClipSource) float64 {
+	var voiceEnd float64
+	if voiced(source.Lines) {
+		for _, line := range source.Lines {
+			voiceEnd = max(voiceEnd, line.Offset+line.Duration)
+		}
+	}
+
+	if source.Kind == ClipKindVideo {
+		duration := DefaultVideoDuration
+		if source.Duration > 0 {
+			duration = source.Duration
+		}
+		return roundMillis(max(duration, voiceEnd))
+	}
+	if voiceEnd > 0 {
+		return roundMillis(max(voiceEnd+readingPause, MinStillDuration))
+	}
+
+	var runes int
+	for _, line := range source.Lines {
+		runes += utf8.RuneCountInString(line.Text)
+	}
+	duration := float64(runes)/readingRate + readingPause
+	return roundMillis(min(max(duration, MinStillDuration), MaxStillDuration))
+}
+
+// clipCues 将片段时长按台词字数比例分给各句台词
+func clipCues(clip Clip, lines []Line, offset int) []Cue {
+	var total int
+	for _, line := range lines {
+		total += max(utf8.RuneCountInString(line.Text), 1)
+	}
+	if total == 0 {
+		return nil
+	}
+
+	cues := make([]Cue, 0, len(lines))
+	at := clip.Start
+	var used int
+	for _, line := range lines {
+		text := strings.TrimSpace(line.Text)
+		used += max(utf8.RuneCountInString(line.Text), 1)
+		end := roundMillis(clip.Start + clip.Duration*float64(used)/float64(total))
+		if text != "" {
+			if !line.Narration && line.Speaker != "" {
+				text = line.Speaker + "：" + text
+			}
+			cues = append(cues, Cue{Index: offset + len(cues) + 1, Start: at, End: end, Text: text})
+		}
+		at = end
+	}
+	return cues
+}
+
+// voiced 片段的每句台词都有配音（空台词除外）
+func voiced(lines []Line) bool {
+	var found bool
+	for _, line := range lines {
+		if strings.TrimSpace(line.Text) == "" {
+			continue
+		}
+		if line.AudioMediaID == "" || line.Duration <= 0 {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// voicedCues 字幕在配音播放期间显示
+func voicedCues(clip Clip, lines []Line, offset int) []Cue {
+	cues := make([]Cue, 0, len(lines))
+	for _, line := range lines {
+		text := strings.TrimSpace(line.Text)
+		if text == "" {
+			continue
+		}
+		if !line.Narration && line.Speaker != "" {
+			text = line.Speaker + "：" + text
+		}
+		start := roundMillis(clip.Start + line.Offset)
+		cues = append(cues, Cue{Index: offset + len(cues) + 1, Start: start, End: roundMillis(start + line.Duration), Text: text})
+	}
+	return cues
+}
+
+// clipAudio 片段中各句台词的配音在成片中的位置
+func clipAudio(clip Clip, lines []Line, offset int) []AudioCue {
+	audio := make([]AudioCue, 0, len(lines))
+	for _, line := range lines {
+		if line.AudioMediaID == "" {
+			continue
+		}
+		audio = append(audio, AudioCue{
+			Index:    offset + len(audio) + 1,
+			MediaID:  line.AudioMediaID,
+			Start:    roundMillis(clip.Start + line.Offset),
+			Duration: line.Duration,
+		})
+	}
+	return audio
+}
+
+// roundMillis 时间精确到毫秒，避免累加误差出现在字幕时间码中
+func roundMillis(seconds float64) float64 {
+	return math.Round(seconds*1000) / 1000
+}
@@ -0,0 +1,144 @@
+package export
+
+import "testing"
+
+func TestBuildTimelineOrdersClipsAndTransitions(t *testing.T) {
+	sources := []ClipSource{
+		{SceneID: "c2-s1", ChapterNumber: 2, SceneNumber: 1, MediaID: "m4", Kind: ClipKindVideo, Duration: 4.5},
+		{SceneID: "c1-s2", ChapterNumber: 1, SceneNumber: 2, MediaID: "m2", Kind: ClipKindVideo},
+		{SceneID: "c1-s1", ChapterNumber: 1, SceneNumber: 1, MediaID: "m1", Kind: ClipKindStill},
+		{SceneID: "c1-s3", ChapterNumber: 1, SceneNumber: 3, MediaID: "m3", Kind: ClipKindStill, Lines: []Line{
+			{Speaker: "林", Text: "这是一句足够长的台词，需要读很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久很久"},
+		}},
+	}
+
+	timeline := BuildTimeline(sources, DefaultAnimaticWidth, DefaultAnimaticHeight, DefaultAnimaticFPS)
+
+	want := []struct {
+		sceneID    string
+		start      float64
+		duration   float64
+		transition TransitionType
+	}{
+		{"c1-s1", 0, MinStillDuration, TransitionFade},
+		{"c1-s2", 3, DefaultVideoDuration, TransitionCut},
+		{"c1-s3", 8, MaxStillDuration, TransitionCut},
+		{"c2-s1", 20, 4.5, TransitionFade},
+	}
+	if len(timeline.Clips) != len(want) {
+		t.Fatalf("len(Clips) = %d, want %d", len(timeline.Clips), len(want))
+	}
+	for i, w := range want {
+		clip := timeline.Clips[i]
+		if clip.Index != i+1 || clip.SceneID != w.sceneID || clip.Start != w.start || clip.Duration != w.duration || clip.Transition.Type != w.transition {
+			t.Errorf("Clips[%d] = %+v, want scene %s start %v duration %v transition %s", i, clip, w.sceneID, w.start, w.duration, w.transition)
+		}
+		if clip.In != 0 || clip.Out != clip.Duration {
+			t.Errorf("Clips[%d] in/out = %v/%v, want 0/%v", i, clip.In, clip.Out, clip.Duration)
+		}
+	}
+	if timeline.Clips[0].Transition.Duration != FadeDuration {
+		t.Errorf("fade duration = %v, want %v", timeline.Clips[0].Transition.Duration, FadeDuration)
+	}
+	if timeline.Duration != 24.5 {
+		t.Errorf("Duration = %v, want 24.5", timeline.Duration)
+	}
+}
+
+func TestBuildTimelineCues(t *testing.T) {
+	sources := []ClipSource{
+		{SceneID: "s1", ChapterNumber: 1, SceneNumber: 1, MediaID: "m1", Kind: ClipKindVideo, Duration: 6, Lines: []Line{
+			{Speaker: "旁白", Text: "夜色渐深。", Narration: true},
+			{Speaker: "林", Text: ""},
+			{Speaker: "林", Text: "你来了。"},
+		}},
+		{SceneID: "s2", ChapterNumber: 1, SceneNumber: 2, MediaID: "m2", Kind: ClipKindStill},
+		{SceneID: "s3", ChapterNumber: 1, SceneNumber: 3, MediaID: "m3", Kind: ClipKindVideo, Duration: 2, Lines: []Line{
+			{Speaker: "白", Text: "嗯"},
+		}},
+	}
+
+	timeline := BuildTimeline(sources, DefaultAnimaticWidth, DefaultAnimaticHeight, DefaultAnimaticFPS)
+
+	// 第一段按字数 5:1:4 分配 6 秒，空台词占位但不生成字幕
+	want := []Cue{
+		{Index: 1, Start: 0, End: 3, Text: "夜色渐深。"},
+		{Index: 2, Start: 3.6, End: 6, Text: "林：你来了。"},
+		{Index: 3, Start: 9, End: 11, Text: "白：嗯"},
+	}
+	if len(timeline.Cues) != len(want) {
+		t.Fatalf("Cues = %+v, want %d cues", timeline.Cues, len(want))
+	}
+	for i, w := range want {
+		if timeline.Cues[i] != w {
+			t.Errorf("Cues[%d] = %+v, want %+v", i, timeline.Cues[i], w)
+		}
+	}
+}
+
+func TestBuildTimelineEmpty(t *testing.T) {
+	timeline := BuildTimeline(nil, DefaultAnimaticWidth, DefaultAnimaticHeight, DefaultAnimaticFPS)
+	if timeline.Duration != 0 || len(timeline.Clips) != 0 || timeline.Cues == nil {
+		t.Errorf("BuildTimeline(nil) = %+v", timeline)
+	}
+}
+
+func TestBuildTimelineVoiceover(t *testing.T) {
+	sources := []ClipSource{
+		{SceneID: "s1", ChapterNumber: 1, SceneNumber: 1, MediaID: "m1", Kind: ClipKindStill, Lines: []Line{
+			{Speaker: "旁白", Text: "夜色渐深。", Narration: true, AudioMediaID: "a1", Offset: 0.5, Duration: 1.2},
+			{Speaker: "林", Text: "你来了。", AudioMediaID: "a2", Offset: 2.1, Duration: 0.8},
+		}},
+		{SceneID: "s2", ChapterNumber: 1, SceneNumber: 2, MediaID: "m2", Kind: ClipKindVideo, Duration: 2, Lines: []Line{
+			{Speaker: "白", Text: "嗯", AudioMediaID: "a3", Offset: 0.5, Duration: 3},
+		}},
+		// 部分台词没有配音时按字数分配字幕，不使用配音
+		{SceneID: "s3", ChapterNumber: 1, SceneNumber: 3, MediaID: "m3", Kind: ClipKindStill, Lines: []Line{
+			{Speaker: "林", Text: "走吧。", AudioMediaID: "a4", Offset: 0.5, Duration: 0.6},
+			{Speaker: "白", Text: "好。"},
+		}},
+	}
+
+	timeline := BuildTimeline(sources, DefaultAnimaticWidth, DefaultAnimaticHeight, DefaultAnimaticFPS)
+
+	// 图片在配音结束后停留 readingPause，视频延长到配音结束
+	if got := []float64{timeline.Clips[0].Duration, timeline.Clips[1].Duration, timeline.Clips[2].Duration}; got[0] != 3.9 || got[1] != 3.5 || got[2] != MinStillDuration {
+		t.Errorf("clip durations = %v, want [3.9 3.5 3]", got)
+	}
+
+	wantCues := []Cue{
+		{Index: 1, Start: 0.5, End: 1.7, Text: "夜色渐深。"},
+		{Index: 2, Start: 2.1, End: 2.9, Text: "林：你来了。"},
+		{Index: 3, Start: 4.4, End: 7.4, Text: "白：嗯"},
+	}
+	for i, want := range wantCues {
+		if timeline.Cues[i] != want {
+			t.Errorf("Cues[%d] = %+v, want %+v", i, timeline.Cues[i], want)
+		}
+	}
+	if len(timeline.Cues) != 5 || timeline.Cues[3].Start != 7.4 {
+		t.Errorf("unvoiced clip cues = %+v, want proportional cues from 7.4", timeline.Cues[3:])
+	}
+
+	wantAudio := []AudioCue{
+		{Index: 1, MediaID: "a1", Start: 0.5, Duration: 1.2},
+		{Index: 2, MediaID: "a2", Start: 2.1, Duration: 0.8},
+		{Index: 3, MediaID: "a3", Start: 4.4, Duration: 3},
+	}
+	if len(timeline.Audio) != len(wantAudio) {
+		t.Fatalf("Audio = %+v, want %d entries", timeline.Audio, len(wantAudio))
+	}
+	for i, want := range wantAudio {
+		if timeline.Audio[i] != want {
+			t.Errorf("Audio[%d] = %+v, want %+v", i, timeline.Audio[i], want)
+		}
+	}
+
+	// 配音较长的图片片段不受 MaxStillDuration 限制
+	long := BuildTimeline([]ClipSource{{SceneID: "s1", ChapterNumber: 1, SceneNumber: 1, MediaID: "m1", Kind: ClipKindStill, Lines: []Line{
+		{Text: "很长的旁白", Narration: true, AudioMediaID: "a1", Offset: 0.5, Duration: 14},
+	}}}, DefaultAnimaticWidth, DefaultAnimaticHeight, DefaultAnimaticFPS)
+	if long.Duration != 15.5 {
+		t.Errorf("long voiced still duration = %v, want 15.5", long.Duration)
+	}
+}
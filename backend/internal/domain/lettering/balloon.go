@@ -0,0 +1,72 @@
+package lettering
+
+import "strings"
+
+// Kind 嵌字类型
+type Kind string
+
+const (
+	KindSpeech  Kind = "speech"  // 对话气泡，带指向画面的尾巴
+	KindThought Kind = "thought" // 思考气泡，云朵形，以小圆圈代替尾巴
+	KindCaption Kind = "caption" // 旁白框，矩形，无尾巴
+)
+
+// Shape 气泡轮廓
+type Shape string
+
+const (
+	ShapeOval   Shape = "oval"   // 椭圆，普通对话
+	ShapeBurst  Shape = "burst"  // 锯齿爆炸形，愤怒、激动、惊讶
+	ShapeWobbly Shape = "wobbly" // 波浪边，悲伤、叹气
+	ShapeCloud  Shape = "cloud"  // 云朵，内心独白
+	ShapeBox    Shape = "box"    // 矩形，旁白
+)
+
+// MaxBalloonsPerPanel 每个面板最多放置的气泡数，超出的台词不再嵌入，避免遮挡画面
+const MaxBalloonsPerPanel = 4
+
+// emotionShapes 情绪到对话气泡轮廓的映射，未列出的情绪使用椭圆
+var emotionShapes = map[string]Shape{
+	"angry":     ShapeBurst,
+	"excited":   ShapeBurst,
+	"surprised": ShapeBurst,
+	"sad":       ShapeWobbly,
+	"sigh":      ShapeWobbly,
+}
+
+// Balloon 一条要嵌入面板的台词
+type Balloon struct {
+	Kind    Kind
+	Shape   Shape
+	Speaker string
+	Text    string
+}
+
+// NewBalloon 创建气泡，思考气泡和旁白框的轮廓固定，对话气泡的轮廓由情绪决定
+func NewBalloon(kind Kind, speaker, text, emotion string) Balloon {
+	return Balloon{
+		Kind:    kind,
+		Shape:   ShapeFor(kind, emotion),
+		Speaker: speaker,
+		Text:    strings.TrimSpace(text),
+	}
+}
+
+// ShapeFor 根据嵌字类型和情绪选择气泡轮廓
+func ShapeFor(kind Kind, emotion string) Shape {
+	switch kind {
+	case KindThought:
+		return ShapeCloud
+	case KindCaption:
+		return ShapeBox
+	}
+	if shape, ok := emotionShapes[strings.ToLower(strings.TrimSpace(emotion))]; ok {
+		return shape
+	}
+	return ShapeOval
+}
+
+// HasTail 气泡是否需要指向画面的尾巴
+func (b Balloon) HasTail() bool {
+	return b.Kind == KindSpeech
+}
@@ -0,0 +1,25 @@
+package lettering
+
+import "testing"
+
+func TestShapeFor(t *testing.T) {
+	tests := []struct {
+		kind    Kind
+		emotion string
+		want    Shape
+	}{
+		{KindSpeech, "angry", ShapeBurst},
+		{KindSpeech, "Surprised", ShapeBurst},
+		{KindSpeech, "sad", ShapeWobbly},
+		{KindSpeech, "neutral", ShapeOval},
+		{KindSpeech, "", ShapeOval},
+		{KindThought, "angry", ShapeCloud},
+		{KindCaption, "happy", ShapeBox},
+	}
+
+	for _, tt := range tests {
+		if got := ShapeFor(tt.kind, tt.emotion); got != tt.want {
+			t.Errorf("ShapeFor(%s, %q) = %s, want %s", tt.kind, tt.emotion, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package lettering
+
+import "image"
+
+// placementSteps 沿每条边尝试的候选位置数
+const placementSteps = 12
+
+// CentreZone 面板中央的保留区域（各边向内缩进 1/4），人物和主体通常位于此处，气泡尽量避开
+func CentreZone(width, height int) image.Rectangle {
+	return image.Rect(width/4, height/4, width-width/4, height-height/4)
+}
+
+// Margin 气泡与面板边缘、气泡之间的最小距离
+func Margin(width, height int) int {
+	return max(4, min(width, height)/40)
+}
+
+// Place 按阅读顺序为气泡在 width × height 的面板中选择位置：依次尝试上边、阅读起始侧、
+// 阅读结束侧和下边，优先避开中央区域，其次只要求不与已放置的气泡重叠。
+// 返回的矩形与 sizes 一一对应，放不下的气泡为空矩形
+func Place(width, height int, sizes []image.Point, rtl bool) []image.Rectangle {
+	margin := Margin(width, height)
+	centre := CentreZone(width, height)
+	rects := make([]image.Rectangle, len(sizes))
+	var placed []image.Rectangle
+
+	free := func(r image.Rectangle) bool {
+		for _, p := range placed {
+			if r.Overlaps(p.Inset(-margin)) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for i, size := range sizes {
+		if size.X <= 0 || size.Y <= 0 || size.X > width-2*margin || size.Y > height-2*margin {
+			continue
+		}
+
+		candidates := candidatePositions(width, height, margin, size, rtl)
+		var found image.Rectangle
+		for _, r := range candidates {
+			if !r.Overlaps(centre) && free(r) {
+				found = r
+				break
+			}
+		}
+		if found.Empty() {
+			for _, r := range candidates {
+				if free(r) {
+					found = r
+					break
+				}
+			}
+		}
+		if !found.Empty() {
+			rects[i] = found
+			placed = append(placed, found)
+		}
+	}
+
+	return rects
+}
+
+// candidatePositions 按优先级列出气泡的候选位置
+func candidatePositions(width, height, margin int, size image.Point, rtl bool) []image.Rectangle {
+	left, right := margin, width-margin-size.X
+	top, bottom := margin, height-margin-size.Y
+
+	// 横向位置按阅读顺序排列，从右到左阅读时从右边开始
+	xs := steps(left, right)
+	if rtl {
+		for i, j := 0, len(xs)-1; i < j; i, j = i+1, j-1 {
+			xs[i], xs[j] = xs[j], xs[i]
+		}
+	}
+	ys := steps(top, bottom)
+
+	var candidates []image.Rectangle
+	add := func(x, y int) {
+		candidates = append(candidates, image.Rect(x, y, x+size.X, y+size.Y))
+	}
+	for _, x := range xs {
+		add(x, top)
+	}
+	for _, x := range []int{xs[0], xs[len(xs)-1]} {
+		for _, y := range ys[1:] {
+			add(x, y)
+		}
+	}
+	for _, x := range xs {
+		add(x, bottom)
+	}
+	return candidates
+}
+
+// steps 将 [from, to] 均匀分成 placementSteps 段，返回各段端点
+func steps(from, to int) []int {
+	if to <= from {
+		return []int{from}
+	}
+	values := make([]int, 0, placementSteps+1)
+	for i := 0; i <= placementSteps; i++ {
+		values = append(values, from+(to-from)*i/placementSteps)
+	}
+	return values
+}
@@ -0,0 +1,50 @@
+package lettering
+
+import (
+	"image"
+	"testing"
+)
+
+func TestPlaceFollowsReadingOrderAndAvoidsCentre(t *testing.T) {
+	width, height := 1000, 600
+	sizes := []image.Point{{220, 120}, {220, 120}, {220, 120}}
+	margin := Margin(width, height)
+	centre := CentreZone(width, height)
+
+	rtl := Place(width, height, sizes, true)
+	if rtl[0].Max.X != width-margin || rtl[0].Min.Y != margin {
+		t.Errorf("first RTL balloon = %v, want top-right corner", rtl[0])
+	}
+	if rtl[1].Min.X >= rtl[0].Min.X {
+		t.Errorf("second RTL balloon %v should be left of %v", rtl[1], rtl[0])
+	}
+	for i, r := range rtl {
+		if r.Empty() || r.Overlaps(centre) {
+			t.Errorf("balloon %d = %v, want placed outside centre %v", i, r, centre)
+		}
+		for j := range i {
+			if r.Overlaps(rtl[j]) {
+				t.Errorf("balloon %d %v overlaps balloon %d %v", i, r, j, rtl[j])
+			}
+		}
+	}
+
+	ltr := Place(width, height, sizes[:1], false)
+	if ltr[0].Min.X != margin || ltr[0].Min.Y != margin {
+		t.Errorf("first LTR balloon = %v, want top-left corner", ltr[0])
+	}
+}
+
+func TestPlaceSkipsBalloonsThatDoNotFit(t *testing.T) {
+	rects := Place(300, 200, []image.Point{{400, 50}, {200, 150}, {200, 150}}, false)
+
+	if !rects[0].Empty() {
+		t.Errorf("oversized balloon placed at %v", rects[0])
+	}
+	if rects[1].Empty() {
+		t.Error("balloon that fits the panel was not placed")
+	}
+	if !rects[2].Empty() {
+		t.Errorf("balloon without free space placed at %v", rects[2])
+	}
+}
@@ -3,6 +3,8 @@ package media
 import (
 	"errors"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type MediaID string
@@ -10,8 +12,11 @@ type MediaType string
 type MediaStatus string
 
 const (
-	MediaTypeImage MediaType = "image"
-	MediaTypeVideo MediaType = "video"
+	MediaTypeImage  MediaType = "image"
+	MediaTypeVideo  MediaType = "video"
+	MediaTypePage   MediaType = "page"   // 由面板排版合成的漫画页面图片
+	MediaTypeExport MediaType = "export" // 导出的 CBZ、PDF、EPUB 文件
+	MediaTypeAudio  MediaType = "audio"  // 台词配音
 )
 
 const (
@@ -25,11 +30,13 @@ var (
 	ErrMediaNotFound    = errors.New("media not found")
 	ErrInvalidMediaType = errors.New("invalid media type")
 	ErrInvalidStatus    = errors.New("invalid media status")
+	ErrLeaseLost        = errors.New("media lease lost")
 )
 
 type Media struct {
 	ID           MediaID
 	NovelID      string // 关联的小说ID
+	UserID       string // 发起生成的用户，后台生成时按该用户检查配额和记账；流水线内生成的媒体为空
 	SceneID      string // 关联的场景ID（可选，用于场景生成模式）
 	Type         MediaType
 	Status       MediaStatus
@@ -37,6 +44,10 @@ type Media struct {
 	Metadata     MediaMetadata
 	GenerationID string
 	ErrorMessage string
+	SubmittedAt  *time.Time  // 提交到 AI 服务的时间（异步生成的视频）
+	PollCount    int         // 已查询生成状态的次数
+	CacheKey     string      // 生成参数的内容寻址键（GenerationParams.CacheKey），参数相同的请求可复用已完成的结果
+	Provenance   *Provenance // 生成来源，未记录的旧数据为 nil
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 	CompletedAt  *time.Time
@@ -72,11 +83,30 @@ func (m *Media) IsReady() bool {
 }
 
 func (m *Media) MarkGenerating(generationID string) {
+	now := time.Now()
 	m.Status = MediaStatusGenerating
 	m.GenerationID = generationID
+	if generationID != "" {
+		m.SubmittedAt = &now
+		m.PollCount = 0
+	}
+	m.UpdatedAt = now
+}
+
+// RecordPoll 记录一次生成状态查询
+func (m *Media) RecordPoll() {
+	m.PollCount++
 	m.UpdatedAt = time.Now()
 }
 
+// GeneratingSince 异步生成的开始时间，未记录提交时间的旧数据使用创建时间
+func (m *Media) GeneratingSince() time.Time {
+	if m.SubmittedAt != nil {
+		return *m.SubmittedAt
+	}
+	return m.CreatedAt
+}
+
 func (m *Media) MarkCompleted(url string, metadata MediaMetadata) {
 	m.Status = MediaStatusCompleted
 	m.URL = url
@@ -86,6 +116,16 @@ func (m *Media) MarkCompleted(url string, metadata MediaMetadata) {
 	m.UpdatedAt = now
 }
 
+// CompleteFromCache 复用参数相同且已完成的媒体的文件，不再调用 AI 服务。
+// 文件对应的改写提示词随文件一起沿用
+func (m *Media) CompleteFromCache(cached *Media) {
+	m.CacheKey = cached.CacheKey
+	if m.Provenance != nil && cached.Provenance != nil {
+		m.Provenance.RevisedPrompt = cached.Provenance.RevisedPrompt
+	}
+	m.MarkCompleted(cached.URL, cached.Metadata)
+}
+
 func (m *Media) MarkFailed(errorMsg string) {
 	m.Status = MediaStatusFailed
 	m.ErrorMessage = errorMsg
@@ -93,7 +133,9 @@ func (m *Media) MarkFailed(errorMsg string) {
 }
 
 func (m *Media) Validate() error {
-	if m.Type != MediaTypeImage && m.Type != MediaTypeVideo {
+	switch m.Type {
+	case MediaTypeImage, MediaTypeVideo, MediaTypePage, MediaTypeExport, MediaTypeAudio:
+	default:
 		return ErrInvalidMediaType
 	}
 	// Either NovelID or SceneID must be provided
@@ -103,15 +145,7 @@ func (m *Media) Validate() error {
 	return nil
 }
 
+// generateID 生成媒体ID，同一秒内批量创建的媒体也不会冲突
 func generateID() string {
-	return time.Now().Format("20060102150405") + "-" + randomString(8)
-}
-
-func randomString(n int) string {
-	const letters = "abcdefghijklmnopqrstuvwxyz0123456789"
-	b := make([]byte, n)
-	for i := range b {
-		b[i] = letters[i%len(letters)]
-	}
-	return string(b)
+	return uuid.New().String()
 }
@@ -1,6 +1,9 @@
 package media
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type MediaRepository interface {
 	Save(ctx context.Context, media *Media) error
@@ -10,4 +13,11 @@ type MediaRepository interface {
 	UpdateStatus(ctx context.Context, id MediaID, status MediaStatus, url string, errorMsg string) error
 	Delete(ctx context.Context, id MediaID) error
 	FindPendingMedia(ctx context.Context, limit int) ([]*Media, error)
+	FindClaimableMedia(ctx context.Context, limit int) ([]*Media, error)
+	FindGeneratingMedia(ctx context.Context, mediaType MediaType, limit int) ([]*Media, error)
+	// FindByCacheKey 查询该键最近一次完成的媒体，不存在时返回 ErrMediaNotFound
+	FindByCacheKey(ctx context.Context, cacheKey string) (*Media, error)
+	AcquireLease(ctx context.Context, id MediaID, owner string, until time.Time) (bool, error)
+	RenewLease(ctx context.Context, id MediaID, owner string, until time.Time) error
+	ReleaseLease(ctx context.Context, id MediaID, owner string) error
 }
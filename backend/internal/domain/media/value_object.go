@@ -1,5 +1,12 @@
 package media
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+)
+
 type MediaMetadata struct {
 	Width      int
 	Height     int
@@ -20,6 +27,38 @@ type GenerationParams struct {
 	CFGScale       float64
 }
 
+// Provenance 媒体的生成来源，记录请求时使用的服务、模型和完整参数，用于复现或微调生成结果。
+// Params.ReferenceImage 记录参考图地址而非内容
+type Provenance struct {
+	Provider      string
+	Model         string
+	Width         int // 请求的尺寸，0 表示使用服务默认尺寸
+	Height        int
+	Params        GenerationParams
+	RevisedPrompt string // 服务改写后实际使用的提示词，服务未返回时为空
+}
+
+// CacheKey 生成结果的内容寻址键：服务、模型、尺寸和影响生成结果的参数相同时键相同。
+// ReferenceImage 应为参考图内容（如 data URI）而非可变的地址，Steps、CFGScale 目前没有服务使用，不参与计算
+func (p GenerationParams) CacheKey(provider, model string, width, height int) string {
+	hash := sha256.New()
+	for _, part := range []string{
+		provider,
+		model,
+		p.Prompt,
+		p.NegativePrompt,
+		p.Style,
+		p.Quality,
+		formatResolution(width, height),
+		strconv.FormatInt(p.Seed, 10),
+		p.ReferenceImage,
+	} {
+		// 带长度前缀，避免相邻字段拼接后产生歧义
+		fmt.Fprintf(hash, "%d:%s", len(part), part)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
 func NewImageMetadata(width, height int, format string, fileSize int64) MediaMetadata {
 	return MediaMetadata{
 		Width:      width,
@@ -42,5 +81,5 @@ func NewVideoMetadata(width, height int, duration float64, format string, fileSi
 }
 
 func formatResolution(width, height int) string {
-	return string(rune(width)) + "x" + string(rune(height))
+	return fmt.Sprintf("%dx%d", width, height)
 }
@@ -0,0 +1,43 @@
+package media
+
+import "testing"
+
+func TestNewImageMetadataFormatsResolution(t *testing.T) {
+	metadata := NewImageMetadata(1344, 768, "image/png", 2048)
+
+	if metadata.Resolution != "1344x768" {
+		t.Errorf("Resolution = %q, want %q", metadata.Resolution, "1344x768")
+	}
+	if metadata.FileSize != 2048 || metadata.Format != "image/png" {
+		t.Errorf("unexpected metadata %+v", metadata)
+	}
+}
+
+func TestGenerationParamsCacheKey(t *testing.T) {
+	params := GenerationParams{Prompt: "a cat on a roof", NegativePrompt: "blurry", ReferenceImage: "data:image/png;base64,AAAA"}
+	key := params.CacheKey("gemini", "gemini-2.5-flash-image", 1344, 768)
+
+	if len(key) != 64 {
+		t.Fatalf("CacheKey() = %q, want sha256 hex", key)
+	}
+	if again := params.CacheKey("gemini", "gemini-2.5-flash-image", 1344, 768); again != key {
+		t.Errorf("CacheKey() not stable: %q != %q", again, key)
+	}
+
+	seeded := params
+	seeded.Seed = 42
+	variants := map[string]string{
+		"provider":  params.CacheKey("mock", "gemini-2.5-flash-image", 1344, 768),
+		"model":     params.CacheKey("gemini", "gemini-3-image", 1344, 768),
+		"size":      params.CacheKey("gemini", "gemini-2.5-flash-image", 1024, 1024),
+		"seed":      seeded.CacheKey("gemini", "gemini-2.5-flash-image", 1344, 768),
+		"reference": GenerationParams{Prompt: params.Prompt, NegativePrompt: params.NegativePrompt}.CacheKey("gemini", "gemini-2.5-flash-image", 1344, 768),
+		// 字段边界移动时键也不同
+		"boundary": GenerationParams{Prompt: "a cat on a roofblurry", ReferenceImage: params.ReferenceImage}.CacheKey("gemini", "gemini-2.5-flash-image", 1344, 768),
+	}
+	for name, variant := range variants {
+		if variant == key {
+			t.Errorf("CacheKey() ignores %s", name)
+		}
+	}
+}
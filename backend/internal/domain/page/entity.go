@@ -0,0 +1,44 @@
+package page
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Page 由任务面板排版合成的一页漫画，由 (TaskID, Number) 唯一确定，合成后的图片保存为 page 类型的媒体
+type Page struct {
+	ID        string           `json:"id"`
+	TaskID    string           `json:"task_id"`
+	Number    int              `json:"number"` // 页码，从 1 开始
+	MediaID   string           `json:"media_id"`
+	Template  string           `json:"template"`
+	Direction ReadingDirection `json:"reading_direction"`
+	Panels    []Placement      `json:"panels"` // 按阅读顺序排列
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// Placement 面板在页面中的位置
+type Placement struct {
+	PanelNumber int    `json:"panel_number"` // 任务面板序号
+	MediaID     string `json:"media_id"`     // 排版时使用的面板版本
+	X           int    `json:"x"`
+	Y           int    `json:"y"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Balloons    int    `json:"balloons,omitempty"` // 嵌入的气泡和旁白框数量
+}
+
+// NewPage 创建页面
+func NewPage(taskID string, number int, mediaID string, layout Layout, panels []Placement) *Page {
+	return &Page{
+		ID:        uuid.New().String(),
+		TaskID:    taskID,
+		Number:    number,
+		MediaID:   mediaID,
+		Template:  layout.Template.Name,
+		Direction: layout.Direction,
+		Panels:    panels,
+		CreatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,207 @@
+package page
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ReadingDirection 页面阅读方向，决定面板在行内的先后顺序
+type ReadingDirection string
+
+const (
+	ReadingDirectionRTL ReadingDirection = "rtl" // 从右到左（日式漫画）
+	ReadingDirectionLTR ReadingDirection = "ltr" // 从左到右
+)
+
+// 内置模板名称
+const (
+	TemplateGrid  = "grid"  // Columns × Rows 等分网格
+	TemplateWide  = "wide"  // 每页三个通栏横幅面板
+	TemplateTall  = "tall"  // 一个纵贯整页的竖长面板加两个上下排列的面板
+	TemplateMixed = "mixed" // 通栏、两个并排、通栏
+)
+
+var (
+	// ErrInvalidLayout 页面版式参数无效
+	ErrInvalidLayout = errors.New("invalid page layout")
+	// ErrNoPanels 没有可排版的面板
+	ErrNoPanels = errors.New("no panels to compose")
+)
+
+const (
+	// MaxPageSize 页面的最大边长（像素），限制合成时的内存占用
+	MaxPageSize = 4096
+	// minCellSize 网格单元的最小边长（像素），页边距和间距过大时拒绝排版
+	minCellSize = 64
+)
+
+// Cell 模板网格中的一个面板格，以网格单元为坐标
+type Cell struct {
+	Col     int
+	Row     int
+	ColSpan int
+	RowSpan int
+}
+
+// Template 页面模板：Cols × Rows 网格上的面板格，按从左到右阅读时的顺序排列，
+// 从右到左阅读时水平镜像
+type Template struct {
+	Name  string
+	Cols  int
+	Rows  int
+	Cells []Cell
+}
+
+// GridTemplate cols × rows 的等分网格
+func GridTemplate(cols, rows int) Template {
+	template := Template{Name: TemplateGrid, Cols: cols, Rows: rows}
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			template.Cells = append(template.Cells, Cell{Col: col, Row: row, ColSpan: 1, RowSpan: 1})
+		}
+	}
+	return template
+}
+
+// LookupTemplate 按名称获取模板，cols 和 rows 只用于 grid 模板
+func LookupTemplate(name string, cols, rows int) (Template, error) {
+	switch name {
+	case TemplateGrid:
+		return GridTemplate(cols, rows), nil
+	case TemplateWide:
+		template := GridTemplate(1, 3)
+		template.Name = TemplateWide
+		return template, nil
+	case TemplateTall:
+		return Template{Name: TemplateTall, Cols: 2, Rows: 2, Cells: []Cell{
+			{Col: 0, Row: 0, ColSpan: 1, RowSpan: 2},
+			{Col: 1, Row: 0, ColSpan: 1, RowSpan: 1},
+			{Col: 1, Row: 1, ColSpan: 1, RowSpan: 1},
+		}}, nil
+	case TemplateMixed:
+		return Template{Name: TemplateMixed, Cols: 2, Rows: 3, Cells: []Cell{
+			{Col: 0, Row: 0, ColSpan: 2, RowSpan: 1},
+			{Col: 0, Row: 1, ColSpan: 1, RowSpan: 1},
+			{Col: 1, Row: 1, ColSpan: 1, RowSpan: 1},
+			{Col: 0, Row: 2, ColSpan: 2, RowSpan: 1},
+		}}, nil
+	}
+	return Template{}, fmt.Errorf("%w: unknown template %q", ErrInvalidLayout, name)
+}
+
+// Validate 检查模板的面板格都在网格内
+func (t Template) Validate() error {
+	if t.Cols <= 0 || t.Rows <= 0 || len(t.Cells) == 0 {
+		return fmt.Errorf("%w: template %q has no cells", ErrInvalidLayout, t.Name)
+	}
+	for _, cell := range t.Cells {
+		if cell.Col < 0 || cell.Row < 0 || cell.ColSpan <= 0 || cell.RowSpan <= 0 ||
+			cell.Col+cell.ColSpan > t.Cols || cell.Row+cell.RowSpan > t.Rows {
+			return fmt.Errorf("%w: template %q cell %+v is outside the %dx%d grid", ErrInvalidLayout, t.Name, cell, t.Cols, t.Rows)
+		}
+	}
+	return nil
+}
+
+// Layout 页面版式
+type Layout struct {
+	Width     int // 页面尺寸（像素）
+	Height    int
+	Margin    int // 页边距
+	Gutter    int // 面板间距
+	Border    int // 面板边框宽度
+	Direction ReadingDirection
+	Template  Template
+}
+
+// DefaultLayout 默认版式：2:3 竖版页面，从右到左阅读，每页三个通栏面板（适合 16:9 的场景图片）
+func DefaultLayout() Layout {
+	template, _ := LookupTemplate(TemplateWide, 0, 0)
+	return Layout{
+		Width:     1600,
+		Height:    2400,
+		Margin:    60,
+		Gutter:    24,
+		Border:    4,
+		Direction: ReadingDirectionRTL,
+		Template:  template,
+	}
+}
+
+// Validate 检查版式参数，网格单元过小时返回 ErrInvalidLayout
+func (l Layout) Validate() error {
+	if l.Direction != ReadingDirectionRTL && l.Direction != ReadingDirectionLTR {
+		return fmt.Errorf("%w: unsupported reading direction %q", ErrInvalidLayout, l.Direction)
+	}
+	if l.Width > MaxPageSize || l.Height > MaxPageSize {
+		return fmt.Errorf("%w: page size must not exceed %d pixels", ErrInvalidLayout, MaxPageSize)
+	}
+	if l.Margin < 0 || l.Gutter < 0 || l.Border < 0 {
+		return fmt.Errorf("%w: margin, gutter and border must not be negative", ErrInvalidLayout)
+	}
+	if err := l.Template.Validate(); err != nil {
+		return err
+	}
+
+	cellWidth := (l.Width - 2*l.Margin - (l.Template.Cols-1)*l.Gutter) / l.Template.Cols
+	cellHeight := (l.Height - 2*l.Margin - (l.Template.Rows-1)*l.Gutter) / l.Template.Rows
+	if cellWidth < minCellSize || cellHeight < minCellSize || cellWidth <= 2*l.Border || cellHeight <= 2*l.Border {
+		return fmt.Errorf("%w: %dx%d page leaves %dx%d pixel cells for a %dx%d grid", ErrInvalidLayout,
+			l.Width, l.Height, cellWidth, cellHeight, l.Template.Cols, l.Template.Rows)
+	}
+	return nil
+}
+
+// Slot 面板在页面中的位置（像素），Panel 为面板在输入中的下标
+type Slot struct {
+	Panel  int
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// PanelsPerPage 每页的面板数
+func (l Layout) PanelsPerPage() int {
+	return len(l.Template.Cells)
+}
+
+// Paginate 将 panelCount 个面板按阅读顺序依次排入页面，最后一页只使用模板的前几个面板格
+func (l Layout) Paginate(panelCount int) [][]Slot {
+	perPage := l.PanelsPerPage()
+	if perPage == 0 {
+		return nil
+	}
+
+	var pages [][]Slot
+	for start := 0; start < panelCount; start += perPage {
+		count := min(perPage, panelCount-start)
+		slots := make([]Slot, 0, count)
+		for i, cell := range l.Template.Cells[:count] {
+			slot := l.place(cell)
+			slot.Panel = start + i
+			slots = append(slots, slot)
+		}
+		pages = append(pages, slots)
+	}
+	return pages
+}
+
+// place 计算面板格的像素位置，从右到左阅读时水平镜像
+func (l Layout) place(cell Cell) Slot {
+	if l.Direction == ReadingDirectionRTL {
+		cell.Col = l.Template.Cols - cell.Col - cell.ColSpan
+	}
+
+	x0, x1 := span(l.Width, l.Margin, l.Gutter, l.Template.Cols, cell.Col, cell.ColSpan)
+	y0, y1 := span(l.Height, l.Margin, l.Gutter, l.Template.Rows, cell.Row, cell.RowSpan)
+	return Slot{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0}
+}
+
+// span 返回跨 count 个单元的面板格在一个方向上的起止坐标，单元之间留出 gutter，余数均匀分配到各单元
+func span(size, margin, gutter, units, start, count int) (int, int) {
+	content := size - 2*margin + gutter
+	from := margin + start*content/units
+	to := margin + (start+count)*content/units - gutter
+	return from, to
+}
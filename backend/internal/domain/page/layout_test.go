@@ -0,0 +1,87 @@
+package page
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPaginateFillsPagesInOrder(t *testing.T) {
+	layout := DefaultLayout()
+	layout.Direction = ReadingDirectionLTR
+
+	pages := layout.Paginate(7)
+	if len(pages) != 3 {
+		t.Fatalf("Paginate(7) = %d pages, want 3", len(pages))
+	}
+	if len(pages[2]) != 1 || pages[2][0].Panel != 6 {
+		t.Errorf("last page = %+v, want only panel 6", pages[2])
+	}
+
+	// 1600x2400，页边距 60，间距 24：三行各 744 像素高
+	want := []Slot{
+		{Panel: 0, X: 60, Y: 60, Width: 1480, Height: 744},
+		{Panel: 1, X: 60, Y: 828, Width: 1480, Height: 744},
+		{Panel: 2, X: 60, Y: 1596, Width: 1480, Height: 744},
+	}
+	for i, slot := range pages[0] {
+		if slot != want[i] {
+			t.Errorf("page 1 slot %d = %+v, want %+v", i, slot, want[i])
+		}
+	}
+}
+
+func TestPaginateMirrorsRightToLeft(t *testing.T) {
+	template, err := LookupTemplate(TemplateGrid, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layout := Layout{Width: 1000, Height: 1000, Margin: 50, Gutter: 20, Direction: ReadingDirectionRTL, Template: template}
+
+	slots := layout.Paginate(4)[0]
+	if slots[0].X <= slots[1].X {
+		t.Errorf("first panel x = %d, second x = %d; right-to-left pages start on the right", slots[0].X, slots[1].X)
+	}
+	if slots[1].X != 50 || slots[0].X+slots[0].Width != 950 {
+		t.Errorf("slots = %+v, want panels flush with the 50px margins", slots[:2])
+	}
+	if slots[2].Y <= slots[0].Y {
+		t.Errorf("third panel y = %d, want below the first row", slots[2].Y)
+	}
+}
+
+func TestTallTemplateSpansRows(t *testing.T) {
+	template, _ := LookupTemplate(TemplateTall, 0, 0)
+	layout := DefaultLayout()
+	layout.Template = template
+
+	slots := layout.Paginate(3)[0]
+	if slots[0].Height != slots[1].Height+layout.Gutter+slots[2].Height {
+		t.Errorf("tall panel height = %d, want the two stacked panels plus gutter (%d + %d + %d)",
+			slots[0].Height, slots[1].Height, layout.Gutter, slots[2].Height)
+	}
+	if slots[0].X < slots[1].X {
+		t.Error("right-to-left tall panel should be on the right side")
+	}
+}
+
+func TestLayoutValidate(t *testing.T) {
+	if err := DefaultLayout().Validate(); err != nil {
+		t.Fatalf("DefaultLayout().Validate() = %v", err)
+	}
+
+	cramped := DefaultLayout()
+	cramped.Template = GridTemplate(20, 20)
+	if err := cramped.Validate(); !errors.Is(err, ErrInvalidLayout) {
+		t.Errorf("20x20 grid Validate() = %v, want ErrInvalidLayout", err)
+	}
+
+	if _, err := LookupTemplate("spiral", 0, 0); !errors.Is(err, ErrInvalidLayout) {
+		t.Errorf("LookupTemplate(spiral) = %v, want ErrInvalidLayout", err)
+	}
+
+	direction := DefaultLayout()
+	direction.Direction = "ttb"
+	if err := direction.Validate(); !errors.Is(err, ErrInvalidLayout) {
+		t.Errorf("Validate() with direction ttb = %v, want ErrInvalidLayout", err)
+	}
+}
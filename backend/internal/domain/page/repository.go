@@ -0,0 +1,12 @@
+package page
+
+import "context"
+
+// Repository 漫画页面仓储
+type Repository interface {
+	// ReplaceByTaskID 用新排版的页面替换任务的全部页面
+	ReplaceByTaskID(ctx context.Context, taskID string, pages []*Page) error
+
+	// FindByTaskID 查询任务的页面，按页码升序
+	FindByTaskID(ctx context.Context, taskID string) ([]*Page, error)
+}
@@ -175,14 +175,27 @@ func (s *SceneDividerService) extractLocation(text, marker string) string {
 	return ""
 }
 
+// dialoguePattern 台词匹配规则，speaker 和 content 为子匹配的下标
+type dialoguePattern struct {
+	re      *regexp.Regexp
+	speaker int
+	content int
+	kind    DialogueKind
+}
+
+// dialoguePatterns 按优先级排列，同一句台词只取第一个匹配的规则，
+// 因此“心想”要排在通用的“某某：“……””之前
+var dialoguePatterns = []dialoguePattern{
+	{regexp.MustCompile(`^旁白[:：]\s*[“"]?([^“”"]+)[”"]?$`), 0, 1, DialogueKindNarration},
+	{regexp.MustCompile(`([一-龥]{2,4}?)(?:心想|暗想|想道|想)[:：，,]?[“"]([^“”"]+)[”"]`), 1, 2, DialogueKindThought},
+	{regexp.MustCompile(`([一-龥]{2,4}?)(?:说道?|道|答|问|[大怒]?喊|[大怒]?叫|怒吼|笑|哭)[:：“"]+([^“”"]+)[”"]`), 1, 2, DialogueKindSpeech},
+	{regexp.MustCompile(`[“"]([^“”"]+)[”"][,，。]?([一-龥]{2,4}?)(?:说道?|道|答|问)`), 2, 1, DialogueKindSpeech},
+	{regexp.MustCompile(`([一-龥]{2,4})[:：][“"]([^“”"]+)[”"]`), 1, 2, DialogueKindSpeech},
+}
+
 func (s *SceneDividerService) extractDialogues(content string) []Dialogue {
 	var dialogues []Dialogue
-
-	patterns := []*regexp.Regexp{
-		regexp.MustCompile(`([一-龥]{2,4})(?:说道?|道|答|问|喊|叫|笑|哭)[::""]([^""]+)[""]`),
-		regexp.MustCompile(`"([^""]+)"[,。]?([一-龥]{2,4})(?:说道?|道|答|问)`),
-		regexp.MustCompile(`([一-龥]{2,4})[::]"([^""]+)"`),
-	}
+	seen := make(map[string]bool)
 
 	lines := strings.Split(content, "\n")
 
@@ -192,21 +205,23 @@ func (s *SceneDividerService) extractDialogues(content string) []Dialogue {
 			continue
 		}
 
-		for _, pattern := range patterns {
-			matches := pattern.FindAllStringSubmatch(line, -1)
+		for _, pattern := range dialoguePatterns {
+			matches := pattern.re.FindAllStringSubmatch(line, -1)
 			for _, match := range matches {
-				if len(match) >= 3 {
-					speaker := match[1]
-					dialogueContent := match[2]
-
-					if len(dialogueContent) > 1 {
-						emotion := s.detectEmotion(line)
-						dialogues = append(dialogues, Dialogue{
-							Speaker: speaker,
-							Content: dialogueContent,
-							Emotion: emotion,
-						})
-					}
+				speaker := NarratorSpeaker
+				if pattern.speaker > 0 {
+					speaker = match[pattern.speaker]
+				}
+				dialogueContent := strings.TrimSpace(match[pattern.content])
+
+				if len([]rune(dialogueContent)) > 1 && !seen[dialogueContent] {
+					seen[dialogueContent] = true
+					dialogues = append(dialogues, Dialogue{
+						Speaker: speaker,
+						Content: dialogueContent,
+						Emotion: s.detectEmotion(line),
+						Kind:    pattern.kind,
+					})
 				}
 			}
 		}
@@ -0,0 +1,51 @@
+package scene
+
+import "testing"
+
+func TestSceneDividerService_extractDialogues(t *testing.T) {
+	s := NewSceneDividerService(nil)
+
+	content := "小红帽说道：“今天天气真好。”\n" +
+		"“你要去哪里？”大灰狼问。\n" +
+		"小红帽心想：“这只狼好奇怪。”\n" +
+		"猎人怒喊：“站住！”\n" +
+		"旁白：第二天清晨，森林里起了雾。"
+
+	want := []Dialogue{
+		{Speaker: "小红帽", Content: "今天天气真好。", Kind: DialogueKindSpeech},
+		{Speaker: "大灰狼", Content: "你要去哪里？", Kind: DialogueKindSpeech},
+		{Speaker: "小红帽", Content: "这只狼好奇怪。", Kind: DialogueKindThought},
+		{Speaker: "猎人", Content: "站住！", Kind: DialogueKindSpeech, Emotion: "angry"},
+		{Speaker: NarratorSpeaker, Content: "第二天清晨，森林里起了雾。", Kind: DialogueKindNarration},
+	}
+
+	got := s.extractDialogues(content)
+	if len(got) != len(want) {
+		t.Fatalf("extractDialogues() = %+v, want %d dialogues", got, len(want))
+	}
+	for i, w := range want {
+		if got[i].Speaker != w.Speaker || got[i].Content != w.Content || got[i].Kind != w.Kind {
+			t.Errorf("dialogue %d = %+v, want %+v", i, got[i], w)
+		}
+		if w.Emotion != "" && got[i].Emotion != w.Emotion {
+			t.Errorf("dialogue %d emotion = %q, want %q", i, got[i].Emotion, w.Emotion)
+		}
+	}
+}
+
+func TestDialogue_EffectiveKind(t *testing.T) {
+	tests := []struct {
+		dialogue Dialogue
+		want     DialogueKind
+	}{
+		{Dialogue{Speaker: "小红帽", Kind: DialogueKindThought}, DialogueKindThought},
+		{Dialogue{Speaker: NarratorSpeaker}, DialogueKindNarration},
+		{Dialogue{Speaker: "小红帽"}, DialogueKindSpeech},
+	}
+
+	for _, tt := range tests {
+		if got := tt.dialogue.EffectiveKind(); got != tt.want {
+			t.Errorf("EffectiveKind(%+v) = %v, want %v", tt.dialogue, got, tt.want)
+		}
+	}
+}
@@ -74,10 +74,34 @@ func (d Description) ToPrompt() string {
 	return strings.Join(parts, ". ")
 }
 
+// DialogueKind 台词类型，决定漫画中使用对话气泡、思考气泡还是旁白框
+type DialogueKind string
+
+const (
+	DialogueKindSpeech    DialogueKind = "speech"
+	DialogueKindThought   DialogueKind = "thought"
+	DialogueKindNarration DialogueKind = "narration"
+)
+
+// NarratorSpeaker 旁白台词的说话人
+const NarratorSpeaker = "旁白"
+
 type Dialogue struct {
 	Speaker string
 	Content string
 	Emotion string
+	Kind    DialogueKind
+}
+
+// EffectiveKind 台词类型，未记录类型的旧数据按说话人推断
+func (d Dialogue) EffectiveKind() DialogueKind {
+	if d.Kind != "" {
+		return d.Kind
+	}
+	if d.Speaker == NarratorSpeaker {
+		return DialogueKindNarration
+	}
+	return DialogueKindSpeech
 }
 
 func NewScene(chapterID, novelID string, sceneNumber int) (*Scene, error) {
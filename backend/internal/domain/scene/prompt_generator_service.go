@@ -55,12 +55,38 @@ func NewPromptGeneratorService(sceneRepo SceneRepository) *PromptGeneratorServic
 type PromptStyle string
 
 const (
-	PromptStyleAnime     PromptStyle = "anime"
-	PromptStyleRealistic PromptStyle = "realistic"
-	PromptStyleCartoon   PromptStyle = "cartoon"
-	PromptStylePainting  PromptStyle = "painting"
+	PromptStyleAnime      PromptStyle = "anime"
+	PromptStyleRealistic  PromptStyle = "realistic"
+	PromptStyleCartoon    PromptStyle = "cartoon"
+	PromptStylePainting   PromptStyle = "painting"
+	PromptStyleManga      PromptStyle = "manga"
+	PromptStyleWatercolor PromptStyle = "watercolor"
 )
 
+// promptStyleDescriptions 画风预设对应的 Prompt 描述
+var promptStyleDescriptions = map[PromptStyle]string{
+	PromptStyleAnime:      "anime style",
+	PromptStyleRealistic:  "realistic style",
+	PromptStyleCartoon:    "cartoon style",
+	PromptStylePainting:   "painting style",
+	PromptStyleManga:      "black and white manga style, screentone shading, bold ink lines",
+	PromptStyleWatercolor: "watercolor style, soft washes, textured paper",
+}
+
+// IsValidPromptStyle 判断是否为支持的画风预设
+func IsValidPromptStyle(style string) bool {
+	_, ok := promptStyleDescriptions[PromptStyle(style)]
+	return ok
+}
+
+// Description 画风的 Prompt 描述，未知画风按 "<style> style" 处理
+func (p PromptStyle) Description() string {
+	if description, ok := promptStyleDescriptions[p]; ok {
+		return description
+	}
+	return string(p) + " style"
+}
+
 type PromptOptions struct {
 	Style       PromptStyle
 	Quality     string
@@ -85,7 +111,7 @@ func (s *PromptGeneratorService) GenerateImagePrompt(
 ) (string, error) {
 	var promptParts []string
 
-	promptParts = append(promptParts, string(options.Style)+" style")
+	promptParts = append(promptParts, options.Style.Description())
 
 	if scene.Description.Setting != "" {
 		promptParts = append(promptParts, scene.Description.Setting)
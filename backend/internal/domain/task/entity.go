@@ -1,6 +1,7 @@
 package task
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,6 +17,46 @@ const (
 	TaskStatusCancelled  TaskStatus = "cancelled"
 )
 
+var (
+	// ErrTaskCancelled 任务已被用户取消
+	ErrTaskCancelled = errors.New("task cancelled")
+	// ErrTaskNotRetryable 任务未失败或失败原因不可重试
+	ErrTaskNotRetryable = errors.New("task is not retryable")
+	// ErrRetryLimitReached 重试次数已达上限
+	ErrRetryLimitReached = errors.New("task retry limit reached")
+	// ErrTaskNotCancellable 任务已完成、失败或已取消，不能再取消
+	ErrTaskNotCancellable = errors.New("task is not cancellable")
+	// ErrTaskNotFound 任务不存在或不属于当前用户
+	ErrTaskNotFound = errors.New("task not found")
+	// ErrTaskNotCompleted 任务尚未完成，不能调整面板
+	ErrTaskNotCompleted = errors.New("task is not completed")
+)
+
+// MaxRetryCount 单个任务允许的最大重试次数
+const MaxRetryCount = 5
+
+// 任务错误码，40001-49999 为可重试错误
+const (
+	ErrorCodeAIService           = 40001 // AI 服务调用失败
+	ErrorCodeInterrupted         = 40002 // 服务重启导致任务中断且无法恢复
+	ErrorCodePipeline            = 40003 // 解析、提取或保存数据失败
+	ErrorCodeProviderUnavailable = 40004 // AI 服务连续失败已熔断，冷却后可重试
+	ErrorCodeNovelLoad           = 50001 // 加载小说失败
+	ErrorCodeNoScenes            = 50002 // 小说内容无法划分出场景
+)
+
+// 漫画生成流水线步骤索引
+const (
+	StepParseNovel         = 1 // 解析小说章节
+	StepExtractCharacters  = 2 // 提取角色
+	StepGenerateCharacters = 3 // 生成角色参考图
+	StepDivideScenes       = 4 // 划分场景并匹配角色
+	StepGenerateScenes     = 5 // 生成场景图片
+	StepCompleted          = 6 // 完成
+
+	TotalSteps = StepCompleted
+)
+
 type ProgressDetails struct {
 	CharactersExtracted int `json:"characters_extracted"`
 	CharactersGenerated int `json:"characters_generated"`
@@ -23,22 +64,35 @@ type ProgressDetails struct {
 	ScenesGenerated     int `json:"scenes_generated"`
 }
 
+// Attempt 一次失败的执行记录，任务重试时归档
+type Attempt struct {
+	Attempt         int       `json:"attempt"` // 第几次执行，从 1 开始
+	ErrorCode       int       `json:"error_code"`
+	ErrorMessage    string    `json:"error_message"`
+	ProgressStep    string    `json:"progress_step"`
+	ScenesGenerated int       `json:"scenes_generated"` // 失败时已完成的面板数
+	FailedAt        time.Time `json:"failed_at"`
+}
+
 type Task struct {
-	ID                 string          `json:"id"`
-	UserID             string          `json:"user_id"` // 用户ID，用于数据隔离
-	NovelID            string          `json:"novel_id"`
-	Status             TaskStatus      `json:"status"`
-	ProgressStep       string          `json:"progress_step"`
-	ProgressStepIndex  int             `json:"progress_step_index"`
-	ProgressPercentage int             `json:"progress_percentage"`
-	ProgressDetails    ProgressDetails `json:"progress_details"`
-	ErrorCode          int             `json:"error_code,omitempty"`
-	ErrorMessage       string          `json:"error_message,omitempty"`
-	CreatedAt          time.Time       `json:"created_at"`
-	UpdatedAt          time.Time       `json:"updated_at"`
-	CompletedAt        *time.Time      `json:"completed_at,omitempty"`
-	FailedAt           *time.Time      `json:"failed_at,omitempty"`
-	cancelChan         chan struct{}   `json:"-"` // 取消信号通道
+	ID                 string            `json:"id"`
+	UserID             string            `json:"user_id"` // 用户ID，用于数据隔离
+	NovelID            string            `json:"novel_id"`
+	Status             TaskStatus        `json:"status"`
+	ProgressStep       string            `json:"progress_step"`
+	ProgressStepIndex  int               `json:"progress_step_index"`
+	ProgressPercentage int               `json:"progress_percentage"`
+	ProgressDetails    ProgressDetails   `json:"progress_details"`
+	Options            GenerationOptions `json:"options"`
+	ErrorCode          int               `json:"error_code,omitempty"`
+	ErrorMessage       string            `json:"error_message,omitempty"`
+	RetryCount         int               `json:"retry_count"`
+	Attempts           []Attempt         `json:"attempts,omitempty"` // 历次失败的执行记录
+	CreatedAt          time.Time         `json:"created_at"`
+	UpdatedAt          time.Time         `json:"updated_at"`
+	CompletedAt        *time.Time        `json:"completed_at,omitempty"`
+	FailedAt           *time.Time        `json:"failed_at,omitempty"`
+	events             []Event           // 待发布的进度事件
 }
 
 // NewTask 创建新任务
@@ -54,29 +108,38 @@ func NewTask(userID, novelID string) *Task {
 		ProgressDetails:    ProgressDetails{},
 		CreatedAt:          time.Now(),
 		UpdatedAt:          time.Now(),
-		cancelChan:         make(chan struct{}),
 	}
 }
 
 // UpdateProgress 更新任务进度
 func (t *Task) UpdateProgress(step string, stepIndex int, percentage int, details ProgressDetails) {
+	stepChanged := t.Status != TaskStatusProcessing || stepIndex != t.ProgressStepIndex
+	progressChanged := step != t.ProgressStep || percentage != t.ProgressPercentage
+
 	t.Status = TaskStatusProcessing
 	t.ProgressStep = step
 	t.ProgressStepIndex = stepIndex
 	t.ProgressPercentage = percentage
 	t.ProgressDetails = details
 	t.UpdatedAt = time.Now()
+
+	if stepChanged {
+		t.recordEvent(EventTypeStepChanged)
+	} else if progressChanged {
+		t.recordEvent(EventTypeProgress)
+	}
 }
 
 // MarkCompleted 标记任务完成
 func (t *Task) MarkCompleted() {
 	t.Status = TaskStatusCompleted
 	t.ProgressStep = "完成"
-	t.ProgressStepIndex = 6
+	t.ProgressStepIndex = StepCompleted
 	t.ProgressPercentage = 100
 	now := time.Now()
 	t.CompletedAt = &now
 	t.UpdatedAt = now
+	t.recordEvent(EventTypeCompleted)
 }
 
 // MarkFailed 标记任务失败
@@ -87,35 +150,61 @@ func (t *Task) MarkFailed(errCode int, errMsg string) {
 	now := time.Now()
 	t.FailedAt = &now
 	t.UpdatedAt = now
+	t.recordEvent(EventTypeFailed)
+}
+
+// Requeue 将中断的任务重新置为待处理，保留已有进度以便从断点继续
+func (t *Task) Requeue() {
+	t.Status = TaskStatusPending
+	t.ProgressStep = "等待恢复"
+	t.UpdatedAt = time.Now()
+	t.recordEvent(EventTypeRequeued)
+}
+
+// Retry 归档本次失败记录并重新置为待处理，保留进度以便只重新生成缺失的面板
+func (t *Task) Retry() error {
+	if !t.IsRetryable() {
+		return ErrTaskNotRetryable
+	}
+	if t.RetryCount >= MaxRetryCount {
+		return ErrRetryLimitReached
+	}
+
+	failedAt := t.UpdatedAt
+	if t.FailedAt != nil {
+		failedAt = *t.FailedAt
+	}
+	t.Attempts = append(t.Attempts, Attempt{
+		Attempt:         t.RetryCount + 1,
+		ErrorCode:       t.ErrorCode,
+		ErrorMessage:    t.ErrorMessage,
+		ProgressStep:    t.ProgressStep,
+		ScenesGenerated: t.ProgressDetails.ScenesGenerated,
+		FailedAt:        failedAt,
+	})
+	t.RetryCount++
+
+	t.Status = TaskStatusPending
+	t.ProgressStep = "等待重试"
+	t.ErrorCode = 0
+	t.ErrorMessage = ""
+	t.FailedAt = nil
+	t.UpdatedAt = time.Now()
+	t.recordEvent(EventTypeRequeued)
+	return nil
 }
 
 // Cancel 取消任务
+// 取消状态持久化到数据库后，执行中的 worker（可能在其他实例上）通过轮询状态感知取消
 func (t *Task) Cancel() {
 	t.Status = TaskStatusCancelled
 	t.UpdatedAt = time.Now()
-	if t.cancelChan != nil {
-		close(t.cancelChan)
-	}
+	t.recordEvent(EventTypeCancelled)
 }
 
 // IsCancelled 检查任务是否已取消
 func (t *Task) IsCancelled() bool {
-	if t.cancelChan == nil {
-		return false
-	}
-	select {
-	case <-t.cancelChan:
-		return true
-	default:
-		return false
-	}
-}
-
-// InitCancelChan 初始化取消通道（用于从数据库加载后）
-func (t *Task) InitCancelChan() {
-	if t.cancelChan == nil {
-		t.cancelChan = make(chan struct{})
-	}
+	return t.Status == TaskStatusCancelled
 }
 
 // IsRetryable 判断任务失败后是否可以重试
@@ -124,5 +213,10 @@ func (t *Task) IsRetryable() bool {
 		return false
 	}
 	// 40001 是 AI 服务调用失败，通常可以重试
-	return t.ErrorCode >= 40001 && t.ErrorCode < 50000
+	return t.ErrorCode >= ErrorCodeAIService && t.ErrorCode < 50000
+}
+
+// IsCompleted 检查任务是否已完成
+func (t *Task) IsCompleted() bool {
+	return t.Status == TaskStatusCompleted
 }
@@ -0,0 +1,128 @@
+package task
+
+import "testing"
+
+func TestTaskRequeue(t *testing.T) {
+	task := NewTask("user-1", "novel-1")
+	details := ProgressDetails{ScenesGenerated: 4}
+	task.UpdateProgress("生成漫画图片 5/10", 1, 50, details)
+
+	task.Requeue()
+
+	if task.Status != TaskStatusPending {
+		t.Errorf("Status = %v, want %v", task.Status, TaskStatusPending)
+	}
+	if task.ProgressDetails.ScenesGenerated != 4 {
+		t.Errorf("ScenesGenerated = %d, want 4 (progress must be kept)", task.ProgressDetails.ScenesGenerated)
+	}
+	if task.ProgressPercentage != 50 {
+		t.Errorf("ProgressPercentage = %d, want 50", task.ProgressPercentage)
+	}
+}
+
+func TestTaskIsRetryable(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    int
+		failed  bool
+		wantRet bool
+	}{
+		{"ai service error", ErrorCodeAIService, true, true},
+		{"interrupted", ErrorCodeInterrupted, true, true},
+		{"provider unavailable", ErrorCodeProviderUnavailable, true, true},
+		{"novel load error", ErrorCodeNovelLoad, true, false},
+		{"not failed", ErrorCodeAIService, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := NewTask("user-1", "novel-1")
+			if tt.failed {
+				task.MarkFailed(tt.code, "error")
+			}
+			if got := task.IsRetryable(); got != tt.wantRet {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.wantRet)
+			}
+		})
+	}
+}
+
+func TestTaskCancel(t *testing.T) {
+	task := NewTask("user-1", "novel-1")
+	if task.IsCancelled() {
+		t.Fatal("new task should not be cancelled")
+	}
+
+	task.Cancel()
+
+	if !task.IsCancelled() {
+		t.Error("IsCancelled() = false after Cancel()")
+	}
+
+	// 从数据库加载的任务同样能通过状态识别取消
+	loaded := &Task{Status: TaskStatusCancelled}
+	if !loaded.IsCancelled() {
+		t.Error("IsCancelled() = false for task loaded with cancelled status")
+	}
+}
+
+func TestTaskRetry(t *testing.T) {
+	task := NewTask("user-1", "novel-1")
+	task.UpdateProgress("生成漫画图片 4/10", 1, 40, ProgressDetails{ScenesGenerated: 3})
+	task.MarkFailed(ErrorCodeAIService, "rate limited")
+
+	if err := task.Retry(); err != nil {
+		t.Fatalf("Retry() error = %v", err)
+	}
+
+	if task.Status != TaskStatusPending {
+		t.Errorf("Status = %v, want %v", task.Status, TaskStatusPending)
+	}
+	if task.RetryCount != 1 {
+		t.Errorf("RetryCount = %d, want 1", task.RetryCount)
+	}
+	if task.ErrorCode != 0 || task.ErrorMessage != "" || task.FailedAt != nil {
+		t.Error("error fields should be cleared after retry")
+	}
+	if task.ProgressDetails.ScenesGenerated != 3 {
+		t.Errorf("ScenesGenerated = %d, want 3 (progress must be kept)", task.ProgressDetails.ScenesGenerated)
+	}
+	if len(task.Attempts) != 1 {
+		t.Fatalf("len(Attempts) = %d, want 1", len(task.Attempts))
+	}
+	if got := task.Attempts[0]; got.Attempt != 1 || got.ErrorCode != ErrorCodeAIService || got.ScenesGenerated != 3 {
+		t.Errorf("unexpected attempt record %+v", got)
+	}
+
+	// 未失败的任务不能重试
+	if err := task.Retry(); err != ErrTaskNotRetryable {
+		t.Errorf("Retry() on pending task error = %v, want %v", err, ErrTaskNotRetryable)
+	}
+}
+
+func TestTaskRetryLimit(t *testing.T) {
+	task := NewTask("user-1", "novel-1")
+	for i := 0; i < MaxRetryCount; i++ {
+		task.MarkFailed(ErrorCodeAIService, "error")
+		if err := task.Retry(); err != nil {
+			t.Fatalf("Retry() #%d error = %v", i+1, err)
+		}
+	}
+
+	task.MarkFailed(ErrorCodeAIService, "error")
+	if err := task.Retry(); err != ErrRetryLimitReached {
+		t.Errorf("Retry() error = %v, want %v", err, ErrRetryLimitReached)
+	}
+	if len(task.Attempts) != MaxRetryCount {
+		t.Errorf("len(Attempts) = %d, want %d", len(task.Attempts), MaxRetryCount)
+	}
+}
+
+func TestTaskMarkCompletedReportsFinalStep(t *testing.T) {
+	task := NewTask("user-1", "novel-1")
+	task.MarkCompleted()
+
+	if task.ProgressStepIndex != TotalSteps {
+		t.Errorf("ProgressStepIndex = %d, want %d", task.ProgressStepIndex, TotalSteps)
+	}
+}
@@ -0,0 +1,73 @@
+package task
+
+import "time"
+
+type EventType string
+
+const (
+	EventTypeStepChanged    EventType = "step_changed"
+	EventTypeProgress       EventType = "progress"
+	EventTypePanelCompleted EventType = "panel_completed"
+	EventTypeFailed         EventType = "failed"
+	EventTypeCompleted      EventType = "completed"
+	EventTypeCancelled      EventType = "cancelled"
+	EventTypeRequeued       EventType = "requeued" // 重试或中断恢复后重新排队
+)
+
+// Event 任务进度事件，由实体状态变更时记录，保存任务后持久化并推送给订阅者
+type Event struct {
+	ID           int64     `json:"id"` // 持久化后分配的自增序号，用作 SSE 的事件ID
+	TaskID       string    `json:"task_id"`
+	Type         EventType `json:"type"`
+	Status       string    `json:"status"`
+	Step         string    `json:"step,omitempty"`
+	StepIndex    int       `json:"step_index"`
+	Percentage   int       `json:"percentage"`
+	PanelNumber  int       `json:"panel_number,omitempty"`
+	MediaID      string    `json:"media_id,omitempty"`
+	ImageURL     string    `json:"image_url,omitempty"`
+	ErrorCode    int       `json:"error_code,omitempty"`
+	ErrorMessage string    `json:"error_message,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// IsTerminal 事件是否表示任务已结束
+func (e *Event) IsTerminal() bool {
+	switch e.Type {
+	case EventTypeCompleted, EventTypeFailed, EventTypeCancelled:
+		return true
+	}
+	return false
+}
+
+func (t *Task) recordEvent(eventType EventType) *Event {
+	event := Event{
+		TaskID:       t.ID,
+		Type:         eventType,
+		Status:       string(t.Status),
+		Step:         t.ProgressStep,
+		StepIndex:    t.ProgressStepIndex,
+		Percentage:   t.ProgressPercentage,
+		ErrorCode:    t.ErrorCode,
+		ErrorMessage: t.ErrorMessage,
+		CreatedAt:    t.UpdatedAt,
+	}
+	t.events = append(t.events, event)
+	return &t.events[len(t.events)-1]
+}
+
+// RecordPanelCompleted 记录单个漫画面板生成完成
+func (t *Task) RecordPanelCompleted(panelNumber int, mediaID, imageURL string) {
+	t.UpdatedAt = time.Now()
+	event := t.recordEvent(EventTypePanelCompleted)
+	event.PanelNumber = panelNumber
+	event.MediaID = mediaID
+	event.ImageURL = imageURL
+}
+
+// PullEvents 取出尚未发布的事件并清空
+func (t *Task) PullEvents() []Event {
+	events := t.events
+	t.events = nil
+	return events
+}
@@ -0,0 +1,59 @@
+package task
+
+import "testing"
+
+func TestTaskRecordsProgressEvents(t *testing.T) {
+	task := NewTask("user-1", "novel-1")
+
+	task.UpdateProgress("生成漫画图片 1/10", 1, 10, ProgressDetails{})
+	task.UpdateProgress("生成漫画图片 2/10", 1, 20, ProgressDetails{ScenesGenerated: 1})
+	task.UpdateProgress("生成漫画图片 2/10", 1, 20, ProgressDetails{ScenesGenerated: 1})
+	task.RecordPanelCompleted(2, "media-2", "https://example.com/2.png")
+	task.MarkCompleted()
+
+	events := task.PullEvents()
+	want := []EventType{EventTypeStepChanged, EventTypeProgress, EventTypePanelCompleted, EventTypeCompleted}
+	if len(events) != len(want) {
+		t.Fatalf("len(events) = %d, want %d", len(events), len(want))
+	}
+	for i, eventType := range want {
+		if events[i].Type != eventType {
+			t.Errorf("events[%d].Type = %s, want %s", i, events[i].Type, eventType)
+		}
+		if events[i].TaskID != task.ID {
+			t.Errorf("events[%d].TaskID = %s, want %s", i, events[i].TaskID, task.ID)
+		}
+	}
+
+	panel := events[2]
+	if panel.PanelNumber != 2 || panel.MediaID != "media-2" || panel.ImageURL != "https://example.com/2.png" {
+		t.Errorf("unexpected panel event %+v", panel)
+	}
+	if !events[3].IsTerminal() || events[1].IsTerminal() {
+		t.Error("only the completed event should be terminal")
+	}
+
+	if len(task.PullEvents()) != 0 {
+		t.Error("PullEvents() should clear pending events")
+	}
+}
+
+func TestTaskFailedEventCarriesError(t *testing.T) {
+	task := NewTask("user-1", "novel-1")
+	task.MarkFailed(ErrorCodeAIService, "rate limited")
+
+	events := task.PullEvents()
+	if len(events) != 1 {
+		t.Fatalf("len(events) = %d, want 1", len(events))
+	}
+	if events[0].Type != EventTypeFailed || events[0].ErrorCode != ErrorCodeAIService || events[0].ErrorMessage != "rate limited" {
+		t.Errorf("unexpected failed event %+v", events[0])
+	}
+
+	if err := task.Retry(); err != nil {
+		t.Fatalf("Retry() error = %v", err)
+	}
+	if events := task.PullEvents(); len(events) != 1 || events[0].Type != EventTypeRequeued {
+		t.Errorf("Retry() should record a requeued event, got %+v", events)
+	}
+}
@@ -0,0 +1,33 @@
+package task
+
+import "errors"
+
+// ErrInvalidOptions 生成参数不合法或当前图像服务不支持
+var ErrInvalidOptions = errors.New("invalid generation options")
+
+// MaxPanelCount 单个任务允许生成的最大面板数
+const MaxPanelCount = 100
+
+// GenerationOptions 创建任务时指定的生成参数，随任务持久化供后台执行使用
+type GenerationOptions struct {
+	PanelCount      int    `json:"panel_count,omitempty"` // 0 表示每个场景生成一个面板
+	AspectRatio     string `json:"aspect_ratio,omitempty"`
+	Width           int    `json:"width,omitempty"` // 根据宽高比和图像服务能力解析出的面板尺寸
+	Height          int    `json:"height,omitempty"`
+	ArtStyle        string `json:"art_style,omitempty"`
+	NegativePrompt  string `json:"negative_prompt,omitempty"`
+	ForceRegenerate bool   `json:"force_regenerate,omitempty"` // 不复用参数相同的已生成图片
+}
+
+// WithDefaults 补全未指定的参数（兼容未保存生成参数的历史任务）
+func (o GenerationOptions) WithDefaults() GenerationOptions {
+	if o.Width == 0 || o.Height == 0 {
+		o.AspectRatio = "16:9"
+		o.Width = 1344
+		o.Height = 768
+	}
+	if o.ArtStyle == "" {
+		o.ArtStyle = "anime"
+	}
+	return o
+}
@@ -0,0 +1,96 @@
+package task
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrPanelNotFound 面板不存在或不属于该任务
+	ErrPanelNotFound = errors.New("panel not found")
+	// ErrPanelVersionNotFound 图片不是该面板的版本
+	ErrPanelVersionNotFound = errors.New("panel version not found")
+)
+
+// Panel 任务结果中的一个面板位置，由 (TaskID, SequenceNum) 唯一确定。
+// 每次生成或重新生成的图片作为一个版本追加到 Versions，ActiveMediaID 指向结果中使用的版本，
+// 面板顺序只由 SequenceNum 决定，重新生成不会改变面板在漫画中的位置
+type Panel struct {
+	ID            string         `json:"id"`
+	TaskID        string         `json:"task_id"`
+	SequenceNum   int            `json:"sequence_num"` // 面板序号，从 1 开始，与进度事件的 panel_number 一致
+	SceneID       string         `json:"scene_id"`
+	ActiveMediaID string         `json:"active_media_id"`
+	Versions      []PanelVersion `json:"versions"` // 按生成顺序排列的全部版本
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// PanelVersion 面板的一个图片版本
+type PanelVersion struct {
+	Version   int       `json:"version"` // 版本号，从 1 开始
+	MediaID   string    `json:"media_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewPanel 创建空面板，生成图片后通过 AddVersion 加入第一个版本
+func NewPanel(taskID string, sequenceNum int, sceneID string) *Panel {
+	now := time.Now()
+	return &Panel{
+		ID:          uuid.New().String(),
+		TaskID:      taskID,
+		SequenceNum: sequenceNum,
+		SceneID:     sceneID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// AddVersion 追加新生成的图片并设为当前版本，返回版本号；图片已是该面板的版本时只切换为当前版本
+func (p *Panel) AddVersion(mediaID string) int {
+	if v, ok := p.Version(mediaID); ok {
+		p.activate(mediaID)
+		return v.Version
+	}
+
+	now := time.Now()
+	version := PanelVersion{
+		Version:   len(p.Versions) + 1,
+		MediaID:   mediaID,
+		CreatedAt: now,
+	}
+	p.Versions = append(p.Versions, version)
+	p.activate(mediaID)
+	return version.Version
+}
+
+// Activate 选定结果中使用的版本
+func (p *Panel) Activate(mediaID string) error {
+	if _, ok := p.Version(mediaID); !ok {
+		return ErrPanelVersionNotFound
+	}
+	p.activate(mediaID)
+	return nil
+}
+
+func (p *Panel) activate(mediaID string) {
+	p.ActiveMediaID = mediaID
+	p.UpdatedAt = time.Now()
+}
+
+// Version 查找图片对应的版本
+func (p *Panel) Version(mediaID string) (PanelVersion, bool) {
+	for _, v := range p.Versions {
+		if v.MediaID == mediaID {
+			return v, true
+		}
+	}
+	return PanelVersion{}, false
+}
+
+// ActiveVersion 当前版本，面板还没有图片时返回 false
+func (p *Panel) ActiveVersion() (PanelVersion, bool) {
+	return p.Version(p.ActiveMediaID)
+}
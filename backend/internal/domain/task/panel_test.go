@@ -0,0 +1,40 @@
+package task
+
+import "testing"
+
+func TestPanelVersions(t *testing.T) {
+	panel := NewPanel("task-1", 3, "scene-1")
+	if _, ok := panel.ActiveVersion(); ok {
+		t.Fatal("ActiveVersion() on empty panel ok = true, want false")
+	}
+
+	if v := panel.AddVersion("media-1"); v != 1 {
+		t.Errorf("AddVersion(media-1) = %d, want 1", v)
+	}
+	if v := panel.AddVersion("media-2"); v != 2 {
+		t.Errorf("AddVersion(media-2) = %d, want 2", v)
+	}
+	if panel.ActiveMediaID != "media-2" {
+		t.Errorf("ActiveMediaID = %q, want media-2", panel.ActiveMediaID)
+	}
+
+	if err := panel.Activate("media-1"); err != nil {
+		t.Fatalf("Activate(media-1) error = %v", err)
+	}
+	active, ok := panel.ActiveVersion()
+	if !ok || active.Version != 1 {
+		t.Errorf("ActiveVersion() = %+v, %v, want version 1", active, ok)
+	}
+
+	if err := panel.Activate("media-9"); err != ErrPanelVersionNotFound {
+		t.Errorf("Activate(media-9) error = %v, want ErrPanelVersionNotFound", err)
+	}
+
+	// 重复加入同一张图片不产生新版本
+	if v := panel.AddVersion("media-2"); v != 2 || len(panel.Versions) != 2 {
+		t.Errorf("AddVersion(media-2) again = %d with %d versions, want 2 with 2", v, len(panel.Versions))
+	}
+	if panel.SequenceNum != 3 || panel.ActiveMediaID != "media-2" {
+		t.Errorf("panel = seq %d active %q, want seq 3 active media-2", panel.SequenceNum, panel.ActiveMediaID)
+	}
+}
@@ -1,21 +1,74 @@
 package task
 
-import "context"
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrLeaseLost 任务租约已过期或被其他 worker 持有
+var ErrLeaseLost = errors.New("task lease lost")
 
 // Repository Task 仓储接口
 type Repository interface {
 	// Save 保存任务（创建或更新）
 	Save(ctx context.Context, task *Task) error
 
+	// SaveUnlessCancelled 保存任务进度，任务在数据库中已被取消时不写入并返回 false
+	SaveUnlessCancelled(ctx context.Context, task *Task) (bool, error)
+
+	// CancelIfActive 保存已取消的任务，仅在数据库中状态仍为待处理或处理中时写入，否则返回 false
+	CancelIfActive(ctx context.Context, task *Task) (bool, error)
+
 	// FindByID 根据ID查找任务
 	FindByID(ctx context.Context, taskID string) (*Task, error)
 
+	// FindStatus 只查询任务状态（后台轮询取消标记使用，不依赖用户身份）
+	FindStatus(ctx context.Context, taskID string) (TaskStatus, error)
+
 	// FindByIDAndUserID 根据ID和用户ID查找任务（用于权限验证）
 	FindByIDAndUserID(ctx context.Context, taskID, userID string) (*Task, error)
 
+	// ExistsByNovelIDAndUserID 用户是否有该小说的任务（用于小说下媒体等资源的权限验证）
+	ExistsByNovelIDAndUserID(ctx context.Context, novelID, userID string) (bool, error)
+
 	// FindByUserID 查询用户的所有任务（分页）
 	FindByUserID(ctx context.Context, userID string, page, pageSize int, status string) ([]*Task, int, error)
 
 	// Delete 删除任务
 	Delete(ctx context.Context, taskID string) error
+
+	// FindClaimable 查询可被 worker 领取的任务（待处理或处理中且租约已过期）
+	FindClaimable(ctx context.Context, limit int) ([]*Task, error)
+
+	// FindInterrupted 查询处理中但没有有效租约的任务（执行进程已退出）。
+	// 未开始的 pending 任务由任务队列的 FindClaimable 领取，不在此返回
+	FindInterrupted(ctx context.Context, limit int) ([]*Task, error)
+
+	// AcquireLease 尝试获取任务租约，租约未过期时返回 false
+	AcquireLease(ctx context.Context, taskID, owner string, until time.Time) (bool, error)
+
+	// RenewLease 续约（心跳），租约不再属于 owner 时返回 ErrLeaseLost
+	RenewLease(ctx context.Context, taskID, owner string, until time.Time) error
+
+	// ReleaseLease 释放任务租约
+	ReleaseLease(ctx context.Context, taskID, owner string) error
+}
+
+// EventRepository 任务进度事件仓储，事件按自增ID排序，支持断线后从指定ID继续读取
+type EventRepository interface {
+	// Append 持久化事件并回填事件ID
+	Append(ctx context.Context, events []Event) error
+
+	// FindAfter 查询任务中ID大于 afterID 的事件，按ID升序
+	FindAfter(ctx context.Context, taskID string, afterID int64, limit int) ([]Event, error)
+}
+
+// PanelRepository 任务面板仓储
+type PanelRepository interface {
+	// Save 保存面板（按 task_id + sequence_num 创建或更新）
+	Save(ctx context.Context, panel *Panel) error
+
+	// FindByTaskID 查询任务的全部面板，按面板序号升序
+	FindByTaskID(ctx context.Context, taskID string) ([]*Panel, error)
 }
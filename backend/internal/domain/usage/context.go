@@ -0,0 +1,22 @@
+package usage
+
+import "context"
+
+type contextKey struct{}
+
+// Caller 发起 AI 调用的用户和任务，随 ctx 传递到记账处
+type Caller struct {
+	UserID string
+	TaskID string
+}
+
+// WithCaller 在 ctx 中记录调用方
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, contextKey{}, caller)
+}
+
+// CallerFrom 读取 ctx 中的调用方，未记录时返回零值
+func CallerFrom(ctx context.Context) Caller {
+	caller, _ := ctx.Value(contextKey{}).(Caller)
+	return caller
+}
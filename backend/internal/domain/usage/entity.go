@@ -0,0 +1,188 @@
+package usage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrQuotaExceeded = errors.New("usage quota exceeded")
+
+// Operation AI 调用类型
+type Operation string
+
+const (
+	OperationTextToImage  Operation = "text_to_image"
+	OperationImageToImage Operation = "image_to_image"
+	OperationTextToVideo  Operation = "text_to_video"
+	OperationImageToVideo Operation = "image_to_video"
+	OperationTextToSpeech Operation = "text_to_speech"
+)
+
+// IsVideo 视频生成按时长计费
+func (o Operation) IsVideo() bool {
+	return o == OperationTextToVideo || o == OperationImageToVideo
+}
+
+// IsSpeech 台词配音按句计费，不计入图片和视频的生成次数
+func (o Operation) IsSpeech() bool {
+	return o == OperationTextToSpeech
+}
+
+// Entry 用量账本记录，每次 AI 生成调用（无论成功与否）记录一条
+type Entry struct {
+	ID            int64
+	UserID        string // 发起调用的用户，后台批量生成等无法确定用户时为空
+	TaskID        string
+	Provider      string
+	Operation     Operation
+	Width         int
+	Height        int
+	Duration      float64       // 请求生成的视频时长（秒），图片为 0
+	Latency       time.Duration // 调用耗时
+	Success       bool
+	EstimatedCost float64 // 按价格表估算的费用（美元），失败的调用不计费
+	CreatedAt     time.Time
+}
+
+// Totals 一段时间内的用量汇总
+type Totals struct {
+	Calls         int
+	FailedCalls   int
+	Images        int
+	Videos        int
+	VideoSeconds  float64
+	SpeechLines   int
+	EstimatedCost float64
+}
+
+// Generations 成功的生成次数
+func (t Totals) Generations() int {
+	return t.Images + t.Videos
+}
+
+// Add 累加一条记录
+func (t *Totals) Add(entry *Entry) {
+	t.Calls++
+	if !entry.Success {
+		t.FailedCalls++
+		return
+	}
+	switch {
+	case entry.Operation.IsVideo():
+		t.Videos++
+		t.VideoSeconds += entry.Duration
+	case entry.Operation.IsSpeech():
+		t.SpeechLines++
+	default:
+		t.Images++
+	}
+	t.EstimatedCost += entry.EstimatedCost
+}
+
+// DailyTotals 某一天（UTC）的用量
+type DailyTotals struct {
+	Date string // 2006-01-02
+	Totals
+}
+
+// Summary 用户在当天和当月的用量
+type Summary struct {
+	Today Totals
+	Month Totals
+	Daily []DailyTotals // 当月每天的用量，按日期升序，没有调用的日期不列出
+}
+
+// MonthStart 用量按 UTC 自然月统计，返回 now 所在月份的第一天
+func MonthStart(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Summarize 汇总当月的记录，entries 应为 MonthStart(now) 之后的记录
+func Summarize(entries []*Entry, now time.Time) Summary {
+	today := now.UTC().Format(time.DateOnly)
+	monthStart := MonthStart(now)
+
+	var summary Summary
+	for _, entry := range entries {
+		if entry.CreatedAt.Before(monthStart) {
+			continue
+		}
+		summary.Month.Add(entry)
+
+		date := entry.CreatedAt.UTC().Format(time.DateOnly)
+		if date == today {
+			summary.Today.Add(entry)
+		}
+		if n := len(summary.Daily); n == 0 || summary.Daily[n-1].Date != date {
+			summary.Daily = append(summary.Daily, DailyTotals{Date: date})
+		}
+		summary.Daily[len(summary.Daily)-1].Add(entry)
+	}
+	return summary
+}
+
+// Pricing 各服务的估算单价（美元），未配置的服务按 0 计算
+type Pricing struct {
+	ImagePrices  map[string]float64 // 每张图片
+	VideoPrices  map[string]float64 // 每秒视频
+	SpeechPrices map[string]float64 // 每句配音
+}
+
+// defaultVideoSeconds 未指定时长的视频按 Sora 默认的 4 秒估算
+const defaultVideoSeconds = 4
+
+// Estimate 估算一次成功调用的费用
+func (p Pricing) Estimate(provider string, operation Operation, duration float64) float64 {
+	if operation.IsSpeech() {
+		return p.SpeechPrices[provider]
+	}
+	if !operation.IsVideo() {
+		return p.ImagePrices[provider]
+	}
+	if duration <= 0 {
+		duration = defaultVideoSeconds
+	}
+	return p.VideoPrices[provider] * duration
+}
+
+// Quota 每个用户的用量上限，0 表示不限制
+type Quota struct {
+	DailyGenerations int     // 每天成功生成的图片和视频数
+	MonthlyCost      float64 // 每月估算费用（美元）
+}
+
+// QuotaError 超出配额时返回的错误，errors.Is(err, ErrQuotaExceeded) 为 true
+type QuotaError struct {
+	Limit   string // daily_generations 或 monthly_cost
+	Used    float64
+	Allowed float64
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s: %s used %g of %g", ErrQuotaExceeded, e.Limit, e.Used, e.Allowed)
+}
+
+func (e *QuotaError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// Check 判断用量是否已达到配额
+func (q Quota) Check(summary Summary) error {
+	if q.DailyGenerations > 0 && summary.Today.Generations() >= q.DailyGenerations {
+		return &QuotaError{
+			Limit:   "daily_generations",
+			Used:    float64(summary.Today.Generations()),
+			Allowed: float64(q.DailyGenerations),
+		}
+	}
+	if q.MonthlyCost > 0 && summary.Month.EstimatedCost >= q.MonthlyCost {
+		return &QuotaError{
+			Limit:   "monthly_cost",
+			Used:    summary.Month.EstimatedCost,
+			Allowed: q.MonthlyCost,
+		}
+	}
+	return nil
+}
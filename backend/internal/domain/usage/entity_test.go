@@ -0,0 +1,85 @@
+package usage
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSummarizeGroupsByDayAndMonth(t *testing.T) {
+	now := time.Date(2024, 3, 15, 18, 0, 0, 0, time.UTC)
+	entries := []*Entry{
+		{Operation: OperationTextToImage, Success: true, EstimatedCost: 0.04, CreatedAt: time.Date(2024, 2, 29, 23, 0, 0, 0, time.UTC)},
+		{Operation: OperationTextToImage, Success: true, EstimatedCost: 0.04, CreatedAt: time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)},
+		{Operation: OperationImageToVideo, Success: true, Duration: 5, EstimatedCost: 0.5, CreatedAt: time.Date(2024, 3, 15, 9, 0, 0, 0, time.UTC)},
+		{Operation: OperationImageToImage, Success: false, CreatedAt: time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)},
+		{Operation: OperationImageToImage, Success: true, EstimatedCost: 0.04, CreatedAt: time.Date(2024, 3, 15, 11, 0, 0, 0, time.UTC)},
+		{Operation: OperationTextToSpeech, Success: true, CreatedAt: time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)},
+	}
+
+	summary := Summarize(entries, now)
+
+	if summary.Month.Calls != 5 || summary.Month.Images != 2 || summary.Month.Videos != 1 || summary.Month.SpeechLines != 1 {
+		t.Errorf("Month = %+v, want 5 calls (2 images, 1 video, 1 speech line) excluding February", summary.Month)
+	}
+	if got := summary.Month.Generations(); got != 3 {
+		t.Errorf("Month.Generations() = %d, want 3 without speech lines", got)
+	}
+	if summary.Today.Calls != 4 || summary.Today.FailedCalls != 1 || summary.Today.VideoSeconds != 5 {
+		t.Errorf("Today = %+v, want 4 calls with 1 failure and 5 video seconds", summary.Today)
+	}
+	if got := summary.Today.EstimatedCost; got < 0.539 || got > 0.541 {
+		t.Errorf("Today.EstimatedCost = %v, want 0.54", got)
+	}
+	if len(summary.Daily) != 2 || summary.Daily[0].Date != "2024-03-01" || summary.Daily[1].Date != "2024-03-15" {
+		t.Errorf("Daily = %+v, want 2024-03-01 and 2024-03-15", summary.Daily)
+	}
+}
+
+func TestPricingEstimate(t *testing.T) {
+	pricing := Pricing{
+		ImagePrices:  map[string]float64{"gemini": 0.04},
+		VideoPrices:  map[string]float64{"sora": 0.1},
+		SpeechPrices: map[string]float64{"mock": 0.002},
+	}
+
+	if got := pricing.Estimate("gemini", OperationTextToImage, 0); got != 0.04 {
+		t.Errorf("image estimate = %v, want 0.04", got)
+	}
+	if got := pricing.Estimate("sora", OperationImageToVideo, 8); got < 0.799 || got > 0.801 {
+		t.Errorf("8s video estimate = %v, want 0.8", got)
+	}
+	if got := pricing.Estimate("sora", OperationTextToVideo, 0); got < 0.399 || got > 0.401 {
+		t.Errorf("default duration estimate = %v, want 4s at 0.1", got)
+	}
+	if got := pricing.Estimate("mock", OperationTextToSpeech, 0); got != 0.002 {
+		t.Errorf("speech estimate = %v, want 0.002 per line", got)
+	}
+	if got := pricing.Estimate("mock", OperationTextToImage, 0); got != 0 {
+		t.Errorf("unpriced provider estimate = %v, want 0", got)
+	}
+}
+
+func TestQuotaCheck(t *testing.T) {
+	summary := Summary{
+		Today: Totals{Images: 9, Videos: 1, EstimatedCost: 1},
+		Month: Totals{Images: 40, EstimatedCost: 19.5},
+	}
+
+	if err := (Quota{}).Check(summary); err != nil {
+		t.Errorf("unlimited quota Check() = %v, want nil", err)
+	}
+
+	err := Quota{DailyGenerations: 10}.Check(summary)
+	var quotaErr *QuotaError
+	if !errors.Is(err, ErrQuotaExceeded) || !errors.As(err, &quotaErr) || quotaErr.Limit != "daily_generations" {
+		t.Errorf("daily limit Check() = %v, want daily_generations quota error", err)
+	}
+
+	if err := (Quota{DailyGenerations: 11, MonthlyCost: 20}).Check(summary); err != nil {
+		t.Errorf("Check() under limits = %v, want nil", err)
+	}
+	if err := (Quota{MonthlyCost: 19.5}).Check(summary); !errors.As(err, &quotaErr) || quotaErr.Limit != "monthly_cost" {
+		t.Errorf("monthly limit Check() = %v, want monthly_cost quota error", err)
+	}
+}
@@ -0,0 +1,15 @@
+package usage
+
+import (
+	"context"
+	"time"
+)
+
+// Repository 用量账本仓储，记录只追加不修改
+type Repository interface {
+	// Append 写入一条记录并回填ID
+	Append(ctx context.Context, entry *Entry) error
+
+	// FindByUserSince 查询用户在 since 之后的记录，按创建时间升序
+	FindByUserSince(ctx context.Context, userID string, since time.Time) ([]*Entry, error)
+}
@@ -0,0 +1,86 @@
+package voiceover
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// LeadIn 场景开始到第一句台词的留白（秒），避开淡入转场
+	LeadIn = 0.5
+	// LineGap 相邻两句台词之间的停顿（秒）
+	LineGap = 0.4
+)
+
+var (
+	// ErrNoDialogues 任务的场景中没有可配音的台词
+	ErrNoDialogues = errors.New("no dialogues to voice")
+)
+
+// Track 一个场景的配音：按台词顺序首尾相接，时间相对于场景开始。由 (TaskID, SceneID) 唯一确定
+type Track struct {
+	ID        string    `json:"id"`
+	TaskID    string    `json:"task_id"`
+	SceneID   string    `json:"scene_id"`
+	Lines     []Line    `json:"lines"`
+	Duration  float64   `json:"duration"` // 最后一句台词结束的时间（秒）
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Line 一句台词的配音，音频保存为 audio 类型的媒体
+type Line struct {
+	Index       int     `json:"index"` // 台词在场景中的序号，从 0 开始
+	Speaker     string  `json:"speaker"`
+	CharacterID string  `json:"character_id,omitempty"` // 匹配到的角色，旁白和未知说话人为空
+	Text        string  `json:"text"`
+	Voice       string  `json:"voice,omitempty"` // 合成使用的音色
+	MediaID     string  `json:"media_id"`
+	Start       float64 `json:"start"`    // 相对场景开始的时间（秒）
+	Duration    float64 `json:"duration"` // 音频时长（秒）
+}
+
+// End 台词结束的时间（秒）
+func (l Line) End() float64 {
+	return l.Start + l.Duration
+}
+
+// NewTrack 创建场景的空配音轨
+func NewTrack(taskID, sceneID string) *Track {
+	return &Track{
+		ID:        uuid.New().String(),
+		TaskID:    taskID,
+		SceneID:   sceneID,
+		Lines:     make([]Line, 0),
+		CreatedAt: time.Now(),
+	}
+}
+
+// AddLine 将台词排在上一句之后：第一句从 LeadIn 开始，之后每句间隔 LineGap
+func (t *Track) AddLine(line Line) {
+	line.Start = LeadIn
+	if len(t.Lines) > 0 {
+		line.Start = t.Lines[len(t.Lines)-1].End() + LineGap
+	}
+	line.Start = roundMillis(line.Start)
+	line.Duration = roundMillis(line.Duration)
+	t.Lines = append(t.Lines, line)
+	t.Duration = roundMillis(line.End())
+}
+
+// LineAt 按台词序号查找配音
+func (t *Track) LineAt(index int) (Line, bool) {
+	for _, line := range t.Lines {
+		if line.Index == index {
+			return line, true
+		}
+	}
+	return Line{}, false
+}
+
+// roundMillis 时间精确到毫秒
+func roundMillis(seconds float64) float64 {
+	return math.Round(seconds*1000) / 1000
+}
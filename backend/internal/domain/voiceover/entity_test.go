@@ -0,0 +1,31 @@
+package voiceover
+
+import "testing"
+
+func TestTrackAddLineSchedulesLinesInOrder(t *testing.T) {
+	track := NewTrack("task-1", "scene-1")
+
+	track.AddLine(Line{Index: 0, Speaker: "旁白", Text: "夜色渐深。", MediaID: "a1", Duration: 1.2})
+	track.AddLine(Line{Index: 2, Speaker: "林", Text: "你来了。", MediaID: "a2", Duration: 0.8004})
+
+	if len(track.Lines) != 2 {
+		t.Fatalf("len(Lines) = %d, want 2", len(track.Lines))
+	}
+	if first := track.Lines[0]; first.Start != LeadIn || first.End() != 1.7 {
+		t.Errorf("Lines[0] = %+v, want start %v end 1.7", first, LeadIn)
+	}
+	// 1.7 + 0.4 的停顿
+	if second := track.Lines[1]; second.Start != 2.1 || second.Duration != 0.8 {
+		t.Errorf("Lines[1] = %+v, want start 2.1 duration 0.8", second)
+	}
+	if track.Duration != 2.9 {
+		t.Errorf("Duration = %v, want 2.9", track.Duration)
+	}
+
+	if line, ok := track.LineAt(2); !ok || line.MediaID != "a2" {
+		t.Errorf("LineAt(2) = %+v, %v", line, ok)
+	}
+	if _, ok := track.LineAt(1); ok {
+		t.Error("LineAt(1) found a line that was never voiced")
+	}
+}
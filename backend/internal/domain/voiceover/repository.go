@@ -0,0 +1,12 @@
+package voiceover
+
+import "context"
+
+// Repository 配音轨仓储
+type Repository interface {
+	// ReplaceByTaskID 用新合成的配音替换任务的全部配音轨
+	ReplaceByTaskID(ctx context.Context, taskID string, tracks []*Track) error
+
+	// FindByTaskID 查询任务的配音轨
+	FindByTaskID(ctx context.Context, taskID string) ([]*Track, error)
+}
@@ -0,0 +1,328 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("ai provider circuit open")
+
+// CircuitState 熔断器状态
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"    // 正常调用
+	CircuitOpen     CircuitState = "open"      // 连续失败后拒绝调用，冷却结束前直接返回 ErrCircuitOpen
+	CircuitHalfOpen CircuitState = "half_open" // 冷却结束，只放行一次探测调用
+)
+
+// BreakerConfig 熔断器配置
+type BreakerConfig struct {
+	FailureThreshold int           // 连续失败多少次后熔断，<= 0 时使用默认值
+	Cooldown         time.Duration // 熔断后等待多久放行探测调用，<= 0 时使用默认值
+}
+
+// DefaultBreakerConfig 默认熔断配置
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		FailureThreshold: 5,
+		Cooldown:         30 * time.Second,
+	}
+}
+
+// CircuitOpenError 熔断期间拒绝调用时返回的错误，errors.Is(err, ErrCircuitOpen) 为 true
+type CircuitOpenError struct {
+	Provider string
+	RetryAt  time.Time // 预计放行探测调用的时间
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s: %s unavailable until %s", ErrCircuitOpen, e.Provider, e.RetryAt.Format(time.RFC3339))
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// ProviderHealth 服务的熔断状态快照
+type ProviderHealth struct {
+	Provider            string       `json:"provider"`
+	State               CircuitState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	TotalFailures       int64        `json:"total_failures"`
+	LastError           string       `json:"last_error,omitempty"`
+	LastFailureAt       *time.Time   `json:"last_failure_at,omitempty"`
+	OpenedAt            *time.Time   `json:"opened_at,omitempty"`
+	RetryAt             *time.Time   `json:"retry_at,omitempty"`
+}
+
+// ProviderState 服务的熔断状态，不含错误详情，用于公开的健康检查
+type ProviderState struct {
+	Provider string       `json:"provider"`
+	State    CircuitState `json:"state"`
+}
+
+// CircuitBreaker 单个 AI 服务的熔断器。
+// 只有 IsRetryable 判定为服务故障的错误（5xx、限流、超时、网络错误）计入失败；
+// 内容拦截、参数错误等说明服务仍能正常响应，视为成功；调用方 ctx 取消不计入
+type CircuitBreaker struct {
+	name string
+	cfg  BreakerConfig
+	now  func() time.Time
+
+	mu            sync.Mutex
+	state         CircuitState
+	failures      int
+	totalFailures int64
+	lastErr       string
+	lastFailureAt time.Time
+	openedAt      time.Time
+	probing       bool // 半开状态下已有探测调用在进行
+}
+
+func NewCircuitBreaker(name string, cfg BreakerConfig) *CircuitBreaker {
+	defaults := DefaultBreakerConfig()
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = defaults.FailureThreshold
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = defaults.Cooldown
+	}
+
+	return &CircuitBreaker{
+		name:  name,
+		cfg:   cfg,
+		now:   time.Now,
+		state: CircuitClosed,
+	}
+}
+
+func (b *CircuitBreaker) Name() string {
+	return b.name
+}
+
+// allow 判断是否放行本次调用，放行后必须调用 done 报告结果
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.currentState() {
+	case CircuitOpen:
+		return &CircuitOpenError{Provider: b.name, RetryAt: b.openedAt.Add(b.cfg.Cooldown)}
+	case CircuitHalfOpen:
+		if b.probing {
+			// 探测结果出来前其他调用继续快速失败，下次可尝试的时间以冷却时长估计
+			return &CircuitOpenError{Provider: b.name, RetryAt: b.now().Add(b.cfg.Cooldown)}
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+	}
+	return nil
+}
+
+// done 记录调用结果
+func (b *CircuitBreaker) done(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	wasProbe := b.probing
+	b.probing = false
+
+	switch {
+	case err == nil || !IsRetryable(err):
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+		b.state = CircuitClosed
+		b.failures = 0
+	case ctx.Err() != nil:
+		// 调用方取消或超时，不能说明服务状态
+		return
+	default:
+		b.failures++
+		b.totalFailures++
+		b.lastErr = err.Error()
+		b.lastFailureAt = b.now()
+		if wasProbe || b.failures >= b.cfg.FailureThreshold {
+			b.state = CircuitOpen
+			b.openedAt = b.lastFailureAt
+		}
+	}
+}
+
+// currentState 熔断冷却结束后视为半开，调用方需持有锁
+func (b *CircuitBreaker) currentState() CircuitState {
+	if b.state == CircuitOpen && !b.now().Before(b.openedAt.Add(b.cfg.Cooldown)) {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// Health 返回当前状态快照
+func (b *CircuitBreaker) Health() ProviderHealth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	health := ProviderHealth{
+		Provider:            b.name,
+		State:               b.currentState(),
+		ConsecutiveFailures: b.failures,
+		TotalFailures:       b.totalFailures,
+		LastError:           b.lastErr,
+	}
+	if !b.lastFailureAt.IsZero() {
+		lastFailureAt := b.lastFailureAt
+		health.LastFailureAt = &lastFailureAt
+	}
+	if health.State != CircuitClosed {
+		openedAt := b.openedAt
+		retryAt := b.openedAt.Add(b.cfg.Cooldown)
+		health.OpenedAt = &openedAt
+		health.RetryAt = &retryAt
+	}
+	return health
+}
+
+// Breakers 按服务名称管理熔断器，同一服务的图像和视频调用共用一个熔断器
+type Breakers struct {
+	cfg      BreakerConfig
+	mu       sync.Mutex
+	breakers map[string]*CircuitBreaker
+}
+
+func NewBreakers(cfg BreakerConfig) *Breakers {
+	return &Breakers{
+		cfg:      cfg,
+		breakers: make(map[string]*CircuitBreaker),
+	}
+}
+
+// For 获取服务的熔断器，不存在时创建
+func (s *Breakers) For(name string) *CircuitBreaker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	breaker, ok := s.breakers[name]
+	if !ok {
+		breaker = NewCircuitBreaker(name, s.cfg)
+		s.breakers[name] = breaker
+	}
+	return breaker
+}
+
+// Health 返回所有服务的状态，按名称排序
+func (s *Breakers) Health() []ProviderHealth {
+	s.mu.Lock()
+	breakers := make([]*CircuitBreaker, 0, len(s.breakers))
+	for _, breaker := range s.breakers {
+		breakers = append(breakers, breaker)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(breakers, func(i, j int) bool { return breakers[i].name < breakers[j].name })
+
+	health := make([]ProviderHealth, 0, len(breakers))
+	for _, breaker := range breakers {
+		health = append(health, breaker.Health())
+	}
+	return health
+}
+
+// States 返回所有服务的熔断状态，按名称排序
+func (s *Breakers) States() []ProviderState {
+	health := s.Health()
+	states := make([]ProviderState, 0, len(health))
+	for _, h := range health {
+		states = append(states, ProviderState{Provider: h.Provider, State: h.State})
+	}
+	return states
+}
+
+// Healthy 所有服务均未熔断时返回 true
+func (s *Breakers) Healthy() bool {
+	for _, health := range s.Health() {
+		if health.State != CircuitClosed {
+			return false
+		}
+	}
+	return true
+}
+
+type breakerImageGenerator struct {
+	ImageGenerator
+	breaker *CircuitBreaker
+}
+
+// BreakImageGenerator 为图像生成服务加上熔断，熔断期间直接返回 ErrCircuitOpen
+func BreakImageGenerator(generator ImageGenerator, breaker *CircuitBreaker) ImageGenerator {
+	return &breakerImageGenerator{ImageGenerator: generator, breaker: breaker}
+}
+
+func (g *breakerImageGenerator) TextToImage(ctx context.Context, req TextToImageRequest) (*ImageResult, error) {
+	if err := g.breaker.allow(); err != nil {
+		return nil, err
+	}
+	result, err := g.ImageGenerator.TextToImage(ctx, req)
+	g.breaker.done(ctx, err)
+	return result, err
+}
+
+func (g *breakerImageGenerator) ImageToImage(ctx context.Context, req ImageToImageRequest) (*ImageResult, error) {
+	if err := g.breaker.allow(); err != nil {
+		return nil, err
+	}
+	result, err := g.ImageGenerator.ImageToImage(ctx, req)
+	g.breaker.done(ctx, err)
+	return result, err
+}
+
+type breakerVideoGenerator struct {
+	VideoGenerator
+	breaker *CircuitBreaker
+}
+
+// BreakVideoGenerator 为视频生成服务加上熔断，提交、状态查询和下载都受熔断保护
+func BreakVideoGenerator(generator VideoGenerator, breaker *CircuitBreaker) VideoGenerator {
+	return &breakerVideoGenerator{VideoGenerator: generator, breaker: breaker}
+}
+
+func (g *breakerVideoGenerator) ImageToVideo(ctx context.Context, req ImageToVideoRequest) (string, error) {
+	if err := g.breaker.allow(); err != nil {
+		return "", err
+	}
+	videoID, err := g.VideoGenerator.ImageToVideo(ctx, req)
+	g.breaker.done(ctx, err)
+	return videoID, err
+}
+
+func (g *breakerVideoGenerator) TextToVideo(ctx context.Context, req TextToVideoRequest) (string, error) {
+	if err := g.breaker.allow(); err != nil {
+		return "", err
+	}
+	videoID, err := g.VideoGenerator.TextToVideo(ctx, req)
+	g.breaker.done(ctx, err)
+	return videoID, err
+}
+
+func (g *breakerVideoGenerator) GetVideoStatus(ctx context.Context, videoID string) (*VideoGenerationResponse, error) {
+	if err := g.breaker.allow(); err != nil {
+		return nil, err
+	}
+	status, err := g.VideoGenerator.GetVideoStatus(ctx, videoID)
+	g.breaker.done(ctx, err)
+	return status, err
+}
+
+func (g *breakerVideoGenerator) DownloadVideo(ctx context.Context, videoID string) (io.ReadCloser, error) {
+	if err := g.breaker.allow(); err != nil {
+		return nil, err
+	}
+	content, err := g.VideoGenerator.DownloadVideo(ctx, videoID)
+	g.breaker.done(ctx, err)
+	return content, err
+}
@@ -0,0 +1,150 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+// stubImageGenerator 按顺序返回预设错误的图像服务
+type stubImageGenerator struct {
+	errs  []error
+	calls int
+}
+
+func (g *stubImageGenerator) Name() string { return "stub" }
+
+func (g *stubImageGenerator) TextToImage(ctx context.Context, req TextToImageRequest) (*ImageResult, error) {
+	g.calls++
+	if len(g.errs) == 0 {
+		return &ImageResult{URL: "https://example.com/a.png"}, nil
+	}
+	err := g.errs[0]
+	g.errs = g.errs[1:]
+	if err != nil {
+		return nil, err
+	}
+	return &ImageResult{URL: "https://example.com/a.png"}, nil
+}
+
+func (g *stubImageGenerator) ImageToImage(ctx context.Context, req ImageToImageRequest) (*ImageResult, error) {
+	return g.TextToImage(ctx, TextToImageRequest{})
+}
+
+func (g *stubImageGenerator) Capabilities() Capabilities { return Capabilities{} }
+
+func newTestBreaker(threshold int, cooldown time.Duration) (*CircuitBreaker, *time.Time) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	breaker := NewCircuitBreaker("stub", BreakerConfig{FailureThreshold: threshold, Cooldown: cooldown})
+	breaker.now = func() time.Time { return now }
+	return breaker, &now
+}
+
+var errServerDown = &APIError{Provider: "stub", StatusCode: http.StatusBadGateway, kind: ErrProviderError}
+
+func TestCircuitBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	breaker, _ := newTestBreaker(3, time.Minute)
+	stub := &stubImageGenerator{errs: []error{errServerDown, errServerDown, errServerDown}}
+	generator := BreakImageGenerator(stub, breaker)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := generator.TextToImage(ctx, TextToImageRequest{}); !errors.Is(err, ErrProviderError) {
+			t.Fatalf("call %d error = %v, want provider error", i+1, err)
+		}
+	}
+
+	_, err := generator.TextToImage(ctx, TextToImageRequest{})
+	if !errors.Is(err, ErrCircuitOpen) || !IsRetryable(err) {
+		t.Errorf("error after threshold = %v, want retryable ErrCircuitOpen", err)
+	}
+	if stub.calls != 3 {
+		t.Errorf("provider calls = %d, want open circuit to skip the provider", stub.calls)
+	}
+
+	health := breaker.Health()
+	if health.State != CircuitOpen || health.ConsecutiveFailures != 3 || health.RetryAt == nil {
+		t.Errorf("Health() = %+v, want open with 3 failures and retry time", health)
+	}
+}
+
+func TestCircuitBreakerHalfOpenProbe(t *testing.T) {
+	breaker, now := newTestBreaker(1, time.Minute)
+	stub := &stubImageGenerator{errs: []error{errServerDown, errServerDown}}
+	generator := BreakImageGenerator(stub, breaker)
+	ctx := context.Background()
+
+	generator.TextToImage(ctx, TextToImageRequest{})
+	*now = now.Add(time.Minute)
+	if state := breaker.Health().State; state != CircuitHalfOpen {
+		t.Fatalf("state after cooldown = %s, want %s", state, CircuitHalfOpen)
+	}
+
+	// 探测失败后重新熔断
+	generator.TextToImage(ctx, TextToImageRequest{})
+	if state := breaker.Health().State; state != CircuitOpen {
+		t.Fatalf("state after failed probe = %s, want %s", state, CircuitOpen)
+	}
+
+	// 探测成功后恢复
+	*now = now.Add(time.Minute)
+	if _, err := generator.TextToImage(ctx, TextToImageRequest{}); err != nil {
+		t.Fatalf("probe error = %v", err)
+	}
+	if health := breaker.Health(); health.State != CircuitClosed || health.ConsecutiveFailures != 0 {
+		t.Errorf("Health() after successful probe = %+v, want closed", health)
+	}
+}
+
+func TestCircuitBreakerAllowsSingleProbe(t *testing.T) {
+	breaker, now := newTestBreaker(1, time.Minute)
+	breaker.allow()
+	breaker.done(context.Background(), errServerDown)
+	*now = now.Add(time.Minute)
+
+	if err := breaker.allow(); err != nil {
+		t.Fatalf("first probe rejected: %v", err)
+	}
+	if err := breaker.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("second concurrent call error = %v, want ErrCircuitOpen while probing", err)
+	}
+}
+
+func TestCircuitBreakerIgnoresTerminalErrors(t *testing.T) {
+	breaker, _ := newTestBreaker(1, time.Minute)
+	filtered := &APIError{Provider: "stub", StatusCode: http.StatusBadRequest, kind: ErrContentFiltered}
+	generator := BreakImageGenerator(&stubImageGenerator{errs: []error{filtered, context.Canceled}}, breaker)
+
+	generator.TextToImage(context.Background(), TextToImageRequest{})
+	generator.TextToImage(context.Background(), TextToImageRequest{})
+
+	if health := breaker.Health(); health.State != CircuitClosed || health.ConsecutiveFailures != 0 {
+		t.Errorf("Health() = %+v, want closed: content filtering and cancellation are not outages", health)
+	}
+}
+
+func TestBreakersSharePerProvider(t *testing.T) {
+	breakers := NewBreakers(BreakerConfig{FailureThreshold: 1})
+	if breakers.For("gemini") != breakers.For("gemini") {
+		t.Error("For() returned different breakers for the same provider")
+	}
+	breakers.For("sora")
+
+	breakers.For("gemini").done(context.Background(), errServerDown)
+
+	health := breakers.Health()
+	if len(health) != 2 || health[0].Provider != "gemini" || health[1].Provider != "sora" {
+		t.Fatalf("Health() = %+v, want gemini and sora sorted by name", health)
+	}
+	if breakers.Healthy() {
+		t.Error("Healthy() = true with an open circuit")
+	}
+
+	want := []ProviderState{{Provider: "gemini", State: CircuitOpen}, {Provider: "sora", State: CircuitClosed}}
+	if states := breakers.States(); !slices.Equal(states, want) {
+		t.Errorf("States() = %+v, want %+v", states, want)
+	}
+}
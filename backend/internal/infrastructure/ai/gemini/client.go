@@ -6,26 +6,32 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"time"
+
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai"
 )
 
+const ProviderName = "gemini"
+
+// imageModel 图片生成使用的模型
+const imageModel = "gemini-2.5-flash-image"
+
 var (
 	ErrAPIKeyRequired  = errors.New("gemini api key is required")
 	ErrBaseURLRequired = errors.New("gemini base url is required")
 	ErrInvalidResponse = errors.New("invalid response from gemini api")
-	ErrRateLimited     = errors.New("rate limited by gemini api")
-	ErrContentFiltered = errors.New("content filtered by gemini api")
+	ErrRateLimited     = ai.ErrRateLimited
+	ErrContentFiltered = ai.ErrContentFiltered
 )
 
 type Client struct {
 	apiKey     string
 	baseURL    string
-	httpClient *http.Client
+	httpClient *ai.HTTPClient
 }
 
-func NewClient(baseURL, apiKey string) (*Client, error) {
+// NewClient 创建 Gemini 图片生成客户端，retry 为请求失败时的重试策略
+func NewClient(baseURL, apiKey string, retry ai.RetryPolicy) (*Client, error) {
 	if apiKey == "" {
 		return nil, ErrAPIKeyRequired
 	}
@@ -34,31 +40,15 @@ func NewClient(baseURL, apiKey string) (*Client, error) {
 	}
 
 	return &Client{
-		apiKey:  apiKey,
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 60 * time.Second,
-		},
+		apiKey:     apiKey,
+		baseURL:    baseURL,
+		httpClient: ai.NewHTTPClient(ProviderName, retry),
 	}, nil
 }
 
-type TextToImageRequest struct {
-	Prompt         string
-	NegativePrompt string
-	Width          int
-	Height         int
-	Quality        string
-	Style          string
-}
+type TextToImageRequest = ai.TextToImageRequest
 
-type ImageToImageRequest struct {
-	ReferenceImage string
-	Prompt         string
-	NegativePrompt string
-	Strength       float64
-	Width          int
-	Height         int
-}
+type ImageToImageRequest = ai.ImageToImageRequest
 
 type OpenAIImageResponse struct {
 	Created int64 `json:"created"`
@@ -69,12 +59,47 @@ type OpenAIImageResponse struct {
 	} `json:"data"`
 }
 
-func (c *Client) TextToImage(ctx context.Context, req TextToImageRequest) (string, error) {
-	// 使用固定尺寸 1344x768
-	size := "1344x768"
+var _ ai.ImageGenerator = (*Client)(nil)
+
+func (c *Client) Name() string {
+	return ProviderName
+}
+
+// supportedSizes imageModel 支持的输出尺寸（按宽高比）
+var supportedSizes = []ai.Size{
+	{Width: 1024, Height: 1024}, // 1:1
+	{Width: 1344, Height: 768},  // 16:9
+	{Width: 768, Height: 1344},  // 9:16
+	{Width: 1184, Height: 864},  // 4:3
+	{Width: 864, Height: 1184},  // 3:4
+	{Width: 1248, Height: 832},  // 3:2
+	{Width: 832, Height: 1248},  // 2:3
+	{Width: 1152, Height: 896},  // 5:4
+	{Width: 896, Height: 1152},  // 4:5
+	{Width: 1536, Height: 672},  // 21:9
+}
+
+// defaultSize 未指定尺寸时使用的默认尺寸
+var defaultSize = ai.Size{Width: 1344, Height: 768}
+
+func (c *Client) Capabilities() ai.Capabilities {
+	return ai.Capabilities{
+		Model:                  imageModel,
+		SupportedSizes:         supportedSizes,
+		SupportsReferenceImage: true,
+		SupportsNegativePrompt: false,
+		SupportsSeed:           false, // 兼容 OpenAI 的图片接口没有 seed 参数
+	}
+}
+
+func (c *Client) TextToImage(ctx context.Context, req TextToImageRequest) (*ai.ImageResult, error) {
+	size, err := c.resolveSize(req.Width, req.Height)
+	if err != nil {
+		return nil, err
+	}
 
 	payload := map[string]interface{}{
-		"model":  "gemini-2.5-flash-image",
+		"model":  imageModel,
 		"prompt": req.Prompt,
 		"n":      1,
 		"size":   size,
@@ -82,17 +107,20 @@ func (c *Client) TextToImage(ctx context.Context, req TextToImageRequest) (strin
 
 	result, err := c.makeRequest(ctx, "images/generations", payload)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return c.extractImageURL(result)
+	return c.extractImage(result)
 }
 
-func (c *Client) ImageToImage(ctx context.Context, req ImageToImageRequest) (string, error) {
-	size := "1344x768"
+func (c *Client) ImageToImage(ctx context.Context, req ImageToImageRequest) (*ai.ImageResult, error) {
+	size, err := c.resolveSize(req.Width, req.Height)
+	if err != nil {
+		return nil, err
+	}
 
 	payload := map[string]interface{}{
-		"model":  "gemini-2.5-flash-image",
+		"model":  imageModel,
 		"prompt": req.Prompt,
 		"image":  req.ReferenceImage,
 		"n":      1,
@@ -105,10 +133,21 @@ func (c *Client) ImageToImage(ctx context.Context, req ImageToImageRequest) (str
 
 	result, err := c.makeRequest(ctx, "images/generations", payload)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return c.extractImageURL(result)
+	return c.extractImage(result)
+}
+
+// resolveSize 校验请求尺寸，未指定时使用默认尺寸
+func (c *Client) resolveSize(width, height int) (string, error) {
+	if width == 0 && height == 0 {
+		return defaultSize.String(), nil
+	}
+	if !c.Capabilities().SupportsSize(width, height) {
+		return "", fmt.Errorf("%w: %dx%d", ai.ErrSizeUnsupported, width, height)
+	}
+	return ai.Size{Width: width, Height: height}.String(), nil
 }
 
 func (c *Client) makeRequest(ctx context.Context, endpoint string, payload interface{}) (map[string]interface{}, error) {
@@ -129,19 +168,10 @@ func (c *Client) makeRequest(ctx context.Context, endpoint string, payload inter
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusTooManyRequests {
-		return nil, ErrRateLimited
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(body))
-	}
-
 	var result map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
@@ -150,21 +180,23 @@ func (c *Client) makeRequest(ctx context.Context, endpoint string, payload inter
 	return result, nil
 }
 
-func (c *Client) extractImageURL(result map[string]interface{}) (string, error) {
+// extractImage 读取第一张图片的 URL（或 base64 数据）和服务改写后的提示词
+func (c *Client) extractImage(result map[string]interface{}) (*ai.ImageResult, error) {
 	data, ok := result["data"].([]interface{})
 	if !ok || len(data) == 0 {
-		return "", ErrInvalidResponse
+		return nil, ErrInvalidResponse
 	}
 
 	firstImage := data[0].(map[string]interface{})
+	revisedPrompt, _ := firstImage["revised_prompt"].(string)
 
 	if url, ok := firstImage["url"].(string); ok && url != "" {
-		return url, nil
+		return &ai.ImageResult{URL: url, RevisedPrompt: revisedPrompt}, nil
 	}
 
 	if b64JSON, ok := firstImage["b64_json"].(string); ok && b64JSON != "" {
-		return b64JSON, nil
+		return &ai.ImageResult{URL: b64JSON, RevisedPrompt: revisedPrompt}, nil
 	}
 
-	return "", ErrInvalidResponse
+	return nil, ErrInvalidResponse
 }
@@ -0,0 +1,148 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrProviderNotFound     = errors.New("image provider not found")
+	ErrNoDefaultProvider    = errors.New("no default image provider configured")
+	ErrReferenceUnsupported = errors.New("provider does not support reference images")
+	ErrSizeUnsupported      = errors.New("provider does not support requested size")
+	ErrInvalidAspectRatio   = errors.New("invalid aspect ratio")
+)
+
+type TextToImageRequest struct {
+	Prompt         string
+	NegativePrompt string
+	Width          int
+	Height         int
+	Quality        string
+	Style          string
+	Seed           int64 // 随机种子，0 表示由服务随机选择；不支持种子的服务忽略
+}
+
+type ImageToImageRequest struct {
+	ReferenceImage string
+	Prompt         string
+	NegativePrompt string
+	Strength       float64
+	Width          int
+	Height         int
+	Seed           int64
+}
+
+// Size 图片尺寸
+type Size struct {
+	Width  int
+	Height int
+}
+
+func (s Size) String() string {
+	return fmt.Sprintf("%dx%d", s.Width, s.Height)
+}
+
+// Capabilities 图像生成服务的能力描述
+type Capabilities struct {
+	Model                  string // 生成使用的模型，同一服务切换模型后生成结果不同
+	SupportedSizes         []Size
+	SupportsReferenceImage bool
+	SupportsNegativePrompt bool
+	SupportsSeed           bool // 是否支持指定随机种子，不支持时请求中的 Seed 被忽略
+}
+
+// SupportsSize 判断是否支持指定尺寸，SupportedSizes 为空表示不限制
+func (c Capabilities) SupportsSize(width, height int) bool {
+	if len(c.SupportedSizes) == 0 {
+		return true
+	}
+	for _, size := range c.SupportedSizes {
+		if size.Width == width && size.Height == height {
+			return true
+		}
+	}
+	return false
+}
+
+// aspectRatioTolerance 支持的尺寸与请求宽高比的最大相对误差
+const aspectRatioTolerance = 0.03
+
+// standardSizes 不限制尺寸的服务使用的常用宽高比尺寸
+var standardSizes = map[string]Size{
+	"1:1":  {Width: 1024, Height: 1024},
+	"16:9": {Width: 1344, Height: 768},
+	"9:16": {Width: 768, Height: 1344},
+	"4:3":  {Width: 1184, Height: 864},
+	"3:4":  {Width: 864, Height: 1184},
+	"3:2":  {Width: 1248, Height: 832},
+	"2:3":  {Width: 832, Height: 1248},
+}
+
+// ParseAspectRatio 解析 "16:9" 形式的宽高比
+func ParseAspectRatio(ratio string) (float64, error) {
+	w, h, ok := strings.Cut(strings.TrimSpace(ratio), ":")
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAspectRatio, ratio)
+	}
+	width, err1 := strconv.Atoi(w)
+	height, err2 := strconv.Atoi(h)
+	if err1 != nil || err2 != nil || width <= 0 || height <= 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAspectRatio, ratio)
+	}
+	return float64(width) / float64(height), nil
+}
+
+// SizeForAspectRatio 按宽高比选择服务支持的尺寸：有尺寸限制时在支持的尺寸中选择宽高比最接近的，
+// 否则使用常用尺寸或按长边 1344 计算
+func (c Capabilities) SizeForAspectRatio(ratio string) (Size, error) {
+	value, err := ParseAspectRatio(ratio)
+	if err != nil {
+		return Size{}, err
+	}
+
+	if len(c.SupportedSizes) == 0 {
+		if size, ok := standardSizes[strings.TrimSpace(ratio)]; ok {
+			return size, nil
+		}
+		if value >= 1 {
+			return Size{Width: 1344, Height: roundTo8(1344 / value)}, nil
+		}
+		return Size{Width: roundTo8(1344 * value), Height: 1344}, nil
+	}
+
+	// 服务的尺寸按 32 像素取整，与标称宽高比相差可达 3%，选择最接近的尺寸
+	best, bestDiff := Size{}, math.Inf(1)
+	for _, size := range c.SupportedSizes {
+		diff := math.Abs(float64(size.Width)/float64(size.Height)-value) / value
+		if diff < bestDiff {
+			best, bestDiff = size, diff
+		}
+	}
+	if bestDiff > aspectRatioTolerance {
+		return Size{}, fmt.Errorf("%w: aspect ratio %s", ErrSizeUnsupported, ratio)
+	}
+	return best, nil
+}
+
+func roundTo8(v float64) int {
+	return max(8, int(math.Round(v/8))*8)
+}
+
+// ImageResult 图像生成结果
+type ImageResult struct {
+	URL           string // 生成图片的 URL 或 base64 数据
+	RevisedPrompt string // 服务改写后实际使用的提示词，服务未返回时为空
+}
+
+// ImageGenerator 图像生成服务接口
+type ImageGenerator interface {
+	Name() string
+	TextToImage(ctx context.Context, req TextToImageRequest) (*ImageResult, error)
+	ImageToImage(ctx context.Context, req ImageToImageRequest) (*ImageResult, error)
+	Capabilities() Capabilities
+}
@@ -0,0 +1,92 @@
+package ai_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai/gemini"
+)
+
+func TestSizeForAspectRatio(t *testing.T) {
+	unrestricted := ai.Capabilities{}
+	restricted := ai.Capabilities{SupportedSizes: []ai.Size{
+		{Width: 1024, Height: 1024},
+		{Width: 1344, Height: 768},
+		{Width: 768, Height: 1344},
+	}}
+
+	tests := []struct {
+		name    string
+		caps    ai.Capabilities
+		ratio   string
+		want    ai.Size
+		wantErr error
+	}{
+		{"standard ratio", unrestricted, "16:9", ai.Size{Width: 1344, Height: 768}, nil},
+		{"custom landscape ratio", unrestricted, "2:1", ai.Size{Width: 1344, Height: 672}, nil},
+		{"custom portrait ratio", unrestricted, "1:2", ai.Size{Width: 672, Height: 1344}, nil},
+		{"supported ratio", restricted, "9:16", ai.Size{Width: 768, Height: 1344}, nil},
+		{"equivalent ratio", restricted, "2:2", ai.Size{Width: 1024, Height: 1024}, nil},
+		{"unsupported ratio", restricted, "4:3", ai.Size{}, ai.ErrSizeUnsupported},
+		{"malformed ratio", unrestricted, "wide", ai.Size{}, ai.ErrInvalidAspectRatio},
+		{"zero ratio", unrestricted, "16:0", ai.Size{}, ai.ErrInvalidAspectRatio},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.caps.SizeForAspectRatio(tt.ratio)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("size = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSizeForAspectRatioGeminiSizes(t *testing.T) {
+	client, err := gemini.NewClient("http://localhost", "test-key", ai.RetryPolicy{})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	caps := client.Capabilities()
+
+	// Gemini 尺寸表中列出的全部宽高比
+	want := map[string]ai.Size{
+		"1:1":  {Width: 1024, Height: 1024},
+		"16:9": {Width: 1344, Height: 768},
+		"9:16": {Width: 768, Height: 1344},
+		"4:3":  {Width: 1184, Height: 864},
+		"3:4":  {Width: 864, Height: 1184},
+		"3:2":  {Width: 1248, Height: 832},
+		"2:3":  {Width: 832, Height: 1248},
+		"5:4":  {Width: 1152, Height: 896},
+		"4:5":  {Width: 896, Height: 1152},
+		"21:9": {Width: 1536, Height: 672},
+	}
+	if len(want) != len(caps.SupportedSizes) {
+		t.Fatalf("Gemini advertises %d sizes, test covers %d", len(caps.SupportedSizes), len(want))
+	}
+	for ratio, size := range want {
+		got, err := caps.SizeForAspectRatio(ratio)
+		if err != nil {
+			t.Errorf("SizeForAspectRatio(%s) error = %v", ratio, err)
+			continue
+		}
+		if got != size || !caps.SupportsSize(got.Width, got.Height) {
+			t.Errorf("SizeForAspectRatio(%s) = %+v, want %+v", ratio, got, size)
+		}
+	}
+
+	if _, err := caps.SizeForAspectRatio("32:9"); !errors.Is(err, ai.ErrSizeUnsupported) {
+		t.Errorf("SizeForAspectRatio(32:9) error = %v, want %v", err, ai.ErrSizeUnsupported)
+	}
+}
@@ -0,0 +1,77 @@
+package ai
+
+import "context"
+
+// semaphore 基于 channel 的计数信号量，等待时响应 ctx 取消
+type semaphore chan struct{}
+
+func (s semaphore) acquire(ctx context.Context) error {
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s semaphore) release() {
+	<-s
+}
+
+type limitedImageGenerator struct {
+	ImageGenerator
+	sem semaphore
+}
+
+// LimitImageGenerator 限制同一服务的并发调用数，limit <= 0 时不做限制
+func LimitImageGenerator(generator ImageGenerator, limit int) ImageGenerator {
+	if limit <= 0 {
+		return generator
+	}
+	return &limitedImageGenerator{ImageGenerator: generator, sem: make(semaphore, limit)}
+}
+
+func (g *limitedImageGenerator) TextToImage(ctx context.Context, req TextToImageRequest) (*ImageResult, error) {
+	if err := g.sem.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer g.sem.release()
+	return g.ImageGenerator.TextToImage(ctx, req)
+}
+
+func (g *limitedImageGenerator) ImageToImage(ctx context.Context, req ImageToImageRequest) (*ImageResult, error) {
+	if err := g.sem.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer g.sem.release()
+	return g.ImageGenerator.ImageToImage(ctx, req)
+}
+
+type limitedVideoGenerator struct {
+	VideoGenerator
+	sem semaphore
+}
+
+// LimitVideoGenerator 限制视频生成提交的并发数，状态查询不受限制
+func LimitVideoGenerator(generator VideoGenerator, limit int) VideoGenerator {
+	if limit <= 0 {
+		return generator
+	}
+	return &limitedVideoGenerator{VideoGenerator: generator, sem: make(semaphore, limit)}
+}
+
+func (g *limitedVideoGenerator) ImageToVideo(ctx context.Context, req ImageToVideoRequest) (string, error) {
+	if err := g.sem.acquire(ctx); err != nil {
+		return "", err
+	}
+	defer g.sem.release()
+	return g.VideoGenerator.ImageToVideo(ctx, req)
+}
+
+func (g *limitedVideoGenerator) TextToVideo(ctx context.Context, req TextToVideoRequest) (string, error) {
+	if err := g.sem.acquire(ctx); err != nil {
+		return "", err
+	}
+	defer g.sem.release()
+	return g.VideoGenerator.TextToVideo(ctx, req)
+}
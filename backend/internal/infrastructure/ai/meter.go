@@ -0,0 +1,138 @@
+package ai
+
+import (
+	"context"
+	"time"
+)
+
+// 计量记录中的调用类型
+const (
+	OperationTextToImage  = "text_to_image"
+	OperationImageToImage = "image_to_image"
+	OperationTextToVideo  = "text_to_video"
+	OperationImageToVideo = "image_to_video"
+	OperationTextToSpeech = "text_to_speech"
+)
+
+// CallRecord 一次生成调用的计量信息
+type CallRecord struct {
+	Provider  string
+	Operation string
+	Width     int
+	Height    int
+	Duration  int // 请求的视频时长（秒）
+	Latency   time.Duration
+	Err       error
+}
+
+// Meter 接收计量记录，在调用返回后同步执行，实现不应阻塞
+type Meter func(ctx context.Context, record CallRecord)
+
+type meteredImageGenerator struct {
+	ImageGenerator
+	meter Meter
+}
+
+// MeterImageGenerator 在每次图像生成调用后上报计量记录，meter 为 nil 时不做包装
+func MeterImageGenerator(generator ImageGenerator, meter Meter) ImageGenerator {
+	if meter == nil {
+		return generator
+	}
+	return &meteredImageGenerator{ImageGenerator: generator, meter: meter}
+}
+
+func (g *meteredImageGenerator) TextToImage(ctx context.Context, req TextToImageRequest) (*ImageResult, error) {
+	start := time.Now()
+	result, err := g.ImageGenerator.TextToImage(ctx, req)
+	g.meter(ctx, CallRecord{
+		Provider:  g.Name(),
+		Operation: OperationTextToImage,
+		Width:     req.Width,
+		Height:    req.Height,
+		Latency:   time.Since(start),
+		Err:       err,
+	})
+	return result, err
+}
+
+func (g *meteredImageGenerator) ImageToImage(ctx context.Context, req ImageToImageRequest) (*ImageResult, error) {
+	start := time.Now()
+	result, err := g.ImageGenerator.ImageToImage(ctx, req)
+	g.meter(ctx, CallRecord{
+		Provider:  g.Name(),
+		Operation: OperationImageToImage,
+		Width:     req.Width,
+		Height:    req.Height,
+		Latency:   time.Since(start),
+		Err:       err,
+	})
+	return result, err
+}
+
+type meteredVideoGenerator struct {
+	VideoGenerator
+	meter Meter
+}
+
+// MeterVideoGenerator 在每次视频提交后上报计量记录，状态查询和下载不计量
+func MeterVideoGenerator(generator VideoGenerator, meter Meter) VideoGenerator {
+	if meter == nil {
+		return generator
+	}
+	return &meteredVideoGenerator{VideoGenerator: generator, meter: meter}
+}
+
+func (g *meteredVideoGenerator) ImageToVideo(ctx context.Context, req ImageToVideoRequest) (string, error) {
+	start := time.Now()
+	videoID, err := g.VideoGenerator.ImageToVideo(ctx, req)
+	g.meter(ctx, CallRecord{
+		Provider:  g.Name(),
+		Operation: OperationImageToVideo,
+		Width:     req.Width,
+		Height:    req.Height,
+		Duration:  req.Duration,
+		Latency:   time.Since(start),
+		Err:       err,
+	})
+	return videoID, err
+}
+
+func (g *meteredVideoGenerator) TextToVideo(ctx context.Context, req TextToVideoRequest) (string, error) {
+	start := time.Now()
+	videoID, err := g.VideoGenerator.TextToVideo(ctx, req)
+	g.meter(ctx, CallRecord{
+		Provider:  g.Name(),
+		Operation: OperationTextToVideo,
+		Width:     req.Width,
+		Height:    req.Height,
+		Duration:  req.Duration,
+		Latency:   time.Since(start),
+		Err:       err,
+	})
+	return videoID, err
+}
+
+type meteredSpeechSynthesizer struct {
+	SpeechSynthesizer
+	meter Meter
+}
+
+// MeterSpeechSynthesizer 在每句台词合成后上报计量记录
+func MeterSpeechSynthesizer(synthesizer SpeechSynthesizer, meter Meter) SpeechSynthesizer {
+	if meter == nil {
+		return synthesizer
+	}
+	return &meteredSpeechSynthesizer{SpeechSynthesizer: synthesizer, meter: meter}
+}
+
+func (s *meteredSpeechSynthesizer) Synthesize(ctx context.Context, req SpeechRequest) (*SpeechResult, error) {
+	start := time.Now()
+	result, err := s.SpeechSynthesizer.Synthesize(ctx, req)
+	s.meter(ctx, CallRecord{
+		Provider:  s.Name(),
+		Operation: OperationTextToSpeech,
+		Latency:   time.Since(start),
+		Err:       err,
+	})
+	return result, err
+}
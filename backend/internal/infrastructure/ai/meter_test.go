@@ -0,0 +1,82 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMeterImageGeneratorRecordsEveryCall(t *testing.T) {
+	var records []CallRecord
+	meter := func(ctx context.Context, record CallRecord) {
+		records = append(records, record)
+	}
+	stub := &stubImageGenerator{errs: []error{nil, errServerDown}}
+	generator := MeterImageGenerator(stub, meter)
+	ctx := context.Background()
+
+	if _, err := generator.TextToImage(ctx, TextToImageRequest{Width: 1024, Height: 768}); err != nil {
+		t.Fatalf("TextToImage() error = %v", err)
+	}
+	if _, err := generator.ImageToImage(ctx, ImageToImageRequest{Width: 512, Height: 512}); err == nil {
+		t.Fatal("ImageToImage() error = nil, want provider error")
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("recorded %d calls, want 2", len(records))
+	}
+	first := records[0]
+	if first.Provider != "stub" || first.Operation != OperationTextToImage || first.Width != 1024 || first.Height != 768 || first.Err != nil {
+		t.Errorf("first record = %+v, want successful stub text_to_image 1024x768", first)
+	}
+	second := records[1]
+	if second.Operation != OperationImageToImage || !errors.Is(second.Err, ErrProviderError) {
+		t.Errorf("second record = %+v, want failed image_to_image", second)
+	}
+}
+
+func TestMeterImageGeneratorNilMeter(t *testing.T) {
+	stub := &stubImageGenerator{}
+	if generator := MeterImageGenerator(stub, nil); generator != ImageGenerator(stub) {
+		t.Error("MeterImageGenerator(nil meter) should return the generator unchanged")
+	}
+}
+
+// stubSpeechSynthesizer 返回预设错误的语音合成服务
+type stubSpeechSynthesizer struct {
+	err error
+}
+
+func (s *stubSpeechSynthesizer) Name() string { return "stub" }
+
+func (s *stubSpeechSynthesizer) Synthesize(ctx context.Context, req SpeechRequest) (*SpeechResult, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &SpeechResult{Audio: []byte("RIFF"), MimeType: "audio/wav", Duration: 1.5}, nil
+}
+
+func TestMeterSpeechSynthesizerRecordsEveryLine(t *testing.T) {
+	var records []CallRecord
+	meter := func(ctx context.Context, record CallRecord) {
+		records = append(records, record)
+	}
+	ctx := context.Background()
+
+	if _, err := MeterSpeechSynthesizer(&stubSpeechSynthesizer{}, meter).Synthesize(ctx, SpeechRequest{Text: "你好"}); err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	if _, err := MeterSpeechSynthesizer(&stubSpeechSynthesizer{err: errServerDown}, meter).Synthesize(ctx, SpeechRequest{Text: "再见"}); err == nil {
+		t.Fatal("Synthesize() error = nil, want provider error")
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("recorded %d calls, want 2", len(records))
+	}
+	if records[0].Provider != "stub" || records[0].Operation != OperationTextToSpeech || records[0].Err != nil {
+		t.Errorf("first record = %+v, want successful stub text_to_speech", records[0])
+	}
+	if !errors.Is(records[1].Err, ErrProviderError) {
+		t.Errorf("second record = %+v, want failed text_to_speech", records[1])
+	}
+}
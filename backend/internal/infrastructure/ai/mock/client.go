@@ -0,0 +1,247 @@
+package mock
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai"
+)
+
+const ProviderName = "mock"
+
+const (
+	defaultWidth  = 1344
+	defaultHeight = 768
+
+	defaultVideoDuration = 5
+)
+
+var (
+	ErrOutputDirRequired = errors.New("mock output dir is required")
+	ErrVideoNotFound     = errors.New("mock video not found")
+)
+
+// panelPattern 从 Prompt 中解析面板编号，例如 "image 3 of 10"
+var panelPattern = regexp.MustCompile(`image (\d+) of \d+`)
+
+// Client 离线模拟的 AI 服务，根据请求参数确定性地生成占位图片和视频文件
+type Client struct {
+	outputDir string
+	publicURL string
+}
+
+// NewClient 创建模拟客户端，文件写入 outputDir，返回的 URL 以 publicURL 为前缀
+func NewClient(outputDir, publicURL string) (*Client, error) {
+	if outputDir == "" {
+		return nil, ErrOutputDirRequired
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create mock output directory: %w", err)
+	}
+
+	return &Client{
+		outputDir: outputDir,
+		publicURL: strings.TrimRight(publicURL, "/"),
+	}, nil
+}
+
+var (
+	_ ai.ImageGenerator = (*Client)(nil)
+	_ ai.VideoGenerator = (*Client)(nil)
+)
+
+func (c *Client) Name() string {
+	return ProviderName
+}
+
+func (c *Client) Capabilities() ai.Capabilities {
+	return ai.Capabilities{
+		Model:                  ProviderName,
+		SupportsReferenceImage: true,
+		SupportsNegativePrompt: true,
+		SupportsSeed:           true,
+	}
+}
+
+func (c *Client) TextToImage(ctx context.Context, req ai.TextToImageRequest) (*ai.ImageResult, error) {
+	hash := hashOf("t2i", req.Prompt, req.NegativePrompt, req.Style, req.Quality,
+		strconv.Itoa(req.Width), strconv.Itoa(req.Height), strconv.FormatInt(req.Seed, 10))
+	imageURL, err := c.renderImage(ctx, hash, req.Prompt, req.Width, req.Height)
+	if err != nil {
+		return nil, err
+	}
+	return &ai.ImageResult{URL: imageURL}, nil
+}
+
+func (c *Client) ImageToImage(ctx context.Context, req ai.ImageToImageRequest) (*ai.ImageResult, error) {
+	hash := hashOf("i2i", req.Prompt, req.NegativePrompt, req.ReferenceImage,
+		strconv.FormatFloat(req.Strength, 'f', -1, 64), strconv.Itoa(req.Width), strconv.Itoa(req.Height),
+		strconv.FormatInt(req.Seed, 10))
+	imageURL, err := c.renderImage(ctx, hash, req.Prompt, req.Width, req.Height)
+	if err != nil {
+		return nil, err
+	}
+	return &ai.ImageResult{URL: imageURL}, nil
+}
+
+func (c *Client) ImageToVideo(ctx context.Context, req ai.ImageToVideoRequest) (string, error) {
+	hash := hashOf("i2v", req.ImageURL, req.Prompt, strconv.Itoa(req.Duration),
+		strconv.Itoa(req.Width), strconv.Itoa(req.Height))
+	return c.writeVideo(ctx, hash, req.Duration, req.Width, req.Height)
+}
+
+func (c *Client) TextToVideo(ctx context.Context, req ai.TextToVideoRequest) (string, error) {
+	hash := hashOf("t2v", req.Prompt, strconv.Itoa(req.Duration),
+		strconv.Itoa(req.Width), strconv.Itoa(req.Height))
+	return c.writeVideo(ctx, hash, req.Duration, req.Width, req.Height)
+}
+
+// GetVideoStatus 模拟视频生成是同步完成的，文件存在即视为已完成
+func (c *Client) GetVideoStatus(ctx context.Context, videoID string) (*ai.VideoGenerationResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	filename := videoID + ".mp4"
+	if _, err := os.Stat(filepath.Join(c.outputDir, filename)); err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrVideoNotFound
+		}
+		return nil, fmt.Errorf("failed to stat mock video: %w", err)
+	}
+
+	return &ai.VideoGenerationResponse{
+		ID:     videoID,
+		Status: ai.VideoStatusCompleted,
+		URL:    c.urlFor(filename),
+	}, nil
+}
+
+// DownloadVideo 读取模拟生成的视频文件
+func (c *Client) DownloadVideo(ctx context.Context, videoID string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filepath.Join(c.outputDir, filepath.Base(videoID)+".mp4"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrVideoNotFound
+		}
+		return nil, fmt.Errorf("failed to open mock video: %w", err)
+	}
+	return file, nil
+}
+
+func (c *Client) renderImage(ctx context.Context, hash, prompt string, width, height int) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	if width <= 0 || height <= 0 {
+		width, height = defaultWidth, defaultHeight
+	}
+
+	data, err := RenderPlaceholder(hash, panelNumber(prompt), width, height)
+	if err != nil {
+		return "", err
+	}
+
+	filename := hash + ".png"
+	if err := os.WriteFile(filepath.Join(c.outputDir, filename), data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write mock image: %w", err)
+	}
+
+	return c.urlFor(filename), nil
+}
+
+func (c *Client) writeVideo(ctx context.Context, hash string, duration, width, height int) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	if duration <= 0 {
+		duration = defaultVideoDuration
+	}
+	if width <= 0 || height <= 0 {
+		width, height = defaultWidth, defaultHeight
+	}
+
+	filename := hash + ".mp4"
+	if err := os.WriteFile(filepath.Join(c.outputDir, filename), stubVideo(hash, duration, width, height), 0644); err != nil {
+		return "", fmt.Errorf("failed to write mock video: %w", err)
+	}
+
+	return hash, nil
+}
+
+func (c *Client) urlFor(filename string) string {
+	return c.publicURL + "/" + filename
+}
+
+// stubVideo 生成一个最小的 MP4 容器（ftyp + moov + mdat），moov 中只记录时长和画面尺寸，mdat 中写入请求哈希
+func stubVideo(hash string, duration, width, height int) []byte {
+	var buf bytes.Buffer
+
+	ftyp := []byte("ftypisom\x00\x00\x02\x00isomiso2mp41")
+	writeBox(&buf, ftyp)
+
+	// mvhd version 0：timescale 为 1，duration 以秒为单位
+	mvhd := make([]byte, 4+100)
+	copy(mvhd, "mvhd")
+	binary.BigEndian.PutUint32(mvhd[4+12:], 1)
+	binary.BigEndian.PutUint32(mvhd[4+16:], uint32(duration))
+
+	// tkhd version 0：最后 8 字节为 16.16 定点数表示的宽高
+	tkhd := make([]byte, 4+84)
+	copy(tkhd, "tkhd")
+	binary.BigEndian.PutUint32(tkhd[4+76:], uint32(width)<<16)
+	binary.BigEndian.PutUint32(tkhd[4+80:], uint32(height)<<16)
+
+	var trak bytes.Buffer
+	trak.WriteString("trak")
+	writeBox(&trak, tkhd)
+
+	var moov bytes.Buffer
+	moov.WriteString("moov")
+	writeBox(&moov, mvhd)
+	writeBox(&moov, trak.Bytes())
+	writeBox(&buf, moov.Bytes())
+
+	mdat := append([]byte("mdat"), []byte(hash)...)
+	writeBox(&buf, mdat)
+
+	return buf.Bytes()
+}
+
+func writeBox(buf *bytes.Buffer, payload []byte) {
+	size := uint32(len(payload) + 4)
+	buf.Write([]byte{byte(size >> 24), byte(size >> 16), byte(size >> 8), byte(size)})
+	buf.Write(payload)
+}
+
+func panelNumber(prompt string) int {
+	match := panelPattern.FindStringSubmatch(prompt)
+	if len(match) < 2 {
+		return 0
+	}
+	n, _ := strconv.Atoi(match[1])
+	return n
+}
+
+func hashOf(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])[:16]
+}
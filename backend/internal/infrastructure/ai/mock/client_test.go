@@ -0,0 +1,141 @@
+package mock
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/video"
+)
+
+func TestTextToImageIsDeterministic(t *testing.T) {
+	dir := t.TempDir()
+	client, err := NewClient(dir, "/mock-assets")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	req := ai.TextToImageRequest{
+		Prompt: "Create a beautiful anime manga panel (image 3 of 10)",
+		Width:  640,
+		Height: 360,
+	}
+
+	firstResult, err := client.TextToImage(context.Background(), req)
+	if err != nil {
+		t.Fatalf("TextToImage() error = %v", err)
+	}
+	first := firstResult.URL
+	firstData, err := os.ReadFile(filepath.Join(dir, filepath.Base(first)))
+	if err != nil {
+		t.Fatalf("failed to read rendered image: %v", err)
+	}
+
+	secondResult, err := client.TextToImage(context.Background(), req)
+	if err != nil {
+		t.Fatalf("TextToImage() error = %v", err)
+	}
+	second := secondResult.URL
+	secondData, err := os.ReadFile(filepath.Join(dir, filepath.Base(second)))
+	if err != nil {
+		t.Fatalf("failed to read rendered image: %v", err)
+	}
+
+	if first != second {
+		t.Errorf("URL not stable: %q != %q", first, second)
+	}
+	if !bytes.Equal(firstData, secondData) {
+		t.Error("rendered image is not deterministic")
+	}
+	if !strings.HasPrefix(first, "/mock-assets/") || !strings.HasSuffix(first, ".png") {
+		t.Errorf("unexpected URL %q", first)
+	}
+
+	img, err := png.Decode(bytes.NewReader(firstData))
+	if err != nil {
+		t.Fatalf("rendered image is not a PNG: %v", err)
+	}
+	if got := img.Bounds().Size(); got.X != 640 || got.Y != 360 {
+		t.Errorf("image size = %v, want 640x360", got)
+	}
+}
+
+func TestTextToImageDiffersByPrompt(t *testing.T) {
+	client, err := NewClient(t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	a, _ := client.TextToImage(context.Background(), ai.TextToImageRequest{Prompt: "a"})
+	b, _ := client.TextToImage(context.Background(), ai.TextToImageRequest{Prompt: "b"})
+	if a.URL == b.URL {
+		t.Error("different prompts produced the same image")
+	}
+}
+
+func TestVideoRoundTrip(t *testing.T) {
+	client, err := NewClient(t.TempDir(), "http://localhost/mock-assets")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	videoID, err := client.ImageToVideo(context.Background(), ai.ImageToVideoRequest{
+		ImageURL: "http://localhost/mock-assets/panel.png",
+		Prompt:   "camera pans",
+		Duration: 5,
+	})
+	if err != nil {
+		t.Fatalf("ImageToVideo() error = %v", err)
+	}
+
+	status, err := client.GetVideoStatus(context.Background(), videoID)
+	if err != nil {
+		t.Fatalf("GetVideoStatus() error = %v", err)
+	}
+	if status.Status != "completed" {
+		t.Errorf("status = %q, want completed", status.Status)
+	}
+	if status.URL != "http://localhost/mock-assets/"+videoID+".mp4" {
+		t.Errorf("unexpected URL %q", status.URL)
+	}
+
+	reader, err := client.DownloadVideo(context.Background(), videoID)
+	if err != nil {
+		t.Fatalf("DownloadVideo() error = %v", err)
+	}
+	defer reader.Close()
+	content, _ := io.ReadAll(reader)
+	if http.DetectContentType(content) != "video/mp4" {
+		t.Errorf("downloaded content type = %q, want video/mp4", http.DetectContentType(content))
+	}
+	if info, err := video.ProbeMP4(content); err != nil || info.Duration != 5 || info.Width != defaultWidth {
+		t.Errorf("ProbeMP4() = %+v, %v, want 5s at default size", info, err)
+	}
+
+	if _, err := client.GetVideoStatus(context.Background(), "missing"); err != ErrVideoNotFound {
+		t.Errorf("GetVideoStatus(missing) error = %v, want %v", err, ErrVideoNotFound)
+	}
+}
+
+func TestPanelNumber(t *testing.T) {
+	tests := []struct {
+		prompt string
+		want   int
+	}{
+		{"manga panel (image 7 of 10) based on", 7},
+		{"character portrait", 0},
+	}
+
+	for _, tt := range tests {
+		if got := panelNumber(tt.prompt); got != tt.want {
+			t.Errorf("panelNumber(%q) = %d, want %d", tt.prompt, got, tt.want)
+		}
+	}
+}
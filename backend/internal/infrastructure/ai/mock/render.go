@@ -0,0 +1,126 @@
+package mock
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+)
+
+// glyphs 3x5 点阵字体，仅包含占位图需要的字符
+var glyphs = map[rune][5]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'a': {"###", "#.#", "###", "#.#", "#.#"},
+	'b': {"##.", "#.#", "##.", "#.#", "##."},
+	'c': {"###", "#..", "#..", "#..", "###"},
+	'd': {"##.", "#.#", "#.#", "#.#", "##."},
+	'e': {"###", "#..", "###", "#..", "###"},
+	'f': {"###", "#..", "###", "#..", "#.."},
+	'x': {"...", "#.#", ".#.", "#.#", "..."},
+	'P': {"###", "#.#", "###", "#..", "#.."},
+	'#': {"#.#", "###", "#.#", "###", "#.#"},
+	' ': {"...", "...", "...", "...", "..."},
+}
+
+// RenderPlaceholder 渲染占位 PNG：背景色由哈希决定，并绘制哈希、面板编号和尺寸
+func RenderPlaceholder(hash string, panel, width, height int) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	background := colorFromHash(hash)
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: background}, image.Point{}, draw.Src)
+
+	foreground := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	if luminance(background) > 140 {
+		foreground = color.RGBA{R: 20, G: 20, B: 20, A: 255}
+	}
+
+	border := max(2, min(width, height)/100)
+	drawFrame(img, border, foreground)
+
+	lines := []string{
+		"#" + hash[:min(8, len(hash))],
+		"P" + strconv.Itoa(panel),
+		fmt.Sprintf("%dx%d", width, height),
+	}
+
+	// 每个字符占 4 列（3 列字形 + 1 列间距），每行占 6 行
+	longest := 0
+	for _, line := range lines {
+		longest = max(longest, len(line))
+	}
+	scale := max(1, min((width-4*border)/(longest*4), (height-4*border)/(len(lines)*6)))
+
+	textHeight := len(lines) * 6 * scale
+	y := (height - textHeight) / 2
+	for _, line := range lines {
+		x := (width - len(line)*4*scale) / 2
+		drawText(img, line, x, y, scale, foreground)
+		y += 6 * scale
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode placeholder: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func drawText(img *image.RGBA, text string, x, y, scale int, c color.Color) {
+	for i, r := range text {
+		glyph, ok := glyphs[r]
+		if !ok {
+			continue
+		}
+		originX := x + i*4*scale
+		for row, bits := range glyph {
+			for col, bit := range bits {
+				if bit != '#' {
+					continue
+				}
+				rect := image.Rect(
+					originX+col*scale, y+row*scale,
+					originX+(col+1)*scale, y+(row+1)*scale,
+				)
+				draw.Draw(img, rect, &image.Uniform{C: c}, image.Point{}, draw.Src)
+			}
+		}
+	}
+}
+
+func drawFrame(img *image.RGBA, width int, c color.Color) {
+	b := img.Bounds()
+	uniform := &image.Uniform{C: c}
+	draw.Draw(img, image.Rect(b.Min.X, b.Min.Y, b.Max.X, b.Min.Y+width), uniform, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(b.Min.X, b.Max.Y-width, b.Max.X, b.Max.Y), uniform, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(b.Min.X, b.Min.Y, b.Min.X+width, b.Max.Y), uniform, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(b.Max.X-width, b.Min.Y, b.Max.X, b.Max.Y), uniform, image.Point{}, draw.Src)
+}
+
+func colorFromHash(hash string) color.RGBA {
+	c := color.RGBA{R: 128, G: 128, B: 128, A: 255}
+	if len(hash) >= 6 {
+		if v, err := strconv.ParseUint(hash[:6], 16, 32); err == nil {
+			c.R = uint8(v >> 16)
+			c.G = uint8(v >> 8)
+			c.B = uint8(v)
+		}
+	}
+	return c
+}
+
+func luminance(c color.RGBA) int {
+	return (299*int(c.R) + 587*int(c.G) + 114*int(c.B)) / 1000
+}
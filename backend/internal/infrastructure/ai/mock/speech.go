@@ -0,0 +1,129 @@
+package mock
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai"
+)
+
+const (
+	speechSampleRate = 16000
+	// speechSyllable 每个汉字（音节）的时长，speechLetter 每个拉丁字母或数字的时长（秒）
+	speechSyllable = 0.2
+	speechLetter   = 0.07
+	// speechPause 标点的停顿，speechSpace 空白的停顿，speechPadding 开头和结尾的静音（秒）
+	speechPause   = 0.2
+	speechSpace   = 0.08
+	speechPadding = 0.1
+)
+
+// SpeechSynthesizer 离线模拟的语音合成服务，不需要模型或 API Key：
+// 按文本逐字生成确定性的音调（音色决定基础音高），时长与真实朗读接近，用于开发和测试字幕、配音的时间轴
+type SpeechSynthesizer struct{}
+
+func NewSpeechSynthesizer() *SpeechSynthesizer {
+	return &SpeechSynthesizer{}
+}
+
+var _ ai.SpeechSynthesizer = (*SpeechSynthesizer)(nil)
+
+func (s *SpeechSynthesizer) Name() string {
+	return ProviderName
+}
+
+// Synthesize 生成 16 kHz 单声道 16 位 PCM 的 WAV，相同请求得到相同音频
+func (s *SpeechSynthesizer) Synthesize(ctx context.Context, req ai.SpeechRequest) (*ai.SpeechResult, error) {
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return nil, ai.ErrEmptySpeechText
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	speed := req.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+	// 音色（未指定时为说话人）决定 140-260 Hz 的基础音高，再按半音偏移
+	voice := req.Voice
+	if voice == "" {
+		voice = req.Speaker
+	}
+	base := (140 + float64(hashNumber("voice", voice)%120)) * math.Pow(2, req.Pitch/12)
+
+	samples := make([]int16, 0, speechSampleRate*4)
+	silence := func(seconds float64) {
+		samples = append(samples, make([]int16, int(seconds*speechSampleRate))...)
+	}
+	tone := func(seconds, frequency float64) {
+		n := int(seconds * speechSampleRate)
+		for i := 0; i < n; i++ {
+			// 汉宁窗包络，避免音节之间出现爆音
+			envelope := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n))
+			value := 0.3 * envelope * math.Sin(2*math.Pi*frequency*float64(i)/speechSampleRate)
+			samples = append(samples, int16(value*math.MaxInt16))
+		}
+	}
+
+	silence(speechPadding)
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+			silence(speechSpace / speed)
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			silence(speechPause / speed)
+		default:
+			// 每个字的音高在基础音高上下浮动，模拟声调
+			step := float64(hashNumber("rune", string(r))%7-3) / 12
+			duration := speechSyllable
+			if r < unicode.MaxASCII {
+				duration = speechLetter
+			}
+			tone(duration/speed, base*math.Pow(2, step))
+		}
+	}
+	silence(speechPadding)
+
+	return &ai.SpeechResult{
+		Audio:    encodeWAV(samples, speechSampleRate),
+		MimeType: "audio/wav",
+		Duration: float64(len(samples)) / speechSampleRate,
+		Model:    ProviderName,
+	}, nil
+}
+
+// hashNumber 由哈希得到的非负整数，用于确定性地选择音高
+func hashNumber(parts ...string) int {
+	n, _ := strconv.ParseUint(hashOf(parts...)[:8], 16, 32)
+	return int(n)
+}
+
+// encodeWAV 写入单声道 16 位 PCM 的 RIFF/WAVE 文件
+func encodeWAV(samples []int16, sampleRate int) []byte {
+	dataSize := len(samples) * 2
+	var buf bytes.Buffer
+	buf.Grow(44 + dataSize)
+
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+dataSize))
+	buf.WriteString("WAVEfmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16))           // fmt 块大小
+	binary.Write(&buf, binary.LittleEndian, uint16(1))            // PCM
+	binary.Write(&buf, binary.LittleEndian, uint16(1))            // 单声道
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate))   // 采样率
+	binary.Write(&buf, binary.LittleEndian, uint32(sampleRate*2)) // 每秒字节数
+	binary.Write(&buf, binary.LittleEndian, uint16(2))            // 每帧字节数
+	binary.Write(&buf, binary.LittleEndian, uint16(16))           // 位深
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(dataSize))
+	binary.Write(&buf, binary.LittleEndian, samples)
+
+	return buf.Bytes()
+}
@@ -0,0 +1,61 @@
+package mock
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai"
+)
+
+func TestSynthesizeWritesWAV(t *testing.T) {
+	synthesizer := NewSpeechSynthesizer()
+	req := ai.SpeechRequest{Text: "你来了。", Voice: "narrator"}
+
+	result, err := synthesizer.Synthesize(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	if result.MimeType != "audio/wav" || !bytes.HasPrefix(result.Audio, []byte("RIFF")) || string(result.Audio[8:16]) != "WAVEfmt " {
+		t.Fatalf("result is not a WAV file: %q", result.Audio[:16])
+	}
+	if rate := binary.LittleEndian.Uint32(result.Audio[24:28]); rate != speechSampleRate {
+		t.Errorf("sample rate = %d, want %d", rate, speechSampleRate)
+	}
+
+	// 3 个字加 1 个标点和首尾静音：0.1 + 3*0.2 + 0.2 + 0.1
+	dataSize := binary.LittleEndian.Uint32(result.Audio[40:44])
+	if int(dataSize) != len(result.Audio)-44 {
+		t.Errorf("data size = %d, want %d", dataSize, len(result.Audio)-44)
+	}
+	if math.Abs(result.Duration-1.0) > 0.01 || math.Abs(float64(dataSize)/2/speechSampleRate-result.Duration) > 0.001 {
+		t.Errorf("Duration = %v, data %d bytes, want about 1s", result.Duration, dataSize)
+	}
+
+	again, _ := synthesizer.Synthesize(context.Background(), req)
+	if !bytes.Equal(again.Audio, result.Audio) {
+		t.Error("same request produced different audio")
+	}
+	other, _ := synthesizer.Synthesize(context.Background(), ai.SpeechRequest{Text: req.Text, Voice: "hero"})
+	if bytes.Equal(other.Audio, result.Audio) {
+		t.Error("different voices produced the same audio")
+	}
+	byName, _ := synthesizer.Synthesize(context.Background(), ai.SpeechRequest{Text: req.Text, Speaker: "narrator"})
+	if !bytes.Equal(byName.Audio, result.Audio) {
+		t.Error("speaker without voice should pick the same voice as its name")
+	}
+
+	fast, _ := synthesizer.Synthesize(context.Background(), ai.SpeechRequest{Text: req.Text, Voice: "narrator", Speed: 2})
+	if fast.Duration >= result.Duration {
+		t.Errorf("Speed 2 duration = %v, want shorter than %v", fast.Duration, result.Duration)
+	}
+}
+
+func TestSynthesizeRejectsEmptyText(t *testing.T) {
+	if _, err := NewSpeechSynthesizer().Synthesize(context.Background(), ai.SpeechRequest{Text: "  "}); !errors.Is(err, ai.ErrEmptySpeechText) {
+		t.Errorf("Synthesize(empty) error = %v, want %v", err, ai.ErrEmptySpeechText)
+	}
+}
@@ -0,0 +1,114 @@
+package ai
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ImageRegistry 图像生成服务注册表，按名称和风格路由到具体的服务
+type ImageRegistry struct {
+	mu          sync.RWMutex
+	providers   map[string]ImageGenerator
+	defaultName string
+	styleRoutes map[string]string
+}
+
+func NewImageRegistry() *ImageRegistry {
+	return &ImageRegistry{
+		providers:   make(map[string]ImageGenerator),
+		styleRoutes: make(map[string]string),
+	}
+}
+
+// Register 注册图像生成服务，第一个注册的服务默认作为默认服务
+func (r *ImageRegistry) Register(provider ImageGenerator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := provider.Name()
+	r.providers[name] = provider
+	if r.defaultName == "" {
+		r.defaultName = name
+	}
+}
+
+// SetDefault 设置默认服务
+func (r *ImageRegistry) SetDefault(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.providers[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrProviderNotFound, name)
+	}
+	r.defaultName = name
+	return nil
+}
+
+// RouteStyle 将指定风格的请求路由到指定服务
+func (r *ImageRegistry) RouteStyle(style, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.providers[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrProviderNotFound, name)
+	}
+	r.styleRoutes[style] = name
+	return nil
+}
+
+// Get 根据名称获取服务
+func (r *ImageRegistry) Get(name string) (ImageGenerator, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProviderNotFound, name)
+	}
+	return provider, nil
+}
+
+// Default 获取默认服务
+func (r *ImageRegistry) Default() (ImageGenerator, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.defaultName == "" {
+		return nil, ErrNoDefaultProvider
+	}
+	return r.providers[r.defaultName], nil
+}
+
+// ForStyle 获取处理指定风格的服务，未配置路由时返回默认服务
+func (r *ImageRegistry) ForStyle(style string) (ImageGenerator, error) {
+	r.mu.RLock()
+	name, ok := r.styleRoutes[style]
+	r.mu.RUnlock()
+
+	if ok {
+		return r.Get(name)
+	}
+	return r.Default()
+}
+
+// Names 返回所有已注册服务的名称
+func (r *ImageRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsEmpty 判断是否没有注册任何服务
+func (r *ImageRegistry) IsEmpty() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.providers) == 0
+}
@@ -0,0 +1,104 @@
+package ai_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai"
+)
+
+// namedGenerator 只提供名称的图像服务，注册表不会调用生成方法
+type namedGenerator struct {
+	ai.ImageGenerator
+	name string
+}
+
+func (g namedGenerator) Name() string { return g.name }
+
+func TestImageRegistryLookup(t *testing.T) {
+	newRegistry := func(t *testing.T) *ai.ImageRegistry {
+		t.Helper()
+		registry := ai.NewImageRegistry()
+		registry.Register(namedGenerator{name: "gemini"})
+		registry.Register(namedGenerator{name: "mock"})
+		if err := registry.RouteStyle("realistic", "mock"); err != nil {
+			t.Fatalf("RouteStyle() error = %v", err)
+		}
+		return registry
+	}
+
+	tests := []struct {
+		name    string
+		lookup  func(r *ai.ImageRegistry) (ai.ImageGenerator, error)
+		want    string
+		wantErr error
+	}{
+		{"by name", func(r *ai.ImageRegistry) (ai.ImageGenerator, error) { return r.Get("mock") }, "mock", nil},
+		{"unknown name", func(r *ai.ImageRegistry) (ai.ImageGenerator, error) { return r.Get("dalle") }, "", ai.ErrProviderNotFound},
+		{"first registered is default", func(r *ai.ImageRegistry) (ai.ImageGenerator, error) { return r.Default() }, "gemini", nil},
+		{"routed style", func(r *ai.ImageRegistry) (ai.ImageGenerator, error) { return r.ForStyle("realistic") }, "mock", nil},
+		{"unrouted style falls back to default", func(r *ai.ImageRegistry) (ai.ImageGenerator, error) { return r.ForStyle("anime") }, "gemini", nil},
+		{"empty style falls back to default", func(r *ai.ImageRegistry) (ai.ImageGenerator, error) { return r.ForStyle("") }, "gemini", nil},
+		{"changed default", func(r *ai.ImageRegistry) (ai.ImageGenerator, error) {
+			if err := r.SetDefault("mock"); err != nil {
+				return nil, err
+			}
+			return r.ForStyle("anime")
+		}, "mock", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.lookup(newRegistry(t))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Name() != tt.want {
+				t.Errorf("provider = %s, want %s", got.Name(), tt.want)
+			}
+		})
+	}
+}
+
+func TestImageRegistryRegister(t *testing.T) {
+	registry := ai.NewImageRegistry()
+	if !registry.IsEmpty() {
+		t.Fatal("new registry should be empty")
+	}
+	if _, err := registry.Default(); !errors.Is(err, ai.ErrNoDefaultProvider) {
+		t.Errorf("Default() on empty registry error = %v, want %v", err, ai.ErrNoDefaultProvider)
+	}
+	if _, err := registry.ForStyle("anime"); !errors.Is(err, ai.ErrNoDefaultProvider) {
+		t.Errorf("ForStyle() on empty registry error = %v, want %v", err, ai.ErrNoDefaultProvider)
+	}
+
+	registry.Register(namedGenerator{name: "mock"})
+	registry.Register(namedGenerator{name: "gemini"})
+	// 同名服务重新注册时替换原有实例，不重复计入
+	registry.Register(namedGenerator{name: "mock"})
+
+	if registry.IsEmpty() {
+		t.Error("registry should not be empty after Register")
+	}
+	if got, want := registry.Names(), []string{"gemini", "mock"}; !slices.Equal(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+
+	if err := registry.SetDefault("dalle"); !errors.Is(err, ai.ErrProviderNotFound) {
+		t.Errorf("SetDefault(unknown) error = %v, want %v", err, ai.ErrProviderNotFound)
+	}
+	if err := registry.RouteStyle("anime", "dalle"); !errors.Is(err, ai.ErrProviderNotFound) {
+		t.Errorf("RouteStyle(unknown) error = %v, want %v", err, ai.ErrProviderNotFound)
+	}
+	// 失败的设置不影响原有的默认服务和路由
+	if got, err := registry.ForStyle("anime"); err != nil || got.Name() != "mock" {
+		t.Errorf("ForStyle() after failed updates = %v, %v; want mock", got, err)
+	}
+}
@@ -8,24 +8,28 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"time"
+
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai"
 )
 
+const ProviderName = "sora"
+
 var (
 	ErrAPIKeyRequired  = errors.New("sora api key is required")
 	ErrBaseURLRequired = errors.New("sora base url is required")
 	ErrInvalidResponse = errors.New("invalid response from sora api")
-	ErrRateLimited     = errors.New("rate limited by sora api")
+	ErrRateLimited     = ai.ErrRateLimited
 	ErrVideoProcessing = errors.New("video is still processing")
 )
 
 type Client struct {
 	apiKey     string
 	baseURL    string
-	httpClient *http.Client
+	httpClient *ai.HTTPClient
 }
 
-func NewClient(baseURL, apiKey string) (*Client, error) {
+// NewClient 创建 Sora 视频生成客户端，retry 为请求失败时的重试策略
+func NewClient(baseURL, apiKey string, retry ai.RetryPolicy) (*Client, error) {
 	if apiKey == "" {
 		return nil, ErrAPIKeyRequired
 	}
@@ -34,33 +38,22 @@ func NewClient(baseURL, apiKey string) (*Client, error) {
 	}
 
 	return &Client{
-		apiKey:  apiKey,
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 120 * time.Second,
-		},
+		apiKey:     apiKey,
+		baseURL:    baseURL,
+		httpClient: ai.NewHTTPClient(ProviderName, retry),
 	}, nil
 }
 
-type ImageToVideoRequest struct {
-	ImageURL string
-	Prompt   string
-	Duration int
-	Width    int
-	Height   int
-}
+type ImageToVideoRequest = ai.ImageToVideoRequest
 
-type TextToVideoRequest struct {
-	Prompt   string
-	Duration int
-	Width    int
-	Height   int
-}
+type TextToVideoRequest = ai.TextToVideoRequest
 
-type VideoGenerationResponse struct {
-	ID     string `json:"id"`
-	Status string `json:"status"`
-	URL    string `json:"url"`
+type VideoGenerationResponse = ai.VideoGenerationResponse
+
+var _ ai.VideoGenerator = (*Client)(nil)
+
+func (c *Client) Name() string {
+	return ProviderName
 }
 
 func (c *Client) ImageToVideo(ctx context.Context, req ImageToVideoRequest) (string, error) {
@@ -121,21 +114,35 @@ func (c *Client) GetVideoStatus(ctx context.Context, videoID string) (*VideoGene
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(body))
-	}
-
-	var result VideoGenerationResponse
+	var result videoResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return &result, nil
+	return result.toResponse(), nil
+}
+
+// DownloadVideo 下载已完成视频的内容（状态中未提供下载地址时使用）
+func (c *Client) DownloadVideo(ctx context.Context, videoID string) (io.ReadCloser, error) {
+	url := fmt.Sprintf("%s/videos/%s/content", c.baseURL, videoID)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
 }
 
 func (c *Client) makeRequest(ctx context.Context, endpoint string, payload interface{}) (map[string]interface{}, error) {
@@ -156,19 +163,10 @@ func (c *Client) makeRequest(ctx context.Context, endpoint string, payload inter
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusTooManyRequests {
-		return nil, ErrRateLimited
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(body))
-	}
-
 	var result map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
@@ -0,0 +1,107 @@
+package sora
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai"
+)
+
+// videoResponse 视频任务查询结果，兼容官方接口（seconds/size/error 对象）和常见代理的字段格式
+type videoResponse struct {
+	ID       string          `json:"id"`
+	Status   string          `json:"status"`
+	URL      string          `json:"url"`
+	VideoURL string          `json:"video_url"`
+	Seconds  json.RawMessage `json:"seconds"`
+	Duration json.RawMessage `json:"duration"`
+	Size     string          `json:"size"`
+	Error    json.RawMessage `json:"error"`
+}
+
+func (r *videoResponse) toResponse() *ai.VideoGenerationResponse {
+	result := &ai.VideoGenerationResponse{
+		ID:     r.ID,
+		Status: normalizeStatus(r.Status),
+		URL:    r.URL,
+		Error:  parseError(r.Error),
+	}
+	if result.URL == "" {
+		result.URL = r.VideoURL
+	}
+
+	result.Duration = parseNumber(r.Seconds)
+	if result.Duration == 0 {
+		result.Duration = parseNumber(r.Duration)
+	}
+
+	if width, height, ok := strings.Cut(r.Size, "x"); ok {
+		result.Width, _ = strconv.Atoi(width)
+		result.Height, _ = strconv.Atoi(height)
+	}
+
+	if result.Status == ai.VideoStatusFailed && result.Error == "" {
+		result.Error = "video generation failed"
+	}
+
+	return result
+}
+
+// normalizeStatus 将服务返回的状态转换为统一的视频生成状态
+func normalizeStatus(status string) string {
+	switch strings.ToLower(status) {
+	case "completed", "succeeded", "success":
+		return ai.VideoStatusCompleted
+	case "failed", "error", "cancelled", "canceled", "expired":
+		return ai.VideoStatusFailed
+	case "queued", "pending", "submitted":
+		return ai.VideoStatusQueued
+	default:
+		return ai.VideoStatusInProgress
+	}
+}
+
+// parseNumber 解析数字或数字字符串（官方接口的 seconds 为字符串）
+func parseNumber(raw json.RawMessage) float64 {
+	if len(raw) == 0 {
+		return 0
+	}
+	var number float64
+	if err := json.Unmarshal(raw, &number); err == nil {
+		return number
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		number, _ = strconv.ParseFloat(strings.TrimSuffix(text, "s"), 64)
+	}
+	return number
+}
+
+// parseError 解析错误信息，支持 {"code": "...", "message": "..."} 对象和字符串
+func parseError(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+
+	var detail struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(raw, &detail); err == nil {
+		switch {
+		case detail.Code != "" && detail.Message != "":
+			return detail.Code + ": " + detail.Message
+		case detail.Message != "":
+			return detail.Message
+		default:
+			return detail.Code
+		}
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	return string(raw)
+}
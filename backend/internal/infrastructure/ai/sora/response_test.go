@@ -0,0 +1,54 @@
+package sora
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai"
+)
+
+func TestVideoResponseToResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want ai.VideoGenerationResponse
+	}{
+		{
+			name: "official completed",
+			body: `{"id":"video_1","status":"completed","seconds":"8","size":"1280x720"}`,
+			want: ai.VideoGenerationResponse{ID: "video_1", Status: ai.VideoStatusCompleted, Duration: 8, Width: 1280, Height: 720},
+		},
+		{
+			name: "official failed",
+			body: `{"id":"video_2","status":"failed","error":{"code":"moderation_blocked","message":"Your request was blocked"}}`,
+			want: ai.VideoGenerationResponse{ID: "video_2", Status: ai.VideoStatusFailed, Error: "moderation_blocked: Your request was blocked"},
+		},
+		{
+			name: "proxy with url",
+			body: `{"id":"task-3","status":"succeeded","video_url":"https://cdn.example.com/v.mp4","duration":5}`,
+			want: ai.VideoGenerationResponse{ID: "task-3", Status: ai.VideoStatusCompleted, URL: "https://cdn.example.com/v.mp4", Duration: 5},
+		},
+		{
+			name: "failed without detail",
+			body: `{"id":"task-4","status":"error"}`,
+			want: ai.VideoGenerationResponse{ID: "task-4", Status: ai.VideoStatusFailed, Error: "video generation failed"},
+		},
+		{
+			name: "in progress",
+			body: `{"id":"video_5","status":"in_progress","progress":40}`,
+			want: ai.VideoGenerationResponse{ID: "video_5", Status: ai.VideoStatusInProgress},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var raw videoResponse
+			if err := json.Unmarshal([]byte(tt.body), &raw); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if got := raw.toResponse(); *got != tt.want {
+				t.Errorf("toResponse() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package ai
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrEmptySpeechText = errors.New("speech text cannot be empty")
+
+// SpeechRequest 语音合成请求，音色参数来自角色的语音配置
+type SpeechRequest struct {
+	Text     string
+	Speaker  string  // 说话人，未指定音色时服务可据此区分不同角色
+	Voice    string  // 服务的音色名称，空值使用服务默认音色
+	Language string  // BCP 47 语言标签，例如 zh-CN
+	Speed    float64 // 语速倍率，1 为正常语速
+	Pitch    float64 // 音高偏移（半音），0 为原始音高
+	Emotion  string  // 情绪提示，不支持的服务忽略
+}
+
+// SpeechResult 合成的音频
+type SpeechResult struct {
+	Audio    []byte
+	MimeType string
+	Duration float64 // 秒
+	Model    string
+}
+
+// SpeechSynthesizer 语音合成（TTS）服务接口，同步返回音频数据
+type SpeechSynthesizer interface {
+	Name() string
+	Synthesize(ctx context.Context, req SpeechRequest) (*SpeechResult, error)
+}
@@ -0,0 +1,288 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrRateLimited     = errors.New("rate limited by ai provider")
+	ErrContentFiltered = errors.New("content filtered by ai provider")
+	ErrUnauthorized    = errors.New("ai provider rejected credentials")
+	ErrProviderError   = errors.New("ai provider error")
+)
+
+// contentFilterMarkers 错误响应中表示内容被安全策略拦截的关键字
+var contentFilterMarkers = []string{
+	"content_policy",
+	"content_filter",
+	"moderation",
+	"safety",
+	"prohibited_content",
+}
+
+// APIError AI 服务返回的非 2xx 响应，通过 errors.Is 可判断错误类别
+// （ErrRateLimited、ErrContentFiltered、ErrUnauthorized、ErrProviderError）
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // 服务通过 Retry-After 要求的等待时间
+	kind       error
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s api error (status %d): %s", e.Provider, e.StatusCode, e.Body)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.kind
+}
+
+// Retryable 限流、服务端错误和请求超时可以重试，内容拦截、鉴权失败等其他 4xx 错误重试也不会成功
+func (e *APIError) Retryable() bool {
+	if errors.Is(e.kind, ErrContentFiltered) || errors.Is(e.kind, ErrUnauthorized) {
+		return false
+	}
+	return e.StatusCode == http.StatusTooManyRequests ||
+		e.StatusCode == http.StatusRequestTimeout ||
+		e.StatusCode >= 500
+}
+
+// IsRetryable 判断 AI 调用失败后是否值得重试：网络错误、可重试的 API 错误和服务熔断返回 true，
+// ctx 取消以及内容拦截、鉴权失败等终止性错误返回 false
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+	if errors.Is(err, ErrContentFiltered) || errors.Is(err, ErrUnauthorized) {
+		return false
+	}
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrCircuitOpen) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// RetryPolicy AI 调用的重试策略
+type RetryPolicy struct {
+	MaxAttempts    int           // 最多尝试次数（含首次），<= 1 时不重试
+	BaseDelay      time.Duration // 首次重试前的基准等待时间，之后每次翻倍
+	MaxDelay       time.Duration // 单次等待时间上限
+	AttemptTimeout time.Duration // 单次请求的超时时间，ctx 的截止时间更早时以 ctx 为准
+}
+
+// DefaultRetryPolicy 默认重试策略
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		BaseDelay:      time.Second,
+		MaxDelay:       30 * time.Second,
+		AttemptTimeout: 2 * time.Minute,
+	}
+}
+
+// backoff 第 attempt 次重试前的等待时间：在 [d/2, d] 内随机，d = BaseDelay * 2^(attempt-1)，不超过 MaxDelay
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// HTTPClient AI 服务共用的 HTTP 调用层：按策略重试可重试的失败，遵循 Retry-After，
+// 并将非 2xx 响应转换为 *APIError。
+// 生成请求不是幂等的，请求已送达但响应丢失时重试可能重复提交，由调用方按需去重
+type HTTPClient struct {
+	provider string
+	policy   RetryPolicy
+	client   *http.Client
+	sleep    func(ctx context.Context, d time.Duration) error
+}
+
+func NewHTTPClient(provider string, policy RetryPolicy) *HTTPClient {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 1
+	}
+	if policy.MaxDelay < policy.BaseDelay {
+		policy.MaxDelay = policy.BaseDelay
+	}
+
+	return &HTTPClient{
+		provider: provider,
+		policy:   policy,
+		// 超时由每次请求的 ctx 控制，不使用固定的 http.Client.Timeout
+		client: &http.Client{},
+		sleep:  sleepContext,
+	}
+}
+
+// Do 发送请求并在可重试的失败后重新发送，请求体需支持 GetBody（bytes.Buffer/Reader 创建的请求均支持）。
+// 成功时返回 2xx 响应，调用方负责关闭 Body；失败时返回最后一次的错误
+func (c *HTTPClient) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		resp, err := c.attempt(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%w (last error: %v)", ctx.Err(), lastErr)
+		}
+		if attempt >= c.policy.MaxAttempts || !IsRetryable(err) {
+			return nil, lastErr
+		}
+
+		delay := c.policy.backoff(attempt)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			// 服务要求的等待时间超过上限时提前重试只会再次被限流，直接返回
+			if apiErr.RetryAfter > c.policy.MaxDelay {
+				return nil, lastErr
+			}
+			delay = apiErr.RetryAfter
+		}
+
+		// 剩余时间不足以等待到下次重试时直接返回
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+			return nil, lastErr
+		}
+		if err := c.sleep(ctx, delay); err != nil {
+			return nil, fmt.Errorf("%w (last error: %v)", err, lastErr)
+		}
+	}
+}
+
+func (c *HTTPClient) attempt(ctx context.Context, req *http.Request) (*http.Response, error) {
+	attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+	if c.policy.AttemptTimeout > 0 {
+		attemptCtx, cancel = context.WithTimeout(ctx, c.policy.AttemptTimeout)
+	}
+
+	attemptReq := req.Clone(attemptCtx)
+	if req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to reset request body: %w", err)
+		}
+		attemptReq.Body = body
+	}
+
+	resp, err := c.client.Do(attemptReq)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer cancel()
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, newAPIError(c.provider, resp, strings.TrimSpace(string(body)))
+	}
+
+	// 响应体读取完毕（Close）后才结束本次请求的 ctx
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+func newAPIError(provider string, resp *http.Response, body string) *APIError {
+	apiErr := &APIError{
+		Provider:   provider,
+		StatusCode: resp.StatusCode,
+		Body:       body,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		kind:       ErrProviderError,
+	}
+
+	lowerBody := strings.ToLower(body)
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		apiErr.kind = ErrRateLimited
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		apiErr.kind = ErrUnauthorized
+	case resp.StatusCode < 500 && containsAny(lowerBody, contentFilterMarkers):
+		apiErr.kind = ErrContentFiltered
+	}
+
+	return apiErr
+}
+
+// parseRetryAfter 支持秒数和 HTTP 日期两种格式
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+func containsAny(s string, markers []string) bool {
+	for _, marker := range markers {
+		if strings.Contains(s, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
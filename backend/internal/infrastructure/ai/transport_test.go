@@ -0,0 +1,221 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestHTTPClient 记录等待时间而不真正等待
+func newTestHTTPClient(policy RetryPolicy) (*HTTPClient, *[]time.Duration) {
+	var sleeps []time.Duration
+	client := NewHTTPClient("test", policy)
+	client.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return ctx.Err()
+	}
+	return client, &sleeps
+}
+
+func postJSON(t *testing.T, ctx context.Context, url string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBufferString(`{"prompt":"a"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func TestHTTPClientRetriesServerErrors(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"prompt":"a"}` {
+			t.Errorf("attempt %d body = %q, want request body resent", attempts.Load()+1, body)
+		}
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	client, sleeps := newTestHTTPClient(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: 10 * time.Second})
+
+	resp, err := client.Do(postJSON(t, context.Background(), server.URL))
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(body) != `{"ok":true}` || attempts.Load() != 3 {
+		t.Errorf("body = %q after %d attempts, want success on attempt 3", body, attempts.Load())
+	}
+	if len(*sleeps) != 2 {
+		t.Fatalf("sleeps = %v, want 2 backoffs", *sleeps)
+	}
+	if d := (*sleeps)[0]; d < 500*time.Millisecond || d > time.Second {
+		t.Errorf("first backoff = %s, want within [0.5s, 1s]", d)
+	}
+	if d := (*sleeps)[1]; d < time.Second || d > 2*time.Second {
+		t.Errorf("second backoff = %s, want within [1s, 2s]", d)
+	}
+}
+
+func TestHTTPClientHonoursRetryAfter(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, sleeps := newTestHTTPClient(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Second, MaxDelay: time.Minute})
+
+	resp, err := client.Do(postJSON(t, context.Background(), server.URL))
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if len(*sleeps) != 1 || (*sleeps)[0] != 7*time.Second {
+		t.Errorf("sleeps = %v, want [7s] from Retry-After", *sleeps)
+	}
+}
+
+func TestHTTPClientGivesUpWhenRetryAfterExceedsMaxDelay(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client, sleeps := newTestHTTPClient(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: time.Minute})
+
+	_, err := client.Do(postJSON(t, context.Background(), server.URL))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Do() error = %v, want 429 APIError", err)
+	}
+	if attempts.Load() != 1 || len(*sleeps) != 0 {
+		t.Errorf("attempts = %d, sleeps = %v, want a single attempt without waiting", attempts.Load(), *sleeps)
+	}
+}
+
+func TestHTTPClientDoesNotRetryTerminalErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{"content filtered", http.StatusBadRequest, `{"error":{"code":"content_policy_violation"}}`, ErrContentFiltered},
+		{"unauthorized", http.StatusUnauthorized, `{"error":"invalid api key"}`, ErrUnauthorized},
+		{"bad request", http.StatusBadRequest, `{"error":"invalid size"}`, ErrProviderError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client, _ := newTestHTTPClient(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second})
+
+			_, err := client.Do(postJSON(t, context.Background(), server.URL))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Do() error = %v, want %v", err, tt.want)
+			}
+			if IsRetryable(err) || attempts.Load() != 1 {
+				t.Errorf("retryable = %v after %d attempts, want terminal after 1", IsRetryable(err), attempts.Load())
+			}
+		})
+	}
+}
+
+func TestHTTPClientRetriesAttemptTimeout(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		if attempts.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, _ := newTestHTTPClient(RetryPolicy{MaxAttempts: 2, AttemptTimeout: 50 * time.Millisecond})
+
+	resp, err := client.Do(postJSON(t, context.Background(), server.URL))
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+	if attempts.Load() != 2 {
+		t.Errorf("attempts = %d, want retry after per-attempt timeout", attempts.Load())
+	}
+}
+
+func TestHTTPClientStopsWhenDeadlineTooClose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, sleeps := newTestHTTPClient(RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := client.Do(postJSON(t, ctx, server.URL))
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Do() error = %v, want the 503 API error", err)
+	}
+	if len(*sleeps) != 0 {
+		t.Errorf("sleeps = %v, want no wait beyond the ctx deadline", *sleeps)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"120", 2 * time.Minute},
+		{"Mon, 01 Jan 2024 12:00:30 GMT", 30 * time.Second},
+		{"Mon, 01 Jan 2024 11:00:00 GMT", 0},
+		{"soon", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package ai
+
+import (
+	"context"
+	"io"
+)
+
+// 视频生成状态，各服务返回的状态统一转换为以下取值
+const (
+	VideoStatusQueued     = "queued"
+	VideoStatusInProgress = "in_progress"
+	VideoStatusCompleted  = "completed"
+	VideoStatusFailed     = "failed"
+)
+
+type ImageToVideoRequest struct {
+	ImageURL string
+	Prompt   string
+	Duration int
+	Width    int
+	Height   int
+}
+
+type TextToVideoRequest struct {
+	Prompt   string
+	Duration int
+	Width    int
+	Height   int
+}
+
+type VideoGenerationResponse struct {
+	ID       string  `json:"id"`
+	Status   string  `json:"status"`
+	URL      string  `json:"url"`                // 完成后的下载地址，服务未提供时通过 DownloadVideo 下载
+	Duration float64 `json:"duration,omitempty"` // 视频时长（秒）
+	Width    int     `json:"width,omitempty"`
+	Height   int     `json:"height,omitempty"`
+	Error    string  `json:"error,omitempty"` // 生成失败时服务返回的错误信息
+}
+
+// VideoGenerator 视频生成服务接口，视频为异步生成，提交后返回任务ID
+type VideoGenerator interface {
+	Name() string
+	ImageToVideo(ctx context.Context, req ImageToVideoRequest) (string, error)
+	TextToVideo(ctx context.Context, req TextToVideoRequest) (string, error)
+	GetVideoStatus(ctx context.Context, videoID string) (*VideoGenerationResponse, error)
+	DownloadVideo(ctx context.Context, videoID string) (io.ReadCloser, error)
+}
@@ -0,0 +1,81 @@
+package comicbook
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/color"
+	"image/jpeg"
+	"time"
+)
+
+var (
+	ErrNoPages = errors.New("book has no pages")
+	ErrNotJPEG = errors.New("page is not a JPEG image")
+)
+
+// Book 待打包的漫画，页面按阅读顺序排列，每页为一张 JPEG 图片
+type Book struct {
+	ID          string // 唯一标识，写入 EPUB 的 dc:identifier
+	Title       string
+	Author      string
+	Language    string // BCP 47 语言代码，为空时使用 zh
+	RightToLeft bool   // 从右到左翻页
+	Modified    time.Time
+	Pages       [][]byte
+}
+
+// pageInfo 页面图片的尺寸和颜色空间
+type pageInfo struct {
+	Width      int
+	Height     int
+	Components int // 颜色通道数：1 灰度，3 RGB，4 CMYK
+}
+
+// inspect 校验所有页面均为 JPEG 并读取尺寸
+func (b *Book) inspect() ([]pageInfo, error) {
+	if len(b.Pages) == 0 {
+		return nil, ErrNoPages
+	}
+
+	infos := make([]pageInfo, 0, len(b.Pages))
+	for i, data := range b.Pages {
+		if !bytes.HasPrefix(data, []byte{0xFF, 0xD8}) {
+			return nil, fmt.Errorf("%w: page %d", ErrNotJPEG, i+1)
+		}
+		config, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: page %d: %v", ErrNotJPEG, i+1, err)
+		}
+
+		info := pageInfo{Width: config.Width, Height: config.Height, Components: 3}
+		switch config.ColorModel {
+		case color.GrayModel:
+			info.Components = 1
+		case color.CMYKModel:
+			info.Components = 4
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (b *Book) language() string {
+	if b.Language == "" {
+		return "zh"
+	}
+	return b.Language
+}
+
+func (b *Book) modified() time.Time {
+	if b.Modified.IsZero() {
+		return time.Now()
+	}
+	return b.Modified
+}
+
+// pageName 页面文件名，补零保证按文件名排序即为阅读顺序
+func pageName(index, total int) string {
+	width := max(3, len(fmt.Sprint(total)))
+	return fmt.Sprintf("%0*d", width, index+1)
+}
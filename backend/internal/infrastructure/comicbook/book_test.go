@@ -0,0 +1,73 @@
+package comicbook
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+	"time"
+)
+
+// testJPEG 生成指定尺寸的纯色 JPEG
+func testJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xC0
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func testBook(t *testing.T) *Book {
+	t.Helper()
+	return &Book{
+		ID:          "3f8a7c1e-0000-4000-8000-000000000001",
+		Title:       "小红帽 & 大灰狼",
+		Author:      "格林兄弟",
+		RightToLeft: true,
+		Modified:    time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC),
+		Pages:       [][]byte{testJPEG(t, 80, 120), testJPEG(t, 100, 60)},
+	}
+}
+
+func TestInspectRejectsInvalidPages(t *testing.T) {
+	if _, err := (&Book{}).inspect(); !errors.Is(err, ErrNoPages) {
+		t.Errorf("empty book error = %v, want ErrNoPages", err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	book := &Book{Pages: [][]byte{testJPEG(t, 4, 4), buf.Bytes()}}
+	if _, err := book.inspect(); !errors.Is(err, ErrNotJPEG) {
+		t.Errorf("png page error = %v, want ErrNotJPEG", err)
+	}
+}
+
+func TestInspectReadsColorComponents(t *testing.T) {
+	var buf bytes.Buffer
+	gray := image.NewGray(image.Rect(0, 0, 8, 6))
+	gray.Set(1, 1, color.Gray{Y: 10})
+	if err := jpeg.Encode(&buf, gray, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	infos, err := (&Book{Pages: [][]byte{buf.Bytes(), testJPEG(t, 3, 5)}}).inspect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []pageInfo{{8, 6, 1}, {3, 5, 3}}
+	for i := range want {
+		if infos[i] != want[i] {
+			t.Errorf("page %d = %+v, want %+v", i+1, infos[i], want[i])
+		}
+	}
+}
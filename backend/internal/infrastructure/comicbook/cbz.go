@@ -0,0 +1,91 @@
+package comicbook
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// comicInfo ComicRack 的 ComicInfo.xml（v2.0），阅读器据此显示标题、作者和翻页方向
+type comicInfo struct {
+	XMLName     xml.Name        `xml:"ComicInfo"`
+	XSI         string          `xml:"xmlns:xsi,attr"`
+	XSD         string          `xml:"xmlns:xsd,attr"`
+	Title       string          `xml:"Title,omitempty"`
+	Writer      string          `xml:"Writer,omitempty"`
+	Year        int             `xml:"Year,omitempty"`
+	Month       int             `xml:"Month,omitempty"`
+	Day         int             `xml:"Day,omitempty"`
+	PageCount   int             `xml:"PageCount"`
+	LanguageISO string          `xml:"LanguageISO,omitempty"`
+	Manga       string          `xml:"Manga"`
+	Pages       []comicInfoPage `xml:"Pages>Page"`
+}
+
+type comicInfoPage struct {
+	Image       int    `xml:"Image,attr"`
+	Type        string `xml:"Type,attr,omitempty"`
+	ImageSize   int    `xml:"ImageSize,attr"`
+	ImageWidth  int    `xml:"ImageWidth,attr"`
+	ImageHeight int    `xml:"ImageHeight,attr"`
+}
+
+// WriteCBZ 写入 CBZ：按页码命名的 JPEG 图片和 ComicInfo.xml。
+// 图片已经压缩，以不压缩方式存入
+func WriteCBZ(w io.Writer, book *Book) error {
+	infos, err := book.inspect()
+	if err != nil {
+		return err
+	}
+
+	modified := book.modified()
+	info := comicInfo{
+		XSI:         "http://www.w3.org/2001/XMLSchema-instance",
+		XSD:         "http://www.w3.org/2001/XMLSchema",
+		Title:       book.Title,
+		Writer:      book.Author,
+		Year:        modified.Year(),
+		Month:       int(modified.Month()),
+		Day:         modified.Day(),
+		PageCount:   len(book.Pages),
+		LanguageISO: book.language(),
+		Manga:       "No",
+	}
+	if book.RightToLeft {
+		info.Manga = "YesAndRightToLeft"
+	}
+	for i, page := range infos {
+		p := comicInfoPage{
+			Image:       i,
+			ImageSize:   len(book.Pages[i]),
+			ImageWidth:  page.Width,
+			ImageHeight: page.Height,
+		}
+		if i == 0 {
+			p.Type = "FrontCover"
+		}
+		info.Pages = append(info.Pages, p)
+	}
+
+	zw := zip.NewWriter(w)
+	for i, data := range book.Pages {
+		if err := writeZipEntry(zw, pageName(i, len(book.Pages))+".jpg", data, zip.Store, modified); err != nil {
+			return err
+		}
+	}
+
+	metadata, err := xml.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal ComicInfo.xml: %w", err)
+	}
+	metadata = append([]byte(xml.Header), metadata...)
+	if err := writeZipEntry(zw, "ComicInfo.xml", metadata, zip.Deflate, modified); err != nil {
+		return err
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package comicbook
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+)
+
+func TestWriteCBZ(t *testing.T) {
+	book := testBook(t)
+	var buf bytes.Buffer
+	if err := WriteCBZ(&buf, book); err != nil {
+		t.Fatalf("WriteCBZ() error = %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	var info comicInfo
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		if f.Name != "ComicInfo.xml" {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(r)
+		r.Close()
+		if err := xml.Unmarshal(data, &info); err != nil {
+			t.Fatalf("invalid ComicInfo.xml: %v", err)
+		}
+	}
+
+	wantNames := []string{"001.jpg", "002.jpg", "ComicInfo.xml"}
+	if len(names) != len(wantNames) {
+		t.Fatalf("entries = %v, want %v", names, wantNames)
+	}
+	for i := range wantNames {
+		if names[i] != wantNames[i] {
+			t.Errorf("entry %d = %q, want %q", i, names[i], wantNames[i])
+		}
+	}
+
+	if info.Title != book.Title || info.Writer != book.Author {
+		t.Errorf("Title/Writer = %q/%q, want %q/%q", info.Title, info.Writer, book.Title, book.Author)
+	}
+	if info.Manga != "YesAndRightToLeft" || info.PageCount != 2 || info.Year != 2026 {
+		t.Errorf("ComicInfo = %+v", info)
+	}
+	if len(info.Pages) != 2 || info.Pages[0].Type != "FrontCover" || info.Pages[1].ImageWidth != 100 || info.Pages[1].ImageHeight != 60 {
+		t.Errorf("Pages = %+v", info.Pages)
+	}
+}
@@ -0,0 +1,143 @@
+package comicbook
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const epubContainer = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+// WriteEPUB 写入固定版式（pre-paginated）的 EPUB 3：每页一个 XHTML 文档，视口为图片尺寸，
+// 第一页作为封面。mimetype 必须是第一个条目且不压缩
+func WriteEPUB(w io.Writer, book *Book) error {
+	infos, err := book.inspect()
+	if err != nil {
+		return err
+	}
+
+	modified := book.modified()
+	zw := zip.NewWriter(w)
+
+	// mimetype 条目不能带扩展字段，因此不设置修改时间
+	if err := writeZipEntry(zw, "mimetype", []byte("application/epub+zip"), zip.Store, time.Time{}); err != nil {
+		return err
+	}
+	if err := writeZipEntry(zw, "META-INF/container.xml", []byte(epubContainer), zip.Deflate, modified); err != nil {
+		return err
+	}
+
+	title := book.Title
+	if title == "" {
+		title = "Untitled"
+	}
+
+	var manifest, spine bytes.Buffer
+	for i, info := range infos {
+		name := pageName(i, len(infos))
+
+		imageProperties := ""
+		if i == 0 {
+			imageProperties = ` properties="cover-image"`
+		}
+		fmt.Fprintf(&manifest, "    <item id=\"image-%s\" href=\"images/%s.jpg\" media-type=\"image/jpeg\"%s/>\n", name, name, imageProperties)
+		fmt.Fprintf(&manifest, "    <item id=\"page-%s\" href=\"pages/%s.xhtml\" media-type=\"application/xhtml+xml\"/>\n", name, name)
+		fmt.Fprintf(&spine, "    <itemref idref=\"page-%s\"/>\n", name)
+
+		if err := writeZipEntry(zw, "OEBPS/images/"+name+".jpg", book.Pages[i], zip.Store, modified); err != nil {
+			return err
+		}
+		page := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head>
+  <meta charset="UTF-8"/>
+  <title>%s - %d</title>
+  <meta name="viewport" content="width=%d, height=%d"/>
+  <style>html, body { margin: 0; padding: 0; } img { display: block; width: %dpx; height: %dpx; }</style>
+</head>
+<body>
+  <img src="../images/%s.jpg" alt="%d"/>
+</body>
+</html>
+`, xmlText(title), i+1, info.Width, info.Height, info.Width, info.Height, name, i+1)
+		if err := writeZipEntry(zw, "OEBPS/pages/"+name+".xhtml", []byte(page), zip.Deflate, modified); err != nil {
+			return err
+		}
+	}
+
+	first := pageName(0, len(infos))
+	nav := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head>
+  <meta charset="UTF-8"/>
+  <title>%s</title>
+</head>
+<body>
+  <nav epub:type="toc">
+    <ol>
+      <li><a href="pages/%s.xhtml">%s</a></li>
+    </ol>
+  </nav>
+</body>
+</html>
+`, xmlText(title), first, xmlText(title))
+	if err := writeZipEntry(zw, "OEBPS/nav.xhtml", []byte(nav), zip.Deflate, modified); err != nil {
+		return err
+	}
+
+	direction := "ltr"
+	if book.RightToLeft {
+		direction = "rtl"
+	}
+	var creator string
+	if book.Author != "" {
+		creator = fmt.Sprintf("\n    <dc:creator>%s</dc:creator>", xmlText(book.Author))
+	}
+	opf := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" prefix="rendition: http://www.idpf.org/vocab/rendition/#">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">urn:uuid:%s</dc:identifier>
+    <dc:title>%s</dc:title>%s
+    <dc:language>%s</dc:language>
+    <meta property="dcterms:modified">%s</meta>
+    <meta property="rendition:layout">pre-paginated</meta>
+    <meta property="rendition:orientation">auto</meta>
+    <meta property="rendition:spread">none</meta>
+    <meta name="cover" content="image-%s"/>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+%s  </manifest>
+  <spine page-progression-direction="%s">
+%s  </spine>
+</package>
+`, xmlText(book.ID), xmlText(title), creator, xmlText(book.language()),
+		modified.UTC().Format("2006-01-02T15:04:05Z"), first, manifest.String(), direction, spine.String())
+	if err := writeZipEntry(zw, "OEBPS/content.opf", []byte(opf), zip.Deflate, modified); err != nil {
+		return err
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return nil
+}
+
+// xmlText 转义 XML 文本和属性值
+func xmlText(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
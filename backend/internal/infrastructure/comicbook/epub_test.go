@@ -0,0 +1,63 @@
+package comicbook
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWriteEPUB(t *testing.T) {
+	book := testBook(t)
+	var buf bytes.Buffer
+	if err := WriteEPUB(&buf, book); err != nil {
+		t.Fatalf("WriteEPUB() error = %v", err)
+	}
+
+	// OCF 要求 mimetype 位于文件开头、不压缩且没有扩展字段
+	if !bytes.HasPrefix(buf.Bytes()[30:], []byte("mimetypeapplication/epub+zip")) {
+		t.Errorf("mimetype is not the first stored entry")
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(r)
+		r.Close()
+		files[f.Name] = string(data)
+	}
+	if zr.File[0].Method != zip.Store {
+		t.Errorf("mimetype method = %d, want Store", zr.File[0].Method)
+	}
+
+	for _, name := range []string{"META-INF/container.xml", "OEBPS/nav.xhtml", "OEBPS/images/001.jpg", "OEBPS/pages/002.xhtml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("missing %s", name)
+		}
+	}
+
+	opf := files["OEBPS/content.opf"]
+	for _, want := range []string{
+		"<dc:title>小红帽 &amp; 大灰狼</dc:title>",
+		"<dc:creator>格林兄弟</dc:creator>",
+		`<meta property="rendition:layout">pre-paginated</meta>`,
+		`page-progression-direction="rtl"`,
+		`properties="cover-image"`,
+		"<meta property=\"dcterms:modified\">2026-10-17T08:00:00Z</meta>",
+	} {
+		if !strings.Contains(opf, want) {
+			t.Errorf("content.opf does not contain %q", want)
+		}
+	}
+	if !strings.Contains(files["OEBPS/pages/002.xhtml"], `content="width=100, height=60"`) {
+		t.Errorf("page viewport missing: %s", files["OEBPS/pages/002.xhtml"])
+	}
+}
@@ -0,0 +1,153 @@
+package comicbook
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// maxPDFPageSize PDF 页面边长上限（点），超过时按比例缩小页面
+const maxPDFPageSize = 14400
+
+// pdfColorSpaces JPEG 颜色通道数对应的 PDF 颜色空间
+var pdfColorSpaces = map[int]string{
+	1: "/DeviceGray",
+	3: "/DeviceRGB",
+	4: "/DeviceCMYK",
+}
+
+// WritePDF 写入 PDF：每页一张原样嵌入（DCTDecode）的 JPEG 图片，页面尺寸按 1 像素 = 1 点。
+// 从右到左翻页时在 ViewerPreferences 中声明阅读方向
+func WritePDF(w io.Writer, book *Book) error {
+	infos, err := book.inspect()
+	if err != nil {
+		return err
+	}
+
+	pw := &pdfWriter{w: bufio.NewWriter(w)}
+	pw.printf("%%PDF-1.7\n%%\xE2\xE3\xCF\xD3\n")
+
+	// 对象编号：1 目录，2 页面树，3 文档信息，之后每页依次为页面、内容流和图片
+	const firstPageObject = 4
+	pageObject := func(i int) int { return firstPageObject + i*3 }
+
+	catalog := "<< /Type /Catalog /Pages 2 0 R"
+	if book.RightToLeft {
+		catalog += " /ViewerPreferences << /Direction /R2L >>"
+	}
+	pw.object(1, catalog+" >>")
+
+	kids := make([]string, len(infos))
+	for i := range infos {
+		kids[i] = fmt.Sprintf("%d 0 R", pageObject(i))
+	}
+	pw.object(2, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(infos)))
+
+	pw.object(3, fmt.Sprintf("<< /Title %s /Author %s /Producer (AI-Motion) /CreationDate (D:%s) >>",
+		pdfText(book.Title), pdfText(book.Author), book.modified().UTC().Format("20060102150405Z")))
+
+	for i, info := range infos {
+		width, height := float64(info.Width), float64(info.Height)
+		if scale := maxPDFPageSize / max(width, height); scale < 1 {
+			width, height = width*scale, height*scale
+		}
+
+		pw.object(pageObject(i), fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /XObject << /Im0 %d 0 R >> >> /Contents %d 0 R >>",
+			width, height, pageObject(i)+2, pageObject(i)+1))
+
+		content := fmt.Sprintf("q %.2f 0 0 %.2f 0 0 cm /Im0 Do Q", width, height)
+		pw.stream(pageObject(i)+1, fmt.Sprintf("<< /Length %d >>", len(content)), []byte(content))
+
+		image := book.Pages[i]
+		pw.stream(pageObject(i)+2, fmt.Sprintf(
+			"<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace %s /BitsPerComponent 8 /Filter /DCTDecode /Length %d >>",
+			info.Width, info.Height, pdfColorSpaces[info.Components], len(image)), image)
+	}
+
+	pw.finish(pageObject(len(infos)), 1, 3)
+	if pw.err != nil {
+		return fmt.Errorf("failed to write pdf: %w", pw.err)
+	}
+	return nil
+}
+
+// pdfWriter 顺序写入 PDF 对象并记录偏移量，用于生成交叉引用表。写入错误保留到结束时返回
+type pdfWriter struct {
+	w       *bufio.Writer
+	offset  int
+	offsets map[int]int
+	err     error
+}
+
+func (pw *pdfWriter) write(data []byte) {
+	if pw.err != nil {
+		return
+	}
+	n, err := pw.w.Write(data)
+	pw.offset += n
+	pw.err = err
+}
+
+func (pw *pdfWriter) printf(format string, args ...any) {
+	pw.write([]byte(fmt.Sprintf(format, args...)))
+}
+
+func (pw *pdfWriter) begin(number int) {
+	if pw.offsets == nil {
+		pw.offsets = make(map[int]int)
+	}
+	pw.offsets[number] = pw.offset
+	pw.printf("%d 0 obj\n", number)
+}
+
+func (pw *pdfWriter) object(number int, body string) {
+	pw.begin(number)
+	pw.printf("%s\nendobj\n", body)
+}
+
+func (pw *pdfWriter) stream(number int, dict string, data []byte) {
+	pw.begin(number)
+	pw.printf("%s\nstream\n", dict)
+	pw.write(data)
+	pw.printf("\nendstream\nendobj\n")
+}
+
+// finish 写入交叉引用表和文件尾，next 为下一个未使用的对象编号
+func (pw *pdfWriter) finish(next, root, info int) {
+	xref := pw.offset
+	pw.printf("xref\n0 %d\n0000000000 65535 f \n", next)
+	for number := 1; number < next; number++ {
+		pw.printf("%010d 00000 n \n", pw.offsets[number])
+	}
+	pw.printf("trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", next, root, info, xref)
+
+	if pw.err == nil {
+		pw.err = pw.w.Flush()
+	}
+}
+
+// pdfText 文本字符串：ASCII 使用字面量，其他使用带 BOM 的 UTF-16BE 十六进制串
+func pdfText(s string) string {
+	ascii := true
+	for _, r := range s {
+		if r > 0x7E || r < 0x20 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		replacer := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`)
+		return "(" + replacer.Replace(s) + ")"
+	}
+
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, unit := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", unit)
+	}
+	b.WriteString(">")
+	return b.String()
+}
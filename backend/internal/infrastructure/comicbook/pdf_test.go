@@ -0,0 +1,69 @@
+package comicbook
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestWritePDF(t *testing.T) {
+	book := testBook(t)
+	var buf bytes.Buffer
+	if err := WritePDF(&buf, book); err != nil {
+		t.Fatalf("WritePDF() error = %v", err)
+	}
+	data := buf.Bytes()
+
+	if !bytes.HasPrefix(data, []byte("%PDF-1.7\n")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatalf("missing pdf header or trailer")
+	}
+	if n := bytes.Count(data, []byte("/Type /Page ")); n != 2 {
+		t.Errorf("page objects = %d, want 2", n)
+	}
+	for _, want := range []string{
+		"/MediaBox [0 0 80.00 120.00]",
+		"/MediaBox [0 0 100.00 60.00]",
+		"/Direction /R2L",
+		"/Title <FEFF", // 中文标题使用 UTF-16
+		"/Filter /DCTDecode",
+	} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("pdf does not contain %q", want)
+		}
+	}
+
+	// 交叉引用表中的偏移量必须指向对应对象
+	match := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	if match == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(match[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1)
+	if len(entries) != 9 {
+		t.Fatalf("xref entries = %d, want 9", len(entries))
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		want := fmt.Sprintf("%d 0 obj\n", i+1)
+		if !bytes.HasPrefix(data[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points to %q", i+1, data[offset:offset+10])
+		}
+	}
+}
+
+func TestPDFText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Hello (world)", `(Hello \(world\))`},
+		{"漫画", "<FEFF6F2B753B>"},
+		{"", "()"},
+	}
+	for _, tt := range tests {
+		if got := pdfText(tt.in); got != tt.want {
+			t.Errorf("pdfText(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
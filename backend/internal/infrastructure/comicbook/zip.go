@@ -0,0 +1,23 @@
+package comicbook
+
+import (
+	"archive/zip"
+	"fmt"
+	"time"
+)
+
+// writeZipEntry 写入一个压缩包条目，method 为 zip.Store 或 zip.Deflate
+func writeZipEntry(zw *zip.Writer, name string, data []byte, method uint16, modified time.Time) error {
+	entry, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   method,
+		Modified: modified,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", name, err)
+	}
+	if _, err := entry.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
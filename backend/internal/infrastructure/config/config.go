@@ -4,12 +4,20 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Supabase SupabaseConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	Supabase  SupabaseConfig
+	AI        AIConfig
+	Queue     QueueConfig
+	Storage   StorageConfig
+	Usage     UsageConfig
+	Lettering LetteringConfig
+	Video     VideoConfig
 }
 
 type ServerConfig struct {
@@ -30,15 +38,218 @@ type SupabaseConfig struct {
 	JWTSecret string
 }
 
+type AIConfig struct {
+	ImageProvider    string
+	ImageStyleRoutes map[string]string
+	VideoProvider    string
+	SpeechProvider   string // 台词配音使用的语音合成服务，目前只有离线的 mock
+	Mock             MockAIConfig
+	Retry            RetryConfig
+	Breaker          BreakerConfig
+}
+
+// RetryConfig AI 服务调用的重试和超时配置
+type RetryConfig struct {
+	MaxAttempts    int
+	BaseDelay      time.Duration
+	MaxDelay       time.Duration
+	RequestTimeout time.Duration // 单次请求超时，调用方 ctx 的截止时间更早时以 ctx 为准
+}
+
+// BreakerConfig AI 服务熔断配置：连续失败 FailureThreshold 次后熔断，Cooldown 后放行一次探测调用
+type BreakerConfig struct {
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+// MockAIConfig 离线模拟 AI 服务配置，启用后无需任何 API Key
+type MockAIConfig struct {
+	Enabled   bool
+	OutputDir string
+	PublicURL string
+}
+
+// QueueConfig 后台任务队列配置
+type QueueConfig struct {
+	Workers        int
+	PollInterval   time.Duration
+	LeaseDuration  time.Duration
+	ResumeMaxAge   time.Duration
+	ProviderLimits map[string]int
+
+	// 已提交视频的状态查询：每次查询后等待时间从 VideoPollInterval 开始翻倍，最长 VideoPollMaxBackoff
+	VideoPollInterval   time.Duration
+	VideoPollMaxBackoff time.Duration
+}
+
+// StorageConfig 文件存储配置，Backend 为 local（本地目录）或 s3（S3 兼容对象存储）
+type StorageConfig struct {
+	Backend   string
+	Path      string
+	PublicURL string
+	S3        S3Config
+
+	// 响应中返回的文件和媒体地址附加有时效的签名，浏览器无需 Authorization 头即可加载
+	URLSecret string
+	URLTTL    time.Duration
+}
+
+// S3Config S3 兼容对象存储（AWS S3、MinIO 等）的连接配置
+type S3Config struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	UsePathStyle    bool
+}
+
+// UsageConfig 用量计费和配额配置，价格单位为美元，限额为 0 表示不限制
+type UsageConfig struct {
+	ImagePrices          map[string]float64 // 每张图片的估算价格，按服务名配置
+	VideoPrices          map[string]float64 // 每秒视频的估算价格，按服务名配置
+	SpeechPrices         map[string]float64 // 每句配音的估算价格，按服务名配置
+	DailyGenerationLimit int                // 每个用户每天可成功生成的图片和视频数
+	MonthlyCostLimit     float64            // 每个用户每月的估算费用上限
+}
+
+// LetteringConfig 漫画嵌字配置
+type LetteringConfig struct {
+	FontPaths []string // 优先使用的字体文件（TTF/OTF/TTC），之后依次回退到系统 CJK 字体和内置拉丁字体
+}
+
+// VideoConfig 动态分镜视频渲染配置，Encoder 为 auto、ffmpeg 或 sequence（图片序列，不依赖 ffmpeg）
+type VideoConfig struct {
+	Encoder    string
+	FFmpegPath string
+}
+
 func Load() (*Config, error) {
 	dbPort, err := strconv.Atoi(getEnv("DB_PORT", "3306"))
 	if err != nil {
 		return nil, fmt.Errorf("invalid DB_PORT: %w", err)
 	}
 
+	mockEnabled, err := strconv.ParseBool(getEnv("AI_MOCK_ENABLED", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid AI_MOCK_ENABLED: %w", err)
+	}
+
+	port := getEnv("PORT", "8080")
+
+	retryMaxAttempts, err := strconv.Atoi(getEnv("AI_RETRY_MAX_ATTEMPTS", "3"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid AI_RETRY_MAX_ATTEMPTS: %w", err)
+	}
+
+	retryBaseDelay, err := time.ParseDuration(getEnv("AI_RETRY_BASE_DELAY", "1s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid AI_RETRY_BASE_DELAY: %w", err)
+	}
+
+	retryMaxDelay, err := time.ParseDuration(getEnv("AI_RETRY_MAX_DELAY", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid AI_RETRY_MAX_DELAY: %w", err)
+	}
+
+	requestTimeout, err := time.ParseDuration(getEnv("AI_REQUEST_TIMEOUT", "2m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid AI_REQUEST_TIMEOUT: %w", err)
+	}
+
+	breakerThreshold, err := strconv.Atoi(getEnv("AI_BREAKER_FAILURE_THRESHOLD", "5"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid AI_BREAKER_FAILURE_THRESHOLD: %w", err)
+	}
+
+	breakerCooldown, err := time.ParseDuration(getEnv("AI_BREAKER_COOLDOWN", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid AI_BREAKER_COOLDOWN: %w", err)
+	}
+
+	queueWorkers, err := strconv.Atoi(getEnv("QUEUE_WORKERS", "4"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid QUEUE_WORKERS: %w", err)
+	}
+
+	queuePollInterval, err := time.ParseDuration(getEnv("QUEUE_POLL_INTERVAL", "2s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid QUEUE_POLL_INTERVAL: %w", err)
+	}
+
+	queueLeaseDuration, err := time.ParseDuration(getEnv("QUEUE_LEASE_DURATION", "60s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid QUEUE_LEASE_DURATION: %w", err)
+	}
+
+	queueResumeMaxAge, err := time.ParseDuration(getEnv("QUEUE_RESUME_MAX_AGE", "24h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid QUEUE_RESUME_MAX_AGE: %w", err)
+	}
+
+	videoPollInterval, err := time.ParseDuration(getEnv("QUEUE_VIDEO_POLL_INTERVAL", "5s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid QUEUE_VIDEO_POLL_INTERVAL: %w", err)
+	}
+
+	videoPollMaxBackoff, err := time.ParseDuration(getEnv("QUEUE_VIDEO_POLL_MAX_BACKOFF", "2m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid QUEUE_VIDEO_POLL_MAX_BACKOFF: %w", err)
+	}
+
+	providerLimits, err := parseLimits(getEnv("AI_PROVIDER_CONCURRENCY", "gemini:2,sora:1"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid AI_PROVIDER_CONCURRENCY: %w", err)
+	}
+
+	storageBackend := getEnv("STORAGE_BACKEND", "local")
+	if storageBackend != "local" && storageBackend != "s3" {
+		return nil, fmt.Errorf("invalid STORAGE_BACKEND: %q (expected local or s3)", storageBackend)
+	}
+
+	s3PathStyle, err := strconv.ParseBool(getEnv("S3_USE_PATH_STYLE", "true"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3_USE_PATH_STYLE: %w", err)
+	}
+
+	storageURLTTL, err := time.ParseDuration(getEnv("STORAGE_URL_TTL", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid STORAGE_URL_TTL: %w", err)
+	}
+
+	videoEncoder := getEnv("VIDEO_ENCODER", "auto")
+	if videoEncoder != "auto" && videoEncoder != "ffmpeg" && videoEncoder != "sequence" {
+		return nil, fmt.Errorf("invalid VIDEO_ENCODER: %q (expected auto, ffmpeg or sequence)", videoEncoder)
+	}
+
+	imagePrices, err := parsePrices(getEnv("USAGE_IMAGE_PRICES", "gemini:0.039,mock:0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid USAGE_IMAGE_PRICES: %w", err)
+	}
+
+	videoPrices, err := parsePrices(getEnv("USAGE_VIDEO_PRICES", "sora:0.1,mock:0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid USAGE_VIDEO_PRICES: %w", err)
+	}
+
+	speechPrices, err := parsePrices(getEnv("USAGE_SPEECH_PRICES", "mock:0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid USAGE_SPEECH_PRICES: %w", err)
+	}
+
+	dailyGenerationLimit, err := strconv.Atoi(getEnv("USAGE_DAILY_GENERATION_LIMIT", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid USAGE_DAILY_GENERATION_LIMIT: %w", err)
+	}
+
+	monthlyCostLimit, err := strconv.ParseFloat(getEnv("USAGE_MONTHLY_COST_LIMIT", "0"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid USAGE_MONTHLY_COST_LIMIT: %w", err)
+	}
+
 	return &Config{
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8080"),
+			Port: port,
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -52,6 +263,65 @@ func Load() (*Config, error) {
 			APIKey:    getEnv("SUPABASE_API_KEY", ""),
 			JWTSecret: getEnv("SUPABASE_JWT_SECRET", ""),
 		},
+		AI: AIConfig{
+			ImageProvider:    getEnv("AI_IMAGE_PROVIDER", "gemini"),
+			ImageStyleRoutes: parseRoutes(getEnv("AI_IMAGE_STYLE_ROUTES", "")),
+			VideoProvider:    getEnv("AI_VIDEO_PROVIDER", "sora"),
+			SpeechProvider:   getEnv("AI_SPEECH_PROVIDER", "mock"),
+			Mock: MockAIConfig{
+				Enabled:   mockEnabled,
+				OutputDir: getEnv("AI_MOCK_OUTPUT_DIR", "./storage/mock"),
+				PublicURL: getEnv("AI_MOCK_PUBLIC_URL", "http://localhost:"+port+"/mock-assets"),
+			},
+			Retry: RetryConfig{
+				MaxAttempts:    retryMaxAttempts,
+				BaseDelay:      retryBaseDelay,
+				MaxDelay:       retryMaxDelay,
+				RequestTimeout: requestTimeout,
+			},
+			Breaker: BreakerConfig{
+				FailureThreshold: breakerThreshold,
+				Cooldown:         breakerCooldown,
+			},
+		},
+		Queue: QueueConfig{
+			Workers:             queueWorkers,
+			PollInterval:        queuePollInterval,
+			LeaseDuration:       queueLeaseDuration,
+			ResumeMaxAge:        queueResumeMaxAge,
+			ProviderLimits:      providerLimits,
+			VideoPollInterval:   videoPollInterval,
+			VideoPollMaxBackoff: videoPollMaxBackoff,
+		},
+		Storage: StorageConfig{
+			Backend:   storageBackend,
+			Path:      getEnv("STORAGE_PATH", "./storage"),
+			PublicURL: getEnv("STORAGE_PUBLIC_URL", "/files"),
+			S3: S3Config{
+				Endpoint:        getEnv("S3_ENDPOINT", ""),
+				Region:          getEnv("S3_REGION", "us-east-1"),
+				Bucket:          getEnv("S3_BUCKET", ""),
+				AccessKeyID:     getEnv("S3_ACCESS_KEY_ID", ""),
+				SecretAccessKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+				UsePathStyle:    s3PathStyle,
+			},
+			URLSecret: getEnv("STORAGE_URL_SECRET", ""),
+			URLTTL:    storageURLTTL,
+		},
+		Usage: UsageConfig{
+			ImagePrices:          imagePrices,
+			VideoPrices:          videoPrices,
+			SpeechPrices:         speechPrices,
+			DailyGenerationLimit: dailyGenerationLimit,
+			MonthlyCostLimit:     monthlyCostLimit,
+		},
+		Lettering: LetteringConfig{
+			FontPaths: parseList(getEnv("LETTERING_FONT_PATHS", "")),
+		},
+		Video: VideoConfig{
+			Encoder:    videoEncoder,
+			FFmpegPath: getEnv("FFMPEG_PATH", "ffmpeg"),
+		},
 	}, nil
 }
 
@@ -61,3 +331,53 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// parseRoutes 解析 "style:provider,style:provider" 格式的路由配置
+func parseRoutes(value string) map[string]string {
+	routes := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		key, target, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || key == "" || target == "" {
+			continue
+		}
+		routes[strings.TrimSpace(key)] = strings.TrimSpace(target)
+	}
+	return routes
+}
+
+// parseList 解析逗号分隔的列表，忽略空项
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseLimits 解析 "provider:n,provider:n" 格式的并发限制配置
+func parseLimits(value string) (map[string]int, error) {
+	limits := make(map[string]int)
+	for name, raw := range parseRoutes(value) {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid limit for %s: %w", name, err)
+		}
+		limits[name] = limit
+	}
+	return limits, nil
+}
+
+// parsePrices 解析 "provider:price,provider:price" 格式的价格配置
+func parsePrices(value string) (map[string]float64, error) {
+	prices := make(map[string]float64)
+	for name, raw := range parseRoutes(value) {
+		price, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid price for %s: %w", name, err)
+		}
+		prices[name] = price
+	}
+	return prices, nil
+}
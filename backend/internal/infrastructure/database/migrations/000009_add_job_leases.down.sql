@@ -0,0 +1,12 @@
+-- Rollback: Remove lease columns from task and media tables
+DROP INDEX IF EXISTS idx_aimotion_media_status_lease;
+
+ALTER TABLE aimotion_media
+DROP COLUMN IF EXISTS lease_expires_at,
+DROP COLUMN IF EXISTS lease_owner;
+
+DROP INDEX IF EXISTS idx_task_status_lease;
+
+ALTER TABLE aimotion_task
+DROP COLUMN IF EXISTS lease_expires_at,
+DROP COLUMN IF EXISTS lease_owner;
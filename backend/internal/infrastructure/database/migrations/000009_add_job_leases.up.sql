@@ -0,0 +1,18 @@
+-- Add lease columns so background workers can claim tasks and media exclusively
+ALTER TABLE aimotion_task
+ADD COLUMN IF NOT EXISTS lease_owner VARCHAR(100),
+ADD COLUMN IF NOT EXISTS lease_expires_at TIMESTAMPTZ NULL;
+
+COMMENT ON COLUMN aimotion_task.lease_owner IS '持有租约的 worker 标识';
+COMMENT ON COLUMN aimotion_task.lease_expires_at IS '租约过期时间，过期后任务可被其他 worker 领取';
+
+CREATE INDEX IF NOT EXISTS idx_task_status_lease ON aimotion_task(status, lease_expires_at);
+
+ALTER TABLE aimotion_media
+ADD COLUMN IF NOT EXISTS lease_owner VARCHAR(100),
+ADD COLUMN IF NOT EXISTS lease_expires_at TIMESTAMPTZ NULL;
+
+COMMENT ON COLUMN aimotion_media.lease_owner IS '持有租约的 worker 标识';
+COMMENT ON COLUMN aimotion_media.lease_expires_at IS '租约过期时间，过期后媒体生成任务可被其他 worker 领取';
+
+CREATE INDEX IF NOT EXISTS idx_aimotion_media_status_lease ON aimotion_media(status, lease_expires_at);
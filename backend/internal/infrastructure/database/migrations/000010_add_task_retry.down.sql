@@ -0,0 +1,4 @@
+-- Rollback: Remove retry tracking columns from task table
+ALTER TABLE aimotion_task
+DROP COLUMN IF EXISTS attempts,
+DROP COLUMN IF EXISTS retry_count;
@@ -0,0 +1,7 @@
+-- Add retry tracking columns to task table
+ALTER TABLE aimotion_task
+ADD COLUMN IF NOT EXISTS retry_count INT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS attempts JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+COMMENT ON COLUMN aimotion_task.retry_count IS '重试次数';
+COMMENT ON COLUMN aimotion_task.attempts IS '历次失败的执行记录（错误码、错误信息、失败时进度）';
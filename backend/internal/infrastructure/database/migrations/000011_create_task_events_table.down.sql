@@ -0,0 +1,2 @@
+-- Rollback: Drop task events table
+DROP TABLE IF EXISTS aimotion_task_event;
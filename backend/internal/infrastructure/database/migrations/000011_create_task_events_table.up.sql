@@ -0,0 +1,19 @@
+-- PostgreSQL migration: Create task events table for real-time progress streaming
+CREATE TABLE IF NOT EXISTS aimotion_task_event (
+    id BIGSERIAL PRIMARY KEY,
+    task_id UUID NOT NULL REFERENCES aimotion_task(id) ON DELETE CASCADE,
+    type VARCHAR(30) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Comments
+COMMENT ON TABLE aimotion_task_event IS '任务进度事件表，用于 SSE 推送和断线续传';
+COMMENT ON COLUMN aimotion_task_event.id IS '自增事件ID，作为 SSE 事件ID（Last-Event-ID）';
+COMMENT ON COLUMN aimotion_task_event.task_id IS '关联的任务ID';
+COMMENT ON COLUMN aimotion_task_event.type IS '事件类型:step_changed,progress,panel_completed,failed,completed,cancelled,requeued';
+COMMENT ON COLUMN aimotion_task_event.payload IS '事件内容（步骤、进度、面板图片URL、错误信息）';
+COMMENT ON COLUMN aimotion_task_event.created_at IS '创建时间';
+
+-- Indexes
+CREATE INDEX idx_task_event_task_id ON aimotion_task_event(task_id, id);
@@ -0,0 +1,3 @@
+-- Rollback: Remove generation options column from task table
+ALTER TABLE aimotion_task
+DROP COLUMN IF EXISTS options;
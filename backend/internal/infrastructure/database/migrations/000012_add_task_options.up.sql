@@ -0,0 +1,5 @@
+-- Add generation options column to task table
+ALTER TABLE aimotion_task
+ADD COLUMN IF NOT EXISTS options JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN aimotion_task.options IS '生成参数（面板数量、宽高比、尺寸、画风、反向提示词）';
@@ -0,0 +1,6 @@
+-- Rollback: Remove media polling columns
+DROP INDEX IF EXISTS idx_aimotion_media_type_status_lease;
+
+ALTER TABLE aimotion_media
+DROP COLUMN IF EXISTS submitted_at,
+DROP COLUMN IF EXISTS poll_count;
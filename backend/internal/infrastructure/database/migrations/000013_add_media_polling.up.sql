@@ -0,0 +1,9 @@
+-- Track vendor job polling for asynchronously generated media (e.g. Sora videos)
+ALTER TABLE aimotion_media
+ADD COLUMN IF NOT EXISTS submitted_at TIMESTAMPTZ NULL,
+ADD COLUMN IF NOT EXISTS poll_count INT NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN aimotion_media.submitted_at IS '提交到 AI 服务的时间，用于判断异步生成是否超时';
+COMMENT ON COLUMN aimotion_media.poll_count IS '已查询生成状态的次数，用于计算轮询退避间隔';
+
+CREATE INDEX IF NOT EXISTS idx_aimotion_media_type_status_lease ON aimotion_media(type, status, lease_expires_at);
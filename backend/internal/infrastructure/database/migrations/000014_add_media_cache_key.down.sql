@@ -0,0 +1,5 @@
+-- Rollback: Remove media cache key
+DROP INDEX IF EXISTS idx_aimotion_media_cache_key;
+
+ALTER TABLE aimotion_media
+DROP COLUMN IF EXISTS cache_key;
@@ -0,0 +1,8 @@
+-- Content-addressed cache key so identical generation requests can reuse completed media
+ALTER TABLE aimotion_media
+ADD COLUMN IF NOT EXISTS cache_key TEXT NULL;
+
+COMMENT ON COLUMN aimotion_media.cache_key IS '生成参数（服务、模型、提示词、尺寸、种子、参考图）的 SHA-256，参数相同的请求复用已完成的媒体';
+
+CREATE INDEX IF NOT EXISTS idx_aimotion_media_cache_key ON aimotion_media(cache_key, created_at DESC)
+WHERE status = 'completed';
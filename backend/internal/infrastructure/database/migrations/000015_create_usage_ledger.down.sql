@@ -0,0 +1,2 @@
+-- Rollback: Drop usage ledger table
+DROP TABLE IF EXISTS aimotion_usage_ledger;
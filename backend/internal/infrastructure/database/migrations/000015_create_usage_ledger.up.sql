@@ -0,0 +1,33 @@
+-- PostgreSQL migration: Create usage ledger table for per-user AI cost accounting
+CREATE TABLE IF NOT EXISTS aimotion_usage_ledger (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    task_id UUID,
+    provider VARCHAR(50) NOT NULL,
+    operation VARCHAR(30) NOT NULL,
+    width INT NOT NULL DEFAULT 0,
+    height INT NOT NULL DEFAULT 0,
+    duration NUMERIC(8, 2) NOT NULL DEFAULT 0,
+    latency_ms BIGINT NOT NULL DEFAULT 0,
+    success BOOLEAN NOT NULL,
+    estimated_cost NUMERIC(12, 6) NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Comments
+COMMENT ON TABLE aimotion_usage_ledger IS 'AI 调用用量账本，每次生成调用记录一条，只追加不修改';
+COMMENT ON COLUMN aimotion_usage_ledger.id IS '自增记录ID';
+COMMENT ON COLUMN aimotion_usage_ledger.user_id IS '发起调用的用户ID';
+COMMENT ON COLUMN aimotion_usage_ledger.task_id IS '关联的漫画生成任务ID，单独生成时为空；任务删除后保留记录';
+COMMENT ON COLUMN aimotion_usage_ledger.provider IS 'AI 服务名称:gemini,sora,mock';
+COMMENT ON COLUMN aimotion_usage_ledger.operation IS '调用类型:text_to_image,image_to_image,text_to_video,image_to_video';
+COMMENT ON COLUMN aimotion_usage_ledger.width IS '请求的宽度（像素）';
+COMMENT ON COLUMN aimotion_usage_ledger.height IS '请求的高度（像素）';
+COMMENT ON COLUMN aimotion_usage_ledger.duration IS '请求的视频时长（秒），图片为0';
+COMMENT ON COLUMN aimotion_usage_ledger.latency_ms IS '调用耗时（毫秒）';
+COMMENT ON COLUMN aimotion_usage_ledger.success IS '调用是否成功';
+COMMENT ON COLUMN aimotion_usage_ledger.estimated_cost IS '按价格表估算的费用（美元），失败的调用为0';
+COMMENT ON COLUMN aimotion_usage_ledger.created_at IS '调用时间';
+
+-- Indexes
+CREATE INDEX idx_usage_ledger_user_created ON aimotion_usage_ledger(user_id, created_at);
@@ -0,0 +1,6 @@
+-- Rollback: Drop media generation provenance columns
+ALTER TABLE aimotion_media
+DROP COLUMN IF EXISTS revised_prompt,
+DROP COLUMN IF EXISTS generation_params,
+DROP COLUMN IF EXISTS model,
+DROP COLUMN IF EXISTS provider;
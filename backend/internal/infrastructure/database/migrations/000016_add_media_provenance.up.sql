@@ -0,0 +1,11 @@
+-- Generation provenance (provider, model, exact parameters, revised prompt) so a panel can be reproduced or tweaked later
+ALTER TABLE aimotion_media
+ADD COLUMN IF NOT EXISTS provider VARCHAR(50) NULL,
+ADD COLUMN IF NOT EXISTS model VARCHAR(100) NULL,
+ADD COLUMN IF NOT EXISTS generation_params JSONB NULL,
+ADD COLUMN IF NOT EXISTS revised_prompt TEXT NULL;
+
+COMMENT ON COLUMN aimotion_media.provider IS '生成使用的 AI 服务:gemini,sora,mock';
+COMMENT ON COLUMN aimotion_media.model IS '生成使用的模型名称';
+COMMENT ON COLUMN aimotion_media.generation_params IS '请求的完整生成参数（提示词、负面提示词、风格、质量、种子、步数、CFG、参考图地址、尺寸）';
+COMMENT ON COLUMN aimotion_media.revised_prompt IS 'AI 服务改写后实际使用的提示词';
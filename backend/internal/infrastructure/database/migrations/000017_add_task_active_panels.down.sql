@@ -0,0 +1,3 @@
+-- Rollback: Drop task active panel selection
+ALTER TABLE aimotion_task
+DROP COLUMN IF EXISTS active_panels;
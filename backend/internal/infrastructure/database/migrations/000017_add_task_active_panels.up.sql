@@ -0,0 +1,5 @@
+-- Per-scene active panel selection so a regenerated panel version can replace the default latest image
+ALTER TABLE aimotion_task
+ADD COLUMN IF NOT EXISTS active_panels JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN aimotion_task.active_panels IS '每个场景当前选用的分镜版本（场景ID -> 媒体ID），未选择的场景使用最新生成的图片';
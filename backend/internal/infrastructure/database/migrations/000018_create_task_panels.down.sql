@@ -0,0 +1,5 @@
+-- Rollback: Drop task panel slots and restore per-scene active panel selection
+DROP TABLE IF EXISTS aimotion_task_panel;
+
+ALTER TABLE aimotion_task
+ADD COLUMN IF NOT EXISTS active_panels JSONB NOT NULL DEFAULT '{}'::jsonb;
@@ -0,0 +1,24 @@
+-- PostgreSQL migration: Create task panel slots so each panel keeps a stable position and its version history
+CREATE TABLE IF NOT EXISTS aimotion_task_panel (
+    id UUID PRIMARY KEY,
+    task_id UUID NOT NULL REFERENCES aimotion_task(id) ON DELETE CASCADE,
+    sequence_num INT NOT NULL,
+    scene_id VARCHAR(100) NULL,
+    active_media_id VARCHAR(100) NULL,
+    versions JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (task_id, sequence_num)
+);
+
+-- Comments
+COMMENT ON TABLE aimotion_task_panel IS '任务面板表，每个面板位置保存全部生成版本和当前使用的版本';
+COMMENT ON COLUMN aimotion_task_panel.task_id IS '关联的任务ID';
+COMMENT ON COLUMN aimotion_task_panel.sequence_num IS '面板序号，从 1 开始，决定面板在漫画中的顺序';
+COMMENT ON COLUMN aimotion_task_panel.scene_id IS '面板对应的场景ID';
+COMMENT ON COLUMN aimotion_task_panel.active_media_id IS '任务结果中使用的图片版本';
+COMMENT ON COLUMN aimotion_task_panel.versions IS '按生成顺序排列的全部版本（版本号、媒体ID、创建时间）';
+
+-- 面板选择改为记录在面板表中
+ALTER TABLE aimotion_task
+DROP COLUMN IF EXISTS active_panels;
@@ -0,0 +1,2 @@
+-- Rollback: Drop composed manga pages
+DROP TABLE IF EXISTS aimotion_task_page;
@@ -0,0 +1,22 @@
+-- PostgreSQL migration: Create composed manga pages laid out from task panels
+CREATE TABLE IF NOT EXISTS aimotion_task_page (
+    id UUID PRIMARY KEY,
+    task_id UUID NOT NULL REFERENCES aimotion_task(id) ON DELETE CASCADE,
+    page_number INT NOT NULL,
+    media_id VARCHAR(100) NOT NULL,
+    template VARCHAR(20) NOT NULL,
+    reading_direction VARCHAR(3) NOT NULL,
+    panels JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (task_id, page_number)
+);
+
+-- Comments
+COMMENT ON TABLE aimotion_task_page IS '漫画页面表，每页由任务面板按版式合成为一张 page 类型的媒体';
+COMMENT ON COLUMN aimotion_task_page.task_id IS '关联的任务ID';
+COMMENT ON COLUMN aimotion_task_page.page_number IS '页码，从 1 开始';
+COMMENT ON COLUMN aimotion_task_page.media_id IS '合成后的页面图片';
+COMMENT ON COLUMN aimotion_task_page.template IS '页面模板:grid,wide,tall,mixed';
+COMMENT ON COLUMN aimotion_task_page.reading_direction IS '阅读方向:rtl,ltr';
+COMMENT ON COLUMN aimotion_task_page.panels IS '按阅读顺序排列的面板位置（面板序号、面板版本、像素坐标和尺寸）';
+COMMENT ON COLUMN aimotion_media.type IS '媒体类型:image,video,page';
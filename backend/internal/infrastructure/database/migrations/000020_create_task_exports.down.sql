@@ -0,0 +1,3 @@
+-- Rollback: Drop export jobs
+DROP TABLE IF EXISTS aimotion_task_export;
+COMMENT ON COLUMN aimotion_media.type IS '媒体类型:image,video,page';
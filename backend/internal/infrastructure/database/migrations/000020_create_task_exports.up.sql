@@ -0,0 +1,31 @@
+-- PostgreSQL migration: Create export jobs that bundle task pages into CBZ, PDF or EPUB files
+CREATE TABLE IF NOT EXISTS aimotion_task_export (
+    id UUID PRIMARY KEY,
+    task_id UUID NOT NULL REFERENCES aimotion_task(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    progress INT NOT NULL DEFAULT 0,
+    media_id VARCHAR(100) NULL,
+    error_message TEXT NULL,
+    lease_owner VARCHAR(100),
+    lease_expires_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_export_task_id ON aimotion_task_export(task_id);
+CREATE INDEX IF NOT EXISTS idx_task_export_status_lease ON aimotion_task_export(status, lease_expires_at);
+
+-- Comments
+COMMENT ON TABLE aimotion_task_export IS '导出作业表，由后台 worker 将任务页面打包为可下载的文件';
+COMMENT ON COLUMN aimotion_task_export.task_id IS '关联的任务ID';
+COMMENT ON COLUMN aimotion_task_export.user_id IS '发起导出的用户ID';
+COMMENT ON COLUMN aimotion_task_export.format IS '导出格式:cbz,pdf,epub';
+COMMENT ON COLUMN aimotion_task_export.status IS '状态:pending,processing,completed,failed';
+COMMENT ON COLUMN aimotion_task_export.progress IS '进度百分比 0-100';
+COMMENT ON COLUMN aimotion_task_export.media_id IS '生成的文件，保存为 export 类型的媒体';
+COMMENT ON COLUMN aimotion_task_export.lease_owner IS '持有租约的 worker 标识';
+COMMENT ON COLUMN aimotion_task_export.lease_expires_at IS '租约过期时间，过期后作业可被其他 worker 领取';
+COMMENT ON COLUMN aimotion_media.type IS '媒体类型:image,video,page,export';
@@ -0,0 +1,5 @@
+-- Rollback: Remove export attachments column
+ALTER TABLE aimotion_task_export
+DROP COLUMN IF EXISTS attachments;
+
+COMMENT ON COLUMN aimotion_task_export.format IS '导出格式:cbz,pdf,epub';
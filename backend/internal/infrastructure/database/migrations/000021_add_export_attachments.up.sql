@@ -0,0 +1,6 @@
+-- Animatic exports: sidecar files (timeline manifest, SRT and WebVTT subtitles) stored next to the rendered video
+ALTER TABLE aimotion_task_export
+ADD COLUMN IF NOT EXISTS attachments JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+COMMENT ON COLUMN aimotion_task_export.attachments IS '附属文件列表 [{kind, media_id}]，kind:timeline,srt,vtt';
+COMMENT ON COLUMN aimotion_task_export.format IS '导出格式:cbz,pdf,epub,animatic';
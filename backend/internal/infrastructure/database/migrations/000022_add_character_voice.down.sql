@@ -0,0 +1,3 @@
+-- Rollback: Remove character voice profiles
+ALTER TABLE aimotion_character
+DROP COLUMN IF EXISTS voice;
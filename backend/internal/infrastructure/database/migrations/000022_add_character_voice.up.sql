@@ -0,0 +1,5 @@
+-- Character voice profiles used by dialogue voice-over
+ALTER TABLE aimotion_character
+ADD COLUMN IF NOT EXISTS voice TEXT;
+
+COMMENT ON COLUMN aimotion_character.voice IS '配音设置 JSON {Voice, Language, Speed, Pitch}，为空使用默认音色';
@@ -0,0 +1,3 @@
+-- Rollback: Drop voice-over tracks
+DROP TABLE IF EXISTS aimotion_task_voiceover;
+COMMENT ON COLUMN aimotion_media.type IS '媒体类型:image,video,page,export';
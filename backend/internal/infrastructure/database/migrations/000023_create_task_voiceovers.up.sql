@@ -0,0 +1,18 @@
+-- PostgreSQL migration: Create per-scene voice-over tracks synthesized from scene dialogues
+CREATE TABLE IF NOT EXISTS aimotion_task_voiceover (
+    id UUID PRIMARY KEY,
+    task_id UUID NOT NULL REFERENCES aimotion_task(id) ON DELETE CASCADE,
+    scene_id VARCHAR(36) NOT NULL,
+    lines JSONB NOT NULL DEFAULT '[]'::jsonb,
+    duration DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (task_id, scene_id)
+);
+
+-- Comments
+COMMENT ON TABLE aimotion_task_voiceover IS '配音轨表，每个场景的台词合成为 audio 类型的媒体并记录时间';
+COMMENT ON COLUMN aimotion_task_voiceover.task_id IS '关联的任务ID';
+COMMENT ON COLUMN aimotion_task_voiceover.scene_id IS '配音对应的场景ID';
+COMMENT ON COLUMN aimotion_task_voiceover.lines IS '按台词顺序排列的配音（台词序号、说话人、角色、音色、媒体ID、相对场景的开始时间和时长）';
+COMMENT ON COLUMN aimotion_task_voiceover.duration IS '最后一句台词结束的时间（秒）';
+COMMENT ON COLUMN aimotion_media.type IS '媒体类型:image,video,page,export,audio';
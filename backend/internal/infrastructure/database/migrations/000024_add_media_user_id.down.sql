@@ -0,0 +1,3 @@
+-- Rollback: Drop media requesting user
+ALTER TABLE aimotion_media
+DROP COLUMN IF EXISTS user_id;
@@ -0,0 +1,5 @@
+-- Requesting user on media so queued generations are charged to the user who asked for them
+ALTER TABLE aimotion_media
+ADD COLUMN IF NOT EXISTS user_id UUID NULL;
+
+COMMENT ON COLUMN aimotion_media.user_id IS '发起生成的用户ID，后台生成时按该用户检查配额和记账；任务流水线生成的媒体为空';
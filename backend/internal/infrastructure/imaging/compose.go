@@ -0,0 +1,95 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Frame 页面上的一个面板，图片等比缩放并居中裁剪后填满 Rect
+type Frame struct {
+	Image image.Image
+	Rect  image.Rectangle
+}
+
+// Compose 在白色画布上绘制面板，border 大于 0 时面板内侧绘制黑色边框
+func Compose(width, height int, frames []Frame, border int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	for _, frame := range frames {
+		rect := frame.Rect.Intersect(dst.Bounds())
+		if rect.Empty() {
+			continue
+		}
+		if border > 0 {
+			draw.Draw(dst, rect, image.NewUniform(color.Black), image.Point{}, draw.Src)
+			rect = rect.Inset(border)
+			if rect.Empty() {
+				continue
+			}
+		}
+		draw.Draw(dst, rect, Cover(frame.Image, rect.Dx(), rect.Dy()), image.Point{}, draw.Over)
+	}
+
+	return dst
+}
+
+// Cover 等比缩放图片使其覆盖 width × height，超出部分居中裁掉
+func Cover(src image.Image, width, height int) *image.RGBA {
+	bounds := src.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	if width <= 0 || height <= 0 || srcWidth == 0 || srcHeight == 0 {
+		return image.NewRGBA(image.Rect(0, 0, max(width, 0), max(height, 0)))
+	}
+
+	// 按宽高比较窄的一边取满，另一边居中裁剪
+	crop := bounds
+	if srcWidth*height > srcHeight*width {
+		cropWidth := max(1, srcHeight*width/height)
+		crop.Min.X += (srcWidth - cropWidth) / 2
+		crop.Max.X = crop.Min.X + cropWidth
+	} else {
+		cropHeight := max(1, srcWidth*height/width)
+		crop.Min.Y += (srcHeight - cropHeight) / 2
+		crop.Max.Y = crop.Min.Y + cropHeight
+	}
+
+	return Resize(subImage(src, crop), width, height)
+}
+
+// Contain 等比缩放图片使其完整放入 width × height，空白部分以 background 填充并使图片居中
+func Contain(src image.Image, width, height int, background color.Color) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, max(width, 0), max(height, 0)))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	bounds := src.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	if width <= 0 || height <= 0 || srcWidth == 0 || srcHeight == 0 {
+		return dst
+	}
+
+	fitWidth, fitHeight := width, height
+	if srcWidth*height > srcHeight*width {
+		fitHeight = max(1, srcHeight*width/srcWidth)
+	} else {
+		fitWidth = max(1, srcWidth*height/srcHeight)
+	}
+	rect := image.Rect(0, 0, fitWidth, fitHeight).Add(image.Pt((width-fitWidth)/2, (height-fitHeight)/2))
+	draw.Draw(dst, rect, Resize(src, fitWidth, fitHeight), image.Point{}, draw.Over)
+
+	return dst
+}
+
+// subImage 截取图片的一部分，不支持 SubImage 的图片先复制再截取
+func subImage(src image.Image, rect image.Rectangle) image.Image {
+	if sub, ok := src.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(rect)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, rect.Min, draw.Src)
+	return dst
+}
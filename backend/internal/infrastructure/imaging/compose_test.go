@@ -0,0 +1,67 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestCoverCropsToFill(t *testing.T) {
+	// 左右两侧为红色、中间为蓝色的横图，裁成正方形后只剩中间部分
+	src := image.NewRGBA(image.Rect(0, 0, 300, 100))
+	draw.Draw(src, src.Bounds(), image.NewUniform(color.RGBA{R: 255, A: 255}), image.Point{}, draw.Src)
+	draw.Draw(src, image.Rect(100, 0, 200, 100), image.NewUniform(color.RGBA{B: 255, A: 255}), image.Point{}, draw.Src)
+
+	got := Cover(src, 50, 50)
+	if got.Bounds().Dx() != 50 || got.Bounds().Dy() != 50 {
+		t.Fatalf("Cover size = %v, want 50x50", got.Bounds())
+	}
+	for _, p := range []image.Point{{0, 0}, {49, 49}, {25, 25}} {
+		if c := got.RGBAAt(p.X, p.Y); c.B != 255 || c.R != 0 {
+			t.Errorf("Cover pixel %v = %+v, want blue centre crop", p, c)
+		}
+	}
+}
+
+func TestContainLetterboxes(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	draw.Draw(src, src.Bounds(), image.NewUniform(color.RGBA{R: 255, A: 255}), image.Point{}, draw.Src)
+
+	got := Contain(src, 160, 90, color.Black)
+	if got.Bounds().Dx() != 160 || got.Bounds().Dy() != 90 {
+		t.Fatalf("Contain size = %v, want 160x90", got.Bounds())
+	}
+	if c := got.RGBAAt(80, 45); c.R != 255 {
+		t.Errorf("centre pixel = %+v, want red", c)
+	}
+	// 正方形图片放入横向画面后左右留黑边：宽 90，起点 x = 35
+	for _, p := range []image.Point{{0, 45}, {34, 45}, {125, 45}, {159, 0}} {
+		if c := got.RGBAAt(p.X, p.Y); c != (color.RGBA{0, 0, 0, 255}) {
+			t.Errorf("letterbox pixel %v = %+v, want black", p, c)
+		}
+	}
+	if c := got.RGBAAt(35, 0); c.R != 255 {
+		t.Errorf("edge pixel = %+v, want red", c)
+	}
+}
+
+func TestComposeDrawsFramesWithBorder(t *testing.T) {
+	green := image.NewRGBA(image.Rect(0, 0, 16, 9))
+	draw.Draw(green, green.Bounds(), image.NewUniform(color.RGBA{G: 255, A: 255}), image.Point{}, draw.Src)
+
+	page := Compose(100, 100, []Frame{{Image: green, Rect: image.Rect(10, 10, 90, 50)}}, 2)
+
+	if c := page.RGBAAt(5, 5); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("margin pixel = %+v, want white", c)
+	}
+	if c := page.RGBAAt(10, 10); c != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("border pixel = %+v, want black", c)
+	}
+	if c := page.RGBAAt(50, 30); c.G != 255 || c.R != 0 {
+		t.Errorf("panel pixel = %+v, want green", c)
+	}
+	if c := page.RGBAAt(50, 70); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("pixel below panel = %+v, want white", c)
+	}
+}
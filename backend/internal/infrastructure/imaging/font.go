@@ -0,0 +1,175 @@
+package imaging
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"os"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// systemFontPaths 常见系统中 CJK 字体的位置，依次为 Debian/Ubuntu、Arch、Fedora 的思源黑体和文泉驿，以及 macOS、Windows 自带字体
+var systemFontPaths = []string{
+	"/usr/share/fonts/opentype/noto/NotoSansCJK-Regular.ttc",
+	"/usr/share/fonts/noto-cjk/NotoSansCJK-Regular.ttc",
+	"/usr/share/fonts/google-noto-cjk/NotoSansCJK-Regular.ttc",
+	"/usr/share/fonts/truetype/wqy/wqy-microhei.ttc",
+	"/usr/share/fonts/wenquanyi/wqy-microhei/wqy-microhei.ttc",
+	"/System/Library/Fonts/PingFang.ttc",
+	"/System/Library/Fonts/STHeiti Medium.ttc",
+	`C:\Windows\Fonts\msyh.ttc`,
+}
+
+// Fonts 按优先级排列的字体，绘制文字时逐字选择第一个包含该字形的字体
+type Fonts struct {
+	fonts []*opentype.Font
+	names []string
+}
+
+// LoadFonts 依次加载 paths 中的字体（不存在时返回错误）、找到的系统 CJK 字体，
+// 最后加入内置的 Go 字体保证拉丁字母可用。TTC 字体集合使用其中的第一个字体
+func LoadFonts(paths []string) (*Fonts, error) {
+	f := &Fonts{}
+
+	for _, path := range paths {
+		if err := f.load(path); err != nil {
+			return nil, err
+		}
+	}
+	for _, path := range systemFontPaths {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if err := f.load(path); err != nil {
+			return nil, err
+		}
+	}
+
+	fallback, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse builtin font: %w", err)
+	}
+	f.fonts = append(f.fonts, fallback)
+	f.names = append(f.names, "goregular")
+
+	return f, nil
+}
+
+func (f *Fonts) load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read font %s: %w", path, err)
+	}
+	collection, err := opentype.ParseCollection(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse font %s: %w", path, err)
+	}
+	if collection.NumFonts() == 0 {
+		return fmt.Errorf("font %s contains no fonts", path)
+	}
+	parsed, err := collection.Font(0)
+	if err != nil {
+		return fmt.Errorf("failed to parse font %s: %w", path, err)
+	}
+
+	f.fonts = append(f.fonts, parsed)
+	f.names = append(f.names, path)
+	return nil
+}
+
+// Names 已加载的字体，按优先级排列
+func (f *Fonts) Names() []string {
+	return f.names
+}
+
+// HasCJK 是否加载了包含汉字的字体，没有时中文台词会显示为方框
+func (f *Fonts) HasCJK() bool {
+	face, err := f.NewFace(16)
+	if err != nil {
+		return false
+	}
+	defer face.Close()
+
+	_, ok := face.(*fallbackFace).pick('中').GlyphAdvance('中')
+	return ok
+}
+
+// NewFace 创建指定字号（像素）的字体。返回的 Face 不能并发使用
+func (f *Fonts) NewFace(size float64) (font.Face, error) {
+	if len(f.fonts) == 0 {
+		return nil, errors.New("no fonts loaded")
+	}
+
+	faces := make([]font.Face, 0, len(f.fonts))
+	for _, parsed := range f.fonts {
+		face, err := opentype.NewFace(parsed, &opentype.FaceOptions{
+			Size:    size,
+			DPI:     72,
+			Hinting: font.HintingFull,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create font face: %w", err)
+		}
+		faces = append(faces, face)
+	}
+	return &fallbackFace{faces: faces}, nil
+}
+
+// fallbackFace 逐字回退的字体：使用第一个包含该字形的字体，都没有时使用首选字体的缺字符号
+type fallbackFace struct {
+	faces []font.Face
+}
+
+func (f *fallbackFace) pick(r rune) font.Face {
+	for _, face := range f.faces {
+		if _, ok := face.GlyphAdvance(r); ok {
+			return face
+		}
+	}
+	return f.faces[0]
+}
+
+func (f *fallbackFace) Close() error {
+	var errs []error
+	for _, face := range f.faces {
+		errs = append(errs, face.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (f *fallbackFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	return f.pick(r).Glyph(dot, r)
+}
+
+func (f *fallbackFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	return f.pick(r).GlyphBounds(r)
+}
+
+func (f *fallbackFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	return f.pick(r).GlyphAdvance(r)
+}
+
+// Kern 只有相邻两个字使用同一字体时才有字距调整
+func (f *fallbackFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	face := f.pick(r0)
+	if face != f.pick(r1) {
+		return 0
+	}
+	return face.Kern(r0, r1)
+}
+
+// Metrics 行高取各字体中的最大值，避免回退字体的字形超出行距
+func (f *fallbackFace) Metrics() font.Metrics {
+	metrics := f.faces[0].Metrics()
+	for _, face := range f.faces[1:] {
+		m := face.Metrics()
+		metrics.Height = max(metrics.Height, m.Height)
+		metrics.Ascent = max(metrics.Ascent, m.Ascent)
+		metrics.Descent = max(metrics.Descent, m.Descent)
+	}
+	return metrics
+}
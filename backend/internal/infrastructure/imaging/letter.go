@@ -0,0 +1,312 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/xiajiayi/ai-motion/internal/domain/lettering"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+const (
+	// maxBalloonLines 每个气泡最多显示的行数，超出部分以省略号结尾
+	maxBalloonLines = 6
+	// ellipseFactor 容纳文字矩形的椭圆与矩形的边长比（√2）
+	ellipseFactor = 1.42
+	// burstDepth 爆炸形锯齿凹处相对外接椭圆的半径比例
+	burstDepth = 0.72
+)
+
+var (
+	captionFill = color.RGBA{R: 255, G: 248, B: 214, A: 255}
+	inkColor    = color.Black
+	paperColor  = color.White
+)
+
+// Letterer 在面板上绘制对话气泡、思考气泡和旁白框
+type Letterer struct {
+	fonts *Fonts
+}
+
+// NewLetterer 创建嵌字器
+func NewLetterer(fonts *Fonts) *Letterer {
+	return &Letterer{fonts: fonts}
+}
+
+// Fonts 嵌字使用的字体
+func (l *Letterer) Fonts() *Fonts {
+	return l.fonts
+}
+
+// balloonLayout 一个气泡排版后的文字和外接尺寸
+type balloonLayout struct {
+	balloon lettering.Balloon
+	lines   []string
+	text    image.Point // 文字区域尺寸
+	size    image.Point // 气泡外接矩形尺寸（不含尾巴）
+}
+
+// Letter 在 dst 的 rect 区域（一个面板）内按阅读顺序绘制气泡，气泡避开面板中央，
+// 尾巴指向面板中央。返回实际放置的气泡数，放不下的气泡跳过
+func (l *Letterer) Letter(dst draw.Image, rect image.Rectangle, balloons []lettering.Balloon, rtl bool) (int, error) {
+	if len(balloons) == 0 || rect.Empty() {
+		return 0, nil
+	}
+	if len(balloons) > lettering.MaxBalloonsPerPanel {
+		balloons = balloons[:lettering.MaxBalloonsPerPanel]
+	}
+
+	size := fontSize(rect)
+	face, err := l.fonts.NewFace(size)
+	if err != nil {
+		return 0, err
+	}
+	defer face.Close()
+
+	metrics := face.Metrics()
+	lineHeight := metrics.Height.Ceil()
+	padding := int(size * 0.5)
+
+	layouts := make([]balloonLayout, 0, len(balloons))
+	sizes := make([]image.Point, 0, len(balloons))
+	for _, b := range balloons {
+		maxWidth := max(rect.Dx()*3/10, int(size*6))
+		if b.Kind == lettering.KindCaption {
+			maxWidth = max(rect.Dx()*9/20, int(size*8))
+		}
+		lines := WrapText(face, b.Text, maxWidth)
+		if len(lines) > maxBalloonLines {
+			lines = append(lines[:maxBalloonLines-1], lines[maxBalloonLines-1]+"…")
+		}
+
+		text := image.Pt(textWidth(face, lines), len(lines)*lineHeight)
+		layout := balloonLayout{
+			balloon: b,
+			lines:   lines,
+			text:    text,
+			size:    balloonSize(b.Shape, text.Add(image.Pt(2*padding, 2*padding)), size),
+		}
+		layouts = append(layouts, layout)
+		sizes = append(sizes, layout.size)
+	}
+
+	rects := lettering.Place(rect.Dx(), rect.Dy(), sizes, rtl)
+	centre := rect.Min.Add(image.Pt(rect.Dx()/2, rect.Dy()/2))
+	stroke := math.Max(2, size/10)
+
+	placed := 0
+	for i, layout := range layouts {
+		if rects[i].Empty() {
+			continue
+		}
+		bounds := rects[i].Add(rect.Min)
+		if err := drawBalloon(dst, rect, layout.balloon, bounds, centre, size, stroke); err != nil {
+			return placed, err
+		}
+		drawLines(dst, face, layout.lines, bounds, lineHeight, metrics.Ascent.Ceil())
+		placed++
+	}
+
+	return placed, nil
+}
+
+// fontSize 根据面板尺寸选择字号（像素），宽幅面板按高度的 1.6 倍折算
+func fontSize(rect image.Rectangle) float64 {
+	base := math.Min(float64(rect.Dx()), float64(rect.Dy())*1.6)
+	return math.Max(14, math.Min(48, base/32))
+}
+
+// balloonSize 容纳 content（文字加内边距）所需的气泡外接尺寸
+func balloonSize(shape lettering.Shape, content image.Point, size float64) image.Point {
+	scale := func(f float64, extra int) image.Point {
+		return image.Pt(int(float64(content.X)*f)+extra, int(float64(content.Y)*f)+extra)
+	}
+
+	switch shape {
+	case lettering.ShapeBox:
+		return content
+	case lettering.ShapeCloud:
+		return scale(ellipseFactor, int(2*cloudBump(size)))
+	case lettering.ShapeBurst:
+		return scale(ellipseFactor/burstDepth, 0)
+	}
+	return scale(ellipseFactor*1.04, 0)
+}
+
+// cloudBump 思考气泡边缘小圆的半径
+func cloudBump(size float64) float64 {
+	return size * 0.6
+}
+
+// point 浮点坐标
+type point struct{ X, Y float64 }
+
+// polygon 闭合多边形
+type polygon []point
+
+// drawBalloon 先用墨色绘制放大 stroke 的轮廓，再用底色绘制原轮廓，得到描边的气泡
+func drawBalloon(dst draw.Image, clip image.Rectangle, b lettering.Balloon, bounds image.Rectangle, target image.Point, size, stroke float64) error {
+	var fill color.Color = paperColor
+	if b.Kind == lettering.KindCaption {
+		fill = captionFill
+	}
+
+	for _, pass := range []struct {
+		grow  float64
+		color color.Color
+	}{{stroke, inkColor}, {0, fill}} {
+		shapes, err := balloonPolygons(b, bounds, target, size, pass.grow)
+		if err != nil {
+			return err
+		}
+		fillPolygons(dst, clip, shapes, pass.color)
+	}
+	return nil
+}
+
+// balloonPolygons 气泡轮廓（含尾巴），grow 为各边向外扩展的像素数
+func balloonPolygons(b lettering.Balloon, bounds image.Rectangle, target image.Point, size, grow float64) ([]polygon, error) {
+	cx := float64(bounds.Min.X+bounds.Max.X) / 2
+	cy := float64(bounds.Min.Y+bounds.Max.Y) / 2
+	rx := float64(bounds.Dx()) / 2
+	ry := float64(bounds.Dy()) / 2
+
+	var shapes []polygon
+	switch b.Shape {
+	case lettering.ShapeBox:
+		r := bounds.Inset(-int(math.Round(grow)))
+		shapes = append(shapes, polygon{
+			{float64(r.Min.X), float64(r.Min.Y)}, {float64(r.Max.X), float64(r.Min.Y)},
+			{float64(r.Max.X), float64(r.Max.Y)}, {float64(r.Min.X), float64(r.Max.Y)},
+		})
+	case lettering.ShapeOval:
+		shapes = append(shapes, ellipse(cx, cy, rx+grow, ry+grow, 72, nil))
+	case lettering.ShapeWobbly:
+		shapes = append(shapes, ellipse(cx, cy, rx+grow, ry+grow, 144, func(i int, theta float64) float64 {
+			return 0.965 + 0.035*math.Sin(14*theta)
+		}))
+	case lettering.ShapeBurst:
+		// 锯齿的凸出长度按固定节奏变化，避免形状过于规整
+		spikes := []float64{1, 0.9, 0.96, 0.86}
+		shapes = append(shapes, ellipse(cx, cy, rx+grow, ry+grow, 32, func(i int, theta float64) float64 {
+			if i%2 == 1 {
+				return burstDepth
+			}
+			return spikes[(i/2)%len(spikes)]
+		}))
+	case lettering.ShapeCloud:
+		bump := cloudBump(size)
+		innerX, innerY := rx-bump, ry-bump
+		shapes = append(shapes, ellipse(cx, cy, innerX+grow, innerY+grow, 72, nil))
+		perimeter := math.Pi * (3*(innerX+innerY) - math.Sqrt((3*innerX+innerY)*(innerX+3*innerY)))
+		count := max(8, int(perimeter/(bump*1.4)))
+		for i := range count {
+			theta := 2 * math.Pi * float64(i) / float64(count)
+			shapes = append(shapes, ellipse(cx+innerX*math.Cos(theta), cy+innerY*math.Sin(theta), bump+grow, bump+grow, 24, nil))
+		}
+	default:
+		return nil, fmt.Errorf("unknown balloon shape %q", b.Shape)
+	}
+
+	// 尾巴从气泡中心指向面板中央，气泡离中央太近时不画
+	dx, dy := float64(target.X)-cx, float64(target.Y)-cy
+	distance := math.Hypot(dx, dy)
+	edge := ellipseRadius(rx, ry, dx, dy)
+	if distance <= edge*1.2 {
+		return shapes, nil
+	}
+	ux, uy := dx/distance, dy/distance
+	length := math.Min(size*1.8, (distance-edge)*0.6)
+
+	switch b.Kind {
+	case lettering.KindSpeech:
+		base := math.Min(rx, ry) * 0.3
+		tip := edge + length + grow*2
+		shapes = append(shapes, polygon{
+			{cx - uy*(base+grow), cy + ux*(base+grow)},
+			{cx + uy*(base+grow), cy - ux*(base+grow)},
+			{cx + ux*tip, cy + uy*tip},
+		})
+	case lettering.KindThought:
+		// 思考气泡用三个逐渐变小的圆圈代替尾巴
+		for i, r := range []float64{0.45, 0.32, 0.22} {
+			offset := edge + size*0.4 + length*float64(i)/2
+			shapes = append(shapes, ellipse(cx+ux*offset, cy+uy*offset, size*r+grow, size*r+grow, 24, nil))
+		}
+	}
+
+	return shapes, nil
+}
+
+// ellipse 以 n 个顶点近似椭圆，radius 为第 i 个顶点相对半径的缩放（为 nil 时不缩放）
+func ellipse(cx, cy, rx, ry float64, n int, radius func(i int, theta float64) float64) polygon {
+	points := make(polygon, n)
+	for i := range n {
+		theta := 2 * math.Pi * float64(i) / float64(n)
+		scale := 1.0
+		if radius != nil {
+			scale = radius(i, theta)
+		}
+		points[i] = point{cx + rx*scale*math.Cos(theta), cy + ry*scale*math.Sin(theta)}
+	}
+	return points
+}
+
+// ellipseRadius 椭圆中心沿 (dx, dy) 方向到边缘的距离
+func ellipseRadius(rx, ry, dx, dy float64) float64 {
+	if dx == 0 && dy == 0 {
+		return 0
+	}
+	theta := math.Atan2(dy, dx)
+	return rx * ry / math.Hypot(ry*math.Cos(theta), rx*math.Sin(theta))
+}
+
+// fillPolygons 以抗锯齿方式填充多边形的并集，只绘制 clip 范围内的部分
+func fillPolygons(dst draw.Image, clip image.Rectangle, shapes []polygon, c color.Color) {
+	var bounds image.Rectangle
+	for _, shape := range shapes {
+		for _, p := range shape {
+			pt := image.Rect(int(math.Floor(p.X)), int(math.Floor(p.Y)), int(math.Ceil(p.X))+1, int(math.Ceil(p.Y))+1)
+			bounds = bounds.Union(pt)
+		}
+	}
+	visible := bounds.Intersect(clip).Intersect(dst.Bounds())
+	if visible.Empty() {
+		return
+	}
+
+	// 在完整的外接矩形上光栅化成蒙版，再按 clip 裁剪绘制
+	z := vector.NewRasterizer(bounds.Dx(), bounds.Dy())
+	ox, oy := float64(bounds.Min.X), float64(bounds.Min.Y)
+	for _, shape := range shapes {
+		z.MoveTo(float32(shape[0].X-ox), float32(shape[0].Y-oy))
+		for _, p := range shape[1:] {
+			z.LineTo(float32(p.X-ox), float32(p.Y-oy))
+		}
+		z.ClosePath()
+	}
+	mask := image.NewAlpha(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	z.Draw(mask, mask.Bounds(), image.Opaque, image.Point{})
+
+	draw.DrawMask(dst, visible, image.NewUniform(c), image.Point{}, mask, visible.Min.Sub(bounds.Min), draw.Over)
+}
+
+// drawLines 在气泡中居中绘制文字
+func drawLines(dst draw.Image, face font.Face, lines []string, bounds image.Rectangle, lineHeight, ascent int) {
+	drawer := &font.Drawer{Dst: dst, Src: image.NewUniform(inkColor), Face: face}
+	top := bounds.Min.Y + (bounds.Dy()-len(lines)*lineHeight)/2
+	centre := fixed.I(bounds.Min.X + bounds.Dx()/2)
+
+	for i, line := range lines {
+		drawer.Dot = fixed.Point26_6{
+			X: centre - drawer.MeasureString(line)/2,
+			Y: fixed.I(top + i*lineHeight + ascent),
+		}
+		drawer.DrawString(line)
+	}
+}
@@ -0,0 +1,82 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+
+	"github.com/xiajiayi/ai-motion/internal/domain/lettering"
+)
+
+func TestWrapText(t *testing.T) {
+	fonts, err := LoadFonts(nil)
+	if err != nil {
+		t.Fatalf("LoadFonts() error = %v", err)
+	}
+	face, err := fonts.NewFace(20)
+	if err != nil {
+		t.Fatalf("NewFace() error = %v", err)
+	}
+	defer face.Close()
+
+	lines := WrapText(face, "the quick brown fox jumps over the lazy dog", 120)
+	if len(lines) < 3 {
+		t.Fatalf("WrapText() = %q, want several lines", lines)
+	}
+	for _, line := range lines {
+		if textWidth(face, []string{line}) > 120 && strings.Contains(line, " ") {
+			t.Errorf("line %q is wider than 120px", line)
+		}
+		if strings.HasPrefix(line, " ") || strings.HasSuffix(line, " ") {
+			t.Errorf("line %q has surrounding spaces", line)
+		}
+	}
+	if got := strings.Join(lines, " "); got != "the quick brown fox jumps over the lazy dog" {
+		t.Errorf("WrapText() lost words: %q", got)
+	}
+
+	// 避头：句号不能出现在行首
+	width := textWidth(face, []string{"今天天气"})
+	for _, line := range WrapText(face, "今天天气真好。我们去森林吧。", width) {
+		if strings.HasPrefix(line, "。") {
+			t.Errorf("line %q starts with closing punctuation", line)
+		}
+	}
+
+	if got := WrapText(face, "第一行\n第二行", 1000); len(got) != 2 {
+		t.Errorf("WrapText() = %q, want explicit line break kept", got)
+	}
+}
+
+func TestLetterDrawsBalloonsAwayFromCentre(t *testing.T) {
+	fonts, err := LoadFonts(nil)
+	if err != nil {
+		t.Fatalf("LoadFonts() error = %v", err)
+	}
+	background := color.RGBA{R: 40, G: 120, B: 40, A: 255}
+	dst := image.NewRGBA(image.Rect(0, 0, 1000, 600))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	balloons := []lettering.Balloon{
+		lettering.NewBalloon(lettering.KindCaption, "", "The next morning", ""),
+		lettering.NewBalloon(lettering.KindSpeech, "a", "Hello there", "angry"),
+		lettering.NewBalloon(lettering.KindThought, "b", "How strange", ""),
+	}
+	placed, err := NewLetterer(fonts).Letter(dst, dst.Bounds(), balloons, true)
+	if err != nil {
+		t.Fatalf("Letter() error = %v", err)
+	}
+	if placed != len(balloons) {
+		t.Errorf("Letter() placed %d balloons, want %d", placed, len(balloons))
+	}
+
+	if c := dst.RGBAAt(500, 300); c != background {
+		t.Errorf("centre pixel = %+v, want untouched background", c)
+	}
+	margin := lettering.Margin(1000, 600)
+	if c := dst.RGBAAt(1000-margin-2, margin+2); c == background {
+		t.Error("top-right corner has no caption, want first balloon at the RTL reading start")
+	}
+}
@@ -0,0 +1,38 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestFitKeepsAspectRatio(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 1344, 768))
+
+	got := Fit(src, 320).Bounds()
+	if got.Dx() != 320 || got.Dy() != 182 {
+		t.Errorf("Fit(1344x768, 320) = %dx%d, want 320x182", got.Dx(), got.Dy())
+	}
+
+	portrait := Fit(image.NewRGBA(image.Rect(0, 0, 768, 1344)), 320).Bounds()
+	if portrait.Dx() != 182 || portrait.Dy() != 320 {
+		t.Errorf("Fit(768x1344, 320) = %dx%d, want 182x320", portrait.Dx(), portrait.Dy())
+	}
+
+	if small := Fit(image.NewRGBA(image.Rect(0, 0, 100, 50)), 320); small.Bounds().Dx() != 100 {
+		t.Error("Fit should not upscale small images")
+	}
+}
+
+func TestResizeAveragesPixels(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	src.Set(0, 0, color.RGBA{R: 255, A: 255})
+	src.Set(1, 0, color.RGBA{R: 255, A: 255})
+	src.Set(0, 1, color.RGBA{B: 255, A: 255})
+	src.Set(1, 1, color.RGBA{B: 255, A: 255})
+
+	got := Resize(src, 1, 1).RGBAAt(0, 0)
+	if got.R < 126 || got.R > 128 || got.B < 126 || got.B > 128 || got.A != 255 {
+		t.Errorf("Resize average = %+v, want half red half blue", got)
+	}
+}
@@ -0,0 +1,86 @@
+package imaging
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// noLineStart 不能出现在行首的标点（避头），换行时挂在上一行末尾
+const noLineStart = "，。、；：？！…）》」』】”’,.;:?!)]}%"
+
+// WrapText 按最大宽度（像素）折行：汉字逐字断行，拉丁字母按单词断行，
+// 行首标点挂到上一行，显式换行符保留。单个过长的单词按字符强制断开
+func WrapText(face font.Face, text string, maxWidth int) []string {
+	limit := fixed.I(maxWidth)
+	var lines []string
+
+	for _, paragraph := range strings.Split(text, "\n") {
+		var line []rune
+		for _, r := range strings.TrimSpace(paragraph) {
+			if len(line) > 0 && font.MeasureString(face, string(append(line, r))) > limit {
+				switch {
+				case strings.ContainsRune(noLineStart, r):
+					// 避头：标点留在当前行末尾
+					lines = append(lines, string(append(line, r)))
+					line = nil
+					continue
+				case isWordRune(r):
+					// 单词在行内开始时整体移到下一行
+					if space := lastSpace(line); space > 0 {
+						lines = append(lines, strings.TrimSpace(string(line[:space])))
+						line = append([]rune(nil), line[space+1:]...)
+						break
+					}
+					lines = append(lines, string(line))
+					line = nil
+				default:
+					lines = append(lines, strings.TrimRight(string(line), " "))
+					line = nil
+				}
+			}
+			if len(line) == 0 && unicode.IsSpace(r) {
+				continue
+			}
+			line = append(line, r)
+		}
+		if len(line) > 0 || len(lines) == 0 {
+			lines = append(lines, strings.TrimRight(string(line), " "))
+		}
+	}
+
+	return lines
+}
+
+// isWordRune 是否为按单词断行的字符（拉丁字母、数字等非 CJK 字符）
+func isWordRune(r rune) bool {
+	if unicode.IsSpace(r) || unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r) {
+		return false
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// lastSpace 行内最后一个空格的位置，只有空格后全部是单词字符时才可在此断行
+func lastSpace(line []rune) int {
+	for i := len(line) - 1; i >= 0; i-- {
+		if line[i] == ' ' {
+			return i
+		}
+		if !isWordRune(line[i]) {
+			return -1
+		}
+	}
+	return -1
+}
+
+// textWidth 多行文字中最宽一行的宽度（像素）
+func textWidth(face font.Face, lines []string) int {
+	var width fixed.Int26_6
+	for _, line := range lines {
+		width = max(width, font.MeasureString(face, line))
+	}
+	return width.Ceil()
+}
@@ -10,6 +10,9 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// RoleAdmin 管理员角色，在 Supabase 用户的 app_metadata.role 中设置（只能由服务端修改）
+const RoleAdmin = "admin"
+
 type AuthMiddleware struct {
 	jwtSecret string
 }
@@ -123,6 +126,30 @@ func (m *AuthMiddleware) SupabaseAuth() gin.HandlerFunc {
 		if email, ok := claims["email"].(string); ok {
 			c.Set("user_email", email)
 		}
+		if appMetadata, ok := claims["app_metadata"].(map[string]interface{}); ok {
+			if role, ok := appMetadata["role"].(string); ok {
+				c.Set("user_role", role)
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// RequireRole 要求已认证用户具有指定角色，需在 SupabaseAuth 之后使用；未认证时同样拒绝
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if userRole, _ := GetUserRole(c); userRole != role {
+			userID, _ := GetUserID(c)
+			log.Printf("[AUTH] User %q lacks role %q for %s %s", userID, role, c.Request.Method, c.Request.URL.Path)
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    20002,
+				"message": "权限不足",
+				"data":    nil,
+			})
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
@@ -144,6 +171,15 @@ func GetUserID(c *gin.Context) (string, bool) {
 	return userID.(string), true
 }
 
+// GetUserRole 从上下文中获取用户角色（app_metadata.role）
+func GetUserRole(c *gin.Context) (string, bool) {
+	role, exists := c.Get("user_role")
+	if !exists {
+		return "", false
+	}
+	return role.(string), true
+}
+
 // GetUserIDFromContext 从标准 Context 中获取用户ID
 func GetUserIDFromContext(ctx context.Context) (string, bool) {
 	userID, ok := ctx.Value("user_id").(string)
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testJWTSecret = "test-secret"
+
+func signToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testJWTSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	auth := NewAuthMiddleware(testJWTSecret)
+
+	router := gin.New()
+	router.GET("/admin", auth.SupabaseAuth(), RequireRole(RoleAdmin), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	// 未启用认证时没有角色信息
+	router.GET("/unauthenticated", RequireRole(RoleAdmin), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		claims jwt.MapClaims
+		want   int
+	}{
+		{"admin", "/admin", jwt.MapClaims{"sub": "u1", "app_metadata": map[string]any{"role": "admin"}}, http.StatusOK},
+		{"regular user", "/admin", jwt.MapClaims{"sub": "u2", "role": "authenticated"}, http.StatusForbidden},
+		{"other app role", "/admin", jwt.MapClaims{"sub": "u3", "app_metadata": map[string]any{"role": "editor"}}, http.StatusForbidden},
+		{"missing token", "/admin", nil, http.StatusUnauthorized},
+		{"auth disabled", "/unauthenticated", nil, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.claims != nil {
+				req.Header.Set("Authorization", "Bearer "+signToken(t, tt.claims))
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
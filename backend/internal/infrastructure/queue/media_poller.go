@@ -0,0 +1,162 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/xiajiayi/ai-motion/internal/domain/media"
+)
+
+// PollHandler 检查一个异步生成中的媒体，返回 ErrNotReady 表示服务仍在生成
+type PollHandler func(ctx context.Context, m *media.Media) error
+
+type PollerConfig struct {
+	Interval      time.Duration // 扫描生成中媒体的间隔
+	BatchSize     int
+	MinBackoff    time.Duration // 首次查询后的等待时间，之后每次翻倍
+	MaxBackoff    time.Duration
+	LeaseDuration time.Duration // 单次查询（含下载结果）的最长时间
+}
+
+// MediaPoller 轮询已提交到 AI 服务的异步生成任务（如 Sora 视频）。
+// 查询后仍在生成时保留租约直到下次查询时间，租约同时作为退避计时，多实例部署时同一媒体只会被一个实例查询
+type MediaPoller struct {
+	cfg       PollerConfig
+	owner     string
+	mediaRepo media.MediaRepository
+	mediaType media.MediaType
+	handler   PollHandler
+	wg        sync.WaitGroup
+}
+
+func NewMediaPoller(cfg PollerConfig, owner string, mediaRepo media.MediaRepository, mediaType media.MediaType, handler PollHandler) *MediaPoller {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 10
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = cfg.Interval
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		cfg.MaxBackoff = cfg.MinBackoff
+	}
+	if cfg.LeaseDuration <= 0 {
+		cfg.LeaseDuration = 5 * time.Minute
+	}
+
+	return &MediaPoller{
+		cfg:       cfg,
+		owner:     owner,
+		mediaRepo: mediaRepo,
+		mediaType: mediaType,
+		handler:   handler,
+	}
+}
+
+// Start 启动轮询协程，ctx 取消后停止
+func (p *MediaPoller) Start(ctx context.Context) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(p.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			p.Poll(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	log.Printf("Media poller started (type: %s, interval: %s)", p.mediaType, p.cfg.Interval)
+}
+
+// Wait 等待轮询协程退出
+func (p *MediaPoller) Wait() {
+	p.wg.Wait()
+}
+
+// Poll 检查一批到达查询时间的生成中媒体
+func (p *MediaPoller) Poll(ctx context.Context) {
+	mediaList, err := p.mediaRepo.FindGeneratingMedia(ctx, p.mediaType, p.cfg.BatchSize)
+	if err != nil {
+		log.Printf("Media poller: failed to find generating %s media: %v", p.mediaType, err)
+		return
+	}
+
+	for _, m := range mediaList {
+		if ctx.Err() != nil {
+			return
+		}
+
+		acquired, err := p.mediaRepo.AcquireLease(ctx, m.ID, p.owner, time.Now().Add(p.cfg.LeaseDuration))
+		if err != nil {
+			log.Printf("Media poller: failed to acquire lease for media %s: %v", m.ID, err)
+			continue
+		}
+		if !acquired {
+			continue
+		}
+
+		p.check(ctx, m)
+	}
+}
+
+func (p *MediaPoller) check(ctx context.Context, m *media.Media) {
+	checkCtx, cancel := context.WithTimeout(ctx, p.cfg.LeaseDuration)
+	err := p.handler(checkCtx, m)
+	cancel()
+
+	if errors.Is(err, ErrNotReady) {
+		if err != ErrNotReady {
+			log.Printf("Media poller: media %s not ready: %v", m.ID, err)
+		}
+		p.backoff(ctx, m)
+		return
+	}
+	if err != nil {
+		log.Printf("Media poller: media %s finished with error: %v", m.ID, err)
+	}
+
+	// 使用独立的 context 释放租约，避免关闭时 ctx 已取消导致释放失败
+	releaseCtx, releaseCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer releaseCancel()
+	if err := p.mediaRepo.ReleaseLease(releaseCtx, m.ID, p.owner); err != nil {
+		log.Printf("Media poller: failed to release lease for media %s: %v", m.ID, err)
+	}
+}
+
+// backoff 记录查询次数，并将租约延长到下次查询时间
+func (p *MediaPoller) backoff(ctx context.Context, m *media.Media) {
+	m.RecordPoll()
+	if err := p.mediaRepo.Save(ctx, m); err != nil {
+		log.Printf("Media poller: failed to record poll for media %s: %v", m.ID, err)
+	}
+
+	next := time.Now().Add(PollBackoff(m.PollCount, p.cfg.MinBackoff, p.cfg.MaxBackoff))
+	if err := p.mediaRepo.RenewLease(ctx, m.ID, p.owner, next); err != nil {
+		log.Printf("Media poller: failed to schedule next poll for media %s: %v", m.ID, err)
+	}
+}
+
+// PollBackoff 第 n 次查询后的等待时间：min * 2^(n-1)，不超过 max
+func PollBackoff(polls int, min, max time.Duration) time.Duration {
+	delay := min
+	for i := 1; i < polls && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
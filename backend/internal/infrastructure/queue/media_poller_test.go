@@ -0,0 +1,108 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/xiajiayi/ai-motion/internal/domain/media"
+)
+
+func TestPollBackoff(t *testing.T) {
+	min, max := 5*time.Second, time.Minute
+	tests := []struct {
+		polls int
+		want  time.Duration
+	}{
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{3, 20 * time.Second},
+		{4, 40 * time.Second},
+		{5, time.Minute},
+		{50, time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := PollBackoff(tt.polls, min, max); got != tt.want {
+			t.Errorf("PollBackoff(%d) = %s, want %s", tt.polls, got, tt.want)
+		}
+	}
+}
+
+// fakePollRepo 只实现轮询器用到的方法
+type fakePollRepo struct {
+	media.MediaRepository
+	generating []*media.Media
+	saved      map[media.MediaID]int
+	leases     map[media.MediaID]time.Time
+	released   map[media.MediaID]bool
+}
+
+func newFakePollRepo(list ...*media.Media) *fakePollRepo {
+	return &fakePollRepo{
+		generating: list,
+		saved:      make(map[media.MediaID]int),
+		leases:     make(map[media.MediaID]time.Time),
+		released:   make(map[media.MediaID]bool),
+	}
+}
+
+func (r *fakePollRepo) FindGeneratingMedia(ctx context.Context, mediaType media.MediaType, limit int) ([]*media.Media, error) {
+	return r.generating, nil
+}
+
+func (r *fakePollRepo) AcquireLease(ctx context.Context, id media.MediaID, owner string, until time.Time) (bool, error) {
+	r.leases[id] = until
+	return true, nil
+}
+
+func (r *fakePollRepo) RenewLease(ctx context.Context, id media.MediaID, owner string, until time.Time) error {
+	r.leases[id] = until
+	return nil
+}
+
+func (r *fakePollRepo) ReleaseLease(ctx context.Context, id media.MediaID, owner string) error {
+	r.released[id] = true
+	return nil
+}
+
+func (r *fakePollRepo) Save(ctx context.Context, m *media.Media) error {
+	r.saved[m.ID] = m.PollCount
+	return nil
+}
+
+func TestMediaPollerBacksOffUntilDone(t *testing.T) {
+	processing := &media.Media{ID: "processing", Type: media.MediaTypeVideo, Status: media.MediaStatusGenerating, PollCount: 2}
+	done := &media.Media{ID: "done", Type: media.MediaTypeVideo, Status: media.MediaStatusGenerating}
+	failed := &media.Media{ID: "failed", Type: media.MediaTypeVideo, Status: media.MediaStatusGenerating}
+	repo := newFakePollRepo(processing, done, failed)
+
+	poller := NewMediaPoller(PollerConfig{MinBackoff: 5 * time.Second, MaxBackoff: time.Minute}, "owner", repo, media.MediaTypeVideo,
+		func(ctx context.Context, m *media.Media) error {
+			switch m.ID {
+			case "processing":
+				return fmt.Errorf("%w: still rendering", ErrNotReady)
+			case "failed":
+				return errors.New("content policy violation")
+			}
+			return nil
+		})
+
+	start := time.Now()
+	poller.Poll(context.Background())
+
+	if repo.saved["processing"] != 3 {
+		t.Errorf("poll count = %d, want 3", repo.saved["processing"])
+	}
+	if next := repo.leases["processing"].Sub(start); next < 20*time.Second || next > 21*time.Second {
+		t.Errorf("next poll in %s, want ~20s", next)
+	}
+	if repo.released["processing"] {
+		t.Error("lease of unfinished media released, want it held until next poll")
+	}
+	if !repo.released["done"] || !repo.released["failed"] {
+		t.Errorf("released = %v, want finished media released", repo.released)
+	}
+}
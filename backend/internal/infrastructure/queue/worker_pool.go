@@ -0,0 +1,313 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xiajiayi/ai-motion/internal/domain/export"
+	"github.com/xiajiayi/ai-motion/internal/domain/media"
+	"github.com/xiajiayi/ai-motion/internal/domain/task"
+)
+
+// ErrNotReady 任务依赖尚未就绪，保留租约直到过期后再重新领取
+var ErrNotReady = errors.New("job dependencies not ready")
+
+// TaskHandler 处理漫画生成任务
+type TaskHandler func(ctx context.Context, taskID string) error
+
+// MediaHandler 处理待生成的媒体
+type MediaHandler func(ctx context.Context, m *media.Media) error
+
+// ExportHandler 处理导出作业
+type ExportHandler func(ctx context.Context, exportID string) error
+
+type Config struct {
+	Workers       int
+	PollInterval  time.Duration
+	LeaseDuration time.Duration
+}
+
+type jobKind string
+
+const (
+	jobKindTask   jobKind = "task"
+	jobKindMedia  jobKind = "media"
+	jobKindExport jobKind = "export"
+)
+
+type job struct {
+	kind  jobKind
+	id    string
+	media *media.Media
+}
+
+// WorkerPool 基于数据库租约的持久化任务队列：任务、媒体和导出记录本身即队列，
+// worker 通过租约独占领取，并定期心跳续约，进程重启后未完成的任务会在租约过期后被重新领取
+type WorkerPool struct {
+	cfg           Config
+	owner         string
+	taskRepo      task.Repository
+	mediaRepo     media.MediaRepository
+	exportRepo    export.Repository
+	taskHandler   TaskHandler
+	mediaHandler  MediaHandler
+	exportHandler ExportHandler
+	jobs          chan job
+	wake          chan struct{}
+	busy          atomic.Int32
+	wg            sync.WaitGroup
+}
+
+func NewWorkerPool(cfg Config, taskRepo task.Repository, mediaRepo media.MediaRepository, exportRepo export.Repository) *WorkerPool {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 2 * time.Second
+	}
+	if cfg.LeaseDuration <= 0 {
+		cfg.LeaseDuration = time.Minute
+	}
+
+	hostname, _ := os.Hostname()
+
+	return &WorkerPool{
+		cfg:        cfg,
+		owner:      fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.New().String()[:8]),
+		taskRepo:   taskRepo,
+		mediaRepo:  mediaRepo,
+		exportRepo: exportRepo,
+		jobs:       make(chan job, cfg.Workers),
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// HandleTasks 注册漫画任务处理函数
+func (p *WorkerPool) HandleTasks(handler TaskHandler) {
+	p.taskHandler = handler
+}
+
+// HandleMedia 注册媒体生成处理函数
+func (p *WorkerPool) HandleMedia(handler MediaHandler) {
+	p.mediaHandler = handler
+}
+
+// HandleExports 注册导出作业处理函数
+func (p *WorkerPool) HandleExports(handler ExportHandler) {
+	p.exportHandler = handler
+}
+
+// Owner 返回当前 worker 池的租约标识
+func (p *WorkerPool) Owner() string {
+	return p.owner
+}
+
+// Start 启动调度协程和 worker，ctx 取消后停止领取新任务
+func (p *WorkerPool) Start(ctx context.Context) {
+	for i := 0; i < p.cfg.Workers; i++ {
+		p.wg.Add(1)
+		go p.work(ctx)
+	}
+
+	p.wg.Add(1)
+	go p.dispatchLoop(ctx)
+
+	log.Printf("Job queue started (owner: %s, workers: %d)", p.owner, p.cfg.Workers)
+}
+
+// Wait 等待所有 worker 退出
+func (p *WorkerPool) Wait() {
+	p.wg.Wait()
+}
+
+// Notify 唤醒调度协程立即检查新任务
+func (p *WorkerPool) Notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (p *WorkerPool) dispatchLoop(ctx context.Context) {
+	defer p.wg.Done()
+	defer close(p.jobs)
+
+	ticker := time.NewTicker(p.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		p.dispatch(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-p.wake:
+		}
+	}
+}
+
+func (p *WorkerPool) dispatch(ctx context.Context) {
+	free := p.cfg.Workers - int(p.busy.Load())
+
+	if free > 0 && p.taskHandler != nil {
+		tasks, err := p.taskRepo.FindClaimable(ctx, free)
+		if err != nil {
+			log.Printf("Job queue: failed to find claimable tasks: %v", err)
+		}
+		for _, t := range tasks {
+			acquired, err := p.taskRepo.AcquireLease(ctx, t.ID, p.owner, p.leaseUntil())
+			if err != nil {
+				log.Printf("Job queue: failed to acquire lease for task %s: %v", t.ID, err)
+				continue
+			}
+			if !acquired {
+				continue
+			}
+			p.busy.Add(1)
+			free--
+			p.jobs <- job{kind: jobKindTask, id: t.ID}
+		}
+	}
+
+	if free > 0 && p.mediaHandler != nil {
+		mediaList, err := p.mediaRepo.FindClaimableMedia(ctx, free)
+		if err != nil {
+			log.Printf("Job queue: failed to find claimable media: %v", err)
+		}
+		for _, m := range mediaList {
+			acquired, err := p.mediaRepo.AcquireLease(ctx, m.ID, p.owner, p.leaseUntil())
+			if err != nil {
+				log.Printf("Job queue: failed to acquire lease for media %s: %v", m.ID, err)
+				continue
+			}
+			if !acquired {
+				continue
+			}
+			p.busy.Add(1)
+			free--
+			p.jobs <- job{kind: jobKindMedia, id: string(m.ID), media: m}
+		}
+	}
+
+	if free > 0 && p.exportHandler != nil {
+		exports, err := p.exportRepo.FindClaimable(ctx, free)
+		if err != nil {
+			log.Printf("Job queue: failed to find claimable exports: %v", err)
+		}
+		for _, e := range exports {
+			acquired, err := p.exportRepo.AcquireLease(ctx, e.ID, p.owner, p.leaseUntil())
+			if err != nil {
+				log.Printf("Job queue: failed to acquire lease for export %s: %v", e.ID, err)
+				continue
+			}
+			if !acquired {
+				continue
+			}
+			p.busy.Add(1)
+			free--
+			p.jobs <- job{kind: jobKindExport, id: e.ID}
+		}
+	}
+}
+
+func (p *WorkerPool) work(ctx context.Context) {
+	defer p.wg.Done()
+
+	for j := range p.jobs {
+		p.run(ctx, j)
+		p.busy.Add(-1)
+	}
+}
+
+func (p *WorkerPool) run(ctx context.Context, j job) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		p.heartbeat(jobCtx, cancel, j)
+	}()
+
+	var err error
+	switch j.kind {
+	case jobKindTask:
+		err = p.taskHandler(jobCtx, j.id)
+	case jobKindMedia:
+		err = p.mediaHandler(jobCtx, j.media)
+	case jobKindExport:
+		err = p.exportHandler(jobCtx, j.id)
+	}
+
+	cancel()
+	<-heartbeatDone
+
+	if errors.Is(err, ErrNotReady) {
+		// 保留租约，到期后再被领取，相当于延迟重试
+		return
+	}
+	if err != nil {
+		log.Printf("Job queue: %s %s finished with error: %v", j.kind, j.id, err)
+	}
+
+	// 使用独立的 context 释放租约，避免关闭时 ctx 已取消导致释放失败
+	releaseCtx, releaseCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer releaseCancel()
+
+	switch j.kind {
+	case jobKindTask:
+		err = p.taskRepo.ReleaseLease(releaseCtx, j.id, p.owner)
+	case jobKindMedia:
+		err = p.mediaRepo.ReleaseLease(releaseCtx, media.MediaID(j.id), p.owner)
+	case jobKindExport:
+		err = p.exportRepo.ReleaseLease(releaseCtx, j.id, p.owner)
+	}
+	if err != nil {
+		log.Printf("Job queue: failed to release lease for %s %s: %v", j.kind, j.id, err)
+	}
+}
+
+// heartbeat 定期续约，租约丢失时取消任务执行
+func (p *WorkerPool) heartbeat(ctx context.Context, cancel context.CancelFunc, j job) {
+	ticker := time.NewTicker(p.cfg.LeaseDuration / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var err error
+		switch j.kind {
+		case jobKindTask:
+			err = p.taskRepo.RenewLease(ctx, j.id, p.owner, p.leaseUntil())
+		case jobKindMedia:
+			err = p.mediaRepo.RenewLease(ctx, media.MediaID(j.id), p.owner, p.leaseUntil())
+		case jobKindExport:
+			err = p.exportRepo.RenewLease(ctx, j.id, p.owner, p.leaseUntil())
+		}
+
+		if errors.Is(err, task.ErrLeaseLost) || errors.Is(err, media.ErrLeaseLost) || errors.Is(err, export.ErrLeaseLost) {
+			log.Printf("Job queue: lease lost for %s %s, stopping", j.kind, j.id)
+			cancel()
+			return
+		}
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Job queue: failed to renew lease for %s %s: %v", j.kind, j.id, err)
+		}
+	}
+}
+
+func (p *WorkerPool) leaseUntil() time.Time {
+	return time.Now().Add(p.cfg.LeaseDuration)
+}
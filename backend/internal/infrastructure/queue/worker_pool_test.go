@@ -0,0 +1,241 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/xiajiayi/ai-motion/internal/domain/task"
+)
+
+// fakeLeaseRepo 只实现 worker 池领取任务用到的方法
+type fakeLeaseRepo struct {
+	task.Repository
+	mu       sync.Mutex
+	pending  []string
+	leases   map[string]string
+	renewals map[string]int
+	released map[string]bool
+	renewErr error
+}
+
+func newFakeLeaseRepo(ids ...string) *fakeLeaseRepo {
+	return &fakeLeaseRepo{
+		pending:  ids,
+		leases:   make(map[string]string),
+		renewals: make(map[string]int),
+		released: make(map[string]bool),
+	}
+}
+
+func (r *fakeLeaseRepo) FindClaimable(ctx context.Context, limit int) ([]*task.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var tasks []*task.Task
+	for _, id := range r.pending {
+		if _, leased := r.leases[id]; !leased && !r.released[id] && len(tasks) < limit {
+			tasks = append(tasks, &task.Task{ID: id})
+		}
+	}
+	return tasks, nil
+}
+
+func (r *fakeLeaseRepo) AcquireLease(ctx context.Context, taskID, owner string, until time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, leased := r.leases[taskID]; leased {
+		return false, nil
+	}
+	r.leases[taskID] = owner
+	return true, nil
+}
+
+func (r *fakeLeaseRepo) RenewLease(ctx context.Context, taskID, owner string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.renewErr != nil {
+		return r.renewErr
+	}
+	r.renewals[taskID]++
+	return nil
+}
+
+func (r *fakeLeaseRepo) ReleaseLease(ctx context.Context, taskID, owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.leases[taskID] != owner {
+		return task.ErrLeaseLost
+	}
+	delete(r.leases, taskID)
+	r.released[taskID] = true
+	return nil
+}
+
+func (r *fakeLeaseRepo) snapshot() (leases map[string]string, renewals map[string]int, released map[string]bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	leases, renewals, released = make(map[string]string), make(map[string]int), make(map[string]bool)
+	for k, v := range r.leases {
+		leases[k] = v
+	}
+	for k, v := range r.renewals {
+		renewals[k] = v
+	}
+	for k, v := range r.released {
+		released[k] = v
+	}
+	return leases, renewals, released
+}
+
+func newTestWorkerPool(repo *fakeLeaseRepo, handler TaskHandler) *WorkerPool {
+	pool := NewWorkerPool(Config{Workers: 2, PollInterval: 10 * time.Millisecond, LeaseDuration: 30 * time.Millisecond}, repo, nil, nil)
+	pool.HandleTasks(handler)
+	return pool
+}
+
+// waitFor 等待信号，超时则测试失败
+func waitFor(t *testing.T, ch <-chan string, what string) string {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+		return ""
+	}
+}
+
+func TestWorkerPoolClaimsAndReleasesTask(t *testing.T) {
+	repo := newFakeLeaseRepo("task-1")
+	handled := make(chan string, 1)
+	var leaseOwner string
+	pool := newTestWorkerPool(repo, func(ctx context.Context, taskID string) error {
+		leases, _, _ := repo.snapshot()
+		leaseOwner = leases[taskID]
+		handled <- taskID
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pool.Start(ctx)
+	id := waitFor(t, handled, "task to be handled")
+	cancel()
+	pool.Wait()
+
+	if id != "task-1" || leaseOwner != pool.Owner() {
+		t.Errorf("handled %q while leased by %q, want task-1 leased by %q", id, leaseOwner, pool.Owner())
+	}
+	leases, _, released := repo.snapshot()
+	if !released["task-1"] || len(leases) != 0 {
+		t.Errorf("released = %v, leases = %v, want lease released after the task finished", released, leases)
+	}
+	select {
+	case id := <-handled:
+		t.Errorf("task %s handled twice", id)
+	default:
+	}
+}
+
+func TestWorkerPoolRenewsLeaseWhileRunning(t *testing.T) {
+	repo := newFakeLeaseRepo("task-1")
+	handled := make(chan string, 1)
+	pool := newTestWorkerPool(repo, func(ctx context.Context, taskID string) error {
+		// 运行时间覆盖多个心跳周期（LeaseDuration / 3 = 10ms）
+		time.Sleep(80 * time.Millisecond)
+		handled <- taskID
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool.Start(ctx)
+	waitFor(t, handled, "task to be handled")
+	cancel()
+	pool.Wait()
+
+	if _, renewals, _ := repo.snapshot(); renewals["task-1"] < 2 {
+		t.Errorf("renewals = %d, want the lease renewed on every heartbeat", renewals["task-1"])
+	}
+}
+
+func TestWorkerPoolCancelsTaskWhenLeaseLost(t *testing.T) {
+	repo := newFakeLeaseRepo("task-1")
+	repo.renewErr = task.ErrLeaseLost
+	stopped := make(chan string, 1)
+	pool := newTestWorkerPool(repo, func(ctx context.Context, taskID string) error {
+		select {
+		case <-ctx.Done():
+			stopped <- taskID
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+			return nil
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool.Start(ctx)
+	waitFor(t, stopped, "task context to be cancelled after the lease was lost")
+
+	if ctx.Err() != nil {
+		t.Error("pool context cancelled, want only the task context cancelled")
+	}
+	cancel()
+	pool.Wait()
+}
+
+func TestWorkerPoolReleasesLeaseOnShutdown(t *testing.T) {
+	repo := newFakeLeaseRepo("task-1")
+	started := make(chan string, 1)
+	var handlerErr error
+	pool := newTestWorkerPool(repo, func(ctx context.Context, taskID string) error {
+		started <- taskID
+		<-ctx.Done()
+		handlerErr = ctx.Err()
+		return handlerErr
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pool.Start(ctx)
+	waitFor(t, started, "task to start")
+	cancel()
+	pool.Wait()
+
+	if !errors.Is(handlerErr, context.Canceled) {
+		t.Errorf("handler ctx error = %v, want %v", handlerErr, context.Canceled)
+	}
+	if leases, _, released := repo.snapshot(); !released["task-1"] || len(leases) != 0 {
+		t.Errorf("released = %v, leases = %v, want lease released on shutdown", released, leases)
+	}
+}
+
+func TestWorkerPoolKeepsLeaseWhenNotReady(t *testing.T) {
+	repo := newFakeLeaseRepo("task-1")
+	handled := make(chan string, 2)
+	pool := newTestWorkerPool(repo, func(ctx context.Context, taskID string) error {
+		handled <- taskID
+		return ErrNotReady
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pool.Start(ctx)
+	waitFor(t, handled, "task to be handled")
+	// 等待若干个调度周期，租约未释放时不会被再次领取
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	pool.Wait()
+
+	if len(handled) != 0 {
+		t.Error("task claimed again while its lease was held")
+	}
+	if leases, _, released := repo.snapshot(); released["task-1"] || leases["task-1"] != pool.Owner() {
+		t.Errorf("released = %v, leases = %v, want lease kept until it expires", released, leases)
+	}
+}
@@ -281,3 +281,130 @@ func (r *MediaRepository) FindPendingMedia(ctx context.Context, limit int) ([]*m
 
 	return mediaList, nil
 }
+
+func (r *MediaRepository) FindClaimableMedia(ctx context.Context, limit int) ([]*media.Media, error) {
+	query := `
+		SELECT id, novel_id, scene_id, type, status, url, width, height, duration,
+			   format, file_size, generation_id, error_message, created_at, updated_at, completed_at
+		FROM media
+		WHERE status = ? AND (lease_expires_at IS NULL OR lease_expires_at < ?)
+		ORDER BY created_at ASC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, media.MediaStatusPending, time.Now(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query claimable media: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMediaRows(rows)
+}
+
+func (r *MediaRepository) FindGeneratingMedia(ctx context.Context, mediaType media.MediaType, limit int) ([]*media.Media, error) {
+	query := `
+		SELECT id, novel_id, scene_id, type, status, url, width, height, duration,
+			   format, file_size, generation_id, error_message, created_at, updated_at, completed_at
+		FROM media
+		WHERE type = ? AND status = ? AND (lease_expires_at IS NULL OR lease_expires_at < ?)
+		ORDER BY updated_at ASC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, mediaType, media.MediaStatusGenerating, time.Now(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query generating media: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMediaRows(rows)
+}
+
+// FindByCacheKey MySQL 存储不保存缓存键和生成来源，生成缓存仅支持 Supabase，这里始终视为未命中
+func (r *MediaRepository) FindByCacheKey(ctx context.Context, cacheKey string) (*media.Media, error) {
+	return nil, media.ErrMediaNotFound
+}
+
+func (r *MediaRepository) AcquireLease(ctx context.Context, id media.MediaID, owner string, until time.Time) (bool, error) {
+	query := `
+		UPDATE media
+		SET lease_owner = ?, lease_expires_at = ?
+		WHERE id = ? AND (lease_expires_at IS NULL OR lease_expires_at < ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, owner, until, id, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire media lease: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+func (r *MediaRepository) RenewLease(ctx context.Context, id media.MediaID, owner string, until time.Time) error {
+	query := `UPDATE media SET lease_expires_at = ? WHERE id = ? AND lease_owner = ?`
+
+	result, err := r.db.ExecContext(ctx, query, until, id, owner)
+	if err != nil {
+		return fmt.Errorf("failed to renew media lease: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rows == 0 {
+		return media.ErrLeaseLost
+	}
+
+	return nil
+}
+
+func (r *MediaRepository) ReleaseLease(ctx context.Context, id media.MediaID, owner string) error {
+	query := `UPDATE media SET lease_owner = NULL, lease_expires_at = NULL WHERE id = ? AND lease_owner = ?`
+
+	if _, err := r.db.ExecContext(ctx, query, id, owner); err != nil {
+		return fmt.Errorf("failed to release media lease: %w", err)
+	}
+
+	return nil
+}
+
+func scanMediaRows(rows *sql.Rows) ([]*media.Media, error) {
+	var mediaList []*media.Media
+	for rows.Next() {
+		var m media.Media
+		var completedAt sql.NullTime
+		var novelID, sceneID sql.NullString
+
+		err := rows.Scan(
+			&m.ID, &novelID, &sceneID, &m.Type, &m.Status, &m.URL,
+			&m.Metadata.Width, &m.Metadata.Height, &m.Metadata.Duration,
+			&m.Metadata.Format, &m.Metadata.FileSize,
+			&m.GenerationID, &m.ErrorMessage,
+			&m.CreatedAt, &m.UpdatedAt, &completedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan media: %w", err)
+		}
+
+		if novelID.Valid {
+			m.NovelID = novelID.String
+		}
+		if sceneID.Valid {
+			m.SceneID = sceneID.String
+		}
+		if completedAt.Valid {
+			m.CompletedAt = &completedAt.Time
+		}
+
+		mediaList = append(mediaList, &m)
+	}
+
+	return mediaList, rows.Err()
+}
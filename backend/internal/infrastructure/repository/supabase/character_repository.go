@@ -28,6 +28,11 @@ func (r *CharacterRepository) Save(ctx context.Context, char *character.Characte
 		return fmt.Errorf("failed to marshal personality: %w", err)
 	}
 
+	voiceJSON, err := json.Marshal(char.Voice)
+	if err != nil {
+		return fmt.Errorf("failed to marshal voice: %w", err)
+	}
+
 	data := map[string]interface{}{
 		"id":                  string(char.ID),
 		"novel_id":            char.NovelID,
@@ -37,6 +42,7 @@ func (r *CharacterRepository) Save(ctx context.Context, char *character.Characte
 		"personality":         string(personalityJSON),
 		"description":         char.Description,
 		"reference_image_url": char.ReferenceImageURL,
+		"voice":               string(voiceJSON),
 		"created_at":          char.CreatedAt,
 		"updated_at":          char.UpdatedAt,
 	}
@@ -173,5 +179,11 @@ func (r *CharacterRepository) mapToCharacter(data map[string]interface{}) (*char
 		}
 	}
 
+	if voiceStr, ok := data["voice"].(string); ok && voiceStr != "" {
+		if err := json.Unmarshal([]byte(voiceStr), &char.Voice); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal voice: %w", err)
+		}
+	}
+
 	return char, nil
 }
@@ -2,6 +2,7 @@ package supabase
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -19,22 +20,47 @@ func NewMediaRepository(client *postgrest.Client) media.MediaRepository {
 
 func (r *MediaRepository) Save(ctx context.Context, m *media.Media) error {
 	data := map[string]interface{}{
-		"id":             string(m.ID),
-		"novel_id":       m.NovelID,
-		"scene_id":       m.SceneID,
-		"type":           string(m.Type),
-		"status":         string(m.Status),
-		"url":            m.URL,
-		"width":          m.Metadata.Width,
-		"height":         m.Metadata.Height,
-		"duration":       m.Metadata.Duration,
-		"format":         m.Metadata.Format,
-		"file_size":      m.Metadata.FileSize,
-		"generation_id":  m.GenerationID,
-		"error_message":  m.ErrorMessage,
-		"created_at":     m.CreatedAt,
-		"updated_at":     m.UpdatedAt,
-		"completed_at":   m.CompletedAt,
+		"id":                string(m.ID),
+		"novel_id":          m.NovelID,
+		"user_id":           nullableUUID(m.UserID),
+		"scene_id":          m.SceneID,
+		"type":              string(m.Type),
+		"status":            string(m.Status),
+		"url":               m.URL,
+		"width":             m.Metadata.Width,
+		"height":            m.Metadata.Height,
+		"duration":          m.Metadata.Duration,
+		"format":            m.Metadata.Format,
+		"file_size":         m.Metadata.FileSize,
+		"generation_id":     m.GenerationID,
+		"error_message":     m.ErrorMessage,
+		"submitted_at":      m.SubmittedAt,
+		"poll_count":        m.PollCount,
+		"cache_key":         m.CacheKey,
+		"created_at":        m.CreatedAt,
+		"updated_at":        m.UpdatedAt,
+		"completed_at":      m.CompletedAt,
+		"provider":          nil,
+		"model":             nil,
+		"generation_params": nil,
+		"revised_prompt":    nil,
+	}
+	if p := m.Provenance; p != nil {
+		data["provider"] = p.Provider
+		data["model"] = p.Model
+		data["generation_params"] = generationParamsRecord{
+			Prompt:         p.Params.Prompt,
+			NegativePrompt: p.Params.NegativePrompt,
+			Style:          p.Params.Style,
+			Quality:        p.Params.Quality,
+			ReferenceImage: p.Params.ReferenceImage,
+			Seed:           p.Params.Seed,
+			Steps:          p.Params.Steps,
+			CFGScale:       p.Params.CFGScale,
+			Width:          p.Width,
+			Height:         p.Height,
+		}
+		data["revised_prompt"] = p.RevisedPrompt
 	}
 
 	_, _, err := r.client.From("aimotion_media").Upsert(data, "", "", "").Execute()
@@ -173,6 +199,135 @@ func (r *MediaRepository) FindPendingMedia(ctx context.Context, limit int) ([]*m
 	return mediaList, nil
 }
 
+// FindClaimableMedia 查询可被 worker 领取的待生成媒体
+func (r *MediaRepository) FindClaimableMedia(ctx context.Context, limit int) ([]*media.Media, error) {
+	var results []map[string]interface{}
+
+	_, err := r.client.From("aimotion_media").
+		Select("*", "", false).
+		Eq("status", string(media.MediaStatusPending)).
+		Or(leaseExpiredFilter(time.Now()), "").
+		Order("created_at", &postgrest.OrderOpts{Ascending: true}).
+		Limit(limit, "").
+		ExecuteTo(&results)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find claimable media: %w", err)
+	}
+
+	var mediaList []*media.Media
+	for _, result := range results {
+		m, err := r.mapToMedia(result)
+		if err != nil {
+			return nil, err
+		}
+		mediaList = append(mediaList, m)
+	}
+
+	return mediaList, nil
+}
+
+// FindGeneratingMedia 查询等待 AI 服务完成的媒体（如已提交的视频），租约未过期的媒体正处于轮询退避中
+func (r *MediaRepository) FindGeneratingMedia(ctx context.Context, mediaType media.MediaType, limit int) ([]*media.Media, error) {
+	var results []map[string]interface{}
+
+	_, err := r.client.From("aimotion_media").
+		Select("*", "", false).
+		Eq("type", string(mediaType)).
+		Eq("status", string(media.MediaStatusGenerating)).
+		Or(leaseExpiredFilter(time.Now()), "").
+		Order("updated_at", &postgrest.OrderOpts{Ascending: true}).
+		Limit(limit, "").
+		ExecuteTo(&results)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find generating media: %w", err)
+	}
+
+	var mediaList []*media.Media
+	for _, result := range results {
+		m, err := r.mapToMedia(result)
+		if err != nil {
+			return nil, err
+		}
+		mediaList = append(mediaList, m)
+	}
+
+	return mediaList, nil
+}
+
+// FindByCacheKey 查询该键最近一次完成的媒体
+func (r *MediaRepository) FindByCacheKey(ctx context.Context, cacheKey string) (*media.Media, error) {
+	var results []map[string]interface{}
+
+	_, err := r.client.From("aimotion_media").
+		Select("*", "", false).
+		Eq("cache_key", cacheKey).
+		Eq("status", string(media.MediaStatusCompleted)).
+		Order("created_at", &postgrest.OrderOpts{Ascending: false}).
+		Limit(1, "").
+		ExecuteTo(&results)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find media by cache key: %w", err)
+	}
+
+	if len(results) == 0 {
+		return nil, media.ErrMediaNotFound
+	}
+
+	return r.mapToMedia(results[0])
+}
+
+func (r *MediaRepository) AcquireLease(ctx context.Context, id media.MediaID, owner string, until time.Time) (bool, error) {
+	var results []map[string]interface{}
+
+	_, err := r.client.From("aimotion_media").
+		Update(newLeaseRecord(owner, until), "representation", "").
+		Eq("id", string(id)).
+		Or(leaseExpiredFilter(time.Now()), "").
+		ExecuteTo(&results)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire media lease: %w", err)
+	}
+
+	return len(results) > 0, nil
+}
+
+func (r *MediaRepository) RenewLease(ctx context.Context, id media.MediaID, owner string, until time.Time) error {
+	var results []map[string]interface{}
+
+	_, err := r.client.From("aimotion_media").
+		Update(newLeaseRecord(owner, until), "representation", "").
+		Eq("id", string(id)).
+		Eq("lease_owner", owner).
+		ExecuteTo(&results)
+
+	if err != nil {
+		return fmt.Errorf("failed to renew media lease: %w", err)
+	}
+	if len(results) == 0 {
+		return media.ErrLeaseLost
+	}
+
+	return nil
+}
+
+func (r *MediaRepository) ReleaseLease(ctx context.Context, id media.MediaID, owner string) error {
+	_, _, err := r.client.From("aimotion_media").
+		Update(leaseRecord{}, "minimal", "").
+		Eq("id", string(id)).
+		Eq("lease_owner", owner).
+		Execute()
+
+	if err != nil {
+		return fmt.Errorf("failed to release media lease: %w", err)
+	}
+
+	return nil
+}
+
 func (r *MediaRepository) mapToMedia(data map[string]interface{}) (*media.Media, error) {
 	m := &media.Media{}
 
@@ -215,6 +370,37 @@ func (r *MediaRepository) mapToMedia(data map[string]interface{}) (*media.Media,
 	if errorMessage, ok := data["error_message"].(string); ok {
 		m.ErrorMessage = errorMessage
 	}
+	if submittedAtStr, ok := data["submitted_at"].(string); ok && submittedAtStr != "" {
+		if submittedAt, err := time.Parse(time.RFC3339, submittedAtStr); err == nil {
+			m.SubmittedAt = &submittedAt
+		}
+	}
+	if pollCount, ok := data["poll_count"].(float64); ok {
+		m.PollCount = int(pollCount)
+	}
+	if cacheKey, ok := data["cache_key"].(string); ok {
+		m.CacheKey = cacheKey
+	}
+	if userID, ok := data["user_id"].(string); ok {
+		m.UserID = userID
+	}
+	if provider, ok := data["provider"].(string); ok && provider != "" {
+		provenance, err := mapToProvenance(provider, data)
+		if err != nil {
+			return nil, err
+		}
+		m.Provenance = provenance
+	}
+	if createdAtStr, ok := data["created_at"].(string); ok && createdAtStr != "" {
+		if createdAt, err := time.Parse(time.RFC3339, createdAtStr); err == nil {
+			m.CreatedAt = createdAt
+		}
+	}
+	if updatedAtStr, ok := data["updated_at"].(string); ok && updatedAtStr != "" {
+		if updatedAt, err := time.Parse(time.RFC3339, updatedAtStr); err == nil {
+			m.UpdatedAt = updatedAt
+		}
+	}
 	if completedAtStr, ok := data["completed_at"].(string); ok && completedAtStr != "" {
 		completedAt, err := time.Parse(time.RFC3339, completedAtStr)
 		if err == nil {
@@ -224,3 +410,52 @@ func (r *MediaRepository) mapToMedia(data map[string]interface{}) (*media.Media,
 
 	return m, nil
 }
+
+// generationParamsRecord generation_params 列的 JSON 结构
+type generationParamsRecord struct {
+	Prompt         string  `json:"prompt"`
+	NegativePrompt string  `json:"negative_prompt,omitempty"`
+	Style          string  `json:"style,omitempty"`
+	Quality        string  `json:"quality,omitempty"`
+	ReferenceImage string  `json:"reference_image,omitempty"`
+	Seed           int64   `json:"seed,string"` // 以字符串保存，避免超过 2^53 的种子经 JSON 数字读写后丢失精度
+	Steps          int     `json:"steps,omitempty"`
+	CFGScale       float64 `json:"cfg_scale,omitempty"`
+	Width          int     `json:"width,omitempty"`
+	Height         int     `json:"height,omitempty"`
+}
+
+func mapToProvenance(provider string, data map[string]interface{}) (*media.Provenance, error) {
+	p := &media.Provenance{Provider: provider}
+	if model, ok := data["model"].(string); ok {
+		p.Model = model
+	}
+	if revisedPrompt, ok := data["revised_prompt"].(string); ok {
+		p.RevisedPrompt = revisedPrompt
+	}
+
+	if raw, ok := data["generation_params"]; ok && raw != nil {
+		encoded, err := json.Marshal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode generation params: %w", err)
+		}
+		var params generationParamsRecord
+		if err := json.Unmarshal(encoded, &params); err != nil {
+			return nil, fmt.Errorf("failed to decode generation params: %w", err)
+		}
+		p.Params = media.GenerationParams{
+			Prompt:         params.Prompt,
+			NegativePrompt: params.NegativePrompt,
+			Style:          params.Style,
+			Quality:        params.Quality,
+			ReferenceImage: params.ReferenceImage,
+			Seed:           params.Seed,
+			Steps:          params.Steps,
+			CFGScale:       params.CFGScale,
+		}
+		p.Width = params.Width
+		p.Height = params.Height
+	}
+
+	return p, nil
+}
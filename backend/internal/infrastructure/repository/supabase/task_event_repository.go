@@ -0,0 +1,100 @@
+package supabase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/supabase-community/postgrest-go"
+	"github.com/xiajiayi/ai-motion/internal/domain/task"
+)
+
+// TaskEventRepository 任务进度事件仓储
+// 事件由后台 worker 写入，读取前调用方已完成任务归属校验，因此均使用服务端客户端
+type TaskEventRepository struct {
+	client *postgrest.Client
+}
+
+func NewTaskEventRepository(client *postgrest.Client) task.EventRepository {
+	return &TaskEventRepository{
+		client: client,
+	}
+}
+
+// taskEventRecord Supabase中的任务事件记录结构
+type taskEventRecord struct {
+	ID        int64           `json:"id,omitempty"`
+	TaskID    string          `json:"task_id"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt string          `json:"created_at,omitempty"`
+}
+
+// Append 持久化事件并回填事件ID
+func (r *TaskEventRepository) Append(ctx context.Context, events []task.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	records := make([]taskEventRecord, 0, len(events))
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal task event: %w", err)
+		}
+		records = append(records, taskEventRecord{
+			TaskID:    event.TaskID,
+			Type:      string(event.Type),
+			Payload:   payload,
+			CreatedAt: event.CreatedAt.UTC().Format(leaseTimeFormat),
+		})
+	}
+
+	var inserted []taskEventRecord
+	_, err := r.client.From("aimotion_task_event").
+		Insert(records, false, "", "representation", "").
+		ExecuteTo(&inserted)
+
+	if err != nil {
+		return fmt.Errorf("failed to append task events: %w", err)
+	}
+
+	// 批量插入按顺序返回，依次回填自增ID
+	for i := range events {
+		if i < len(inserted) {
+			events[i].ID = inserted[i].ID
+		}
+	}
+
+	return nil
+}
+
+// FindAfter 查询任务中ID大于 afterID 的事件，按ID升序
+func (r *TaskEventRepository) FindAfter(ctx context.Context, taskID string, afterID int64, limit int) ([]task.Event, error) {
+	var records []taskEventRecord
+
+	_, err := r.client.From("aimotion_task_event").
+		Select("*", "", false).
+		Eq("task_id", taskID).
+		Gt("id", strconv.FormatInt(afterID, 10)).
+		Order("id", &postgrest.OrderOpts{Ascending: true}).
+		Limit(limit, "").
+		ExecuteTo(&records)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find task events: %w", err)
+	}
+
+	events := make([]task.Event, 0, len(records))
+	for _, record := range records {
+		var event task.Event
+		if err := json.Unmarshal(record.Payload, &event); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal task event %d: %w", record.ID, err)
+		}
+		event.ID = record.ID
+		events = append(events, event)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,207 @@
+package supabase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/supabase-community/postgrest-go"
+	"github.com/xiajiayi/ai-motion/internal/domain/export"
+)
+
+// TaskExportRepository 导出作业仓储
+// 作业由后台 worker 处理，用户查询前调用方已完成任务归属校验，因此均使用服务端客户端
+type TaskExportRepository struct {
+	client *postgrest.Client
+}
+
+func NewTaskExportRepository(client *postgrest.Client) export.Repository {
+	return &TaskExportRepository{
+		client: client,
+	}
+}
+
+// taskExportRecord Supabase中的导出作业记录结构，不含租约字段以免 Save 覆盖 worker 的心跳
+type taskExportRecord struct {
+	ID           string          `json:"id"`
+	TaskID       string          `json:"task_id"`
+	UserID       string          `json:"user_id"`
+	Format       string          `json:"format"`
+	Status       string          `json:"status"`
+	Progress     int             `json:"progress"`
+	MediaID      *string         `json:"media_id"`
+	Attachments  json.RawMessage `json:"attachments"`
+	ErrorMessage *string         `json:"error_message"`
+	CreatedAt    string          `json:"created_at"`
+	UpdatedAt    string          `json:"updated_at"`
+	CompletedAt  *string         `json:"completed_at"`
+}
+
+// Save 保存导出作业（创建或更新）
+func (r *TaskExportRepository) Save(ctx context.Context, e *export.Export) error {
+	attachments := e.Attachments
+	if attachments == nil {
+		attachments = []export.Attachment{}
+	}
+	attachmentsJSON, err := json.Marshal(attachments)
+	if err != nil {
+		return fmt.Errorf("failed to marshal export attachments: %w", err)
+	}
+
+	record := taskExportRecord{
+		ID:          e.ID,
+		TaskID:      e.TaskID,
+		UserID:      e.UserID,
+		Format:      string(e.Format),
+		Status:      string(e.Status),
+		Progress:    e.Progress,
+		Attachments: attachmentsJSON,
+		CreatedAt:   e.CreatedAt.UTC().Format(leaseTimeFormat),
+		UpdatedAt:   e.UpdatedAt.UTC().Format(leaseTimeFormat),
+	}
+	if e.MediaID != "" {
+		record.MediaID = &e.MediaID
+	}
+	if e.ErrorMessage != "" {
+		record.ErrorMessage = &e.ErrorMessage
+	}
+	if e.CompletedAt != nil {
+		completedAt := e.CompletedAt.UTC().Format(leaseTimeFormat)
+		record.CompletedAt = &completedAt
+	}
+
+	_, _, err = r.client.From("aimotion_task_export").
+		Upsert(record, "", "", "").
+		Execute()
+	if err != nil {
+		return fmt.Errorf("failed to save export: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID 查询导出作业
+func (r *TaskExportRepository) FindByID(ctx context.Context, id string) (*export.Export, error) {
+	var records []taskExportRecord
+
+	_, err := r.client.From("aimotion_task_export").
+		Select("*", "", false).
+		Eq("id", id).
+		ExecuteTo(&records)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find export: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, export.ErrExportNotFound
+	}
+
+	return recordToExport(&records[0])
+}
+
+// FindClaimable 查询可被 worker 领取的导出作业
+func (r *TaskExportRepository) FindClaimable(ctx context.Context, limit int) ([]*export.Export, error) {
+	var records []taskExportRecord
+	_, err := r.client.From("aimotion_task_export").
+		Select("*", "", false).
+		In("status", []string{string(export.StatusPending), string(export.StatusProcessing)}).
+		Or(leaseExpiredFilter(time.Now()), "").
+		Order("created_at", &postgrest.OrderOpts{Ascending: true}).
+		Limit(limit, "").
+		ExecuteTo(&records)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find claimable exports: %w", err)
+	}
+
+	exports := make([]*export.Export, 0, len(records))
+	for i := range records {
+		e, err := recordToExport(&records[i])
+		if err != nil {
+			return nil, err
+		}
+		exports = append(exports, e)
+	}
+
+	return exports, nil
+}
+
+// AcquireLease 尝试获取导出作业租约（条件更新，仅当租约为空或已过期时成功）
+func (r *TaskExportRepository) AcquireLease(ctx context.Context, id, owner string, until time.Time) (bool, error) {
+	var records []taskExportRecord
+	_, err := r.client.From("aimotion_task_export").
+		Update(newLeaseRecord(owner, until), "representation", "").
+		Eq("id", id).
+		Or(leaseExpiredFilter(time.Now()), "").
+		ExecuteTo(&records)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire export lease: %w", err)
+	}
+
+	return len(records) > 0, nil
+}
+
+// RenewLease 续约（心跳）
+func (r *TaskExportRepository) RenewLease(ctx context.Context, id, owner string, until time.Time) error {
+	var records []taskExportRecord
+	_, err := r.client.From("aimotion_task_export").
+		Update(newLeaseRecord(owner, until), "representation", "").
+		Eq("id", id).
+		Eq("lease_owner", owner).
+		ExecuteTo(&records)
+
+	if err != nil {
+		return fmt.Errorf("failed to renew export lease: %w", err)
+	}
+	if len(records) == 0 {
+		return export.ErrLeaseLost
+	}
+
+	return nil
+}
+
+// ReleaseLease 释放导出作业租约
+func (r *TaskExportRepository) ReleaseLease(ctx context.Context, id, owner string) error {
+	_, _, err := r.client.From("aimotion_task_export").
+		Update(leaseRecord{}, "minimal", "").
+		Eq("id", id).
+		Eq("lease_owner", owner).
+		Execute()
+
+	if err != nil {
+		return fmt.Errorf("failed to release export lease: %w", err)
+	}
+
+	return nil
+}
+
+func recordToExport(record *taskExportRecord) (*export.Export, error) {
+	e := &export.Export{
+		ID:        record.ID,
+		TaskID:    record.TaskID,
+		UserID:    record.UserID,
+		Format:    export.Format(record.Format),
+		Status:    export.Status(record.Status),
+		Progress:  record.Progress,
+		CreatedAt: parseTimestamp(record.CreatedAt),
+		UpdatedAt: parseTimestamp(record.UpdatedAt),
+	}
+	if record.MediaID != nil {
+		e.MediaID = *record.MediaID
+	}
+	if record.ErrorMessage != nil {
+		e.ErrorMessage = *record.ErrorMessage
+	}
+	if record.CompletedAt != nil {
+		completedAt := parseTimestamp(*record.CompletedAt)
+		e.CompletedAt = &completedAt
+	}
+	if len(record.Attachments) > 0 && string(record.Attachments) != "null" {
+		if err := json.Unmarshal(record.Attachments, &e.Attachments); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal attachments of export %s: %w", record.ID, err)
+		}
+	}
+	return e, nil
+}
@@ -0,0 +1,116 @@
+package supabase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/supabase-community/postgrest-go"
+	"github.com/xiajiayi/ai-motion/internal/domain/page"
+)
+
+// TaskPageRepository 漫画页面仓储
+// 调用方已完成任务归属校验，因此均使用服务端客户端
+type TaskPageRepository struct {
+	client *postgrest.Client
+}
+
+func NewTaskPageRepository(client *postgrest.Client) page.Repository {
+	return &TaskPageRepository{
+		client: client,
+	}
+}
+
+// taskPageRecord Supabase中的漫画页面记录结构
+type taskPageRecord struct {
+	ID               string          `json:"id"`
+	TaskID           string          `json:"task_id"`
+	PageNumber       int             `json:"page_number"`
+	MediaID          string          `json:"media_id"`
+	Template         string          `json:"template"`
+	ReadingDirection string          `json:"reading_direction"`
+	Panels           json.RawMessage `json:"panels"`
+	CreatedAt        string          `json:"created_at"`
+}
+
+// ReplaceByTaskID 删除任务原有的页面后写入新页面，页面图片保留在媒体表中
+func (r *TaskPageRepository) ReplaceByTaskID(ctx context.Context, taskID string, pages []*page.Page) error {
+	records := make([]taskPageRecord, 0, len(pages))
+	for _, p := range pages {
+		panels := p.Panels
+		if panels == nil {
+			panels = []page.Placement{}
+		}
+		panelsJSON, err := json.Marshal(panels)
+		if err != nil {
+			return fmt.Errorf("failed to marshal page panels: %w", err)
+		}
+		records = append(records, taskPageRecord{
+			ID:               p.ID,
+			TaskID:           taskID,
+			PageNumber:       p.Number,
+			MediaID:          p.MediaID,
+			Template:         p.Template,
+			ReadingDirection: string(p.Direction),
+			Panels:           panelsJSON,
+			CreatedAt:        p.CreatedAt.UTC().Format(leaseTimeFormat),
+		})
+	}
+
+	_, _, err := r.client.From("aimotion_task_page").
+		Delete("", "").
+		Eq("task_id", taskID).
+		Execute()
+	if err != nil {
+		return fmt.Errorf("failed to delete task pages: %w", err)
+	}
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	_, _, err = r.client.From("aimotion_task_page").
+		Insert(records, false, "", "", "").
+		Execute()
+	if err != nil {
+		return fmt.Errorf("failed to insert task pages: %w", err)
+	}
+
+	return nil
+}
+
+// FindByTaskID 查询任务的页面，按页码升序
+func (r *TaskPageRepository) FindByTaskID(ctx context.Context, taskID string) ([]*page.Page, error) {
+	var records []taskPageRecord
+
+	_, err := r.client.From("aimotion_task_page").
+		Select("*", "", false).
+		Eq("task_id", taskID).
+		Order("page_number", &postgrest.OrderOpts{Ascending: true}).
+		ExecuteTo(&records)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find task pages: %w", err)
+	}
+
+	pages := make([]*page.Page, 0, len(records))
+	for _, record := range records {
+		p := &page.Page{
+			ID:        record.ID,
+			TaskID:    record.TaskID,
+			Number:    record.PageNumber,
+			MediaID:   record.MediaID,
+			Template:  record.Template,
+			Direction: page.ReadingDirection(record.ReadingDirection),
+			CreatedAt: parseTimestamp(record.CreatedAt),
+		}
+		if len(record.Panels) > 0 && string(record.Panels) != "null" {
+			if err := json.Unmarshal(record.Panels, &p.Panels); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal panels of page %d: %w", record.PageNumber, err)
+			}
+		}
+		pages = append(pages, p)
+	}
+
+	return pages, nil
+}
@@ -0,0 +1,111 @@
+package supabase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/supabase-community/postgrest-go"
+	"github.com/xiajiayi/ai-motion/internal/domain/task"
+)
+
+// TaskPanelRepository 任务面板仓储
+// 面板由后台 worker 生成时写入，用户操作前调用方已完成任务归属校验，因此均使用服务端客户端
+type TaskPanelRepository struct {
+	client *postgrest.Client
+}
+
+func NewTaskPanelRepository(client *postgrest.Client) task.PanelRepository {
+	return &TaskPanelRepository{
+		client: client,
+	}
+}
+
+// taskPanelRecord Supabase中的任务面板记录结构
+type taskPanelRecord struct {
+	ID            string          `json:"id"`
+	TaskID        string          `json:"task_id"`
+	SequenceNum   int             `json:"sequence_num"`
+	SceneID       *string         `json:"scene_id"`
+	ActiveMediaID *string         `json:"active_media_id"`
+	Versions      json.RawMessage `json:"versions"`
+	CreatedAt     string          `json:"created_at"`
+	UpdatedAt     string          `json:"updated_at"`
+}
+
+// Save 保存面板（按 task_id + sequence_num 创建或更新）
+func (r *TaskPanelRepository) Save(ctx context.Context, panel *task.Panel) error {
+	versions := panel.Versions
+	if versions == nil {
+		versions = []task.PanelVersion{}
+	}
+	versionsJSON, err := json.Marshal(versions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal panel versions: %w", err)
+	}
+
+	record := taskPanelRecord{
+		ID:          panel.ID,
+		TaskID:      panel.TaskID,
+		SequenceNum: panel.SequenceNum,
+		Versions:    versionsJSON,
+		CreatedAt:   panel.CreatedAt.UTC().Format(leaseTimeFormat),
+		UpdatedAt:   panel.UpdatedAt.UTC().Format(leaseTimeFormat),
+	}
+	if panel.SceneID != "" {
+		record.SceneID = &panel.SceneID
+	}
+	if panel.ActiveMediaID != "" {
+		record.ActiveMediaID = &panel.ActiveMediaID
+	}
+
+	_, _, err = r.client.From("aimotion_task_panel").
+		Upsert(record, "task_id,sequence_num", "", "").
+		Execute()
+
+	if err != nil {
+		return fmt.Errorf("failed to save task panel: %w", err)
+	}
+
+	return nil
+}
+
+// FindByTaskID 查询任务的全部面板，按面板序号升序
+func (r *TaskPanelRepository) FindByTaskID(ctx context.Context, taskID string) ([]*task.Panel, error) {
+	var records []taskPanelRecord
+
+	_, err := r.client.From("aimotion_task_panel").
+		Select("*", "", false).
+		Eq("task_id", taskID).
+		Order("sequence_num", &postgrest.OrderOpts{Ascending: true}).
+		ExecuteTo(&records)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find task panels: %w", err)
+	}
+
+	panels := make([]*task.Panel, 0, len(records))
+	for _, record := range records {
+		panel := &task.Panel{
+			ID:          record.ID,
+			TaskID:      record.TaskID,
+			SequenceNum: record.SequenceNum,
+			CreatedAt:   parseTimestamp(record.CreatedAt),
+			UpdatedAt:   parseTimestamp(record.UpdatedAt),
+		}
+		if record.SceneID != nil {
+			panel.SceneID = *record.SceneID
+		}
+		if record.ActiveMediaID != nil {
+			panel.ActiveMediaID = *record.ActiveMediaID
+		}
+		if len(record.Versions) > 0 && string(record.Versions) != "null" {
+			if err := json.Unmarshal(record.Versions, &panel.Versions); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal versions of panel %s: %w", record.ID, err)
+			}
+		}
+		panels = append(panels, panel)
+	}
+
+	return panels, nil
+}
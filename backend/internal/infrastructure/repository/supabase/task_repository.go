@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/supabase-community/postgrest-go"
 	"github.com/xiajiayi/ai-motion/internal/domain/task"
@@ -46,8 +47,11 @@ type taskRecord struct {
 	ProgressStepIndex  int             `json:"progress_step_index"`
 	ProgressPercentage int             `json:"progress_percentage"`
 	ProgressDetails    json.RawMessage `json:"progress_details"`
+	Options            json.RawMessage `json:"options"`
 	ErrorCode          *int            `json:"error_code"`
 	ErrorMessage       *string         `json:"error_message"`
+	RetryCount         int             `json:"retry_count"`
+	Attempts           json.RawMessage `json:"attempts"`
 	CreatedAt          string          `json:"created_at"`
 	UpdatedAt          string          `json:"updated_at"`
 	CompletedAt        *string         `json:"completed_at"`
@@ -56,13 +60,92 @@ type taskRecord struct {
 
 // Save 保存任务（创建或更新）
 func (r *TaskRepository) Save(ctx context.Context, t *task.Task) error {
+	record, err := r.taskToRecord(t)
+	if err != nil {
+		return err
+	}
+
+	// 使用 upsert（如果ID存在则更新，否则插入）
+	client := r.getClientWithAuth(ctx)
+	_, _, err = client.From("aimotion_task").
+		Upsert(record, "", "", "").
+		Execute()
+
+	if err != nil {
+		return fmt.Errorf("failed to save task: %w", err)
+	}
+
+	return nil
+}
+
+// SaveUnlessCancelled 条件更新任务，数据库中状态已为 cancelled 时不覆盖（后台调用，使用服务端客户端）
+func (r *TaskRepository) SaveUnlessCancelled(ctx context.Context, t *task.Task) (bool, error) {
+	record, err := r.taskToRecord(t)
+	if err != nil {
+		return false, err
+	}
+
+	var records []taskRecord
+	_, err = r.client.From("aimotion_task").
+		Update(record, "representation", "").
+		Eq("id", t.ID).
+		Neq("status", string(task.TaskStatusCancelled)).
+		ExecuteTo(&records)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to save task: %w", err)
+	}
+
+	return len(records) > 0, nil
+}
+
+// CancelIfActive 条件更新任务，仅在数据库中状态为 pending 或 processing 时写入，避免覆盖 worker 已写入的结束状态
+func (r *TaskRepository) CancelIfActive(ctx context.Context, t *task.Task) (bool, error) {
+	record, err := r.taskToRecord(t)
+	if err != nil {
+		return false, err
+	}
+
+	var records []taskRecord
+	client := r.getClientWithAuth(ctx)
+	_, err = client.From("aimotion_task").
+		Update(record, "representation", "").
+		Eq("id", t.ID).
+		In("status", []string{string(task.TaskStatusPending), string(task.TaskStatusProcessing)}).
+		ExecuteTo(&records)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel task: %w", err)
+	}
+
+	return len(records) > 0, nil
+}
+
+// taskToRecord 将domain对象转换为数据库记录
+func (r *TaskRepository) taskToRecord(t *task.Task) (*taskRecord, error) {
 	// 序列化 progress_details
 	detailsJSON, err := json.Marshal(t.ProgressDetails)
 	if err != nil {
-		return fmt.Errorf("failed to marshal progress details: %w", err)
+		return nil, fmt.Errorf("failed to marshal progress details: %w", err)
+	}
+
+	// 序列化 options
+	optionsJSON, err := json.Marshal(t.Options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal options: %w", err)
 	}
 
-	record := taskRecord{
+	// 序列化 attempts
+	attempts := t.Attempts
+	if attempts == nil {
+		attempts = []task.Attempt{}
+	}
+	attemptsJSON, err := json.Marshal(attempts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal attempts: %w", err)
+	}
+
+	record := &taskRecord{
 		ID:                 t.ID,
 		UserID:             t.UserID,
 		NovelID:            t.NovelID,
@@ -71,6 +154,9 @@ func (r *TaskRepository) Save(ctx context.Context, t *task.Task) error {
 		ProgressStepIndex:  t.ProgressStepIndex,
 		ProgressPercentage: t.ProgressPercentage,
 		ProgressDetails:    detailsJSON,
+		Options:            optionsJSON,
+		RetryCount:         t.RetryCount,
+		Attempts:           attemptsJSON,
 		CreatedAt:          t.CreatedAt.Format("2006-01-02T15:04:05.999999Z07:00"),
 		UpdatedAt:          t.UpdatedAt.Format("2006-01-02T15:04:05.999999Z07:00"),
 	}
@@ -90,17 +176,7 @@ func (r *TaskRepository) Save(ctx context.Context, t *task.Task) error {
 		record.FailedAt = &failedStr
 	}
 
-	// 使用 upsert（如果ID存在则更新，否则插入）
-	client := r.getClientWithAuth(ctx)
-	_, _, err = client.From("aimotion_task").
-		Upsert(record, "", "", "").
-		Execute()
-
-	if err != nil {
-		return fmt.Errorf("failed to save task: %w", err)
-	}
-
-	return nil
+	return record, nil
 }
 
 // FindByID 根据ID查找任务
@@ -121,6 +197,25 @@ func (r *TaskRepository) FindByID(ctx context.Context, taskID string) (*task.Tas
 	return r.recordToTask(&record)
 }
 
+// FindStatus 只查询任务状态（后台调用，使用服务端客户端）
+func (r *TaskRepository) FindStatus(ctx context.Context, taskID string) (task.TaskStatus, error) {
+	var record struct {
+		Status string `json:"status"`
+	}
+
+	_, err := r.client.From("aimotion_task").
+		Select("status", "", false).
+		Eq("id", taskID).
+		Single().
+		ExecuteTo(&record)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to find task status: %w", err)
+	}
+
+	return task.TaskStatus(record.Status), nil
+}
+
 // FindByIDAndUserID 根据ID和用户ID查找任务（用于权限验证）
 func (r *TaskRepository) FindByIDAndUserID(ctx context.Context, taskID, userID string) (*task.Task, error) {
 	var record taskRecord
@@ -145,6 +240,27 @@ func (r *TaskRepository) FindByIDAndUserID(ctx context.Context, taskID, userID s
 	return r.recordToTask(&record)
 }
 
+// ExistsByNovelIDAndUserID 用户是否有该小说的任务（用于小说下媒体等资源的权限验证）
+func (r *TaskRepository) ExistsByNovelIDAndUserID(ctx context.Context, novelID, userID string) (bool, error) {
+	var records []struct {
+		ID string `json:"id"`
+	}
+
+	client := r.getClientWithAuth(ctx)
+	_, err := client.From("aimotion_task").
+		Select("id", "", false).
+		Eq("novel_id", novelID).
+		Eq("user_id", userID).
+		Limit(1, "").
+		ExecuteTo(&records)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to find task by novel: %w", err)
+	}
+
+	return len(records) > 0, nil
+}
+
 // FindByUserID 查询用户的所有任务（分页）
 func (r *TaskRepository) FindByUserID(ctx context.Context, userID string, page, pageSize int, status string) ([]*task.Task, int, error) {
 	// 计算偏移量
@@ -199,6 +315,130 @@ func (r *TaskRepository) Delete(ctx context.Context, taskID string) error {
 	return nil
 }
 
+// leaseTimeFormat 租约时间统一使用 UTC，避免查询参数中出现 "+"
+const leaseTimeFormat = "2006-01-02T15:04:05.999999Z"
+
+// leaseRecord 租约字段，与 taskRecord 分开以免 Save 覆盖 worker 的心跳
+type leaseRecord struct {
+	LeaseOwner     *string `json:"lease_owner"`
+	LeaseExpiresAt *string `json:"lease_expires_at"`
+}
+
+func newLeaseRecord(owner string, until time.Time) leaseRecord {
+	expiresAt := until.UTC().Format(leaseTimeFormat)
+	return leaseRecord{LeaseOwner: &owner, LeaseExpiresAt: &expiresAt}
+}
+
+// leaseExpiredFilter 租约为空或已过期
+func leaseExpiredFilter(now time.Time) string {
+	return fmt.Sprintf(`lease_expires_at.is.null,lease_expires_at.lt."%s"`, now.UTC().Format(leaseTimeFormat))
+}
+
+// FindClaimable 查询可被 worker 领取的任务（后台调用，使用服务端客户端）
+func (r *TaskRepository) FindClaimable(ctx context.Context, limit int) ([]*task.Task, error) {
+	var records []taskRecord
+	_, err := r.client.From("aimotion_task").
+		Select("*", "", false).
+		In("status", []string{string(task.TaskStatusPending), string(task.TaskStatusProcessing)}).
+		Or(leaseExpiredFilter(time.Now()), "").
+		Order("created_at", &postgrest.OrderOpts{Ascending: true}).
+		Limit(limit, "").
+		ExecuteTo(&records)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find claimable tasks: %w", err)
+	}
+
+	tasks := make([]*task.Task, 0, len(records))
+	for i := range records {
+		t, err := r.recordToTask(&records[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert task: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+
+	return tasks, nil
+}
+
+// FindInterrupted 查询处理中但租约已过期的任务（后台调用，使用服务端客户端）；
+// pending 任务在租约为空或过期时由 FindClaimable 领取，无需恢复
+func (r *TaskRepository) FindInterrupted(ctx context.Context, limit int) ([]*task.Task, error) {
+	var records []taskRecord
+	_, err := r.client.From("aimotion_task").
+		Select("*", "", false).
+		Eq("status", string(task.TaskStatusProcessing)).
+		Or(leaseExpiredFilter(time.Now()), "").
+		Order("updated_at", &postgrest.OrderOpts{Ascending: true}).
+		Limit(limit, "").
+		ExecuteTo(&records)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find interrupted tasks: %w", err)
+	}
+
+	tasks := make([]*task.Task, 0, len(records))
+	for i := range records {
+		t, err := r.recordToTask(&records[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert task: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+
+	return tasks, nil
+}
+
+// AcquireLease 尝试获取任务租约（条件更新，仅当租约为空或已过期时成功）
+func (r *TaskRepository) AcquireLease(ctx context.Context, taskID, owner string, until time.Time) (bool, error) {
+	var records []taskRecord
+	_, err := r.client.From("aimotion_task").
+		Update(newLeaseRecord(owner, until), "representation", "").
+		Eq("id", taskID).
+		Or(leaseExpiredFilter(time.Now()), "").
+		ExecuteTo(&records)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire task lease: %w", err)
+	}
+
+	return len(records) > 0, nil
+}
+
+// RenewLease 续约（心跳）
+func (r *TaskRepository) RenewLease(ctx context.Context, taskID, owner string, until time.Time) error {
+	var records []taskRecord
+	_, err := r.client.From("aimotion_task").
+		Update(newLeaseRecord(owner, until), "representation", "").
+		Eq("id", taskID).
+		Eq("lease_owner", owner).
+		ExecuteTo(&records)
+
+	if err != nil {
+		return fmt.Errorf("failed to renew task lease: %w", err)
+	}
+	if len(records) == 0 {
+		return task.ErrLeaseLost
+	}
+
+	return nil
+}
+
+// ReleaseLease 释放任务租约
+func (r *TaskRepository) ReleaseLease(ctx context.Context, taskID, owner string) error {
+	_, _, err := r.client.From("aimotion_task").
+		Update(leaseRecord{}, "minimal", "").
+		Eq("id", taskID).
+		Eq("lease_owner", owner).
+		Execute()
+
+	if err != nil {
+		return fmt.Errorf("failed to release task lease: %w", err)
+	}
+
+	return nil
+}
+
 // recordToTask 将数据库记录转换为domain对象
 func (r *TaskRepository) recordToTask(record *taskRecord) (*task.Task, error) {
 	t := &task.Task{
@@ -209,6 +449,7 @@ func (r *TaskRepository) recordToTask(record *taskRecord) (*task.Task, error) {
 		ProgressStep:       record.ProgressStep,
 		ProgressStepIndex:  record.ProgressStepIndex,
 		ProgressPercentage: record.ProgressPercentage,
+		RetryCount:         record.RetryCount,
 	}
 
 	// 解析 progress_details
@@ -218,6 +459,20 @@ func (r *TaskRepository) recordToTask(record *taskRecord) (*task.Task, error) {
 		}
 	}
 
+	// 解析 options
+	if len(record.Options) > 0 && string(record.Options) != "null" {
+		if err := json.Unmarshal(record.Options, &t.Options); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal options: %w", err)
+		}
+	}
+
+	// 解析 attempts
+	if len(record.Attempts) > 0 && string(record.Attempts) != "null" {
+		if err := json.Unmarshal(record.Attempts, &t.Attempts); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal attempts: %w", err)
+		}
+	}
+
 	// 解析错误信息
 	if record.ErrorCode != nil {
 		t.ErrorCode = *record.ErrorCode
@@ -227,11 +482,31 @@ func (r *TaskRepository) recordToTask(record *taskRecord) (*task.Task, error) {
 	}
 
 	// 解析时间字段
-	// CreatedAt, UpdatedAt 由Supabase自动处理，这里简化处理
-	// 实际生产环境需要正确解析时间格式
-
-	// 初始化取消通道
-	t.InitCancelChan()
+	t.CreatedAt = parseTimestamp(record.CreatedAt)
+	t.UpdatedAt = parseTimestamp(record.UpdatedAt)
+	if record.CompletedAt != nil {
+		completedAt := parseTimestamp(*record.CompletedAt)
+		t.CompletedAt = &completedAt
+	}
+	if record.FailedAt != nil {
+		failedAt := parseTimestamp(*record.FailedAt)
+		t.FailedAt = &failedAt
+	}
 
 	return t, nil
 }
+
+// parseTimestamp 解析 Supabase 返回的时间，兼容带时区和不带时区（TIMESTAMP 列）的格式
+func parseTimestamp(value string) time.Time {
+	layouts := []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05.999999",
+		"2006-01-02 15:04:05.999999",
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
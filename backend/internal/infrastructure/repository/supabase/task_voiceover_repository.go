@@ -0,0 +1,109 @@
+package supabase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/supabase-community/postgrest-go"
+	"github.com/xiajiayi/ai-motion/internal/domain/voiceover"
+)
+
+// TaskVoiceoverRepository 配音轨仓储
+// 调用方已完成任务归属校验，因此均使用服务端客户端
+type TaskVoiceoverRepository struct {
+	client *postgrest.Client
+}
+
+func NewTaskVoiceoverRepository(client *postgrest.Client) voiceover.Repository {
+	return &TaskVoiceoverRepository{
+		client: client,
+	}
+}
+
+// taskVoiceoverRecord Supabase中的配音轨记录结构
+type taskVoiceoverRecord struct {
+	ID        string          `json:"id"`
+	TaskID    string          `json:"task_id"`
+	SceneID   string          `json:"scene_id"`
+	Lines     json.RawMessage `json:"lines"`
+	Duration  float64         `json:"duration"`
+	CreatedAt string          `json:"created_at"`
+}
+
+// ReplaceByTaskID 删除任务原有的配音轨后写入新配音轨，配音音频保留在媒体表中
+func (r *TaskVoiceoverRepository) ReplaceByTaskID(ctx context.Context, taskID string, tracks []*voiceover.Track) error {
+	records := make([]taskVoiceoverRecord, 0, len(tracks))
+	for _, t := range tracks {
+		lines := t.Lines
+		if lines == nil {
+			lines = []voiceover.Line{}
+		}
+		linesJSON, err := json.Marshal(lines)
+		if err != nil {
+			return fmt.Errorf("failed to marshal voiceover lines: %w", err)
+		}
+		records = append(records, taskVoiceoverRecord{
+			ID:        t.ID,
+			TaskID:    taskID,
+			SceneID:   t.SceneID,
+			Lines:     linesJSON,
+			Duration:  t.Duration,
+			CreatedAt: t.CreatedAt.UTC().Format(leaseTimeFormat),
+		})
+	}
+
+	_, _, err := r.client.From("aimotion_task_voiceover").
+		Delete("", "").
+		Eq("task_id", taskID).
+		Execute()
+	if err != nil {
+		return fmt.Errorf("failed to delete task voiceovers: %w", err)
+	}
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	_, _, err = r.client.From("aimotion_task_voiceover").
+		Insert(records, false, "", "", "").
+		Execute()
+	if err != nil {
+		return fmt.Errorf("failed to insert task voiceovers: %w", err)
+	}
+
+	return nil
+}
+
+// FindByTaskID 查询任务的配音轨
+func (r *TaskVoiceoverRepository) FindByTaskID(ctx context.Context, taskID string) ([]*voiceover.Track, error) {
+	var records []taskVoiceoverRecord
+
+	_, err := r.client.From("aimotion_task_voiceover").
+		Select("*", "", false).
+		Eq("task_id", taskID).
+		ExecuteTo(&records)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find task voiceovers: %w", err)
+	}
+
+	tracks := make([]*voiceover.Track, 0, len(records))
+	for _, record := range records {
+		t := &voiceover.Track{
+			ID:        record.ID,
+			TaskID:    record.TaskID,
+			SceneID:   record.SceneID,
+			Duration:  record.Duration,
+			CreatedAt: parseTimestamp(record.CreatedAt),
+		}
+		if len(record.Lines) > 0 && string(record.Lines) != "null" {
+			if err := json.Unmarshal(record.Lines, &t.Lines); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal lines of scene %s: %w", record.SceneID, err)
+			}
+		}
+		tracks = append(tracks, t)
+	}
+
+	return tracks, nil
+}
@@ -0,0 +1,119 @@
+package supabase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/supabase-community/postgrest-go"
+	"github.com/xiajiayi/ai-motion/internal/domain/usage"
+)
+
+// UsageRepository 用量账本仓储
+// 记录由 AI 调用计量写入，查询前调用方已按登录用户过滤，因此均使用服务端客户端
+type UsageRepository struct {
+	client *postgrest.Client
+}
+
+func NewUsageRepository(client *postgrest.Client) usage.Repository {
+	return &UsageRepository{
+		client: client,
+	}
+}
+
+// usageRecord Supabase中的用量记录结构
+type usageRecord struct {
+	ID            int64   `json:"id,omitempty"`
+	UserID        *string `json:"user_id"`
+	TaskID        *string `json:"task_id"`
+	Provider      string  `json:"provider"`
+	Operation     string  `json:"operation"`
+	Width         int     `json:"width"`
+	Height        int     `json:"height"`
+	Duration      float64 `json:"duration"`
+	LatencyMs     int64   `json:"latency_ms"`
+	Success       bool    `json:"success"`
+	EstimatedCost float64 `json:"estimated_cost"`
+	CreatedAt     string  `json:"created_at,omitempty"`
+}
+
+// Append 写入一条记录并回填ID
+func (r *UsageRepository) Append(ctx context.Context, entry *usage.Entry) error {
+	record := usageRecord{
+		UserID:        nullableUUID(entry.UserID),
+		TaskID:        nullableUUID(entry.TaskID),
+		Provider:      entry.Provider,
+		Operation:     string(entry.Operation),
+		Width:         entry.Width,
+		Height:        entry.Height,
+		Duration:      entry.Duration,
+		LatencyMs:     entry.Latency.Milliseconds(),
+		Success:       entry.Success,
+		EstimatedCost: entry.EstimatedCost,
+		CreatedAt:     entry.CreatedAt.UTC().Format(leaseTimeFormat),
+	}
+
+	var inserted []usageRecord
+	_, err := r.client.From("aimotion_usage_ledger").
+		Insert(record, false, "", "representation", "").
+		ExecuteTo(&inserted)
+
+	if err != nil {
+		return fmt.Errorf("failed to append usage entry: %w", err)
+	}
+
+	if len(inserted) > 0 {
+		entry.ID = inserted[0].ID
+	}
+
+	return nil
+}
+
+// FindByUserSince 查询用户在 since 之后的记录，按创建时间升序
+func (r *UsageRepository) FindByUserSince(ctx context.Context, userID string, since time.Time) ([]*usage.Entry, error) {
+	var records []usageRecord
+
+	_, err := r.client.From("aimotion_usage_ledger").
+		Select("*", "", false).
+		Eq("user_id", userID).
+		Gte("created_at", since.UTC().Format(leaseTimeFormat)).
+		Order("created_at", &postgrest.OrderOpts{Ascending: true}).
+		ExecuteTo(&records)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find usage entries: %w", err)
+	}
+
+	entries := make([]*usage.Entry, 0, len(records))
+	for _, record := range records {
+		entry := &usage.Entry{
+			ID:            record.ID,
+			Provider:      record.Provider,
+			Operation:     usage.Operation(record.Operation),
+			Width:         record.Width,
+			Height:        record.Height,
+			Duration:      record.Duration,
+			Latency:       time.Duration(record.LatencyMs) * time.Millisecond,
+			Success:       record.Success,
+			EstimatedCost: record.EstimatedCost,
+			CreatedAt:     parseTimestamp(record.CreatedAt),
+		}
+		if record.UserID != nil {
+			entry.UserID = *record.UserID
+		}
+		if record.TaskID != nil {
+			entry.TaskID = *record.TaskID
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// nullableUUID 空字符串写入为 NULL，UUID 列不接受空字符串
+func nullableUUID(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
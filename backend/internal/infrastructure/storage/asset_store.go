@@ -0,0 +1,309 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/video"
+)
+
+var (
+	ErrEmptySource      = errors.New("empty asset source")
+	ErrUnsupportedAsset = errors.New("unsupported asset type")
+	ErrAssetNotManaged  = errors.New("asset is not in managed storage")
+	ErrDownloadFailed   = errors.New("failed to download asset")
+)
+
+// assetExtensions 支持保存的资源类型及对应的文件扩展名
+var assetExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+	"video/mp4":  ".mp4",
+	"video/webm": ".webm",
+}
+
+// Asset 已写入存储的生成资源
+type Asset struct {
+	Path     string // 文件存储中的相对路径
+	URL      string // 对外访问的稳定 URL
+	MimeType string
+	Size     int64
+	Width    int // 图片或视频的实际尺寸，无法解析时为 0
+	Height   int
+	Duration float64 // 视频时长（秒），无法解析时为 0
+}
+
+// AssetStore 将 AI 服务返回的生成结果（临时 URL、data URI 或 base64 数据）下载并写入文件存储，
+// 保存后使用稳定的访问 URL 代替服务方的临时地址
+type AssetStore struct {
+	files      Storage
+	publicURL  string
+	httpClient *http.Client
+	mounts     map[string]string
+}
+
+// NewAssetStore 创建资源存储，publicURL 为存储目录对外提供访问的 URL 前缀
+func NewAssetStore(files Storage, publicURL string) *AssetStore {
+	return &AssetStore{
+		files:     files,
+		publicURL: strings.TrimRight(publicURL, "/"),
+		httpClient: &http.Client{
+			Timeout: 2 * time.Minute,
+		},
+		mounts: make(map[string]string),
+	}
+}
+
+// MountDir 以 urlPrefix 开头的 URL 直接从本地目录读取（模拟服务生成的文件无需经过 HTTP 下载）
+func (s *AssetStore) MountDir(urlPrefix, dir string) {
+	s.mounts[strings.TrimRight(urlPrefix, "/")+"/"] = dir
+}
+
+// Save 读取生成结果并写入存储，name 作为文件名前缀（通常为媒体ID）
+func (s *AssetStore) Save(ctx context.Context, source, name string) (*Asset, error) {
+	data, err := s.fetch(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	return s.store(ctx, data, name)
+}
+
+// SaveReader 读取生成结果的内容并写入存储（用于需要鉴权下载的服务）
+func (s *AssetStore) SaveReader(ctx context.Context, reader io.Reader, name string) (*Asset, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, MaxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDownloadFailed, err)
+	}
+	if len(data) == 0 {
+		return nil, ErrEmptySource
+	}
+	return s.store(ctx, data, name)
+}
+
+// SaveFile 写入服务端生成的文件（如导出的电子书），按调用方给出的扩展名和 MIME 类型保存，不检测内容类型
+func (s *AssetStore) SaveFile(ctx context.Context, data []byte, name, ext, mimeType string) (*Asset, error) {
+	if len(data) == 0 {
+		return nil, ErrEmptySource
+	}
+	if len(data) > MaxFileSize {
+		return nil, ErrFileTooLarge
+	}
+
+	filePath, err := s.files.Upload(ctx, name+ext, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to store file: %w", err)
+	}
+
+	return &Asset{
+		Path:     filePath,
+		URL:      s.URLFor(filePath),
+		MimeType: mimeType,
+		Size:     int64(len(data)),
+	}, nil
+}
+
+func (s *AssetStore) store(ctx context.Context, data []byte, name string) (*Asset, error) {
+	if len(data) > MaxFileSize {
+		return nil, ErrFileTooLarge
+	}
+
+	mimeType := http.DetectContentType(data)
+	ext, ok := assetExtensions[mimeType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAsset, mimeType)
+	}
+
+	filePath, err := s.files.Upload(ctx, name+ext, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to store asset: %w", err)
+	}
+
+	asset := &Asset{
+		Path:     filePath,
+		URL:      s.URLFor(filePath),
+		MimeType: mimeType,
+		Size:     int64(len(data)),
+	}
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		if config, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+			asset.Width = config.Width
+			asset.Height = config.Height
+		}
+	case mimeType == "video/mp4":
+		if info, err := video.ProbeMP4(data); err == nil {
+			asset.Width = info.Width
+			asset.Height = info.Height
+			asset.Duration = info.Duration
+		}
+	}
+
+	return asset, nil
+}
+
+// URLFor 存储路径对应的访问 URL
+func (s *AssetStore) URLFor(filePath string) string {
+	return s.publicURL + "/" + filepath.ToSlash(filePath)
+}
+
+// PathFromURL 由访问 URL 解析存储路径，非本存储的 URL 返回 false
+func (s *AssetStore) PathFromURL(url string) (string, bool) {
+	rel, ok := strings.CutPrefix(url, s.publicURL+"/")
+	if !ok {
+		return "", false
+	}
+	return cleanRelPath(rel)
+}
+
+// Open 打开存储中的资源
+func (s *AssetStore) Open(ctx context.Context, url string) (io.ReadCloser, error) {
+	filePath, ok := s.PathFromURL(url)
+	if !ok {
+		return nil, ErrAssetNotManaged
+	}
+	return s.files.Download(ctx, filePath)
+}
+
+// OpenPath 按存储路径打开资源，路径跳出存储目录时返回 ErrAssetNotManaged
+func (s *AssetStore) OpenPath(ctx context.Context, filePath string) (io.ReadCloser, error) {
+	filePath, ok := cleanRelPath(filePath)
+	if !ok {
+		return nil, ErrAssetNotManaged
+	}
+	return s.files.Download(ctx, filePath)
+}
+
+// PresignedURL 生成资源的临时直链，存储不支持时返回 ErrPresignUnsupported
+func (s *AssetStore) PresignedURL(ctx context.Context, filePath string, expires time.Duration) (string, error) {
+	filePath, ok := cleanRelPath(filePath)
+	if !ok {
+		return "", ErrAssetNotManaged
+	}
+	return s.files.PresignedURL(ctx, filePath, expires)
+}
+
+// Inline 将存储中的图片转换为 data URI，供需要直接传入图片内容的 AI 服务使用（存储 URL 对外部服务不可达）；
+// 其他 URL 原样返回
+func (s *AssetStore) Inline(ctx context.Context, url string) (string, error) {
+	if _, ok := s.PathFromURL(url); !ok {
+		return url, nil
+	}
+
+	reader, err := s.Open(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to read asset: %w", err)
+	}
+
+	return "data:" + http.DetectContentType(data) + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// fetch 按来源类型读取资源内容
+func (s *AssetStore) fetch(ctx context.Context, source string) ([]byte, error) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return nil, ErrEmptySource
+	}
+
+	if strings.HasPrefix(source, "data:") {
+		return decodeDataURI(source)
+	}
+
+	for prefix, dir := range s.mounts {
+		if rel, ok := strings.CutPrefix(source, prefix); ok {
+			rel, ok := cleanRelPath(rel)
+			if !ok {
+				return nil, fmt.Errorf("%w: invalid path %q", ErrDownloadFailed, source)
+			}
+			data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrDownloadFailed, err)
+			}
+			return data, nil
+		}
+	}
+
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		return s.download(ctx, source)
+	}
+
+	// 其余情况按服务返回的 b64_json 处理
+	data, err := decodeBase64(source)
+	if err != nil {
+		return nil, fmt.Errorf("%w: source is neither a URL nor base64 data", ErrUnsupportedAsset)
+	}
+	return data, nil
+}
+
+func (s *AssetStore) download(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDownloadFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrDownloadFailed, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDownloadFailed, err)
+	}
+	return data, nil
+}
+
+// decodeDataURI 解析 base64 编码的 data URI，例如 data:image/png;base64,iVBOR...
+func decodeDataURI(uri string) ([]byte, error) {
+	header, payload, ok := strings.Cut(strings.TrimPrefix(uri, "data:"), ",")
+	if !ok || !strings.HasSuffix(header, ";base64") {
+		return nil, fmt.Errorf("%w: only base64 data URIs are supported", ErrUnsupportedAsset)
+	}
+	data, err := decodeBase64(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid base64 data URI", ErrUnsupportedAsset)
+	}
+	return data, nil
+}
+
+func decodeBase64(s string) ([]byte, error) {
+	if data, err := base64.StdEncoding.DecodeString(s); err == nil {
+		return data, nil
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
+
+// cleanRelPath 规范化相对路径，拒绝跳出存储目录的路径
+func cleanRelPath(rel string) (string, bool) {
+	cleaned := path.Clean("/" + rel)[1:]
+	if cleaned == "" || cleaned != rel {
+		return "", false
+	}
+	return cleaned, true
+}
@@ -0,0 +1,166 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/storage"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/storage/local"
+)
+
+func newTestAssetStore(t *testing.T) *storage.AssetStore {
+	t.Helper()
+	files, err := local.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStorage() error = %v", err)
+	}
+	return storage.NewAssetStore(files, "/files")
+}
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestAssetStoreSavesEverySourceKind(t *testing.T) {
+	data := encodePNG(t, 64, 32)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	mockDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(mockDir, "panel.png"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sources := map[string]string{
+		"http":     server.URL + "/image.png",
+		"data uri": "data:image/png;base64," + base64.StdEncoding.EncodeToString(data),
+		"b64_json": base64.StdEncoding.EncodeToString(data),
+		"mounted":  "http://localhost:8080/mock-assets/panel.png",
+	}
+
+	for name, source := range sources {
+		t.Run(name, func(t *testing.T) {
+			store := newTestAssetStore(t)
+			store.MountDir("http://localhost:8080/mock-assets", mockDir)
+
+			asset, err := store.Save(context.Background(), source, "media-1")
+			if err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			if asset.MimeType != "image/png" || asset.Size != int64(len(data)) {
+				t.Errorf("asset = %+v, want image/png of %d bytes", asset, len(data))
+			}
+			if asset.Width != 64 || asset.Height != 32 {
+				t.Errorf("dimensions = %dx%d, want 64x32", asset.Width, asset.Height)
+			}
+			if !strings.HasPrefix(asset.URL, "/files/") || !strings.HasSuffix(asset.URL, ".png") {
+				t.Errorf("URL = %q, want stable /files/*.png URL", asset.URL)
+			}
+
+			reader, err := store.Open(context.Background(), asset.URL)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer reader.Close()
+			stored, _ := io.ReadAll(reader)
+			if !bytes.Equal(stored, data) {
+				t.Error("stored content differs from source")
+			}
+		})
+	}
+}
+
+func TestAssetStoreRejectsUnsupportedContent(t *testing.T) {
+	store := newTestAssetStore(t)
+
+	_, err := store.Save(context.Background(), base64.StdEncoding.EncodeToString([]byte("plain text")), "media-1")
+	if !errors.Is(err, storage.ErrUnsupportedAsset) {
+		t.Errorf("Save(text) error = %v, want %v", err, storage.ErrUnsupportedAsset)
+	}
+
+	_, err = store.Save(context.Background(), "", "media-1")
+	if !errors.Is(err, storage.ErrEmptySource) {
+		t.Errorf("Save(empty) error = %v, want %v", err, storage.ErrEmptySource)
+	}
+}
+
+func TestAssetStoreSaveFile(t *testing.T) {
+	store := newTestAssetStore(t)
+	data := []byte("PK\x03\x04 comic archive")
+
+	asset, err := store.SaveFile(context.Background(), data, "export-1", ".cbz", "application/vnd.comicbook+zip")
+	if err != nil {
+		t.Fatalf("SaveFile() error = %v", err)
+	}
+	if asset.MimeType != "application/vnd.comicbook+zip" || asset.Size != int64(len(data)) || !strings.HasSuffix(asset.URL, ".cbz") {
+		t.Errorf("asset = %+v", asset)
+	}
+
+	reader, err := store.Open(context.Background(), asset.URL)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer reader.Close()
+	if stored, _ := io.ReadAll(reader); !bytes.Equal(stored, data) {
+		t.Error("stored content differs from source")
+	}
+
+	// 文本类型的扩展名解析结果带 charset 参数
+	if _, err := store.SaveFile(context.Background(), []byte("WEBVTT\n"), "export-1", ".vtt", "text/vtt"); err != nil {
+		t.Errorf("SaveFile(vtt) error = %v", err)
+	}
+	if _, err := store.SaveFile(context.Background(), []byte("RIFF\x24\x00\x00\x00WAVE"), "voice-1", ".wav", "audio/wav"); err != nil {
+		t.Errorf("SaveFile(wav) error = %v", err)
+	}
+
+	if _, err := store.SaveFile(context.Background(), nil, "export-2", ".pdf", "application/pdf"); !errors.Is(err, storage.ErrEmptySource) {
+		t.Errorf("SaveFile(empty) error = %v, want %v", err, storage.ErrEmptySource)
+	}
+}
+
+func TestAssetStoreInline(t *testing.T) {
+	store := newTestAssetStore(t)
+	data := encodePNG(t, 8, 8)
+
+	asset, err := store.Save(context.Background(), base64.StdEncoding.EncodeToString(data), "ref")
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	inlined, err := store.Inline(context.Background(), asset.URL)
+	if err != nil {
+		t.Fatalf("Inline() error = %v", err)
+	}
+	if inlined != "data:image/png;base64,"+base64.StdEncoding.EncodeToString(data) {
+		t.Errorf("Inline() = %.40q..., want PNG data URI", inlined)
+	}
+
+	external := "https://cdn.example.com/ref.png"
+	if got, _ := store.Inline(context.Background(), external); got != external {
+		t.Errorf("Inline(external) = %q, want unchanged", got)
+	}
+
+	if _, ok := store.PathFromURL("/files/../secret.png"); ok {
+		t.Error("PathFromURL accepted a path escaping the storage directory")
+	}
+}
@@ -5,40 +5,33 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"mime"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
+
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/storage"
 )
 
 var (
-	ErrFileNotFound      = errors.New("file not found")
-	ErrInvalidFileType   = errors.New("invalid file type")
-	ErrFileTooLarge      = errors.New("file too large")
+	ErrFileNotFound      = storage.ErrFileNotFound
+	ErrInvalidFileType   = storage.ErrInvalidFileType
+	ErrFileTooLarge      = storage.ErrFileTooLarge
 	ErrStoragePathNotSet = errors.New("storage path not set")
 )
 
 const (
-	MaxFileSize = 100 * 1024 * 1024
+	MaxFileSize = storage.MaxFileSize
 )
 
-var AllowedMimeTypes = map[string]bool{
-	"text/plain":           true,
-	"application/pdf":      true,
-	"application/epub+zip": true,
-	"image/jpeg":           true,
-	"image/png":            true,
-	"image/gif":            true,
-	"image/webp":           true,
-	"video/mp4":            true,
-	"video/webm":           true,
-}
+var AllowedMimeTypes = storage.AllowedMimeTypes
 
+// FileStorage 本地目录存储
 type FileStorage struct {
 	basePath string
 }
 
+var _ storage.Storage = (*FileStorage)(nil)
+
 func NewFileStorage(basePath string) (*FileStorage, error) {
 	if basePath == "" {
 		return nil, ErrStoragePathNotSet
@@ -54,17 +47,12 @@ func NewFileStorage(basePath string) (*FileStorage, error) {
 }
 
 func (s *FileStorage) Upload(ctx context.Context, filename string, reader io.Reader, fileSize int64) (string, error) {
-	if fileSize > MaxFileSize {
-		return "", ErrFileTooLarge
-	}
-
-	mimeType := mime.TypeByExtension(filepath.Ext(filename))
-	if !AllowedMimeTypes[mimeType] {
-		return "", ErrInvalidFileType
+	if _, err := storage.ValidateUpload(filename, fileSize); err != nil {
+		return "", err
 	}
 
-	relPath := s.generateFilePath(filename)
-	fullPath := filepath.Join(s.basePath, relPath)
+	relPath := storage.NewObjectKey(filename)
+	fullPath := filepath.Join(s.basePath, filepath.FromSlash(relPath))
 
 	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
 		return "", fmt.Errorf("failed to create directory: %w", err)
@@ -144,44 +132,7 @@ func (s *FileStorage) GetFileSize(ctx context.Context, filePath string) (int64,
 	return info.Size(), nil
 }
 
-func (s *FileStorage) generateFilePath(filename string) string {
-	now := time.Now()
-	dateDir := now.Format("2006/01/02")
-
-	ext := filepath.Ext(filename)
-	nameWithoutExt := strings.TrimSuffix(filename, ext)
-
-	safeFilename := sanitizeFilename(nameWithoutExt)
-
-	timestamp := now.Format("150405")
-	randomSuffix := randomString(6)
-
-	finalFilename := fmt.Sprintf("%s_%s_%s%s", safeFilename, timestamp, randomSuffix, ext)
-
-	return filepath.Join(dateDir, finalFilename)
-}
-
-func sanitizeFilename(filename string) string {
-	filename = strings.ReplaceAll(filename, " ", "_")
-	filename = strings.Map(func(r rune) rune {
-		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '-' {
-			return r
-		}
-		return -1
-	}, filename)
-
-	if len(filename) > 50 {
-		filename = filename[:50]
-	}
-
-	return filename
-}
-
-func randomString(n int) string {
-	const letters = "abcdefghijklmnopqrstuvwxyz0123456789"
-	b := make([]byte, n)
-	for i := range b {
-		b[i] = letters[i%len(letters)]
-	}
-	return string(b)
+// PresignedURL 本地存储没有独立的下载服务，文件由应用自身提供访问
+func (s *FileStorage) PresignedURL(ctx context.Context, filePath string, expires time.Duration) (string, error) {
+	return "", storage.ErrPresignUnsupported
 }
@@ -0,0 +1,244 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/storage"
+)
+
+var (
+	ErrEndpointRequired    = errors.New("s3 endpoint is required")
+	ErrBucketRequired      = errors.New("s3 bucket is required")
+	ErrCredentialsRequired = errors.New("s3 access key and secret key are required")
+)
+
+const (
+	defaultRegion = "us-east-1"
+	// maxPresignExpiry SigV4 预签名 URL 的最长有效期
+	maxPresignExpiry = 7 * 24 * time.Hour
+)
+
+// Config S3 兼容对象存储配置
+type Config struct {
+	Endpoint        string // 例如 https://s3.amazonaws.com 或 http://minio:9000
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	UsePathStyle    bool // 使用 endpoint/bucket/key 形式的地址（MinIO 等自建服务通常需要）
+}
+
+// Storage S3 兼容的对象存储，多实例部署时共享生成的媒体文件
+type Storage struct {
+	endpoint   *url.URL
+	bucket     string
+	pathStyle  bool
+	signer     *signer
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+var _ storage.Storage = (*Storage)(nil)
+
+func NewStorage(cfg Config) (*Storage, error) {
+	if cfg.Endpoint == "" {
+		return nil, ErrEndpointRequired
+	}
+	if cfg.Bucket == "" {
+		return nil, ErrBucketRequired
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, ErrCredentialsRequired
+	}
+
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", cfg.Endpoint)
+	}
+
+	region := cfg.Region
+	if region == "" {
+		region = defaultRegion
+	}
+
+	return &Storage{
+		endpoint:  endpoint,
+		bucket:    cfg.Bucket,
+		pathStyle: cfg.UsePathStyle,
+		signer: &signer{
+			accessKeyID:     cfg.AccessKeyID,
+			secretAccessKey: cfg.SecretAccessKey,
+			region:          region,
+		},
+		httpClient: &http.Client{
+			Timeout: 5 * time.Minute,
+		},
+		now: time.Now,
+	}, nil
+}
+
+func (s *Storage) Upload(ctx context.Context, filename string, reader io.Reader, fileSize int64) (string, error) {
+	mimeType, err := storage.ValidateUpload(filename, fileSize)
+	if err != nil {
+		return "", err
+	}
+
+	// 签名需要请求体的 SHA256，先读入内存（单个文件不超过 MaxFileSize）
+	data, err := io.ReadAll(io.LimitReader(reader, fileSize+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	if int64(len(data)) != fileSize {
+		return "", fmt.Errorf("file size mismatch: expected %d, got %d", fileSize, len(data))
+	}
+
+	key := storage.NewObjectKey(filename)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key).String(), bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", mimeType)
+
+	resp, err := s.do(req, hashHex(data))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", s.responseError("upload", key, resp)
+	}
+
+	return key, nil
+}
+
+func (s *Storage) Download(ctx context.Context, filePath string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(filePath).String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, storage.ErrFileNotFound
+		}
+		return nil, s.responseError("download", filePath, resp)
+	}
+
+	return resp.Body, nil
+}
+
+// Delete 删除对象，对象不存在时返回 ErrFileNotFound
+func (s *Storage) Delete(ctx context.Context, filePath string) error {
+	// S3 删除不存在的对象同样返回成功，先检查以保持与本地存储一致的语义
+	exists, err := s.Exists(ctx, filePath)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return storage.ErrFileNotFound
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(filePath).String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return s.responseError("delete", filePath, resp)
+	}
+
+	return nil
+}
+
+func (s *Storage) Exists(ctx context.Context, filePath string) (bool, error) {
+	_, err := s.GetFileSize(ctx, filePath)
+	if errors.Is(err, storage.ErrFileNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *Storage) GetFileSize(ctx context.Context, filePath string) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, s.objectURL(filePath).String(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.ContentLength, nil
+	case http.StatusNotFound:
+		return 0, storage.ErrFileNotFound
+	default:
+		return 0, s.responseError("head", filePath, resp)
+	}
+}
+
+// PresignedURL 生成对象的预签名下载地址，有效期最长 7 天
+func (s *Storage) PresignedURL(ctx context.Context, filePath string, expires time.Duration) (string, error) {
+	if expires <= 0 || expires > maxPresignExpiry {
+		return "", fmt.Errorf("presigned url expiry must be between 1s and %s", maxPresignExpiry)
+	}
+	return s.signer.presign(http.MethodGet, s.objectURL(filePath), expires, s.now()), nil
+}
+
+// objectURL 对象地址，路径风格为 endpoint/bucket/key，否则为 bucket.endpoint/key
+func (s *Storage) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	objectPath := "/" + strings.TrimLeft(key, "/")
+	if s.pathStyle {
+		objectPath = "/" + s.bucket + objectPath
+	} else {
+		u.Host = s.bucket + "." + u.Host
+	}
+	u.Path = strings.TrimRight(u.Path, "/") + objectPath
+	return &u
+}
+
+func (s *Storage) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	s.signer.sign(req, payloadHash, s.now())
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 request failed: %w", err)
+	}
+	return resp, nil
+}
+
+func (s *Storage) responseError(operation, key string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("s3 %s %s failed (status %s): %s",
+		operation, key, strconv.Itoa(resp.StatusCode), strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	ErrFileNotFound       = errors.New("file not found")
+	ErrInvalidFileType    = errors.New("invalid file type")
+	ErrFileTooLarge       = errors.New("file too large")
+	ErrPresignUnsupported = errors.New("storage does not support presigned urls")
+)
+
+const (
+	MaxFileSize = 100 * 1024 * 1024
+)
+
+var AllowedMimeTypes = map[string]bool{
+	"text/plain":                    true,
+	"application/pdf":               true,
+	"application/epub+zip":          true,
+	"application/vnd.comicbook+zip": true,
+	"application/zip":               true,
+	"application/json":              true,
+	"application/x-subrip":          true,
+	"text/vtt":                      true,
+	"image/jpeg":                    true,
+	"image/png":                     true,
+	"image/gif":                     true,
+	"image/webp":                    true,
+	"video/mp4":                     true,
+	"video/webm":                    true,
+	"audio/wav":                     true,
+	"audio/mpeg":                    true,
+}
+
+// 系统 MIME 表中不一定有电子书、压缩包、字幕和音频格式，按扩展名校验前先注册
+func init() {
+	mime.AddExtensionType(".epub", "application/epub+zip")
+	mime.AddExtensionType(".cbz", "application/vnd.comicbook+zip")
+	mime.AddExtensionType(".zip", "application/zip")
+	mime.AddExtensionType(".srt", "application/x-subrip")
+	mime.AddExtensionType(".vtt", "text/vtt")
+	mime.AddExtensionType(".wav", "audio/wav")
+	mime.AddExtensionType(".mp3", "audio/mpeg")
+}
+
+// Storage 文件存储接口，文件以存储内的相对路径（对象键）标识。
+// 本地目录适合单实例部署，多实例部署使用 S3 兼容的对象存储共享生成的媒体
+type Storage interface {
+	// Upload 保存文件，返回生成的存储路径
+	Upload(ctx context.Context, filename string, reader io.Reader, fileSize int64) (string, error)
+
+	// Download 读取文件，文件不存在时返回 ErrFileNotFound
+	Download(ctx context.Context, filePath string) (io.ReadCloser, error)
+
+	// Delete 删除文件
+	Delete(ctx context.Context, filePath string) error
+
+	// Exists 文件是否存在
+	Exists(ctx context.Context, filePath string) (bool, error)
+
+	// GetFileSize 获取文件大小，文件不存在时返回 ErrFileNotFound
+	GetFileSize(ctx context.Context, filePath string) (int64, error)
+
+	// PresignedURL 生成有时效的直接下载地址，不支持时返回 ErrPresignUnsupported
+	PresignedURL(ctx context.Context, filePath string, expires time.Duration) (string, error)
+}
+
+// ValidateUpload 校验上传文件的大小和类型（按扩展名），返回文件的 MIME 类型
+func ValidateUpload(filename string, fileSize int64) (string, error) {
+	if fileSize > MaxFileSize {
+		return "", ErrFileTooLarge
+	}
+
+	// 文本类型带有 charset 参数，按不含参数的类型校验
+	mimeType := mime.TypeByExtension(path.Ext(filename))
+	mediaType, _, _ := mime.ParseMediaType(mimeType)
+	if !AllowedMimeTypes[mediaType] {
+		return "", ErrInvalidFileType
+	}
+
+	return mimeType, nil
+}
+
+// NewObjectKey 生成按日期分目录的存储路径：2006/01/02/<文件名>_<时间>_<随机后缀><扩展名>
+func NewObjectKey(filename string) string {
+	now := time.Now()
+	dateDir := now.Format("2006/01/02")
+
+	ext := path.Ext(filename)
+	safeFilename := sanitizeFilename(strings.TrimSuffix(filename, ext))
+
+	finalFilename := fmt.Sprintf("%s_%s_%s%s", safeFilename, now.Format("150405"), randomSuffix(), ext)
+
+	return dateDir + "/" + finalFilename
+}
+
+func sanitizeFilename(filename string) string {
+	filename = strings.ReplaceAll(filename, " ", "_")
+	filename = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == '-' {
+			return r
+		}
+		return -1
+	}, filename)
+
+	if len(filename) > 50 {
+		filename = filename[:50]
+	}
+
+	return filename
+}
+
+func randomSuffix() string {
+	b := make([]byte, 3)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestURLSignerSignAndVerify(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 10, 0, 0, time.UTC)
+	signer := NewURLSigner("secret", time.Hour)
+	signer.now = func() time.Time { return now }
+
+	signed := signer.Sign("https://cdn.example.com/files/2024/03/15/m1.png")
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatalf("Sign() returned invalid url %q: %v", signed, err)
+	}
+	if err := signer.Verify(u.Path, u.Query()); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if again := signer.Sign("https://cdn.example.com/files/2024/03/15/m1.png"); again != signed {
+		t.Errorf("Sign() = %q, then %q; want the same url within one window so browsers can cache it", signed, again)
+	}
+
+	// 签名只覆盖路径，缩略图尺寸等参数可以修改
+	thumb, _ := url.Parse(signer.Sign("/api/v1/media/m1/thumbnail"))
+	query := thumb.Query()
+	query.Set("size", "640")
+	if err := signer.Verify(thumb.Path, query); err != nil {
+		t.Errorf("Verify() with extra query = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		query   url.Values
+		signer  *URLSigner
+		wantErr error
+	}{
+		{"other path", "/files/2024/03/15/m2.png", u.Query(), signer, ErrInvalidSignature},
+		{"missing signature", u.Path, url.Values{"expires": {u.Query().Get("expires")}}, signer, ErrInvalidSignature},
+		{"tampered expiry", u.Path, url.Values{"expires": {"9999999999"}, "signature": {u.Query().Get("signature")}}, signer, ErrInvalidSignature},
+		{"other secret", u.Path, u.Query(), NewURLSigner("other", time.Hour), ErrInvalidSignature},
+		{"nil signer", u.Path, u.Query(), nil, ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.signer.Verify(tt.path, tt.query); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestURLSignerExpiry(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 59, 0, 0, time.UTC)
+	signer := NewURLSigner("secret", time.Hour)
+	signer.now = func() time.Time { return now }
+
+	u, _ := url.Parse(signer.Sign("/files/a.png"))
+
+	// 至少在有效期内可用
+	signer.now = func() time.Time { return now.Add(time.Hour) }
+	if err := signer.Verify(u.Path, u.Query()); err != nil {
+		t.Errorf("Verify() after one ttl = %v, want valid", err)
+	}
+	signer.now = func() time.Time { return now.Add(2 * time.Hour) }
+	if err := signer.Verify(u.Path, u.Query()); !errors.Is(err, ErrURLExpired) {
+		t.Errorf("Verify() after two ttl = %v, want %v", err, ErrURLExpired)
+	}
+}
+
+func TestURLSignerLeavesUnsignableURLs(t *testing.T) {
+	signer := NewURLSigner("secret", time.Hour)
+	for _, raw := range []string{"", "data:image/png;base64,AAAA"} {
+		if got := signer.Sign(raw); got != raw {
+			t.Errorf("Sign(%q) = %q, want unchanged", raw, got)
+		}
+	}
+	var nilSigner *URLSigner
+	if got := nilSigner.Sign("/files/a.png"); got != "/files/a.png" {
+		t.Errorf("nil Sign() = %q, want unchanged", got)
+	}
+	if got := signer.Sign("/files/a.png"); !strings.Contains(got, "signature=") {
+		t.Errorf("Sign() = %q, want signature query", got)
+	}
+}
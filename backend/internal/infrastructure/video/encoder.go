@@ -0,0 +1,88 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+
+	"github.com/xiajiayi/ai-motion/internal/domain/export"
+)
+
+var (
+	ErrEmptyTimeline = errors.New("timeline has no clips")
+	ErrMissingClip   = errors.New("clip file missing")
+	ErrMissingAudio  = errors.New("audio file missing")
+)
+
+const (
+	EncoderAuto     = "auto"     // 找到 ffmpeg 时使用 ffmpeg，否则输出图片序列
+	EncoderFFmpeg   = "ffmpeg"   // 调用 ffmpeg 渲染 MP4
+	EncoderSequence = "sequence" // 输出图片序列，不依赖外部程序
+)
+
+// ClipFile 片段对应的本地素材文件
+type ClipFile struct {
+	Path   string // 视频或图片文件
+	Poster string // 视频片段的代表图片（可选），供无法解码视频的编码器使用
+}
+
+// Job 一次渲染：按时间线拼接素材并混入配音，Files 以 Clip.MediaID 和 AudioCue.MediaID 为键
+type Job struct {
+	Timeline  export.Timeline
+	Files     map[string]ClipFile
+	Subtitles string // SRT 字幕文件（可选）
+	WorkDir   string // 输出文件写入此目录，由调用方清理
+}
+
+// Output 渲染得到的文件
+type Output struct {
+	Path      string
+	Extension string
+	MimeType  string
+}
+
+// Encoder 将时间线渲染为成片
+type Encoder interface {
+	Name() string
+	Encode(ctx context.Context, job Job) (*Output, error)
+}
+
+// NewEncoder 按配置创建编码器，auto 时在 PATH 中查找 ffmpeg，找不到则使用图片序列
+func NewEncoder(kind, ffmpegPath string) (Encoder, error) {
+	switch kind {
+	case EncoderFFmpeg:
+		path, err := exec.LookPath(ffmpegPath)
+		if err != nil {
+			return nil, fmt.Errorf("ffmpeg not found: %w", err)
+		}
+		return NewFFmpegEncoder(path), nil
+	case EncoderSequence:
+		return NewSequenceEncoder(), nil
+	case EncoderAuto, "":
+		if path, err := exec.LookPath(ffmpegPath); err == nil {
+			return NewFFmpegEncoder(path), nil
+		}
+		return NewSequenceEncoder(), nil
+	default:
+		return nil, fmt.Errorf("unknown video encoder %q", kind)
+	}
+}
+
+// clipFile 查找片段的素材文件
+func (j Job) clipFile(clip export.Clip) (ClipFile, error) {
+	file, ok := j.Files[clip.MediaID]
+	if !ok || file.Path == "" {
+		return ClipFile{}, fmt.Errorf("clip %d (media %s): %w", clip.Index, clip.MediaID, ErrMissingClip)
+	}
+	return file, nil
+}
+
+// audioFile 查找配音的音频文件
+func (j Job) audioFile(cue export.AudioCue) (string, error) {
+	file, ok := j.Files[cue.MediaID]
+	if !ok || file.Path == "" {
+		return "", fmt.Errorf("audio %d (media %s): %w", cue.Index, cue.MediaID, ErrMissingAudio)
+	}
+	return file.Path, nil
+}
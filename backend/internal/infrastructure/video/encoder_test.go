@@ -0,0 +1,253 @@
+package video
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xiajiayi/ai-motion/internal/domain/export"
+)
+
+// testJob 两个片段：带淡入的图片和直接切换的视频
+func testJob(t *testing.T) Job {
+	t.Helper()
+	dir := t.TempDir()
+
+	still := filepath.Join(dir, "still.png")
+	img := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	file, err := os.Create(still)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(file, img); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	subtitles := filepath.Join(dir, "subtitles.srt")
+	if err := os.WriteFile(subtitles, []byte("1\n00:00:00,000 --> 00:00:01,000\n你好\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return Job{
+		Timeline: export.Timeline{
+			Width: 64, Height: 36, FPS: 24, Duration: 5,
+			Clips: []export.Clip{
+				{Index: 1, MediaID: "m1", Kind: export.ClipKindStill, Start: 0, Out: 3, Duration: 3,
+					Transition: export.Transition{Type: export.TransitionFade, Duration: 0.5}},
+				{Index: 2, MediaID: "m2", Kind: export.ClipKindVideo, Start: 3, In: 0.5, Out: 2.5, Duration: 2,
+					Transition: export.Transition{Type: export.TransitionCut}},
+			},
+		},
+		Files: map[string]ClipFile{
+			"m1": {Path: still},
+			"m2": {Path: filepath.Join(dir, "clip.mp4"), Poster: still},
+		},
+		Subtitles: subtitles,
+		WorkDir:   dir,
+	}
+}
+
+func TestFFmpegArgs(t *testing.T) {
+	job := testJob(t)
+	args, err := ffmpegArgs(job, "out.mp4")
+	if err != nil {
+		t.Fatalf("ffmpegArgs() error = %v", err)
+	}
+	joined := strings.Join(args, " ")
+
+	for _, want := range []string{
+		"-loop 1 -framerate 24 -t 3.000 -i " + job.Files["m1"].Path,
+		"-ss 0.500 -i " + job.Files["m2"].Path,
+		"-i " + job.Subtitles,
+		"[0:v]scale=64:36:force_original_aspect_ratio=decrease,pad=64:36:(ow-iw)/2:(oh-ih)/2:color=black,setsar=1,fps=24,trim=duration=3.000,setpts=PTS-STARTPTS,fade=t=in:st=0:d=0.500,format=yuv420p[v0]",
+		"[1:v]scale=64:36:force_original_aspect_ratio=decrease,pad=64:36:(ow-iw)/2:(oh-ih)/2:color=black,setsar=1,fps=24,tpad=stop_mode=clone:stop_duration=2.000,trim=duration=2.000,setpts=PTS-STARTPTS,format=yuv420p[v1]",
+		"[v0][v1]concat=n=2:v=1:a=0[out]",
+		"-map [out] -map 2:s -c:s mov_text",
+		"-movflags +faststart out.mp4",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("ffmpegArgs() missing %q\nargs: %s", want, joined)
+		}
+	}
+
+	job.Timeline.Audio = []export.AudioCue{
+		{Index: 1, MediaID: "a1", Start: 0.5, Duration: 1},
+		{Index: 2, MediaID: "a2", Start: 3.25, Duration: 1},
+	}
+	job.Files["a1"] = ClipFile{Path: "a1.wav"}
+	job.Files["a2"] = ClipFile{Path: "a2.wav"}
+	args, err = ffmpegArgs(job, "out.mp4")
+	if err != nil {
+		t.Fatalf("ffmpegArgs() with audio error = %v", err)
+	}
+	joined = strings.Join(args, " ")
+	for _, want := range []string{
+		"-i a1.wav -i a2.wav -i " + job.Subtitles,
+		"[2:a]aresample=48000,adelay=500|500[a0]",
+		"[3:a]aresample=48000,adelay=3250|3250[a1]",
+		"[a0][a1]amix=inputs=2:normalize=0,apad,atrim=duration=5.000[aout]",
+		"-map [out] -map [aout] -c:a aac -b:a 128k -map 4:s",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("ffmpegArgs() missing %q\nargs: %s", want, joined)
+		}
+	}
+
+	delete(job.Files, "a2")
+	if _, err := ffmpegArgs(job, "out.mp4"); !errors.Is(err, ErrMissingAudio) {
+		t.Errorf("missing audio error = %v, want ErrMissingAudio", err)
+	}
+	job.Timeline.Audio = nil
+
+	delete(job.Files, "m2")
+	if _, err := ffmpegArgs(job, "out.mp4"); !errors.Is(err, ErrMissingClip) {
+		t.Errorf("missing clip error = %v, want ErrMissingClip", err)
+	}
+	if _, err := ffmpegArgs(Job{}, "out.mp4"); !errors.Is(err, ErrEmptyTimeline) {
+		t.Errorf("empty timeline error = %v, want ErrEmptyTimeline", err)
+	}
+}
+
+func TestSequenceEncoder(t *testing.T) {
+	job := testJob(t)
+	voice := filepath.Join(job.WorkDir, "voice.wav")
+	if err := os.WriteFile(voice, []byte("RIFF"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	job.Timeline.Audio = []export.AudioCue{{Index: 1, MediaID: "a1", Start: 0.5, Duration: 1}}
+	job.Files["a1"] = ClipFile{Path: voice}
+
+	output, err := NewSequenceEncoder().Encode(context.Background(), job)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if output.Extension != ".zip" || output.MimeType != "application/zip" {
+		t.Errorf("output = %+v", output)
+	}
+
+	archive, err := zip.OpenReader(output.Path)
+	if err != nil {
+		t.Fatalf("open output: %v", err)
+	}
+	defer archive.Close()
+
+	files := make(map[string]*zip.File)
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+	// 淡入 0.5 秒按 8 帧/秒展开为 4 帧，加上两个片段各一帧
+	for i := 1; i <= 6; i++ {
+		name := fmt.Sprintf("frames/%06d.jpg", i)
+		f, ok := files[name]
+		if !ok {
+			t.Fatalf("missing %s", name)
+		}
+		rc, _ := f.Open()
+		frame, _, err := image.Decode(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("decode %s: %v", name, err)
+		}
+		if frame.Bounds().Dx() != 64 || frame.Bounds().Dy() != 36 {
+			t.Errorf("%s size = %v, want 64x36", name, frame.Bounds())
+		}
+		// 第一帧为黑场，片段帧中间为白色图片、两侧为黑边
+		r, _, _, _ := frame.At(32, 18).RGBA()
+		if i == 1 && r > 0x1000 {
+			t.Errorf("first fade frame centre = %v, want black", frame.At(32, 18))
+		}
+		if i >= 5 {
+			if r < 0xe000 {
+				t.Errorf("%s centre = %v, want white", name, frame.At(32, 18))
+			}
+			if edge, _, _, _ := frame.At(2, 18).RGBA(); edge > 0x1000 {
+				t.Errorf("%s edge = %v, want black letterbox", name, color.RGBAModel.Convert(frame.At(2, 18)))
+			}
+		}
+	}
+	if _, ok := files["frames/000007.jpg"]; ok {
+		t.Error("unexpected frame 7")
+	}
+
+	rc, err := files["sequence.ffconcat"].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	playlist, _ := io.ReadAll(rc)
+	rc.Close()
+	for _, want := range []string{
+		"ffconcat version 1.0\nfile frames/000001.jpg\nduration 0.125\n",
+		"file frames/000005.jpg\nduration 2.500\n",
+		"file frames/000006.jpg\nduration 2.000\nfile frames/000006.jpg\n",
+	} {
+		if !strings.Contains(string(playlist), want) {
+			t.Errorf("playlist missing %q:\n%s", want, playlist)
+		}
+	}
+	if _, ok := files["subtitles.srt"]; !ok {
+		t.Error("missing subtitles.srt")
+	}
+	if _, ok := files["audio/001.wav"]; !ok {
+		t.Error("missing audio/001.wav")
+	}
+}
+
+func TestFFmpegEncoder(t *testing.T) {
+	path, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		t.Skip("ffmpeg not installed")
+	}
+
+	// 视频片段换成第二张图片，避免依赖样例视频
+	job := testJob(t)
+	job.Timeline.Clips[1].Kind = export.ClipKindStill
+	job.Timeline.Clips[1].In = 0
+	job.Files["m2"] = ClipFile{Path: job.Files["m1"].Path}
+
+	output, err := NewFFmpegEncoder(path).Encode(context.Background(), job)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	data, err := os.ReadFile(output.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := ProbeMP4(data)
+	if err != nil {
+		t.Fatalf("ProbeMP4() error = %v", err)
+	}
+	if info.Width != 64 || info.Height != 36 || info.Duration < 4.5 || info.Duration > 5.5 {
+		t.Errorf("output info = %+v, want 64x36 about 5s", info)
+	}
+}
+
+func TestNewEncoder(t *testing.T) {
+	if _, err := NewEncoder("gif", "ffmpeg"); err == nil {
+		t.Error("NewEncoder(gif) error = nil")
+	}
+	encoder, err := NewEncoder(EncoderSequence, "ffmpeg")
+	if err != nil || encoder.Name() != EncoderSequence {
+		t.Errorf("NewEncoder(sequence) = %v, %v", encoder, err)
+	}
+	encoder, err = NewEncoder(EncoderAuto, "/nonexistent/ffmpeg")
+	if err != nil || encoder.Name() != EncoderSequence {
+		t.Errorf("NewEncoder(auto) without ffmpeg = %v, %v", encoder, err)
+	}
+	if _, err := NewEncoder(EncoderFFmpeg, "/nonexistent/ffmpeg"); err == nil {
+		t.Error("NewEncoder(ffmpeg) without ffmpeg error = nil")
+	}
+}
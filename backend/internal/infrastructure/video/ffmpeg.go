@@ -0,0 +1,139 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/xiajiayi/ai-motion/internal/domain/export"
+)
+
+// FFmpegEncoder 调用 ffmpeg 渲染 H.264 MP4：每个片段缩放并补黑边到统一画面尺寸后按顺序拼接，
+// 配音按时间线位置混合为 AAC 音轨，字幕作为可开关的 mov_text 字幕轨道写入，不烧录到画面
+type FFmpegEncoder struct {
+	path string
+}
+
+func NewFFmpegEncoder(path string) *FFmpegEncoder {
+	return &FFmpegEncoder{path: path}
+}
+
+func (e *FFmpegEncoder) Name() string {
+	return EncoderFFmpeg
+}
+
+// Encode 渲染 MP4，失败时错误中附带 ffmpeg 输出的最后几行
+func (e *FFmpegEncoder) Encode(ctx context.Context, job Job) (*Output, error) {
+	output := filepath.Join(job.WorkDir, "animatic.mp4")
+	args, err := ffmpegArgs(job, output)
+	if err != nil {
+		return nil, err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.path, args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg failed: %w: %s", err, lastLines(stderr.String(), 5))
+	}
+
+	return &Output{Path: output, Extension: ".mp4", MimeType: "video/mp4"}, nil
+}
+
+// ffmpegArgs 生成 ffmpeg 参数。图片循环输入为视频流；视频从入点截取，
+// 素材比时间线短时重复最后一帧补足，因此成片时长与时间线一致
+func ffmpegArgs(job Job, output string) ([]string, error) {
+	timeline := job.Timeline
+	if len(timeline.Clips) == 0 {
+		return nil, ErrEmptyTimeline
+	}
+
+	args := []string{"-hide_banner", "-loglevel", "error", "-y"}
+	var filters []string
+	var labels strings.Builder
+	for i, clip := range timeline.Clips {
+		file, err := job.clipFile(clip)
+		if err != nil {
+			return nil, err
+		}
+
+		duration := seconds(clip.Duration)
+		if clip.Kind == export.ClipKindStill {
+			args = append(args, "-loop", "1", "-framerate", strconv.Itoa(timeline.FPS), "-t", duration, "-i", file.Path)
+		} else {
+			args = append(args, "-ss", seconds(clip.In), "-i", file.Path)
+		}
+
+		chain := []string{
+			fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease", timeline.Width, timeline.Height),
+			fmt.Sprintf("pad=%d:%d:(ow-iw)/2:(oh-ih)/2:color=black", timeline.Width, timeline.Height),
+			"setsar=1",
+			fmt.Sprintf("fps=%d", timeline.FPS),
+		}
+		if clip.Kind == export.ClipKindVideo {
+			chain = append(chain, "tpad=stop_mode=clone:stop_duration="+duration)
+		}
+		chain = append(chain, "trim=duration="+duration, "setpts=PTS-STARTPTS")
+		if clip.Transition.Type == export.TransitionFade && clip.Transition.Duration > 0 {
+			chain = append(chain, "fade=t=in:st=0:d="+seconds(clip.Transition.Duration))
+		}
+		chain = append(chain, "format=yuv420p")
+
+		filters = append(filters, fmt.Sprintf("[%d:v]%s[v%d]", i, strings.Join(chain, ","), i))
+		fmt.Fprintf(&labels, "[v%d]", i)
+	}
+	filters = append(filters, fmt.Sprintf("%sconcat=n=%d:v=1:a=0[out]", labels.String(), len(timeline.Clips)))
+
+	// 配音输入排在片段之后，各自延迟到开始时间后混合，补静音并截断到成片时长
+	inputs := len(timeline.Clips)
+	if len(timeline.Audio) > 0 {
+		var audioLabels strings.Builder
+		for i, cue := range timeline.Audio {
+			file, err := job.audioFile(cue)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, "-i", file)
+			delay := strconv.FormatInt(int64(cue.Start*1000+0.5), 10)
+			filters = append(filters, fmt.Sprintf("[%d:a]aresample=48000,adelay=%s|%s[a%d]", inputs+i, delay, delay, i))
+			fmt.Fprintf(&audioLabels, "[a%d]", i)
+		}
+		filters = append(filters, fmt.Sprintf("%samix=inputs=%d:normalize=0,apad,atrim=duration=%s[aout]",
+			audioLabels.String(), len(timeline.Audio), seconds(timeline.Duration)))
+		inputs += len(timeline.Audio)
+	}
+
+	if job.Subtitles != "" {
+		args = append(args, "-i", job.Subtitles)
+	}
+	args = append(args, "-filter_complex", strings.Join(filters, ";"), "-map", "[out]")
+	if len(timeline.Audio) > 0 {
+		args = append(args, "-map", "[aout]", "-c:a", "aac", "-b:a", "128k")
+	}
+	if job.Subtitles != "" {
+		args = append(args, "-map", strconv.Itoa(inputs)+":s", "-c:s", "mov_text", "-metadata:s:s:0", "language=chi")
+	}
+	args = append(args,
+		"-c:v", "libx264", "-preset", "veryfast", "-crf", "23", "-pix_fmt", "yuv420p",
+		"-r", strconv.Itoa(timeline.FPS), "-movflags", "+faststart", output)
+
+	return args, nil
+}
+
+// seconds 格式化为 ffmpeg 接受的秒数
+func seconds(value float64) string {
+	return strconv.FormatFloat(value, 'f', 3, 64)
+}
+
+// lastLines 返回文本的最后 n 行
+func lastLines(text string, n int) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "; ")
+}
@@ -0,0 +1,117 @@
+package video
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var ErrInvalidMP4 = errors.New("invalid mp4 data")
+
+// MP4Info 从 MP4 容器头部读取的视频信息
+type MP4Info struct {
+	Duration float64 // 秒
+	Width    int
+	Height   int
+}
+
+// ProbeMP4 解析 moov/mvhd 得到时长、解析视频轨道的 tkhd 得到画面尺寸，不解码媒体数据
+func ProbeMP4(data []byte) (MP4Info, error) {
+	moov, ok := findBox(data, "moov")
+	if !ok {
+		return MP4Info{}, ErrInvalidMP4
+	}
+
+	mvhd, ok := findBox(moov, "mvhd")
+	if !ok {
+		return MP4Info{}, ErrInvalidMP4
+	}
+	duration, err := parseMvhd(mvhd)
+	if err != nil {
+		return MP4Info{}, err
+	}
+
+	info := MP4Info{Duration: duration}
+
+	// 音频轨道的宽高为 0，取第一个有画面尺寸的轨道
+	for _, trak := range findBoxes(moov, "trak") {
+		tkhd, ok := findBox(trak, "tkhd")
+		if !ok || len(tkhd) < 8 {
+			continue
+		}
+		width := int(binary.BigEndian.Uint32(tkhd[len(tkhd)-8:]) >> 16)
+		height := int(binary.BigEndian.Uint32(tkhd[len(tkhd)-4:]) >> 16)
+		if width > 0 && height > 0 {
+			info.Width, info.Height = width, height
+			break
+		}
+	}
+
+	return info, nil
+}
+
+// parseMvhd 时长 = duration / timescale，version 1 使用 64 位字段
+func parseMvhd(payload []byte) (float64, error) {
+	if len(payload) < 4 {
+		return 0, ErrInvalidMP4
+	}
+
+	var timescale uint32
+	var duration uint64
+	switch payload[0] {
+	case 0:
+		if len(payload) < 20 {
+			return 0, ErrInvalidMP4
+		}
+		timescale = binary.BigEndian.Uint32(payload[12:16])
+		duration = uint64(binary.BigEndian.Uint32(payload[16:20]))
+	case 1:
+		if len(payload) < 32 {
+			return 0, ErrInvalidMP4
+		}
+		timescale = binary.BigEndian.Uint32(payload[20:24])
+		duration = binary.BigEndian.Uint64(payload[24:32])
+	default:
+		return 0, ErrInvalidMP4
+	}
+
+	if timescale == 0 {
+		return 0, ErrInvalidMP4
+	}
+	return float64(duration) / float64(timescale), nil
+}
+
+func findBox(data []byte, boxType string) ([]byte, bool) {
+	boxes := findBoxes(data, boxType)
+	if len(boxes) == 0 {
+		return nil, false
+	}
+	return boxes[0], true
+}
+
+// findBoxes 返回当前层级中指定类型的所有 box 的内容（不含头部）
+func findBoxes(data []byte, boxType string) [][]byte {
+	var boxes [][]byte
+	for len(data) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(data[:4]))
+		header := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return boxes
+			}
+			size = binary.BigEndian.Uint64(data[8:16])
+			header = 16
+		}
+		if size < header || size > uint64(len(data)) {
+			return boxes
+		}
+
+		if string(data[4:8]) == boxType {
+			boxes = append(boxes, data[header:size])
+		}
+		data = data[size:]
+	}
+	return boxes
+}
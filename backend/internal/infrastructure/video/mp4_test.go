@@ -0,0 +1,63 @@
+package video
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func box(boxType string, payload ...[]byte) []byte {
+	content := bytes.Join(payload, nil)
+	data := make([]byte, 8, 8+len(content))
+	binary.BigEndian.PutUint32(data, uint32(8+len(content)))
+	copy(data[4:], boxType)
+	return append(data, content...)
+}
+
+func mvhd(timescale, duration uint32) []byte {
+	payload := make([]byte, 100)
+	binary.BigEndian.PutUint32(payload[12:], timescale)
+	binary.BigEndian.PutUint32(payload[16:], duration)
+	return box("mvhd", payload)
+}
+
+func tkhd(width, height uint32) []byte {
+	payload := make([]byte, 84)
+	binary.BigEndian.PutUint32(payload[76:], width<<16)
+	binary.BigEndian.PutUint32(payload[80:], height<<16)
+	return box("tkhd", payload)
+}
+
+func TestProbeMP4(t *testing.T) {
+	data := bytes.Join([][]byte{
+		box("ftyp", []byte("isom\x00\x00\x02\x00isomiso2mp41")),
+		box("moov",
+			mvhd(1000, 8500),
+			box("trak", tkhd(0, 0)), // 音频轨道
+			box("trak", tkhd(1280, 720)),
+		),
+		box("mdat", []byte("frames")),
+	}, nil)
+
+	info, err := ProbeMP4(data)
+	if err != nil {
+		t.Fatalf("ProbeMP4() error = %v", err)
+	}
+	if info.Duration != 8.5 || info.Width != 1280 || info.Height != 720 {
+		t.Errorf("ProbeMP4() = %+v, want 8.5s 1280x720", info)
+	}
+}
+
+func TestProbeMP4RejectsMissingMoov(t *testing.T) {
+	data := box("ftyp", []byte("isom"))
+	if _, err := ProbeMP4(data); !errors.Is(err, ErrInvalidMP4) {
+		t.Errorf("ProbeMP4() error = %v, want %v", err, ErrInvalidMP4)
+	}
+
+	// box 长度超出数据范围
+	truncated := box("moov", mvhd(1000, 1000))[:20]
+	if _, err := ProbeMP4(truncated); !errors.Is(err, ErrInvalidMP4) {
+		t.Errorf("ProbeMP4(truncated) error = %v, want %v", err, ErrInvalidMP4)
+	}
+}
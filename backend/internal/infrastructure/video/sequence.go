@@ -0,0 +1,188 @@
+package video
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/xiajiayi/ai-motion/internal/domain/export"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/imaging"
+)
+
+const (
+	// sequenceFadeFPS 图片序列中淡入转场的帧率，其余时间每个片段只有一帧
+	sequenceFadeFPS = 8
+	// sequenceQuality 帧图片的 JPEG 质量
+	sequenceQuality = 85
+)
+
+// SequenceEncoder 不依赖外部程序的编码器：每个片段渲染为一张补黑边的 JPEG（淡入转场展开为多帧），
+// 与 ffconcat 播放列表、字幕和配音一起打包为 zip，可用 ffmpeg -f concat 转换为视频。
+// 无法解码视频，视频片段使用 ClipFile.Poster，没有时为黑场
+type SequenceEncoder struct{}
+
+func NewSequenceEncoder() *SequenceEncoder {
+	return &SequenceEncoder{}
+}
+
+func (e *SequenceEncoder) Name() string {
+	return EncoderSequence
+}
+
+// Encode 输出 zip：frames/ 下的帧图片、sequence.ffconcat 播放列表，提供字幕时附带 subtitles.srt，
+// 配音按 AudioCue.Index 命名放在 audio/ 下，开始时间见时间线清单
+func (e *SequenceEncoder) Encode(ctx context.Context, job Job) (*Output, error) {
+	timeline := job.Timeline
+	if len(timeline.Clips) == 0 {
+		return nil, ErrEmptyTimeline
+	}
+
+	output := filepath.Join(job.WorkDir, "animatic.zip")
+	file, err := os.Create(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output: %w", err)
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+	var playlist bytes.Buffer
+	playlist.WriteString("ffconcat version 1.0\n")
+	var frames int
+	var last string
+	addFrame := func(frame image.Image, duration float64) error {
+		frames++
+		last = fmt.Sprintf("frames/%06d.jpg", frames)
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: last, Method: zip.Store})
+		if err != nil {
+			return err
+		}
+		if err := jpeg.Encode(w, frame, &jpeg.Options{Quality: sequenceQuality}); err != nil {
+			return fmt.Errorf("failed to encode frame: %w", err)
+		}
+		fmt.Fprintf(&playlist, "file %s\nduration %s\n", last, seconds(duration))
+		return nil
+	}
+
+	for _, clip := range timeline.Clips {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		frame, err := e.clipFrame(job, clip)
+		if err != nil {
+			return nil, err
+		}
+
+		remaining := clip.Duration
+		if clip.Transition.Type == export.TransitionFade && clip.Transition.Duration > 0 {
+			steps := int(math.Ceil(clip.Transition.Duration * sequenceFadeFPS))
+			for step := 0; step < steps; step++ {
+				if err := addFrame(fade(frame, float64(step)/float64(steps)), 1.0/sequenceFadeFPS); err != nil {
+					return nil, err
+				}
+			}
+			remaining -= float64(steps) / sequenceFadeFPS
+		}
+		if err := addFrame(frame, max(remaining, 1.0/sequenceFadeFPS)); err != nil {
+			return nil, err
+		}
+	}
+	// concat 分离器忽略最后一个条目的 duration，重复最后一帧使其生效
+	fmt.Fprintf(&playlist, "file %s\n", last)
+
+	w, err := zw.Create("sequence.ffconcat")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(playlist.Bytes()); err != nil {
+		return nil, err
+	}
+
+	if job.Subtitles != "" {
+		data, err := os.ReadFile(job.Subtitles)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read subtitles: %w", err)
+		}
+		w, err := zw.Create("subtitles.srt")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, cue := range timeline.Audio {
+		file, err := job.audioFile(cue)
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audio %d: %w", cue.Index, err)
+		}
+		w, err := zw.Create(fmt.Sprintf("audio/%03d%s", cue.Index, filepath.Ext(file)))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return &Output{Path: output, Extension: ".zip", MimeType: "application/zip"}, nil
+}
+
+// clipFrame 读取片段的图片并补黑边到画面尺寸
+func (e *SequenceEncoder) clipFrame(job Job, clip export.Clip) (*image.RGBA, error) {
+	file, err := job.clipFile(clip)
+	if err != nil {
+		return nil, err
+	}
+
+	path := file.Path
+	if clip.Kind == export.ClipKindVideo {
+		path = file.Poster
+	}
+	if path == "" {
+		frame := image.NewRGBA(image.Rect(0, 0, job.Timeline.Width, job.Timeline.Height))
+		draw.Draw(frame, frame.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+		return frame, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read clip %d: %w", clip.Index, err)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode clip %d: %w", clip.Index, err)
+	}
+	return imaging.Contain(img, job.Timeline.Width, job.Timeline.Height, color.Black), nil
+}
+
+// fade 按 level（0 为全黑，1 为原图）调暗画面
+func fade(src *image.RGBA, level float64) *image.RGBA {
+	dst := image.NewRGBA(src.Bounds())
+	for i := 0; i < len(src.Pix); i += 4 {
+		dst.Pix[i] = uint8(float64(src.Pix[i]) * level)
+		dst.Pix[i+1] = uint8(float64(src.Pix[i+1]) * level)
+		dst.Pix[i+2] = uint8(float64(src.Pix[i+2]) * level)
+		dst.Pix[i+3] = src.Pix[i+3]
+	}
+	return dst
+}
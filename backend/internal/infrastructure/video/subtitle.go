@@ -0,0 +1,53 @@
+package video
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/xiajiayi/ai-motion/internal/domain/export"
+)
+
+// WriteSRT 写入 SRT 字幕，时间码格式为 00:00:01,500
+func WriteSRT(w io.Writer, cues []export.Cue) error {
+	bw := bufio.NewWriter(w)
+	for i, cue := range cues {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		fmt.Fprintf(bw, "%d\n%s --> %s\n%s\n", i+1, timecode(cue.Start, ','), timecode(cue.End, ','), cueText(cue.Text))
+	}
+	return bw.Flush()
+}
+
+// WriteWebVTT 写入 WebVTT 字幕，时间码格式为 00:00:01.500
+func WriteWebVTT(w io.Writer, cues []export.Cue) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("WEBVTT\n")
+	for i, cue := range cues {
+		// WebVTT 正文中 & 和 < 有特殊含义，需要转义
+		text := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(cueText(cue.Text))
+		fmt.Fprintf(bw, "\n%d\n%s --> %s\n%s\n", i+1, timecode(cue.Start, '.'), timecode(cue.End, '.'), text)
+	}
+	return bw.Flush()
+}
+
+// timecode 将秒数格式化为 时:分:秒 加毫秒，separator 为秒和毫秒之间的分隔符
+func timecode(seconds float64, separator byte) string {
+	millis := int64(math.Round(max(seconds, 0) * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d",
+		millis/3600000, millis/60000%60, millis/1000%60, separator, millis%1000)
+}
+
+// cueText 去掉空行，空行在两种格式中都表示一条字幕结束
+func cueText(text string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
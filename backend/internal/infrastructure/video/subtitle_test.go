@@ -0,0 +1,39 @@
+package video
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/xiajiayi/ai-motion/internal/domain/export"
+)
+
+var testCues = []export.Cue{
+	{Index: 1, Start: 0, End: 1.5, Text: "夜色渐深。"},
+	{Index: 2, Start: 61.25, End: 3725.004, Text: "林：<你> & 我\n\n第二行"},
+}
+
+func TestWriteSRT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSRT(&buf, testCues); err != nil {
+		t.Fatalf("WriteSRT() error = %v", err)
+	}
+
+	want := "1\n00:00:00,000 --> 00:00:01,500\n夜色渐深。\n\n" +
+		"2\n00:01:01,250 --> 01:02:05,004\n林：<你> & 我\n第二行\n"
+	if buf.String() != want {
+		t.Errorf("WriteSRT() =\n%q\nwant\n%q", buf.String(), want)
+	}
+}
+
+func TestWriteWebVTT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteWebVTT(&buf, testCues); err != nil {
+		t.Fatalf("WriteWebVTT() error = %v", err)
+	}
+
+	want := "WEBVTT\n\n1\n00:00:00.000 --> 00:00:01.500\n夜色渐深。\n\n" +
+		"2\n00:01:01.250 --> 01:02:05.004\n林：&lt;你&gt; &amp; 我\n第二行\n"
+	if buf.String() != want {
+		t.Errorf("WriteWebVTT() =\n%q\nwant\n%q", buf.String(), want)
+	}
+}
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiajiayi/ai-motion/internal/application/service"
+)
+
+// CacheHandler 生成缓存统计处理器
+type CacheHandler struct {
+	cache *service.GenerationCache
+}
+
+func NewCacheHandler(cache *service.GenerationCache) *CacheHandler {
+	return &CacheHandler{
+		cache: cache,
+	}
+}
+
+// Stats 返回生成缓存的命中统计
+func (h *CacheHandler) Stats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    h.cache.Stats(),
+	})
+}
@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
 	"github.com/xiajiayi/ai-motion/internal/application/dto"
 	"github.com/xiajiayi/ai-motion/internal/application/service"
+	"github.com/xiajiayi/ai-motion/internal/domain/character"
 	"github.com/xiajiayi/ai-motion/internal/interfaces/http/response"
 )
 
@@ -61,13 +64,17 @@ func (h *CharacterHandler) Update(c *gin.Context) {
 		return
 	}
 
-	character, err := h.characterService.UpdateCharacter(c.Request.Context(), id, &req)
+	updated, err := h.characterService.UpdateCharacter(c.Request.Context(), id, &req)
+	if errors.Is(err, character.ErrInvalidVoice) {
+		response.InvalidParams(c, err.Error())
+		return
+	}
 	if err != nil {
 		response.InternalError(c, "Failed to update character: "+err.Error())
 		return
 	}
 
-	response.Success(c, character)
+	response.Success(c, updated)
 }
 
 func (h *CharacterHandler) Delete(c *gin.Context) {
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/storage"
+)
+
+// presignedURLExpiry 对象存储直链的有效期
+const presignedURLExpiry = time.Hour
+
+// FileHandler 按存储路径提供生成文件的访问，替代只能读取本地目录的静态文件服务；
+// 只接受接口响应中签发的签名地址，浏览器可直接通过 <img>/<video> 加载
+type FileHandler struct {
+	assets *storage.AssetStore
+	links  *storage.URLSigner
+}
+
+func NewFileHandler(assets *storage.AssetStore, links *storage.URLSigner) *FileHandler {
+	return &FileHandler{
+		assets: assets,
+		links:  links,
+	}
+}
+
+// Serve 输出存储中的文件；对象存储支持预签名时重定向到临时直链，避免文件经由本服务中转
+func (h *FileHandler) Serve(c *gin.Context) {
+	if err := h.links.Verify(c.Request.URL.Path, c.Request.URL.Query()); err != nil {
+		c.Status(http.StatusForbidden)
+		return
+	}
+
+	filePath := strings.TrimPrefix(c.Param("filepath"), "/")
+	ctx := c.Request.Context()
+
+	presigned, err := h.assets.PresignedURL(ctx, filePath, presignedURLExpiry)
+	if err == nil {
+		c.Redirect(http.StatusFound, presigned)
+		return
+	}
+	if !errors.Is(err, storage.ErrPresignUnsupported) {
+		h.handleError(c, filePath, err)
+		return
+	}
+
+	reader, err := h.assets.OpenPath(ctx, filePath)
+	if err != nil {
+		h.handleError(c, filePath, err)
+		return
+	}
+	defer reader.Close()
+
+	content, ok := reader.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			h.handleError(c, filePath, err)
+			return
+		}
+		content = bytes.NewReader(data)
+	}
+
+	if mimeType := mime.TypeByExtension(path.Ext(filePath)); mimeType != "" {
+		c.Header("Content-Type", mimeType)
+	}
+	c.Header("Cache-Control", mediaCacheControl)
+	http.ServeContent(c.Writer, c.Request, "", time.Time{}, content)
+}
+
+func (h *FileHandler) handleError(c *gin.Context, filePath string, err error) {
+	if errors.Is(err, storage.ErrFileNotFound) || errors.Is(err, storage.ErrAssetNotManaged) {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	slog.Error("Failed to serve file", "error", err, "path", filePath)
+	c.Status(http.StatusInternalServerError)
+}
@@ -1,9 +1,14 @@
 package handler
 
 import (
+	"errors"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/xiajiayi/ai-motion/internal/application/dto"
 	"github.com/xiajiayi/ai-motion/internal/application/service"
+	"github.com/xiajiayi/ai-motion/internal/domain/usage"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/middleware"
 	"github.com/xiajiayi/ai-motion/internal/interfaces/http/response"
 )
 
@@ -24,9 +29,19 @@ func (h *GenerationHandler) GenerateImage(c *gin.Context) {
 		return
 	}
 
-	result, err := h.generationService.GenerateSceneImage(c.Request.Context(), &req)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    20001,
+			"message": "未授权",
+			"data":    nil,
+		})
+		return
+	}
+
+	result, err := h.generationService.GenerateSceneImage(c.Request.Context(), userID, &req)
 	if err != nil {
-		response.GenerationError(c, "Failed to generate image: "+err.Error())
+		h.handleError(c, err, "Failed to generate image: ")
 		return
 	}
 
@@ -40,9 +55,19 @@ func (h *GenerationHandler) GenerateVideo(c *gin.Context) {
 		return
 	}
 
-	result, err := h.generationService.GenerateSceneVideo(c.Request.Context(), &req)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    20001,
+			"message": "未授权",
+			"data":    nil,
+		})
+		return
+	}
+
+	result, err := h.generationService.GenerateSceneVideo(c.Request.Context(), userID, &req)
 	if err != nil {
-		response.GenerationError(c, "Failed to generate video: "+err.Error())
+		h.handleError(c, err, "Failed to generate video: ")
 		return
 	}
 
@@ -56,9 +81,19 @@ func (h *GenerationHandler) BatchGenerate(c *gin.Context) {
 		return
 	}
 
-	result, err := h.generationService.BatchGenerateScenes(c.Request.Context(), &req)
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    20001,
+			"message": "未授权",
+			"data":    nil,
+		})
+		return
+	}
+
+	result, err := h.generationService.BatchGenerateScenes(c.Request.Context(), userID, &req)
 	if err != nil {
-		response.GenerationError(c, "Failed to batch generate: "+err.Error())
+		h.handleError(c, err, "Failed to batch generate: ")
 		return
 	}
 
@@ -80,3 +115,21 @@ func (h *GenerationHandler) GetStatus(c *gin.Context) {
 
 	response.Success(c, result)
 }
+
+// handleError 配额用尽返回 429，其他错误按生成失败处理
+func (h *GenerationHandler) handleError(c *gin.Context, err error, message string) {
+	var quotaErr *usage.QuotaError
+	if errors.As(err, &quotaErr) {
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"code":    20004,
+			"message": "用量配额已用尽: " + quotaMessage(quotaErr),
+			"data": gin.H{
+				"limit":   quotaErr.Limit,
+				"used":    quotaErr.Used,
+				"allowed": quotaErr.Allowed,
+			},
+		})
+		return
+	}
+	response.GenerationError(c, message+err.Error())
+}
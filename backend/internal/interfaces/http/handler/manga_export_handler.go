@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestExport 将已完成任务的页面导出为 CBZ、PDF 或 EPUB，导出在后台进行，
+// 返回的作业可通过 GetExport 查询进度和下载地址
+func (h *MangaWorkflowHandler) RequestExport(c *gin.Context) {
+	ctx, userID, ok := panelRequestContext(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.workflowService.RequestExport(ctx, userID, c.Param("task_id"), c.Query("format"))
+	if err != nil {
+		handlePanelError(c, err, 50001, "创建导出失败")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"code":    0,
+		"message": "导出已开始",
+		"data":    result,
+	})
+}
+
+// GetExport 查询导出进度，完成后返回下载地址
+func (h *MangaWorkflowHandler) GetExport(c *gin.Context) {
+	ctx, userID, ok := panelRequestContext(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.workflowService.GetExport(ctx, userID, c.Param("task_id"), c.Param("export_id"))
+	if err != nil {
+		handlePanelError(c, err, 50001, "查询导出失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    result,
+	})
+}
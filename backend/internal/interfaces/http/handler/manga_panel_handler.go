@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiajiayi/ai-motion/internal/application/dto"
+	"github.com/xiajiayi/ai-motion/internal/application/service"
+	"github.com/xiajiayi/ai-motion/internal/domain/export"
+	"github.com/xiajiayi/ai-motion/internal/domain/media"
+	"github.com/xiajiayi/ai-motion/internal/domain/page"
+	"github.com/xiajiayi/ai-motion/internal/domain/task"
+	"github.com/xiajiayi/ai-motion/internal/domain/usage"
+	"github.com/xiajiayi/ai-motion/internal/domain/voiceover"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/middleware"
+)
+
+// RegeneratePanel 重新生成已完成任务中的单个面板
+func (h *MangaWorkflowHandler) RegeneratePanel(c *gin.Context) {
+	var req dto.RegeneratePanelRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    10001,
+			"message": "参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	ctx, userID, ok := panelRequestContext(c)
+	if !ok {
+		return
+	}
+
+	version, err := h.workflowService.RegeneratePanel(ctx, userID, c.Param("task_id"), c.Param("media_id"), &req)
+	if err != nil {
+		handlePanelError(c, err, 40003, "重新生成面板失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "面板已重新生成",
+		"data":    version,
+	})
+}
+
+// ListPanels 列出任务的全部面板及其版本历史
+func (h *MangaWorkflowHandler) ListPanels(c *gin.Context) {
+	ctx, userID, ok := panelRequestContext(c)
+	if !ok {
+		return
+	}
+
+	panels, err := h.workflowService.ListPanels(ctx, userID, c.Param("task_id"))
+	if err != nil {
+		handlePanelError(c, err, 50001, "查询面板失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"panels": panels,
+		},
+	})
+}
+
+// ListPanelVersions 列出面板的全部图片版本
+func (h *MangaWorkflowHandler) ListPanelVersions(c *gin.Context) {
+	ctx, userID, ok := panelRequestContext(c)
+	if !ok {
+		return
+	}
+
+	versions, err := h.workflowService.ListPanelVersions(ctx, userID, c.Param("task_id"), c.Param("media_id"))
+	if err != nil {
+		handlePanelError(c, err, 50001, "查询面板版本失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    versions,
+	})
+}
+
+// ActivatePanelVersion 选定面板在任务结果中使用的图片版本
+func (h *MangaWorkflowHandler) ActivatePanelVersion(c *gin.Context) {
+	ctx, userID, ok := panelRequestContext(c)
+	if !ok {
+		return
+	}
+
+	version, err := h.workflowService.SelectPanelVersion(ctx, userID, c.Param("task_id"), c.Param("media_id"))
+	if err != nil {
+		handlePanelError(c, err, 50001, "切换面板版本失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "面板版本已切换",
+		"data":    version,
+	})
+}
+
+// ComposePages 将任务面板排版合成漫画页面，替换任务原有的页面
+func (h *MangaWorkflowHandler) ComposePages(c *gin.Context) {
+	var req dto.ComposePagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    10001,
+			"message": "参数错误: " + err.Error(),
+			"data":    nil,
+		})
+		return
+	}
+
+	ctx, userID, ok := panelRequestContext(c)
+	if !ok {
+		return
+	}
+
+	pages, err := h.workflowService.ComposePages(ctx, userID, c.Param("task_id"), &req)
+	if err != nil {
+		handlePanelError(c, err, 50002, "页面排版失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "页面已生成",
+		"data": gin.H{
+			"pages": pages,
+		},
+	})
+}
+
+// ListPages 查询任务当前的漫画页面
+func (h *MangaWorkflowHandler) ListPages(c *gin.Context) {
+	ctx, userID, ok := panelRequestContext(c)
+	if !ok {
+		return
+	}
+
+	pages, err := h.workflowService.ListPages(ctx, userID, c.Param("task_id"))
+	if err != nil {
+		handlePanelError(c, err, 50001, "查询页面失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"pages": pages,
+		},
+	})
+}
+
+// panelRequestContext 获取当前用户ID，并将JWT Token添加到context中
+func panelRequestContext(c *gin.Context) (context.Context, string, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    20001,
+			"message": "未授权",
+			"data":    nil,
+		})
+		return nil, "", false
+	}
+
+	jwtToken, _ := middleware.GetJWTToken(c)
+	return context.WithValue(c.Request.Context(), "jwt_token", jwtToken), userID, true
+}
+
+// handlePanelError 将面板和页面操作的错误转换为响应，未识别的错误使用 code 返回
+func handlePanelError(c *gin.Context, err error, code int, message string) {
+	var quotaErr *usage.QuotaError
+	switch {
+	case errors.Is(err, task.ErrTaskNotFound),
+		errors.Is(err, task.ErrPanelNotFound),
+		errors.Is(err, task.ErrPanelVersionNotFound),
+		errors.Is(err, media.ErrMediaNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    10002,
+			"message": "任务或面板不存在",
+			"data":    nil,
+		})
+	case errors.Is(err, export.ErrExportNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    10002,
+			"message": "导出不存在",
+			"data":    nil,
+		})
+	case errors.Is(err, export.ErrInvalidFormat):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    10001,
+			"message": "导出格式错误，支持 cbz、pdf、epub、animatic",
+			"data":    nil,
+		})
+	case errors.Is(err, task.ErrTaskNotCompleted):
+		c.JSON(http.StatusConflict, gin.H{
+			"code":    10001,
+			"message": "任务尚未完成，不能调整面板",
+			"data":    nil,
+		})
+	case errors.Is(err, service.ErrMediaNotReady):
+		c.JSON(http.StatusConflict, gin.H{
+			"code":    10001,
+			"message": "该版本尚未生成完成",
+			"data":    nil,
+		})
+	case errors.Is(err, page.ErrInvalidLayout):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    10001,
+			"message": "版式参数错误: " + err.Error(),
+			"data":    nil,
+		})
+	case errors.Is(err, page.ErrNoPanels):
+		c.JSON(http.StatusConflict, gin.H{
+			"code":    10001,
+			"message": "任务没有可排版的面板",
+			"data":    nil,
+		})
+	case errors.Is(err, voiceover.ErrNoDialogues):
+		c.JSON(http.StatusConflict, gin.H{
+			"code":    10001,
+			"message": "任务没有可配音的台词",
+			"data":    nil,
+		})
+	case errors.Is(err, service.ErrSpeechUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"code":    40004,
+			"message": "语音合成服务未配置",
+			"data":    nil,
+		})
+	case errors.Is(err, ai.ErrReferenceUnsupported):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    10001,
+			"message": "当前画风的图像服务不支持参考图",
+			"data":    nil,
+		})
+	case errors.As(err, &quotaErr):
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"code":    20004,
+			"message": "用量配额已用尽: " + quotaMessage(quotaErr),
+			"data": gin.H{
+				"limit":   quotaErr.Limit,
+				"used":    quotaErr.Used,
+				"allowed": quotaErr.Allowed,
+			},
+		})
+	case errors.Is(err, ai.ErrCircuitOpen):
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"code":    40004,
+			"message": "AI 服务暂时不可用，请稍后重试",
+			"data":    nil,
+		})
+	default:
+		slog.Error(message,
+			"error", err,
+			"task_id", c.Param("task_id"),
+			"media_id", c.Param("media_id"),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    code,
+			"message": message + ": " + err.Error(),
+			"data":    nil,
+		})
+	}
+}
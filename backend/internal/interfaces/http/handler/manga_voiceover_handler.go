@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GenerateVoiceover 为已完成任务的场景台词合成配音，替换任务原有的配音。同步执行，台词较多时耗时较长
+func (h *MangaWorkflowHandler) GenerateVoiceover(c *gin.Context) {
+	ctx, userID, ok := panelRequestContext(c)
+	if !ok {
+		return
+	}
+
+	tracks, err := h.workflowService.GenerateVoiceover(ctx, userID, c.Param("task_id"))
+	if err != nil {
+		handlePanelError(c, err, 50002, "合成配音失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "配音已生成",
+		"data": gin.H{
+			"scenes": tracks,
+		},
+	})
+}
+
+// GetVoiceover 查询任务当前的配音和时间
+func (h *MangaWorkflowHandler) GetVoiceover(c *gin.Context) {
+	ctx, userID, ok := panelRequestContext(c)
+	if !ok {
+		return
+	}
+
+	tracks, err := h.workflowService.GetVoiceover(ctx, userID, c.Param("task_id"))
+	if err != nil {
+		handlePanelError(c, err, 50001, "查询配音失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"scenes": tracks,
+		},
+	})
+}
@@ -2,16 +2,26 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xiajiayi/ai-motion/internal/application/dto"
 	"github.com/xiajiayi/ai-motion/internal/application/service"
+	"github.com/xiajiayi/ai-motion/internal/domain/task"
+	"github.com/xiajiayi/ai-motion/internal/domain/usage"
 	"github.com/xiajiayi/ai-motion/internal/infrastructure/middleware"
 )
 
+// sseKeepAliveInterval SSE 连接空闲时发送注释行的间隔，避免被代理断开
+const sseKeepAliveInterval = 15 * time.Second
+
 type MangaWorkflowHandler struct {
 	workflowService *service.MangaWorkflowService
 }
@@ -69,8 +79,29 @@ func (h *MangaWorkflowHandler) GenerateManga(c *gin.Context) {
 	)
 
 	// 3. 创建任务
-	task, err := h.workflowService.CreateTask(ctx, userID, &req)
+	taskEntity, err := h.workflowService.CreateTask(ctx, userID, &req)
 	if err != nil {
+		if errors.Is(err, task.ErrInvalidOptions) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    10001,
+				"message": "生成参数错误: " + err.Error(),
+				"data":    nil,
+			})
+			return
+		}
+		var quotaErr *usage.QuotaError
+		if errors.As(err, &quotaErr) {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"code":    20004,
+				"message": "用量配额已用尽: " + quotaMessage(quotaErr),
+				"data": gin.H{
+					"limit":   quotaErr.Limit,
+					"used":    quotaErr.Used,
+					"allowed": quotaErr.Allowed,
+				},
+			})
+			return
+		}
 		slog.Error("Failed to create task",
 			"error", err,
 			"user_id", userID,
@@ -85,21 +116,18 @@ func (h *MangaWorkflowHandler) GenerateManga(c *gin.Context) {
 	}
 
 	slog.Info("Task created successfully",
-		"task_id", task.ID,
+		"task_id", taskEntity.ID,
 		"user_id", userID,
 	)
 
-	// 4. 异步执行任务
-	go h.workflowService.ExecuteTask(context.Background(), task.ID)
-
-	// 5. 立即返回任务ID
+	// 4. 任务已持久化为 pending，由后台任务队列领取执行，立即返回任务ID
 	c.JSON(http.StatusOK, gin.H{
 		"code":    0,
 		"message": "任务已创建",
 		"data": gin.H{
-			"task_id":    task.ID,
-			"status":     task.Status,
-			"created_at": task.CreatedAt,
+			"task_id":    taskEntity.ID,
+			"status":     taskEntity.Status,
+			"created_at": taskEntity.CreatedAt,
 		},
 	})
 }
@@ -234,9 +262,13 @@ func (h *MangaWorkflowHandler) CancelTask(c *gin.Context) {
 	// 3. 取消任务
 	err := h.workflowService.CancelTask(ctx, userID, taskID)
 	if err != nil {
+		message := err.Error()
+		if errors.Is(err, task.ErrTaskNotCancellable) {
+			message = "任务已完成或已取消，无法取消"
+		}
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    10001,
-			"message": err.Error(),
+			"message": message,
 			"data":    nil,
 		})
 		return
@@ -252,3 +284,149 @@ func (h *MangaWorkflowHandler) CancelTask(c *gin.Context) {
 		},
 	})
 }
+
+// RetryTask 重试失败的任务
+func (h *MangaWorkflowHandler) RetryTask(c *gin.Context) {
+	// 1. 获取任务ID
+	taskID := c.Param("task_id")
+	if taskID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    10001,
+			"message": "任务ID不能为空",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 2. 获取当前用户ID
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    20001,
+			"message": "未授权",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 获取JWT Token并添加到context中
+	jwtToken, _ := middleware.GetJWTToken(c)
+	ctx := context.WithValue(c.Request.Context(), "jwt_token", jwtToken)
+
+	// 3. 重新入队
+	taskEntity, err := h.workflowService.RetryTask(ctx, userID, taskID)
+	if err != nil {
+		message := err.Error()
+		switch {
+		case errors.Is(err, task.ErrTaskNotRetryable):
+			message = "任务未失败或失败原因不可重试"
+		case errors.Is(err, task.ErrRetryLimitReached):
+			message = fmt.Sprintf("重试次数已达上限（%d 次）", task.MaxRetryCount)
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    10001,
+			"message": message,
+			"data":    nil,
+		})
+		return
+	}
+
+	// 4. 返回任务状态，由后台任务队列继续执行
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "任务已重新提交",
+		"data": dto.TaskRetryResponse{
+			TaskID:     taskEntity.ID,
+			Status:     string(taskEntity.Status),
+			RetryCount: taskEntity.RetryCount,
+		},
+	})
+}
+
+// StreamTaskEvents 通过 Server-Sent Events 推送任务进度
+// 支持 Last-Event-ID 请求头（或 last_event_id 查询参数）断线续传
+func (h *MangaWorkflowHandler) StreamTaskEvents(c *gin.Context) {
+	// 1. 获取任务ID
+	taskID := c.Param("task_id")
+	if taskID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    10001,
+			"message": "任务ID不能为空",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 2. 获取当前用户ID
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    20001,
+			"message": "未授权",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 3. 解析断线续传位置
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	var afterID int64
+	if lastEventID != "" {
+		parsed, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    10001,
+				"message": "Last-Event-ID 格式错误",
+				"data":    nil,
+			})
+			return
+		}
+		afterID = parsed
+	}
+
+	// 获取JWT Token并添加到context中
+	jwtToken, _ := middleware.GetJWTToken(c)
+	ctx := context.WithValue(c.Request.Context(), "jwt_token", jwtToken)
+
+	// 4. 订阅任务事件
+	events, err := h.workflowService.SubscribeTaskEvents(ctx, userID, taskID, afterID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    10002,
+			"message": "任务不存在",
+			"data":    nil,
+		})
+		return
+	}
+
+	// 5. 推送事件，空闲时定期发送注释行保持连接
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				slog.Error("Failed to marshal task event", "task_id", taskID, "error", err)
+				return true
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		return true
+	})
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiajiayi/ai-motion/internal/application/service"
+	"github.com/xiajiayi/ai-motion/internal/domain/media"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/middleware"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/storage"
+)
+
+// mediaCacheControl 媒体文件写入后不再变化，允许浏览器私有缓存
+const mediaCacheControl = "private, max-age=86400"
+
+// MediaHandler 媒体文件访问处理器；接口响应中的媒体地址带有签名，浏览器可不带 Authorization 头直接加载
+type MediaHandler struct {
+	mediaService *service.MediaService
+	links        *storage.URLSigner
+}
+
+func NewMediaHandler(mediaService *service.MediaService, links *storage.URLSigner) *MediaHandler {
+	return &MediaHandler{
+		mediaService: mediaService,
+		links:        links,
+	}
+}
+
+// Authenticate 签名有效的请求直接放行，其他请求交给 auth 校验登录
+func (h *MediaHandler) Authenticate(auth gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.signed(c) {
+			c.Next()
+			return
+		}
+		auth(c)
+	}
+}
+
+// GetContent 输出媒体文件，支持 Range 请求和条件请求（ETag/Last-Modified）
+func (h *MediaHandler) GetContent(c *gin.Context) {
+	var content *service.MediaContent
+	var err error
+	if h.signed(c) {
+		content, err = h.mediaService.GetSignedMediaContent(c.Request.Context(), c.Param("id"))
+	} else {
+		userID, ok := h.authorize(c)
+		if !ok {
+			return
+		}
+		jwtToken, _ := middleware.GetJWTToken(c)
+		ctx := context.WithValue(c.Request.Context(), "jwt_token", jwtToken)
+		content, err = h.mediaService.GetMediaContent(ctx, userID, c.Param("id"))
+	}
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	defer content.Close()
+
+	h.serve(c, content)
+}
+
+// GetThumbnail 输出图片媒体的缩略图，size 指定长边像素（默认 320，最大 1024）
+func (h *MediaHandler) GetThumbnail(c *gin.Context) {
+	size := service.DefaultThumbnailSize
+	if value := c.Query("size"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    10001,
+				"message": "size 参数必须为正整数",
+				"data":    nil,
+			})
+			return
+		}
+		size = parsed
+	}
+
+	var content *service.MediaContent
+	var err error
+	if h.signed(c) {
+		content, err = h.mediaService.GetSignedMediaThumbnail(c.Request.Context(), c.Param("id"), size)
+	} else {
+		userID, ok := h.authorize(c)
+		if !ok {
+			return
+		}
+		jwtToken, _ := middleware.GetJWTToken(c)
+		ctx := context.WithValue(c.Request.Context(), "jwt_token", jwtToken)
+		content, err = h.mediaService.GetMediaThumbnail(ctx, userID, c.Param("id"), size)
+	}
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	defer content.Close()
+
+	h.serve(c, content)
+}
+
+func (h *MediaHandler) serve(c *gin.Context, content *service.MediaContent) {
+	c.Header("Content-Type", content.MimeType)
+	c.Header("ETag", content.ETag)
+	c.Header("Cache-Control", mediaCacheControl)
+	if content.Filename != "" {
+		c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": content.Filename}))
+	}
+	http.ServeContent(c.Writer, c.Request, "", content.ModTime, content.Content)
+}
+
+// signed 请求地址是否带有有效签名，签名只覆盖路径，size 等参数不影响校验
+func (h *MediaHandler) signed(c *gin.Context) bool {
+	return h.links.Verify(c.Request.URL.Path, c.Request.URL.Query()) == nil
+}
+
+func (h *MediaHandler) authorize(c *gin.Context) (string, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    20001,
+			"message": "未授权",
+			"data":    nil,
+		})
+		return "", false
+	}
+	return userID, true
+}
+
+func (h *MediaHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, media.ErrMediaNotFound),
+		errors.Is(err, storage.ErrAssetNotManaged),
+		errors.Is(err, storage.ErrFileNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    10002,
+			"message": "媒体不存在或无权访问",
+			"data":    nil,
+		})
+	case errors.Is(err, service.ErrMediaNotReady):
+		c.JSON(http.StatusConflict, gin.H{
+			"code":    10001,
+			"message": "媒体尚未生成完成",
+			"data":    nil,
+		})
+	case errors.Is(err, service.ErrThumbnailUnsupported):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    10001,
+			"message": "该媒体不支持缩略图",
+			"data":    nil,
+		})
+	default:
+		slog.Error("Failed to read media", "error", err, "media_id", c.Param("id"))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    50002,
+			"message": "读取媒体失败",
+			"data":    nil,
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/xiajiayi/ai-motion/internal/application/service"
+	"github.com/xiajiayi/ai-motion/internal/domain/media"
+	"github.com/xiajiayi/ai-motion/internal/domain/task"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/middleware"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/storage"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/storage/local"
+	"github.com/xiajiayi/ai-motion/internal/interfaces/http/handler"
+)
+
+const testJWTSecret = "test-secret"
+
+type fakeMediaRepo struct {
+	media.MediaRepository
+	items map[media.MediaID]*media.Media
+}
+
+func (r *fakeMediaRepo) FindByID(ctx context.Context, id media.MediaID) (*media.Media, error) {
+	if m, ok := r.items[id]; ok {
+		return m, nil
+	}
+	return nil, media.ErrMediaNotFound
+}
+
+// fakeTaskRepo 用户 owner 拥有小说 novel-1 的任务
+type fakeTaskRepo struct {
+	task.Repository
+}
+
+func (r *fakeTaskRepo) ExistsByNovelIDAndUserID(ctx context.Context, novelID, userID string) (bool, error) {
+	return novelID == "novel-1" && userID == "owner", nil
+}
+
+func newMediaRouter(t *testing.T, data []byte) (*gin.Engine, *storage.URLSigner, string) {
+	t.Helper()
+	files, err := local.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStorage() error = %v", err)
+	}
+	assets := storage.NewAssetStore(files, "/files")
+	asset, err := assets.SaveFile(context.Background(), data, "voice", ".mp3", "audio/mpeg")
+	if err != nil {
+		t.Fatalf("SaveFile() error = %v", err)
+	}
+
+	m := media.NewMediaForNovel("novel-1", media.MediaTypeAudio)
+	m.MarkCompleted(asset.URL, media.MediaMetadata{Format: asset.MimeType})
+	repo := &fakeMediaRepo{items: map[media.MediaID]*media.Media{m.ID: m}}
+
+	links := storage.NewURLSigner("url-secret", time.Hour)
+	mediaService := service.NewMediaService(repo, nil, &fakeTaskRepo{}, assets)
+	mediaHandler := handler.NewMediaHandler(mediaService, links)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	group := router.Group("/api/v1/media")
+	group.Use(mediaHandler.Authenticate(middleware.NewAuthMiddleware(testJWTSecret).SupabaseAuth()))
+	group.GET("/:id/content", mediaHandler.GetContent)
+	group.GET("/:id/thumbnail", mediaHandler.GetThumbnail)
+
+	return router, links, string(m.ID)
+}
+
+func bearerToken(t *testing.T, userID string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": userID}).SignedString([]byte(testJWTSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + token
+}
+
+func TestMediaContentURLLoadsWithoutBearer(t *testing.T) {
+	data := []byte("0123456789abcdef")
+	router, links, mediaID := newMediaRouter(t, data)
+	signedURL := links.Sign("/api/v1/media/" + mediaID + "/content")
+
+	// 浏览器通过 <audio src> 加载：不带 Authorization 头
+	req := httptest.NewRequest(http.MethodGet, signedURL, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if got := w.Body.String(); got != string(data) {
+		t.Errorf("body = %q, want %q", got, data)
+	}
+	if got := w.Header().Get("Content-Type"); got != "audio/mpeg" {
+		t.Errorf("Content-Type = %q, want audio/mpeg", got)
+	}
+
+	// 播放器拖动进度时发起 Range 请求
+	req = httptest.NewRequest(http.MethodGet, signedURL, nil)
+	req.Header.Set("Range", "bytes=4-7")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusPartialContent {
+		t.Fatalf("range status = %d, want %d", w.Code, http.StatusPartialContent)
+	}
+	if got := w.Body.String(); got != "4567" {
+		t.Errorf("range body = %q, want %q", got, "4567")
+	}
+}
+
+func TestMediaContentAccess(t *testing.T) {
+	router, links, mediaID := newMediaRouter(t, []byte("audio"))
+	contentPath := "/api/v1/media/" + mediaID + "/content"
+	signedURL := links.Sign(contentPath)
+
+	tests := []struct {
+		name          string
+		url           string
+		authorization string
+		want          int
+	}{
+		{"signed", signedURL, "", http.StatusOK},
+		{"unsigned", contentPath, "", http.StatusUnauthorized},
+		{"tampered signature", strings.Replace(signedURL, "signature=", "signature=x", 1), "", http.StatusUnauthorized},
+		{"signature reused for another media", strings.Replace(signedURL, mediaID, "other", 1), "", http.StatusUnauthorized},
+		{"signed for content used on thumbnail", strings.Replace(signedURL, "/content", "/thumbnail", 1), "", http.StatusUnauthorized},
+		{"owner bearer", contentPath, bearerToken(t, "owner"), http.StatusOK},
+		{"other user bearer", contentPath, bearerToken(t, "stranger"), http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/ai"
+)
+
+// ProviderHandler AI 服务健康状态处理器
+type ProviderHandler struct {
+	breakers *ai.Breakers
+}
+
+func NewProviderHandler(breakers *ai.Breakers) *ProviderHandler {
+	return &ProviderHandler{
+		breakers: breakers,
+	}
+}
+
+// List 返回各 AI 服务的熔断状态
+func (h *ProviderHandler) List(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"healthy":   h.breakers.Healthy(),
+			"providers": h.breakers.Health(),
+		},
+	})
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xiajiayi/ai-motion/internal/application/service"
+	"github.com/xiajiayi/ai-motion/internal/domain/usage"
+	"github.com/xiajiayi/ai-motion/internal/infrastructure/middleware"
+)
+
+// UsageHandler 用量查询处理器
+type UsageHandler struct {
+	usageService *service.UsageService
+}
+
+func NewUsageHandler(usageService *service.UsageService) *UsageHandler {
+	return &UsageHandler{
+		usageService: usageService,
+	}
+}
+
+// GetUsage 返回当前用户本日和本月的 AI 调用用量及剩余配额
+func (h *UsageHandler) GetUsage(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    20001,
+			"message": "未授权",
+			"data":    nil,
+		})
+		return
+	}
+
+	summary, err := h.usageService.GetSummary(c.Request.Context(), userID)
+	if err != nil {
+		slog.Error("Failed to get usage summary",
+			"error", err,
+			"user_id", userID,
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    50001,
+			"message": "查询用量失败",
+			"data":    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    summary,
+	})
+}
+
+// quotaMessage 描述超出的配额
+func quotaMessage(err *usage.QuotaError) string {
+	if err.Limit == "monthly_cost" {
+		return fmt.Sprintf("本月估算费用 $%.2f，上限 $%.2f", err.Used, err.Allowed)
+	}
+	return fmt.Sprintf("今日已生成 %.0f 次，上限 %.0f 次", err.Used, err.Allowed)
+}